@@ -1,10 +1,12 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"github.com/gogodjzhu/word-flow/pkg/cmd/root"
 	"github.com/gogodjzhu/word-flow/pkg/cmdutil"
 	"os"
+	"os/signal"
 )
 
 type exitCode int
@@ -27,7 +29,10 @@ func mainRun() exitCode {
 		fmt.Println(err)
 		return exitError
 	}
-	if _, err := mainCmd.ExecuteC(); err != nil {
+	// Ctrl-C cancels in-flight lookups and translations
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if _, err := mainCmd.ExecuteContextC(ctx); err != nil {
 		return exitError
 	}
 	return exitOK
@@ -32,6 +32,13 @@ func HttpError(msg string) BuzzError {
 	}
 }
 
+func PluginError(msg string) BuzzError {
+	return BuzzError{
+		Code:    CodePluginError,
+		Message: "Plugin error: " + msg,
+	}
+}
+
 const (
 	CodeSuccess      = 0
 	CodeUnknownError = 1
@@ -40,6 +47,8 @@ const (
 	CodeInvalidEndpoint = 1002
 
 	CodeHttpError = 2001
+
+	CodePluginError = 3001
 )
 
 const (
@@ -50,4 +59,6 @@ const (
 	MsgInvalidEndpoint = "Invalid endpoint"
 
 	MsgHttpError = "Http error"
+
+	MsgPluginError = "Plugin error"
 )
@@ -0,0 +1,228 @@
+// Package cassette records and replays the HTTP traffic of dictionary and
+// translator tests. A cassette is a golden YAML file in the testdata directory
+// of the package under test. Tests replay it offline; running them with
+// -update sends the requests to the live services and rewrites the cassette:
+//
+//	go test ./pkg/dict/youdao -update
+package cassette
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gogodjzhu/word-flow/internal/util"
+	"gopkg.in/yaml.v3"
+)
+
+var update = flag.Bool("update", false, "record cassettes from the live services")
+
+// redacted replaces the values of ignored parameters in recorded requests.
+const redacted = "REDACTED"
+
+// Cassette is an http.RoundTripper that replays recorded interactions, or
+// records them with -update.
+type Cassette struct {
+	Interactions []*Interaction `yaml:"interactions"`
+
+	path   string
+	ignore []string
+	record bool
+
+	mu     sync.Mutex
+	played map[*Interaction]bool
+}
+
+// Interaction is a recorded request and its response.
+type Interaction struct {
+	Request  Request  `yaml:"request"`
+	Response Response `yaml:"response"`
+}
+
+type Request struct {
+	Method string `yaml:"method"`
+	URL    string `yaml:"url"`
+	Body   string `yaml:"body,omitempty"`
+}
+
+type Response struct {
+	Status      int    `yaml:"status"`
+	ContentType string `yaml:"content_type,omitempty"`
+	Body        string `yaml:"body"`
+}
+
+// Load returns the cassette testdata/<name>.yaml. The query and form
+// parameters in ignore, such as API keys, signatures and salts, are redacted
+// when recording and not compared when replaying.
+func Load(t testing.TB, name string, ignore ...string) *Cassette {
+	t.Helper()
+	c := &Cassette{
+		path:   filepath.Join("testdata", name+".yaml"),
+		ignore: ignore,
+		record: *update,
+		played: make(map[*Interaction]bool),
+	}
+	if c.record {
+		t.Cleanup(func() {
+			if err := c.save(); err != nil {
+				t.Errorf("failed to save cassette: %v", err)
+			}
+		})
+		return c
+	}
+	data, err := os.ReadFile(c.path)
+	if err != nil {
+		t.Fatalf("failed to read cassette, record it with -update: %v", err)
+	}
+	if err := yaml.Unmarshal(data, c); err != nil {
+		t.Fatalf("invalid cassette %s: %v", c.path, err)
+	}
+	return c
+}
+
+// Context returns ctx with the cassette as transport of util.HTTPClientBuilder.
+func (c *Cassette) Context(ctx context.Context) context.Context {
+	return util.WithTransport(ctx, c)
+}
+
+// Recording reports whether the cassette talks to the live services.
+func (c *Cassette) Recording() bool {
+	return c.record
+}
+
+func (c *Cassette) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+	}
+	request := Request{
+		Method: req.Method,
+		URL:    c.redactURL(req.URL),
+		Body:   c.redactBody(req.Header.Get("Content-Type"), string(body)),
+	}
+	if c.record {
+		return c.forward(req, body, request)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// Identical requests replay their interactions in order, the last one
+	// repeatedly.
+	var match *Interaction
+	for _, interaction := range c.Interactions {
+		if interaction.Request != request {
+			continue
+		}
+		match = interaction
+		if !c.played[interaction] {
+			break
+		}
+	}
+	if match == nil {
+		return nil, fmt.Errorf("cassette %s has no interaction for %s %s, record it with -update", c.path, request.Method, request.URL)
+	}
+	c.played[match] = true
+	return match.Response.http(req), nil
+}
+
+// forward sends the request to the live service and records the interaction.
+func (c *Cassette) forward(req *http.Request, body []byte, request Request) (*http.Response, error) {
+	live := req.Clone(req.Context())
+	live.Body = io.NopCloser(bytes.NewReader(body))
+	transport := util.Transport()
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	resp, err := transport.RoundTrip(live)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	interaction := &Interaction{
+		Request: request,
+		Response: Response{
+			Status:      resp.StatusCode,
+			ContentType: resp.Header.Get("Content-Type"),
+			Body:        string(data),
+		},
+	}
+	c.mu.Lock()
+	c.Interactions = append(c.Interactions, interaction)
+	c.mu.Unlock()
+	return interaction.Response.http(req), nil
+}
+
+func (c *Cassette) save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return err
+	}
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(c.path, data, 0644)
+}
+
+func (r Response) http(req *http.Request) *http.Response {
+	header := make(http.Header)
+	if r.ContentType != "" {
+		header.Set("Content-Type", r.ContentType)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", r.Status, http.StatusText(r.Status)),
+		StatusCode:    r.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(r.Body)),
+		ContentLength: int64(len(r.Body)),
+		Request:       req,
+	}
+}
+
+func (c *Cassette) redactURL(u *url.URL) string {
+	redactedURL := *u
+	redactedURL.RawQuery = c.redactValues(u.Query())
+	return redactedURL.String()
+}
+
+func (c *Cassette) redactBody(contentType, body string) string {
+	if !strings.HasPrefix(contentType, "application/x-www-form-urlencoded") {
+		return body
+	}
+	values, err := url.ParseQuery(body)
+	if err != nil {
+		return body
+	}
+	return c.redactValues(values)
+}
+
+// redactValues encodes values sorted by key with the ignored ones redacted.
+func (c *Cassette) redactValues(values url.Values) string {
+	for _, key := range c.ignore {
+		if values.Has(key) {
+			values.Set(key, redacted)
+		}
+	}
+	return values.Encode()
+}
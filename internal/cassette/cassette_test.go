@@ -0,0 +1,85 @@
+package cassette
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gogodjzhu/word-flow/internal/util"
+	"gopkg.in/yaml.v3"
+)
+
+func read(response *http.Response) (interface{}, error) {
+	defer response.Body.Close()
+	body, err := io.ReadAll(response.Body)
+	return response.Status + " " + string(body), err
+}
+
+func TestCassette_Replay(t *testing.T) {
+	c := Load(t, "example", "key", "sign")
+	ctx := c.Context(context.Background())
+
+	// Identical requests replay their interactions in order, the last one repeatedly.
+	for _, want := range []string{"503 Service Unavailable busy", `200 OK {"word":"hello"}`, `200 OK {"word":"hello"}`} {
+		got, err := util.SendGetContext(ctx, "https://api.example.com/lookup?q=hello&key=secret", nil, read)
+		if err != nil || got != want {
+			t.Errorf("SendGetContext() = %v, %v, want %q", got, err, want)
+		}
+	}
+
+	got, err := util.SendPostContext(ctx, "https://api.example.com/translate",
+		map[string]string{"Content-Type": "application/x-www-form-urlencoded"}, []byte("sign=abc&q=hello"), read)
+	if err != nil || got != "200 OK 你好" {
+		t.Errorf("SendPostContext() = %v, %v", got, err)
+	}
+
+	if _, err := util.SendGetContext(ctx, "https://api.example.com/lookup?q=other", nil, read); err == nil || !strings.Contains(err.Error(), "-update") {
+		t.Errorf("unrecorded request error = %v", err)
+	}
+}
+
+func TestCassette_Record(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte("recorded " + r.URL.Query().Get("q")))
+	}))
+	defer server.Close()
+
+	c := &Cassette{
+		path:   filepath.Join(t.TempDir(), "testdata", "recorded.yaml"),
+		ignore: []string{"key"},
+		record: true,
+		played: make(map[*Interaction]bool),
+	}
+	got, err := util.SendGetContext(c.Context(context.Background()), server.URL+"/?q=hello&key=secret", nil, read)
+	if err != nil || got != "200 OK recorded hello" {
+		t.Fatalf("SendGetContext() = %v, %v", got, err)
+	}
+	if err := c.save(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(c.path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "secret") {
+		t.Errorf("cassette leaks the key:\n%s", data)
+	}
+	var saved Cassette
+	if err := yaml.Unmarshal(data, &saved); err != nil {
+		t.Fatal(err)
+	}
+	want := Interaction{
+		Request:  Request{Method: "GET", URL: server.URL + "/?key=REDACTED&q=hello"},
+		Response: Response{Status: 200, ContentType: "text/plain", Body: "recorded hello"},
+	}
+	if len(saved.Interactions) != 1 || *saved.Interactions[0] != want {
+		t.Errorf("saved interactions = %+v", saved.Interactions)
+	}
+}
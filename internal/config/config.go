@@ -42,47 +42,96 @@ func (d *Duration) UnmarshalYAML(value *yaml.Node) error {
 	}
 }
 
+// HTTPConfig makes the requests of an online endpoint resilient to transient
+// upstream failures. Zero values get defaults, -1 disables retries or the
+// circuit breaker.
+type HTTPConfig struct {
+	Retries int      `yaml:"retries,omitempty"`
+	Backoff Duration `yaml:"backoff,omitempty"`
+	// RateLimit is the number of requests per second to the host of the
+	// endpoint, zero is unlimited.
+	RateLimit float64 `yaml:"rate_limit,omitempty"`
+	Burst     int     `yaml:"burst,omitempty"`
+	// BreakerFailures failed requests in a row open the circuit of the host
+	// for BreakerCooldown, failing requests at once so the fallback is used.
+	BreakerFailures int      `yaml:"breaker_failures,omitempty"`
+	BreakerCooldown Duration `yaml:"breaker_cooldown,omitempty"`
+}
+
+// DefaultTimeout sets an unset endpoint timeout.
+func DefaultTimeout(timeout *Duration, d time.Duration) {
+	if *timeout == 0 {
+		*timeout = Duration(d)
+	}
+}
+
+// DefaultHTTP fills in the unset HTTP settings of an online endpoint, with
+// rateLimit requests per second for a new section.
+func DefaultHTTP(c **HTTPConfig, rateLimit float64) {
+	if *c == nil {
+		*c = &HTTPConfig{RateLimit: rateLimit}
+	}
+	h := *c
+	if h.Retries == 0 {
+		h.Retries = 2
+	}
+	if h.Backoff == 0 {
+		h.Backoff = Duration(500 * time.Millisecond)
+	}
+	if h.Burst == 0 {
+		h.Burst = 1
+	}
+	if h.BreakerFailures == 0 {
+		h.BreakerFailures = 5
+	}
+	if h.BreakerCooldown == 0 {
+		h.BreakerCooldown = Duration(30 * time.Second)
+	}
+}
+
 type Config struct {
 	Version  string          `yaml:"version"`
 	Common   *CommonConfig   `yaml:"-"`
 	Dict     *DictConfig     `yaml:"dict"`
 	Trans    *TransConfig    `yaml:"trans"`
 	Notebook *NotebookConfig `yaml:"notebook"`
+	Network  *NetworkConfig  `yaml:"network,omitempty"`
 }
 
 type TransConfig struct {
-	Default string              `yaml:"default"`
-	LLM     *TransLLMConfig     `yaml:"llm"`
-	Google  *TransGoogleConfig  `yaml:"google"`
-	Baidu   *TransBaiduConfig   `yaml:"baidu"`
+	Default  string             `yaml:"default"`
+	Memory   *TransMemoryConfig `yaml:"memory,omitempty"`
+	Glossary string             `yaml:"glossary,omitempty"`
+	// Endpoints holds the sections of the registered translators.
+	Endpoints map[string]EndpointConfig `yaml:"-"`
 }
 
-type TransBaiduConfig struct {
-	AppID  string `yaml:"app_id"`
-	Secret string `yaml:"secret"`
+// GetEndpointConfig returns the section of a registered translator endpoint.
+func (tc *TransConfig) GetEndpointConfig(endpoint string) (TransEndpointConfig, error) {
+	spec := transEndpoints.lookup(endpoint)
+	if spec == nil {
+		return nil, fmt.Errorf("unknown endpoint: %s", endpoint)
+	}
+	return transEndpoints.section(tc.Endpoints, spec), nil
 }
 
-func (tbc *TransBaiduConfig) Validate() error {
-	if tbc.AppID == "" {
-		return errors.New("trans.baidu.app_id is required")
-	}
-	if tbc.Secret == "" {
-		return errors.New("trans.baidu.secret is required")
+func (tc *TransConfig) UnmarshalYAML(value *yaml.Node) error {
+	type plain TransConfig
+	if err := value.Decode((*plain)(tc)); err != nil {
+		return err
 	}
-	return nil
+	endpoints, err := transEndpoints.decode(value)
+	tc.Endpoints = endpoints
+	return err
 }
 
-func (tc *TransConfig) GetEndpointConfig(endpoint string) (TransEndpointConfig, error) {
-	switch endpoint {
-	case "llm":
-		return tc.LLM, nil
-	case "google":
-		return tc.Google, nil
-	case "baidu":
-		return tc.Baidu, nil
-	default:
-		return nil, fmt.Errorf("unknown endpoint: %s", endpoint)
+func (tc *TransConfig) MarshalYAML() (interface{}, error) {
+	type plain TransConfig
+	var node yaml.Node
+	if err := node.Encode((*plain)(tc)); err != nil {
+		return nil, err
 	}
+	return &node, transEndpoints.encode(&node, tc.Endpoints)
 }
 
 type CommonConfig struct {
@@ -91,32 +140,38 @@ type CommonConfig struct {
 }
 
 type DictConfig struct {
-	Default   string            `yaml:"default"`
-	LLM       *LLMConfig        `yaml:"llm"`
-	Youdao    *YoudaoConfig     `yaml:"youdao"`
-	Ecdict    *EcdictConfig     `yaml:"ecdict"`
-	Etymoline *EtymonlineConfig `yaml:"etymonline"`
-	MWebster  *MWebsterConfig   `yaml:"mwebster"`
-	Google    *GoogleConfig     `yaml:"google"`
+	Default  string   `yaml:"default"`
+	Fallback []string `yaml:"fallback,omitempty"`
+	// Endpoints holds the sections of the registered dictionaries.
+	Endpoints map[string]EndpointConfig `yaml:"-"`
 }
 
+// GetEndpointConfig returns the section of a registered dictionary endpoint.
 func (dc *DictConfig) GetEndpointConfig(endpoint string) (DictEndpointConfig, error) {
-	switch endpoint {
-	case "youdao":
-		return dc.Youdao, nil
-	case "llm":
-		return dc.LLM, nil
-	case "ecdict":
-		return dc.Ecdict, nil
-	case "etymonline":
-		return dc.Etymoline, nil
-	case "mwebster":
-		return dc.MWebster, nil
-	case "google":
-		return dc.Google, nil
-	default:
+	spec := dictEndpoints.lookup(endpoint)
+	if spec == nil {
 		return nil, fmt.Errorf("unknown endpoint: %s", endpoint)
 	}
+	return dictEndpoints.section(dc.Endpoints, spec), nil
+}
+
+func (dc *DictConfig) UnmarshalYAML(value *yaml.Node) error {
+	type plain DictConfig
+	if err := value.Decode((*plain)(dc)); err != nil {
+		return err
+	}
+	endpoints, err := dictEndpoints.decode(value)
+	dc.Endpoints = endpoints
+	return err
+}
+
+func (dc *DictConfig) MarshalYAML() (interface{}, error) {
+	type plain DictConfig
+	var node yaml.Node
+	if err := node.Encode((*plain)(dc)); err != nil {
+		return nil, err
+	}
+	return &node, dictEndpoints.encode(&node, dc.Endpoints)
 }
 
 type NotebookConfig struct {
@@ -144,81 +199,36 @@ const DefaultConfigVersion = "v1"
 
 func DefaultConfig() *Config {
 	dir := configDir()
-	return &Config{
-		Version: DefaultConfigVersion,
-		Common: &CommonConfig{
-			BasePath:       dir,
-			ConfigFilename: filepath.Join(dir, "config.yaml"),
-		},
-		Dict: &DictConfig{
-			Default:   "youdao",
-			LLM:       &LLMConfig{Timeout: Duration(30 * time.Second), MaxTokens: 2000, Temperature: 0.3},
-			Youdao:    &YoudaoConfig{},
-			Ecdict:    &EcdictConfig{},
-			Etymoline: &EtymonlineConfig{},
-			MWebster:  &MWebsterConfig{},
-		},
-		Notebook: &NotebookConfig{
-			Default:  "default",
-			Settings: &NotebookSettings{MaxReviews: 50, NewCardsPerDay: 20},
-		},
-	}
+	cfg := &Config{}
+	applyDefaults(cfg, filepath.Join(dir, "config.yaml"))
+	return cfg
 }
 
+// ConfigTemplate returns the commented config file written on the first run,
+// with a section for every registered endpoint.
 func ConfigTemplate() string {
-	return configTemplate
-}
-
-const configTemplate = `# Wordflow configuration
+	return `# Wordflow configuration
 version: v1
 
 dict:
-  # Default dictionary endpoint. Options: youdao, llm, ecdict, etymonline, mwebster, google
+  # Default dictionary endpoint. Options: ` + strings.Join(DictEndpointNames(), ", ") + `
   default: youdao
-
-  youdao: {}
-
-  llm:
-    # LLM provider settings (required if dict.default is llm or using trans command)
-    # api_key: ""           # Required. Set via WORDFLOW_DICT_LLM_API_KEY or wordflow config set dict.llm.api_key
-    # url: ""               # Required. Full API endpoint URL, not base URL. e.g. https://dashscope.aliyuncs.com/compatible-mode/v1/chat/completions
-    # model: ""             # Required. LLM model name, e.g. glm-4
-    timeout: 30s
-    max_tokens: 2000
-    temperature: 0.3
-
-  ecdict:
-    # Local dictionary database path. Defaults to <WORDFLOW_HOME>/stardict.db if empty
-    # db_filename: ""
-
-  etymonline: {}
-
-  mwebster:
-    # Merriam-Webster API key (required if using mwebster)
-    # key: ""
-
-  google: {}
-
+  # Endpoints tried in order when the default one does not know the word or fails, e.g. [youdao, ecdict]
+  # fallback: []
+` + dictEndpoints.template() + `
 trans:
-  # Default translator endpoint. Options: baidu, google, llm
+  # Default translator endpoint. Options: ` + strings.Join(TransEndpointNames(), ", ") + `
   default: baidu
-
-  google: {}
-
-  baidu:
-    # Baidu Translate API credentials (required if trans.default is baidu)
-    # app_id: ""           # Required. Set via WORDFLOW_TRANS_BAIDU_APP_ID or wordflow config set trans.baidu.app_id
-    # secret: ""            # Required. Set via WORDFLOW_TRANS_BAIDU_SECRET or wordflow config set trans.baidu.secret
-
-  # llm:
-  #   # LLM provider settings for translation (required if trans.default is llm)
-  #   # api_key: ""           # Required. Set via WORDFLOW_TRANS_LLM_API_KEY or wordflow config set trans.llm.api_key
-  #   # url: ""               # Required. Full API endpoint URL
-  #   # model: ""             # Required. LLM model name
-  #   timeout: 30s
-  #   max_tokens: 2000
-  #   temperature: 0.3
-
+  # Translation memory, reused segment by segment. Share it with: wordflow tm export / import
+  memory:
+    disabled: false
+    # Defaults to <WORDFLOW_HOME>/tm.db if empty
+    # db_filename: ""
+    fuzzy_threshold: 0.8   # Minimum similarity of the fuzzy matches offered for reuse
+  # Terms every translation must follow, a TSV (term<TAB>translation) or TBX file.
+  # Relative to <WORDFLOW_HOME>. Terms without a translation stay untranslated
+  # glossary: terms.tsv
+` + transEndpoints.template() + `
 notebook:
   default: default
 
@@ -227,7 +237,20 @@ notebook:
     # basepath: ""
     max_reviews_per_session: 50
     new_cards_per_day: 20
+
+# Proxy, TLS and headers of all network requests
+# network:
+#   proxy: socks5://127.0.0.1:1080   # http(s):// or socks5://. Empty uses HTTPS_PROXY, "direct" ignores it
+#   ca_file: /etc/ssl/certs/corp.pem # Extra CA bundle (PEM) trusted in addition to the system roots
+#   insecure_skip_verify: false      # Skip TLS verification, only for test labs
+#   user_agent: ""                   # Replaces the User-Agent of all requests
+#   endpoints:
+#     trans.llm:
+#       headers:
+#         X-Team: translations
+#       user_agent: ""
 `
+}
 
 func ConfigFilePath() string {
 	return filepath.Join(configDir(), "config.yaml")
@@ -245,7 +268,7 @@ func InitConfig(configFilename string) error {
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return errors.Wrap(err, fmt.Sprintf("failed to create config dir: %s", dir))
 	}
-	return os.WriteFile(configFilename, []byte(configTemplate), 0644)
+	return os.WriteFile(configFilename, []byte(ConfigTemplate()), 0644)
 }
 
 func ReadConfig() (*Config, error) {
@@ -289,56 +312,25 @@ func applyDefaults(cfg *Config, configFilename string) {
 	if cfg.Dict.Default == "" {
 		cfg.Dict.Default = "youdao"
 	}
-	if cfg.Dict.LLM == nil {
-		cfg.Dict.LLM = &LLMConfig{}
-	}
-	if cfg.Dict.LLM.Timeout == 0 {
-		cfg.Dict.LLM.Timeout = Duration(30 * time.Second)
-	}
-	if cfg.Dict.LLM.MaxTokens == 0 {
-		cfg.Dict.LLM.MaxTokens = 2000
-	}
-	if cfg.Dict.LLM.Temperature == 0 {
-		cfg.Dict.LLM.Temperature = 0.3
-	}
-	if cfg.Dict.Youdao == nil {
-		cfg.Dict.Youdao = &YoudaoConfig{}
-	}
-	if cfg.Dict.Ecdict == nil {
-		cfg.Dict.Ecdict = &EcdictConfig{}
-	}
-	if cfg.Dict.Etymoline == nil {
-		cfg.Dict.Etymoline = &EtymonlineConfig{}
-	}
-	if cfg.Dict.MWebster == nil {
-		cfg.Dict.MWebster = &MWebsterConfig{}
-	}
-	if cfg.Dict.Google == nil {
-		cfg.Dict.Google = &GoogleConfig{}
-	}
+	dictEndpoints.applyDefaults(&cfg.Dict.Endpoints, dir)
 	if cfg.Trans == nil {
 		cfg.Trans = &TransConfig{}
 	}
 	if cfg.Trans.Default == "" {
 		cfg.Trans.Default = "baidu"
 	}
-	if cfg.Trans.LLM == nil {
-		cfg.Trans.LLM = &TransLLMConfig{}
+	transEndpoints.applyDefaults(&cfg.Trans.Endpoints, dir)
+	if cfg.Trans.Memory == nil {
+		cfg.Trans.Memory = &TransMemoryConfig{}
 	}
-	if cfg.Trans.LLM.Timeout == 0 {
-		cfg.Trans.LLM.Timeout = Duration(30 * time.Second)
+	if cfg.Trans.Memory.DBFilename == "" {
+		cfg.Trans.Memory.DBFilename = filepath.Join(dir, "tm.db")
 	}
-	if cfg.Trans.LLM.MaxTokens == 0 {
-		cfg.Trans.LLM.MaxTokens = 2000
+	if cfg.Trans.Memory.FuzzyThreshold == 0 {
+		cfg.Trans.Memory.FuzzyThreshold = 0.8
 	}
-	if cfg.Trans.LLM.Temperature == 0 {
-		cfg.Trans.LLM.Temperature = 0.3
-	}
-	if cfg.Trans.Google == nil {
-		cfg.Trans.Google = &TransGoogleConfig{}
-	}
-	if cfg.Trans.Baidu == nil {
-		cfg.Trans.Baidu = &TransBaiduConfig{}
+	if cfg.Trans.Glossary != "" && !filepath.IsAbs(cfg.Trans.Glossary) {
+		cfg.Trans.Glossary = filepath.Join(dir, cfg.Trans.Glossary)
 	}
 	if cfg.Notebook == nil {
 		cfg.Notebook = &NotebookConfig{}
@@ -356,12 +348,13 @@ func applyDefaults(cfg *Config, configFilename string) {
 		cfg.Notebook.Settings.NewCardsPerDay = 20
 	}
 
+	if cfg.Network == nil {
+		cfg.Network = &NetworkConfig{}
+	}
+
 	if cfg.Notebook.Settings.BasePath == "" {
 		cfg.Notebook.Settings.BasePath = filepath.Join(dir, "notebooks")
 	}
-	if cfg.Dict.Ecdict.DBFilename == "" {
-		cfg.Dict.Ecdict.DBFilename = filepath.Join(dir, "stardict.db")
-	}
 }
 
 func applyEnvOverrides(cfg *Config) {
@@ -369,7 +362,29 @@ func applyEnvOverrides(cfg *Config) {
 	knownPaths := buildKnownPaths(reflect.ValueOf(cfg).Elem(), "")
 	for envKey, envVal := range envMap {
 		if yamlPath, ok := knownPaths[envKey]; ok {
-			setValueByPath(cfg, yamlPath, envVal)
+			setValueByPath(reflect.ValueOf(cfg).Elem(), yamlPath, envVal)
+		}
+	}
+	// The sections of the registered endpoints live in maps.
+	if cfg.Dict != nil {
+		applyEndpointEnvOverrides(envMap, "dict", cfg.Dict.Endpoints)
+	}
+	if cfg.Trans != nil {
+		applyEndpointEnvOverrides(envMap, "trans", cfg.Trans.Endpoints)
+	}
+}
+
+func applyEndpointEnvOverrides(envMap map[string]string, prefix string, endpoints map[string]EndpointConfig) {
+	for name, endpointConfig := range endpoints {
+		v := reflect.ValueOf(endpointConfig)
+		if v.Kind() != reflect.Ptr || v.IsNil() {
+			continue
+		}
+		sectionPrefix := prefix + "." + name + "."
+		for envKey, yamlPath := range buildKnownPaths(v, prefix+"."+name) {
+			if envVal, ok := envMap[envKey]; ok {
+				setValueByPath(v.Elem(), strings.TrimPrefix(yamlPath, sectionPrefix), envVal)
+			}
 		}
 	}
 }
@@ -447,8 +462,7 @@ func buildKnownPaths(v reflect.Value, prefix string) map[string]string {
 	return result
 }
 
-func setValueByPath(cfg *Config, path string, value string) {
-	v := reflect.ValueOf(cfg).Elem()
+func setValueByPath(v reflect.Value, path string, value string) {
 	parts := strings.Split(path, ".")
 	current := v
 
@@ -490,6 +504,10 @@ func setFieldValueFromString(field reflect.Value, value string) {
 		if boolVal, err := strconv.ParseBool(value); err == nil {
 			field.SetBool(boolVal)
 		}
+	case reflect.Slice:
+		if field.Type().Elem().Kind() == reflect.String {
+			field.Set(reflect.ValueOf(SplitList(value)))
+		}
 	default:
 		if field.Type() == reflect.TypeOf(Duration(0)) {
 			if dur, err := time.ParseDuration(value); err == nil {
@@ -499,6 +517,17 @@ func setFieldValueFromString(field reflect.Value, value string) {
 	}
 }
 
+// SplitList splits a comma separated value into its trimmed, non-empty items.
+func SplitList(value string) []string {
+	items := make([]string, 0)
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 func findFieldByYAMLTag(v reflect.Value, tag string) (reflect.Value, bool) {
 	t := v.Type()
 	for i := 0; i < v.NumField(); i++ {
@@ -538,24 +567,28 @@ func (c *Config) Validate(activeEndpoint string) error {
 	if c.Version != DefaultConfigVersion {
 		return fmt.Errorf("unsupported config version: %q. Run 'wordflow config init' to regenerate your config", c.Version)
 	}
-	if activeEndpoint == "llm" {
-		if err := c.Dict.LLM.Validate(); err != nil {
-			return err
-		}
+	endpointConfig, err := c.Dict.GetEndpointConfig(activeEndpoint)
+	if err != nil {
+		return err
 	}
-	if activeEndpoint == "ecdict" {
-		if err := c.Dict.Ecdict.Validate(); err != nil {
-			return err
-		}
+	if err := endpointConfig.Validate(); err != nil {
+		return err
 	}
-	if activeEndpoint == "mwebster" {
-		if err := c.Dict.MWebster.Validate(); err != nil {
-			return err
+	for _, endpoint := range c.Dict.Fallback {
+		endpointConfig, err := c.Dict.GetEndpointConfig(endpoint)
+		if err != nil {
+			return fmt.Errorf("invalid dict.fallback: %w", err)
+		}
+		if err := endpointConfig.Validate(); err != nil {
+			return fmt.Errorf("invalid dict.fallback endpoint %s: %w", endpoint, err)
 		}
 	}
 	if err := c.Notebook.Settings.Validate(); err != nil {
 		return err
 	}
+	if err := c.Network.Validate(); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -566,6 +599,15 @@ func ValidateForTrans(cfg *Config) error {
 	if cfg.Trans == nil {
 		return errors.New("trans config is missing")
 	}
+	if err := cfg.Network.Validate(); err != nil {
+		return err
+	}
+	if err := cfg.Trans.Memory.Validate(); err != nil {
+		return err
+	}
+	if ext := strings.ToLower(filepath.Ext(cfg.Trans.Glossary)); cfg.Trans.Glossary != "" && ext != ".tsv" && ext != ".tbx" {
+		return errors.Errorf("trans.glossary must be a .tsv or .tbx file, got %s", cfg.Trans.Glossary)
+	}
 	endpointConfig, err := cfg.Trans.GetEndpointConfig(cfg.Trans.Default)
 	if err != nil {
 		return err
@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
 	"time"
 )
@@ -14,17 +15,11 @@ func TestLoadConfig(t *testing.T) {
 	content := `version: v1
 dict:
   default: youdao
-  youdao: {}
-  llm:
-    api_key: test-key
+  testplugin:
     url: "https://api.example.com/v1/chat/completions"
-    model: test-model
     timeout: 30s
     max_tokens: 2000
     temperature: 0.3
-  ecdict: {}
-  etymonline: {}
-  mwebster: {}
 notebook:
   default: default
   settings:
@@ -46,20 +41,17 @@ notebook:
 	if cfg.Dict.Default != "youdao" {
 		t.Errorf("expected dict default youdao, got %q", cfg.Dict.Default)
 	}
-	if cfg.Dict.LLM.ApiKey != "test-key" {
-		t.Errorf("expected LLM api_key test-key, got %q", cfg.Dict.LLM.ApiKey)
+	if testPlugin(cfg).URL != "https://api.example.com/v1/chat/completions" {
+		t.Errorf("expected testplugin url, got %q", testPlugin(cfg).URL)
 	}
-	if cfg.Dict.LLM.Model != "test-model" {
-		t.Errorf("expected LLM model test-model, got %q", cfg.Dict.LLM.Model)
+	if time.Duration(testPlugin(cfg).Timeout) != 30*time.Second {
+		t.Errorf("expected testplugin timeout 30s, got %v", time.Duration(testPlugin(cfg).Timeout))
 	}
-	if time.Duration(cfg.Dict.LLM.Timeout) != 30*time.Second {
-		t.Errorf("expected LLM timeout 30s, got %v", time.Duration(cfg.Dict.LLM.Timeout))
+	if testPlugin(cfg).MaxTokens != 2000 {
+		t.Errorf("expected testplugin max_tokens 2000, got %d", testPlugin(cfg).MaxTokens)
 	}
-	if cfg.Dict.LLM.MaxTokens != 2000 {
-		t.Errorf("expected LLM max_tokens 2000, got %d", cfg.Dict.LLM.MaxTokens)
-	}
-	if cfg.Dict.LLM.Temperature != 0.3 {
-		t.Errorf("expected LLM temperature 0.3, got %f", cfg.Dict.LLM.Temperature)
+	if testPlugin(cfg).Temperature != 0.3 {
+		t.Errorf("expected testplugin temperature 0.3, got %f", testPlugin(cfg).Temperature)
 	}
 	if cfg.Notebook.Default != "default" {
 		t.Errorf("expected notebook default 'default', got %q", cfg.Notebook.Default)
@@ -86,17 +78,11 @@ dict:
 		t.Fatal(err)
 	}
 
-	if cfg.Dict.LLM == nil {
-		t.Error("expected LLM config to be initialized with defaults")
-	}
-	if cfg.Dict.LLM.Timeout != Duration(30*time.Second) {
-		t.Errorf("expected default timeout 30s, got %v", cfg.Dict.LLM.Timeout)
-	}
-	if cfg.Dict.LLM.MaxTokens != 2000 {
-		t.Errorf("expected default max_tokens 2000, got %d", cfg.Dict.LLM.MaxTokens)
+	if cfg.Dict.Endpoints["testplugin"] == nil {
+		t.Fatal("expected testplugin config to be initialized with defaults")
 	}
-	if cfg.Dict.LLM.Temperature != 0.3 {
-		t.Errorf("expected default temperature 0.3, got %f", cfg.Dict.LLM.Temperature)
+	if testPlugin(cfg).Timeout != Duration(5*time.Second) {
+		t.Errorf("expected default timeout 5s, got %v", testPlugin(cfg).Timeout)
 	}
 }
 
@@ -125,8 +111,8 @@ notebook:
 	if cfg.Notebook.Settings.BasePath == "" {
 		t.Error("expected basepath to be dynamically set")
 	}
-	if cfg.Dict.Ecdict.DBFilename == "" {
-		t.Error("expected ecdict db_filename to be dynamically set")
+	if len(testPlugin(cfg).Paths) == 0 {
+		t.Error("expected testplugin paths to be dynamically set")
 	}
 }
 
@@ -137,21 +123,18 @@ func TestEnvOverride(t *testing.T) {
 	content := `version: v1
 dict:
   default: youdao
-  llm:
-    api_key: file-key
+  testplugin:
     url: "https://file-url.com"
-    model: file-model
     timeout: 30s
     max_tokens: 2000
-    temperature: 0.3
 `
 	if err := os.WriteFile(configFile, []byte(content), 0644); err != nil {
 		t.Fatal(err)
 	}
 
-	os.Setenv("WORDFLOW_DICT_LLM_API_KEY", "env-key")
-	os.Setenv("WORDFLOW_DICT_DEFAULT", "llm")
-	defer os.Unsetenv("WORDFLOW_DICT_LLM_API_KEY")
+	os.Setenv("WORDFLOW_DICT_TESTPLUGIN_URL", "https://env-url.com")
+	os.Setenv("WORDFLOW_DICT_DEFAULT", "testplugin")
+	defer os.Unsetenv("WORDFLOW_DICT_TESTPLUGIN_URL")
 	defer os.Unsetenv("WORDFLOW_DICT_DEFAULT")
 
 	cfg, err := LoadConfig(configFile)
@@ -159,14 +142,14 @@ dict:
 		t.Fatal(err)
 	}
 
-	if cfg.Dict.LLM.ApiKey != "env-key" {
-		t.Errorf("expected env var override, got %q", cfg.Dict.LLM.ApiKey)
+	if testPlugin(cfg).URL != "https://env-url.com" {
+		t.Errorf("expected env var override, got %q", testPlugin(cfg).URL)
 	}
-	if cfg.Dict.Default != "llm" {
+	if cfg.Dict.Default != "testplugin" {
 		t.Errorf("expected env var override for default, got %q", cfg.Dict.Default)
 	}
-	if cfg.Dict.LLM.Model != "file-model" {
-		t.Errorf("expected file value for model, got %q", cfg.Dict.LLM.Model)
+	if testPlugin(cfg).MaxTokens != 2000 {
+		t.Errorf("expected file value for max_tokens, got %d", testPlugin(cfg).MaxTokens)
 	}
 }
 
@@ -182,16 +165,42 @@ dict:
 		t.Fatal(err)
 	}
 
-	os.Setenv("WORDFLOW_DICT_LLM_TIMEOUT", "60s")
-	defer os.Unsetenv("WORDFLOW_DICT_LLM_TIMEOUT")
+	os.Setenv("WORDFLOW_DICT_TESTPLUGIN_TIMEOUT", "60s")
+	defer os.Unsetenv("WORDFLOW_DICT_TESTPLUGIN_TIMEOUT")
+
+	cfg, err := LoadConfig(configFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if time.Duration(testPlugin(cfg).Timeout) != 60*time.Second {
+		t.Errorf("expected timeout 60s from env, got %v", time.Duration(testPlugin(cfg).Timeout))
+	}
+}
+
+func TestEnvOverrideList(t *testing.T) {
+	tmpDir := t.TempDir()
+	configFile := filepath.Join(tmpDir, "config.yaml")
+
+	content := `version: v1
+dict:
+  default: testplugin
+`
+	if err := os.WriteFile(configFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	os.Setenv("WORDFLOW_DICT_TESTPLUGIN_PATHS", "/data/oxford, /data/collins")
+	defer os.Unsetenv("WORDFLOW_DICT_TESTPLUGIN_PATHS")
 
 	cfg, err := LoadConfig(configFile)
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	if time.Duration(cfg.Dict.LLM.Timeout) != 60*time.Second {
-		t.Errorf("expected timeout 60s from env, got %v", time.Duration(cfg.Dict.LLM.Timeout))
+	want := []string{"/data/oxford", "/data/collins"}
+	if !reflect.DeepEqual(testPlugin(cfg).Paths, want) {
+		t.Errorf("expected paths %v from env, got %v", want, testPlugin(cfg).Paths)
 	}
 }
 
@@ -205,70 +214,44 @@ func TestValidate(t *testing.T) {
 		cleanupEnvVars []string
 	}{
 		{
-			name: "youdao no validation needed",
-			endpoint: "youdao",
-			configYAML: `version: v1
-dict:
-  default: youdao
-notebook:
-  default: default
-  settings:
-    max_reviews_per_session: 50
-    new_cards_per_day: 20
-`,
-		},
-		{
-			name:     "llm missing api_key",
-			endpoint: "llm",
+			name:     "testplugin valid",
+			endpoint: "testplugin",
 			configYAML: `version: v1
 dict:
-  default: llm
-  llm:
+  default: testplugin
+  testplugin:
     url: "https://api.example.com"
-    model: test-model
-    timeout: 30s
-    max_tokens: 2000
-    temperature: 0.3
 notebook:
   default: default
   settings:
     max_reviews_per_session: 50
     new_cards_per_day: 20
 `,
-			expectedErr: "llm.api_key is required",
 		},
 		{
-			name:     "llm missing url",
-			endpoint: "llm",
+			name:     "testplugin missing url",
+			endpoint: "testplugin",
 			configYAML: `version: v1
 dict:
-  default: llm
-  llm:
-    api_key: test-key
-    model: test-model
+  default: testplugin
+  testplugin:
     timeout: 30s
-    max_tokens: 2000
-    temperature: 0.3
 notebook:
   default: default
   settings:
     max_reviews_per_session: 50
     new_cards_per_day: 20
 `,
-			expectedErr: "llm.url is required",
+			expectedErr: "url is required",
 		},
 		{
-			name:     "llm invalid temperature",
-			endpoint: "llm",
+			name:     "testplugin invalid temperature",
+			endpoint: "testplugin",
 			configYAML: `version: v1
 dict:
-  default: llm
-  llm:
-    api_key: test-key
+  default: testplugin
+  testplugin:
     url: "https://api.example.com"
-    model: test-model
-    timeout: 30s
-    max_tokens: 2000
     temperature: 5.0
 notebook:
   default: default
@@ -276,11 +259,11 @@ notebook:
     max_reviews_per_session: 50
     new_cards_per_day: 20
 `,
-			expectedErr: "llm.temperature must be between 0 and 2",
+			expectedErr: "testplugin.temperature must be between 0 and 2",
 		},
 		{
 			name:     "wrong version",
-			endpoint: "youdao",
+			endpoint: "testplugin",
 			configYAML: `version: "0.1"
 dict:
   default: youdao
@@ -293,25 +276,74 @@ notebook:
 			expectedErr: "unsupported config version",
 		},
 		{
-			name:     "llm with env var api_key",
-			endpoint: "llm",
+			name:     "testplugin with env var url",
+			endpoint: "testplugin",
 			configYAML: `version: v1
 dict:
-  default: llm
-  llm:
-    url: "https://api.example.com"
-    model: test-model
-    timeout: 30s
-    max_tokens: 2000
-    temperature: 0.3
+  default: testplugin
 notebook:
   default: default
   settings:
     max_reviews_per_session: 50
     new_cards_per_day: 20
 `,
-			envVars:        map[string]string{"WORDFLOW_DICT_LLM_API_KEY": "env-key"},
-			cleanupEnvVars: []string{"WORDFLOW_DICT_LLM_API_KEY"},
+			envVars:        map[string]string{"WORDFLOW_DICT_TESTPLUGIN_URL": "https://env-url.com"},
+			cleanupEnvVars: []string{"WORDFLOW_DICT_TESTPLUGIN_URL"},
+		},
+		{
+			name:     "unknown dict endpoint",
+			endpoint: "youdoa",
+			configYAML: `version: v1
+dict:
+  default: youdoa
+`,
+			expectedErr: "unknown endpoint: youdoa",
+		},
+		{
+			name:     "network proxy scheme",
+			endpoint: "testplugin",
+			configYAML: `version: v1
+dict:
+  default: testplugin
+  testplugin:
+    url: "https://api.example.com"
+network:
+  proxy: ftp://proxy.example.com:21
+`,
+			expectedErr: "scheme must be one of",
+		},
+		{
+			name:     "network unknown endpoint",
+			endpoint: "testplugin",
+			configYAML: `version: v1
+dict:
+  default: testplugin
+  testplugin:
+    url: "https://api.example.com"
+network:
+  endpoints:
+    dict.unknown:
+      user_agent: test
+`,
+			expectedErr: "invalid network.endpoints key",
+		},
+		{
+			name:     "network proxy from env",
+			endpoint: "testplugin",
+			configYAML: `version: v1
+dict:
+  default: testplugin
+  testplugin:
+    url: "https://api.example.com"
+network:
+  endpoints:
+    trans.testtrans:
+      headers:
+        X-Team: docs
+`,
+			envVars:        map[string]string{"WORDFLOW_NETWORK_PROXY": "ftp://proxy.example.com:21"},
+			expectedErr:    "invalid network.proxy",
+			cleanupEnvVars: []string{"WORDFLOW_NETWORK_PROXY"},
 		},
 	}
 
@@ -373,8 +405,8 @@ func TestInitConfig(t *testing.T) {
 	if !contains(content, "default: youdao") {
 		t.Error("expected default youdao in init config")
 	}
-	if !contains(content, "timeout: 30s") {
-		t.Error("expected timeout in init config")
+	if !contains(content, "# testplugin:") {
+		t.Error("expected the testplugin section in init config")
 	}
 }
 
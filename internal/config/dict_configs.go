@@ -1,77 +1,47 @@
 package config
 
 import (
-	"errors"
+	"fmt"
+	"time"
 )
 
-type DictEndpointConfig interface {
-	Validate() error
-}
-
-type YoudaoConfig struct{}
-
-func (c *YoudaoConfig) Validate() error {
-	return nil
-}
+type DictEndpointConfig = EndpointConfig
 
-type EtymonlineConfig struct{}
+// OnlineTimeout is the default request timeout of the online dictionaries.
+const OnlineTimeout = 10 * time.Second
 
-func (c *EtymonlineConfig) Validate() error {
-	return nil
-}
+// ExecProtocols are the plugin protocols accepted by exec.protocol.
+var ExecProtocols = []string{"stdin", "jsonrpc"}
 
-type EcdictConfig struct {
-	DBFilename string `yaml:"db_filename,omitempty"`
+// ExecConfig runs an external process as a dictionary or translator.
+type ExecConfig struct {
+	Command string   `yaml:"command,omitempty"`
+	Args    []string `yaml:"args,omitempty"`
+	// Env holds extra KEY=VALUE variables for the plugin process.
+	Env      []string `yaml:"env,omitempty"`
+	Protocol string   `yaml:"protocol,omitempty"`
+	Timeout  Duration `yaml:"timeout,omitempty"`
 }
 
-func (c *EcdictConfig) Validate() error {
-	if c.DBFilename == "" {
-		return errors.New("ecdict.db_filename is required when ecdict is the default dictionary")
+// SetDefaults fills in the protocol and timeout of a plugin.
+func (c *ExecConfig) SetDefaults() {
+	if c.Protocol == "" {
+		c.Protocol = "stdin"
 	}
-	return nil
-}
-
-type MWebsterConfig struct {
-	Key string `yaml:"key,omitempty"`
-}
-
-func (c *MWebsterConfig) Validate() error {
-	if c.Key == "" {
-		return errors.New("mwebster.key is required when mwebster is the default dictionary")
+	if c.Timeout == 0 {
+		c.Timeout = Duration(10 * time.Second)
 	}
-	return nil
-}
-
-type LLMConfig struct {
-	ApiKey      string   `yaml:"api_key,omitempty"`
-	URL         string   `yaml:"url,omitempty"`
-	Model       string   `yaml:"model,omitempty"`
-	Timeout     Duration `yaml:"timeout,omitempty"`
-	MaxTokens   int      `yaml:"max_tokens,omitempty"`
-	Temperature float64  `yaml:"temperature,omitempty"`
 }
 
-func (c *LLMConfig) Validate() error {
-	if c.ApiKey == "" {
-		return errors.New("llm.api_key is required. Set it via: wordflow config set dict.llm.api_key <key> or env var WORDFLOW_DICT_LLM_API_KEY")
-	}
-	if c.URL == "" {
-		return errors.New("llm.url is required. Set it via: wordflow config set dict.llm.url <url> or env var WORDFLOW_DICT_LLM_URL")
+// ValidateSection checks a plugin configured in section.exec, dict or trans.
+func (c *ExecConfig) ValidateSection(section string) error {
+	if c.Command == "" {
+		return fmt.Errorf("%s.exec.command is required. Set it via: wordflow config set %s.exec.command <path>", section, section)
 	}
-	if c.Model == "" {
-		return errors.New("llm.model is required. Set it via: wordflow config set dict.llm.model <model> or env var WORDFLOW_DICT_LLM_MODEL")
+	for _, protocol := range ExecProtocols {
+		if c.Protocol == protocol {
+			return nil
+		}
 	}
-	if c.MaxTokens <= 0 {
-		return errors.New("llm.max_tokens must be positive")
-	}
-	if c.Temperature < 0 || c.Temperature > 2 {
-		return errors.New("llm.temperature must be between 0 and 2")
-	}
-	return nil
+	return fmt.Errorf("%s.exec.protocol must be one of %v, got %q", section, ExecProtocols, c.Protocol)
 }
-
-type GoogleConfig struct{}
-
-func (c *GoogleConfig) Validate() error {
-	return nil
-}
\ No newline at end of file
@@ -0,0 +1,69 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// ProxySchemes are the supported schemes of network.proxy.
+var ProxySchemes = []string{"http", "https", "socks5", "socks5h"}
+
+// NetworkConfig applies to every HTTP request of wordflow.
+type NetworkConfig struct {
+	// Proxy is an http(s):// or socks5:// URL. Empty uses the HTTPS_PROXY,
+	// HTTP_PROXY and NO_PROXY environment variables, "direct" ignores them.
+	Proxy string `yaml:"proxy,omitempty"`
+	// CAFile is a PEM bundle trusted in addition to the system roots.
+	CAFile             string `yaml:"ca_file,omitempty"`
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify,omitempty"`
+	// UserAgent replaces the User-Agent of all requests.
+	UserAgent string `yaml:"user_agent,omitempty"`
+	// Endpoints holds the settings of single endpoints, keyed by
+	// "dict.<name>" or "trans.<name>".
+	Endpoints map[string]*EndpointNetworkConfig `yaml:"endpoints,omitempty"`
+}
+
+// EndpointNetworkConfig is the network configuration of one endpoint.
+type EndpointNetworkConfig struct {
+	Headers   map[string]string `yaml:"headers,omitempty"`
+	UserAgent string            `yaml:"user_agent,omitempty"`
+}
+
+func (c *NetworkConfig) Validate() error {
+	if c == nil {
+		return nil
+	}
+	if c.Proxy != "" && c.Proxy != "direct" {
+		u, err := url.Parse(c.Proxy)
+		if err != nil || u.Host == "" {
+			return fmt.Errorf("invalid network.proxy %q: expected a URL like socks5://127.0.0.1:1080", c.Proxy)
+		}
+		if !slices.Contains(ProxySchemes, u.Scheme) {
+			return fmt.Errorf("invalid network.proxy %q: scheme must be one of %s", c.Proxy, strings.Join(ProxySchemes, ", "))
+		}
+	}
+	for name := range c.Endpoints {
+		kind, endpoint, _ := strings.Cut(name, ".")
+		var ok bool
+		switch kind {
+		case "dict":
+			ok = dictEndpoints.lookup(endpoint) != nil
+		case "trans":
+			ok = transEndpoints.lookup(endpoint) != nil
+		}
+		if !ok {
+			return fmt.Errorf("invalid network.endpoints key %q: expected dict.<name> or trans.<name> of a known endpoint", name)
+		}
+	}
+	return nil
+}
+
+// Endpoint returns the settings of an endpoint, nil if it has none.
+func (c *NetworkConfig) Endpoint(name string) *EndpointNetworkConfig {
+	if c == nil {
+		return nil
+	}
+	return c.Endpoints[name]
+}
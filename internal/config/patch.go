@@ -168,10 +168,20 @@ func indexOfMappingKey(node *yaml.Node, key string) int {
 	return -1
 }
 
+// ValidateKey checks that a dotted key such as trans.llm.api_key names a
+// setting of the config or of a registered endpoint.
+func ValidateKey(key string) error {
+	_, err := resolveFieldType(strings.Split(key, "."))
+	return err
+}
+
 func resolveFieldType(parts []string) (reflect.Type, error) {
 	t := reflect.TypeOf(Config{})
 	for i, part := range parts {
 		field, found := findFieldTypeByYAMLTag(t, part)
+		if !found {
+			field.Type, found = endpointFieldType(t, part)
+		}
 		if !found {
 			return nil, fmt.Errorf("config key %q not found at part %q", strings.Join(parts, "."), part)
 		}
@@ -186,6 +196,18 @@ func resolveFieldType(parts []string) (reflect.Type, error) {
 	return nil, fmt.Errorf("config key %q not found", strings.Join(parts, "."))
 }
 
+// endpointFieldType resolves the sections of registered endpoints, kept in
+// the Endpoints maps of DictConfig and TransConfig.
+func endpointFieldType(t reflect.Type, name string) (reflect.Type, bool) {
+	switch t {
+	case reflect.TypeOf(DictConfig{}):
+		return dictEndpoints.fieldType(name)
+	case reflect.TypeOf(TransConfig{}):
+		return transEndpoints.fieldType(name)
+	}
+	return nil, false
+}
+
 func findFieldTypeByYAMLTag(t reflect.Type, tag string) (reflect.StructField, bool) {
 	for i := 0; i < t.NumField(); i++ {
 		field := t.Field(i)
@@ -244,6 +266,14 @@ func setNodeValue(node *yaml.Node, value string, fieldType reflect.Type) {
 			node.Value = value
 			node.Tag = ""
 		}
+	case reflect.Slice:
+		node.Kind = yaml.SequenceNode
+		node.Value = ""
+		node.Tag = ""
+		node.Content = nil
+		for _, item := range SplitList(value) {
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: item})
+		}
 	default:
 		node.Kind = yaml.ScalarNode
 		node.Value = value
@@ -16,7 +16,7 @@ func TestPatchYAMLFile_SetLeafValue(t *testing.T) {
 dict:
   # Default dictionary endpoint
   default: youdao
-  llm:
+  testplugin:
     timeout: 30s
     max_tokens: 2000
 `
@@ -24,7 +24,7 @@ dict:
 		t.Fatal(err)
 	}
 
-	if err := PatchYAMLFile(configFile, "dict.llm.timeout", "60s"); err != nil {
+	if err := PatchYAMLFile(configFile, "dict.testplugin.timeout", "60s"); err != nil {
 		t.Fatal(err)
 	}
 
@@ -60,7 +60,7 @@ dict:
 		t.Fatal(err)
 	}
 
-	if err := PatchYAMLFile(configFile, "trans.llm.api_key", "sk-xxx"); err != nil {
+	if err := PatchYAMLFile(configFile, "trans.testtrans.api_key", "sk-xxx"); err != nil {
 		t.Fatal(err)
 	}
 
@@ -70,8 +70,8 @@ dict:
 	}
 	applyDefaults(cfg, configFile)
 
-	if cfg.Trans.LLM.ApiKey != "sk-xxx" {
-		t.Errorf("expected trans.llm.api_key to be 'sk-xxx', got %q", cfg.Trans.LLM.ApiKey)
+	if cfg.Trans.Endpoints["testtrans"].(*testTransConfig).APIKey != "sk-xxx" {
+		t.Errorf("expected trans.testtrans.api_key to be 'sk-xxx', got %q", cfg.Trans.Endpoints["testtrans"].(*testTransConfig).APIKey)
 	}
 }
 
@@ -96,7 +96,7 @@ func TestPatchYAMLFile_TypeCoercion(t *testing.T) {
 		},
 		{
 			name:   "duration value",
-			key:    "dict.llm.timeout",
+			key:    "dict.testplugin.timeout",
 			value:  "60s",
 			wantIn: "60s",
 		},
@@ -109,7 +109,7 @@ func TestPatchYAMLFile_TypeCoercion(t *testing.T) {
 			original := `version: v1
 dict:
   default: youdao
-  llm:
+  testplugin:
     timeout: 30s
     max_tokens: 2000
     temperature: 0.3
@@ -144,7 +144,7 @@ func TestPatchYAMLFile_BooleanAndFloatTypes(t *testing.T) {
 	original := `version: v1
 dict:
   default: youdao
-  llm:
+  testplugin:
     timeout: 30s
     max_tokens: 2000
     temperature: 0.3
@@ -153,7 +153,7 @@ dict:
 		t.Fatal(err)
 	}
 
-	if err := PatchYAMLFile(configFile, "dict.llm.temperature", "0.7"); err != nil {
+	if err := PatchYAMLFile(configFile, "dict.testplugin.temperature", "0.7"); err != nil {
 		t.Fatal(err)
 	}
 
@@ -163,8 +163,8 @@ dict:
 	}
 	applyDefaults(cfg, configFile)
 
-	if cfg.Dict.LLM.Temperature != 0.7 {
-		t.Errorf("expected temperature 0.7, got %f", cfg.Dict.LLM.Temperature)
+	if testPlugin(cfg).Temperature != 0.7 {
+		t.Errorf("expected temperature 0.7, got %f", testPlugin(cfg).Temperature)
 	}
 }
 
@@ -202,14 +202,14 @@ func TestPatchYAMLFile_DurationType(t *testing.T) {
 	original := `version: v1
 dict:
   default: youdao
-  llm:
+  testplugin:
     timeout: 30s
 `
 	if err := os.WriteFile(configFile, []byte(original), 0644); err != nil {
 		t.Fatal(err)
 	}
 
-	if err := PatchYAMLFile(configFile, "dict.llm.timeout", "60s"); err != nil {
+	if err := PatchYAMLFile(configFile, "dict.testplugin.timeout", "60s"); err != nil {
 		t.Fatal(err)
 	}
 
@@ -219,8 +219,33 @@ dict:
 	}
 	applyDefaults(cfg, configFile)
 
-	if time.Duration(cfg.Dict.LLM.Timeout) != 60*time.Second {
-		t.Errorf("expected timeout 60s, got %v", time.Duration(cfg.Dict.LLM.Timeout))
+	if time.Duration(testPlugin(cfg).Timeout) != 60*time.Second {
+		t.Errorf("expected timeout 60s, got %v", time.Duration(testPlugin(cfg).Timeout))
+	}
+}
+
+func TestPatchYAMLFile_ListType(t *testing.T) {
+	tmpDir := t.TempDir()
+	configFile := filepath.Join(tmpDir, "config.yaml")
+	original := `version: v1
+dict:
+  default: testplugin
+`
+	if err := os.WriteFile(configFile, []byte(original), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := PatchYAMLFile(configFile, "dict.testplugin.paths", "/data/oxford,/data/collins"); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := ReadConfigSpecified(configFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(testPlugin(cfg).Paths) != 2 || testPlugin(cfg).Paths[1] != "/data/collins" {
+		t.Errorf("expected two testplugin paths, got %v", testPlugin(cfg).Paths)
 	}
 }
 
@@ -231,20 +256,19 @@ func TestPatchYAMLFile_CommentedSectionSurvives(t *testing.T) {
 	original := `version: v1
 dict:
   default: youdao
-  llm:
+  testplugin:
     # timeout for LLM calls
     timeout: 30s
 trans:
   default: google
-  google: {}
-  # llm:
+  # testtrans:
   #   api_key: ""
 `
 	if err := os.WriteFile(configFile, []byte(original), 0644); err != nil {
 		t.Fatal(err)
 	}
 
-	if err := PatchYAMLFile(configFile, "dict.llm.timeout", "60s"); err != nil {
+	if err := PatchYAMLFile(configFile, "dict.testplugin.timeout", "60s"); err != nil {
 		t.Fatal(err)
 	}
 
@@ -260,8 +284,8 @@ trans:
 	if !strings.Contains(content, "# timeout for LLM calls") {
 		t.Errorf("expected comment preserved")
 	}
-	if !strings.Contains(content, "# llm:") {
-		t.Errorf("expected commented-out llm section preserved, got:\n%s", content)
+	if !strings.Contains(content, "# testtrans:") {
+		t.Errorf("expected commented-out testtrans section preserved, got:\n%s", content)
 	}
 	if !strings.Contains(content, "#   api_key:") {
 		t.Errorf("expected commented-out api_key preserved, got:\n%s", content)
@@ -275,15 +299,14 @@ func TestPatchYAMLFile_CreateNextToCommentedSection(t *testing.T) {
 	original := `version: v1
 trans:
   default: google
-  google: {}
-  # llm:
+  # testtrans:
   #   api_key: ""
 `
 	if err := os.WriteFile(configFile, []byte(original), 0644); err != nil {
 		t.Fatal(err)
 	}
 
-	if err := PatchYAMLFile(configFile, "trans.llm.api_key", "sk-test"); err != nil {
+	if err := PatchYAMLFile(configFile, "trans.testtrans.api_key", "sk-test"); err != nil {
 		t.Fatal(err)
 	}
 
@@ -296,8 +319,8 @@ trans:
 	if !strings.Contains(content, "api_key: sk-test") {
 		t.Errorf("expected api_key to be set, got:\n%s", content)
 	}
-	if !strings.Contains(content, "# llm:") {
-		t.Errorf("expected commented-out llm to be preserved, got:\n%s", content)
+	if !strings.Contains(content, "# testtrans:") {
+		t.Errorf("expected commented-out testtrans to be preserved, got:\n%s", content)
 	}
 
 	cfg, err := ReadConfigSpecified(configFile)
@@ -306,8 +329,8 @@ trans:
 	}
 	applyDefaults(cfg, configFile)
 
-	if cfg.Trans.LLM.ApiKey != "sk-test" {
-		t.Errorf("expected trans.llm.api_key to be 'sk-test', got %q", cfg.Trans.LLM.ApiKey)
+	if cfg.Trans.Endpoints["testtrans"].(*testTransConfig).APIKey != "sk-test" {
+		t.Errorf("expected trans.testtrans.api_key to be 'sk-test', got %q", cfg.Trans.Endpoints["testtrans"].(*testTransConfig).APIKey)
 	}
 }
 
@@ -362,7 +385,7 @@ dict:
   # Dictionary endpoint options: youdao, llm, ecdict
   default: youdao
 
-  llm:
+  testplugin:
     # Required for LLM dictionary
     # api_key: ""
     timeout: 30s
@@ -377,7 +400,7 @@ trans:
 		t.Fatal(err)
 	}
 
-	if err := PatchYAMLFile(configFile, "dict.llm.timeout", "60s"); err != nil {
+	if err := PatchYAMLFile(configFile, "dict.testplugin.timeout", "60s"); err != nil {
 		t.Fatal(err)
 	}
 
@@ -0,0 +1,202 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// EndpointConfig is the configuration section of a dictionary or translator
+// endpoint.
+type EndpointConfig interface {
+	Validate() error
+}
+
+// EndpointSpec describes the configuration section of an endpoint, kept in
+// the Endpoints map of DictConfig or TransConfig.
+type EndpointSpec struct {
+	Name string
+	// New returns an empty configuration, a pointer to a struct with yaml tags.
+	New func() EndpointConfig
+	// Defaults fills in unset fields, dir is the wordflow home directory.
+	Defaults func(c EndpointConfig, dir string)
+	// Template is the commented YAML section written to new config files,
+	// without indentation. Defaults to "<name>: {}".
+	Template string
+}
+
+type endpointRegistry struct {
+	kind  string
+	specs []*EndpointSpec
+}
+
+var (
+	dictEndpoints  = &endpointRegistry{kind: "dict"}
+	transEndpoints = &endpointRegistry{kind: "trans"}
+)
+
+// RegisterDictEndpoint adds the configuration section of a dictionary
+// endpoint. It panics if the name is already taken.
+func RegisterDictEndpoint(spec EndpointSpec) {
+	dictEndpoints.register(spec)
+}
+
+// RegisterTransEndpoint adds the configuration section of a translator
+// endpoint. It panics if the name is already taken.
+func RegisterTransEndpoint(spec EndpointSpec) {
+	transEndpoints.register(spec)
+}
+
+// DictEndpoint returns the registered section of a dictionary endpoint.
+func DictEndpoint(name string) (EndpointSpec, bool) {
+	return dictEndpoints.get(name)
+}
+
+// TransEndpoint returns the registered section of a translator endpoint.
+func TransEndpoint(name string) (EndpointSpec, bool) {
+	return transEndpoints.get(name)
+}
+
+// DictEndpointNames returns the registered dictionary endpoints in order.
+func DictEndpointNames() []string {
+	return dictEndpoints.names()
+}
+
+// TransEndpointNames returns the registered translator endpoints in order.
+func TransEndpointNames() []string {
+	return transEndpoints.names()
+}
+
+func (r *endpointRegistry) register(spec EndpointSpec) {
+	if spec.Name == "" || spec.New == nil {
+		panic("config: " + r.kind + " endpoint needs a name and a config type")
+	}
+	if r.lookup(spec.Name) != nil {
+		panic("config: " + r.kind + " endpoint " + spec.Name + " registered twice")
+	}
+	r.specs = append(r.specs, &spec)
+}
+
+func (r *endpointRegistry) lookup(name string) *EndpointSpec {
+	for _, spec := range r.specs {
+		if spec.Name == name {
+			return spec
+		}
+	}
+	return nil
+}
+
+func (r *endpointRegistry) get(name string) (EndpointSpec, bool) {
+	if spec := r.lookup(name); spec != nil {
+		return *spec, true
+	}
+	return EndpointSpec{}, false
+}
+
+func (r *endpointRegistry) names() []string {
+	names := make([]string, len(r.specs))
+	for i, spec := range r.specs {
+		names[i] = spec.Name
+	}
+	return names
+}
+
+// section returns the configuration of an endpoint from endpoints. Endpoints
+// without one get their defaults.
+func (r *endpointRegistry) section(endpoints map[string]EndpointConfig, spec *EndpointSpec) EndpointConfig {
+	if c, ok := endpoints[spec.Name]; ok && c != nil {
+		return c
+	}
+	return withDefaults(spec, nil, configDir())
+}
+
+// applyDefaults makes sure every registered endpoint has a section with its
+// defaults filled in.
+func (r *endpointRegistry) applyDefaults(endpoints *map[string]EndpointConfig, dir string) {
+	if *endpoints == nil {
+		*endpoints = make(map[string]EndpointConfig)
+	}
+	for _, spec := range r.specs {
+		(*endpoints)[spec.Name] = withDefaults(spec, (*endpoints)[spec.Name], dir)
+	}
+}
+
+func withDefaults(spec *EndpointSpec, c EndpointConfig, dir string) EndpointConfig {
+	if c == nil {
+		c = spec.New()
+	}
+	if spec.Defaults != nil {
+		spec.Defaults(c, dir)
+	}
+	return c
+}
+
+// decode reads the sections of the registered endpoints from a mapping node.
+func (r *endpointRegistry) decode(node *yaml.Node) (map[string]EndpointConfig, error) {
+	var endpoints map[string]EndpointConfig
+	for _, spec := range r.specs {
+		i := indexOfMappingKey(node, spec.Name)
+		if i < 0 {
+			continue
+		}
+		c := spec.New()
+		if err := node.Content[i+1].Decode(c); err != nil {
+			return nil, fmt.Errorf("invalid %s.%s config: %w", r.kind, spec.Name, err)
+		}
+		if endpoints == nil {
+			endpoints = make(map[string]EndpointConfig)
+		}
+		endpoints[spec.Name] = c
+	}
+	return endpoints, nil
+}
+
+// encode appends the sections of the registered endpoints to a mapping node.
+func (r *endpointRegistry) encode(node *yaml.Node, endpoints map[string]EndpointConfig) error {
+	for _, spec := range r.specs {
+		c, ok := endpoints[spec.Name]
+		if !ok {
+			continue
+		}
+		var value yaml.Node
+		if err := value.Encode(c); err != nil {
+			return err
+		}
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: spec.Name}, &value)
+	}
+	return nil
+}
+
+// fieldType returns the struct type of an endpoint section for config keys.
+func (r *endpointRegistry) fieldType(name string) (reflect.Type, bool) {
+	spec := r.lookup(name)
+	if spec == nil {
+		return nil, false
+	}
+	t := reflect.TypeOf(spec.New())
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t, true
+}
+
+// template renders the sections of all endpoints for the config template.
+func (r *endpointRegistry) template() string {
+	var b strings.Builder
+	for _, spec := range r.specs {
+		section := spec.Template
+		if section == "" {
+			section = spec.Name + ": {}\n"
+		}
+		b.WriteString("\n")
+		for _, line := range strings.SplitAfter(section, "\n") {
+			if strings.TrimSpace(line) != "" {
+				b.WriteString("  ")
+			}
+			b.WriteString(line)
+		}
+	}
+	return b.String()
+}
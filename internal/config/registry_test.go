@@ -0,0 +1,146 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// testPluginConfig stands in for the dictionaries, which register their
+// sections from pkg/dict.
+type testPluginConfig struct {
+	URL         string   `yaml:"url"`
+	Timeout     Duration `yaml:"timeout"`
+	MaxTokens   int      `yaml:"max_tokens,omitempty"`
+	Temperature float64  `yaml:"temperature,omitempty"`
+	Paths       []string `yaml:"paths,omitempty"`
+}
+
+func (c *testPluginConfig) Validate() error {
+	if c.URL == "" {
+		return errors.New("url is required")
+	}
+	if c.Temperature < 0 || c.Temperature > 2 {
+		return errors.New("testplugin.temperature must be between 0 and 2")
+	}
+	return nil
+}
+
+func testPlugin(cfg *Config) *testPluginConfig {
+	return cfg.Dict.Endpoints["testplugin"].(*testPluginConfig)
+}
+
+// testTransConfig stands in for the translators, which register their
+// sections from pkg/translator.
+type testTransConfig struct {
+	APIKey  string   `yaml:"api_key,omitempty"`
+	Timeout Duration `yaml:"timeout,omitempty"`
+	From    string   `yaml:"from,omitempty"`
+	To      string   `yaml:"to,omitempty"`
+}
+
+func (c *testTransConfig) Validate() error {
+	if c.APIKey == "" {
+		return errors.New("trans.testtrans.api_key is required")
+	}
+	return ValidateLanguages("trans.testtrans", c.From, c.To)
+}
+
+func init() {
+	RegisterTransEndpoint(EndpointSpec{
+		Name: "testtrans",
+		New:  func() EndpointConfig { return &testTransConfig{} },
+		Defaults: func(c EndpointConfig, dir string) {
+			trans := c.(*testTransConfig)
+			DefaultTimeout(&trans.Timeout, 30*time.Second)
+			DefaultLanguages(&trans.From, &trans.To)
+		},
+		Template: "# testtrans:\n#   api_key: \"\"\n",
+	})
+	RegisterDictEndpoint(EndpointSpec{
+		Name: "testplugin",
+		New:  func() EndpointConfig { return &testPluginConfig{} },
+		Defaults: func(c EndpointConfig, dir string) {
+			plugin := c.(*testPluginConfig)
+			DefaultTimeout(&plugin.Timeout, 5*time.Second)
+			if len(plugin.Paths) == 0 {
+				plugin.Paths = []string{filepath.Join(dir, "testplugin")}
+			}
+		},
+		Template: "# testplugin:\n#   url: \"\"\n",
+	})
+}
+
+func TestRegisteredEndpoint(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	content := `version: v1
+dict:
+  default: testplugin
+  testplugin:
+    url: https://file.example.com
+`
+	if err := os.WriteFile(configFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv("WORDFLOW_DICT_TESTPLUGIN_URL", "https://env.example.com")
+	defer os.Unsetenv("WORDFLOW_DICT_TESTPLUGIN_URL")
+
+	cfg, err := LoadConfig(configFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	endpointConfig, err := cfg.Dict.GetEndpointConfig("testplugin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	plugin := endpointConfig.(*testPluginConfig)
+	if plugin.URL != "https://env.example.com" || plugin.Timeout != Duration(5*time.Second) {
+		t.Errorf("plugin config = %+v", plugin)
+	}
+	if err := cfg.Validate("testplugin"); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+
+	out, err := yaml.Marshal(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(out), "testplugin:\n        url: https://env.example.com") {
+		t.Errorf("marshalled config misses the plugin section:\n%s", out)
+	}
+
+	plugin.URL = ""
+	if err := cfg.Validate("testplugin"); err == nil {
+		t.Error("expected validation error from the plugin config")
+	}
+}
+
+func TestRegisteredEndpoint_TemplateAndPatch(t *testing.T) {
+	template := ConfigTemplate()
+	if !strings.Contains(template, "testplugin") || !strings.Contains(template, "  # testplugin:\n  #   url: \"\"\n") {
+		t.Errorf("template misses the plugin section:\n%s", template)
+	}
+
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(configFile, []byte("version: v1\ndict:\n  default: youdao\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := PatchYAMLFile(configFile, "dict.testplugin.timeout", "1m"); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadConfig(configFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := testPlugin(cfg).Timeout; got != Duration(time.Minute) {
+		t.Errorf("patched timeout = %v", got)
+	}
+	if err := PatchYAMLFile(configFile, "dict.testplugin.missing", "x"); err == nil {
+		t.Error("expected error for unknown plugin key")
+	}
+}
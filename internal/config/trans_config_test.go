@@ -21,8 +21,9 @@ dict:
     max_tokens: 2000
     temperature: 0.3
 trans:
-  default: google
-  google: {}
+  default: testtrans
+  testtrans:
+    api_key: trans-key
 notebook:
   default: default
   settings:
@@ -37,24 +38,43 @@ notebook:
 		t.Fatal(err)
 	}
 	if err := ValidateForTrans(cfg); err != nil {
-		t.Errorf("expected no error for google trans, got %v", err)
+		t.Errorf("expected no error for testtrans, got %v", err)
+	}
+	cfg.Trans.Endpoints["testtrans"].(*testTransConfig).APIKey = ""
+	if err := ValidateForTrans(cfg); err == nil || !contains(err.Error(), "trans.testtrans.api_key is required") {
+		t.Errorf("ValidateForTrans() error = %v, want the translator's error", err)
+	}
+}
+
+func TestGetEndpointConfig(t *testing.T) {
+	tc := &TransConfig{
+		Default:   "testtrans",
+		Endpoints: map[string]EndpointConfig{"testtrans": &testTransConfig{APIKey: "key"}},
+	}
+
+	c, err := tc.GetEndpointConfig("testtrans")
+	if err != nil {
+		t.Fatalf("expected no error for testtrans, got %v", err)
+	}
+	if trans, ok := c.(*testTransConfig); !ok || trans.APIKey != "key" {
+		t.Errorf("expected the configured *testTransConfig, got %#v", c)
+	}
+
+	_, err = tc.GetEndpointConfig("unknown")
+	if err == nil {
+		t.Error("expected error for unknown endpoint, got nil")
 	}
 }
 
-func TestValidateForTrans_GoogleNoApiKey(t *testing.T) {
+func TestTransConfigDefaults(t *testing.T) {
 	tmpDir := t.TempDir()
 	configFile := filepath.Join(tmpDir, "config.yaml")
 	content := `version: v1
 dict:
   default: youdao
 trans:
-  default: google
-  google: {}
-notebook:
-  default: default
-  settings:
-    max_reviews_per_session: 50
-    new_cards_per_day: 20
+  testtrans:
+    from: EN
 `
 	if err := os.WriteFile(configFile, []byte(content), 0644); err != nil {
 		t.Fatal(err)
@@ -63,165 +83,114 @@ notebook:
 	if err != nil {
 		t.Fatal(err)
 	}
-	if err := ValidateForTrans(cfg); err != nil {
-		t.Errorf("expected no error for google trans without api key, got %v", err)
+	if cfg.Trans.Default != "baidu" {
+		t.Errorf("expected trans default 'baidu', got %q", cfg.Trans.Default)
+	}
+	trans, ok := cfg.Trans.Endpoints["testtrans"].(*testTransConfig)
+	if !ok {
+		t.Fatalf("expected the testtrans section, got %#v", cfg.Trans.Endpoints)
+	}
+	if trans.Timeout != Duration(30*time.Second) || trans.From != "EN" || trans.To != DefaultTargetLanguage {
+		t.Errorf("testtrans = %+v, want its defaults and the configured source language", trans)
 	}
 }
 
-func TestValidateForTrans_LLMRequiresFields(t *testing.T) {
+func TestHTTPConfigDefaults(t *testing.T) {
+	configured := &HTTPConfig{Retries: -1, RateLimit: 5}
+	DefaultHTTP(&configured, 0)
+	if configured.Retries != -1 || configured.RateLimit != 5 || configured.Backoff != Duration(500*time.Millisecond) || configured.BreakerFailures != 5 {
+		t.Errorf("configured http = %+v", configured)
+	}
+
+	var http *HTTPConfig
+	DefaultHTTP(&http, 1)
+	if http.RateLimit != 1 || http.Retries != 2 || http.Burst != 1 {
+		t.Errorf("new http = %+v, want rate_limit 1 and 2 retries", http)
+	}
+}
+
+func TestValidateLanguages(t *testing.T) {
 	tests := []struct {
-		name        string
-		configYAML  string
-		expectedErr string
+		name    string
+		from    string
+		to      string
+		wantErr string
 	}{
-		{
-			name: "llm missing api_key",
-			configYAML: `version: v1
-dict:
-  default: youdao
-trans:
-  default: llm
-  llm:
-    url: "https://api.example.com"
-    model: test-model
-    timeout: 30s
-    max_tokens: 2000
-    temperature: 0.3
-notebook:
-  default: default
-  settings:
-    max_reviews_per_session: 50
-    new_cards_per_day: 20
-`,
-			expectedErr: "trans.llm.api_key is required",
-		},
-		{
-			name: "llm missing url",
-			configYAML: `version: v1
-dict:
-  default: youdao
-trans:
-  default: llm
-  llm:
-    api_key: test-key
-    model: test-model
-    timeout: 30s
-    max_tokens: 2000
-    temperature: 0.3
-notebook:
-  default: default
-  settings:
-    max_reviews_per_session: 50
-    new_cards_per_day: 20
-`,
-			expectedErr: "trans.llm.url is required",
-		},
-		{
-			name: "llm missing model",
-			configYAML: `version: v1
-dict:
-  default: youdao
-trans:
-  default: llm
-  llm:
-    api_key: test-key
-    url: "https://api.example.com"
-    timeout: 30s
-    max_tokens: 2000
-    temperature: 0.3
-notebook:
-  default: default
-  settings:
-    max_reviews_per_session: 50
-    new_cards_per_day: 20
-`,
-			expectedErr: "trans.llm.model is required",
-		},
-		{
-			name: "llm valid config",
-			configYAML: `version: v1
-dict:
-  default: youdao
-trans:
-  default: llm
-  llm:
-    api_key: test-key
-    url: "https://api.example.com"
-    model: test-model
-    timeout: 30s
-    max_tokens: 2000
-    temperature: 0.3
-notebook:
-  default: default
-  settings:
-    max_reviews_per_session: 50
-    new_cards_per_day: 20
-`,
-			expectedErr: "",
-		},
+		{name: "defaults"},
+		{name: "aliases", from: "EN", to: "jp"},
+		{name: "pair", from: "zh-CN", to: "zh_TW"},
+		{name: "unknown", to: "xx", wantErr: "invalid trans.test.to"},
+		{name: "auto target", to: "auto", wantErr: "invalid trans.test languages"},
+		{name: "same", from: "en", to: "en", wantErr: "invalid trans.test languages"},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			tmpDir := t.TempDir()
-			configFile := filepath.Join(tmpDir, "config.yaml")
-			if err := os.WriteFile(configFile, []byte(tt.configYAML), 0644); err != nil {
-				t.Fatal(err)
-			}
-			cfg, err := LoadConfig(configFile)
-			if err != nil {
-				t.Fatal(err)
+			err := ValidateLanguages("trans.test", tt.from, tt.to)
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("ValidateLanguages() error = %v", err)
 			}
-			err = ValidateForTrans(cfg)
-			if tt.expectedErr == "" {
-				if err != nil {
-					t.Errorf("expected no error, got %v", err)
-				}
-			} else {
-				if err == nil {
-					t.Errorf("expected error containing %q, got nil", tt.expectedErr)
-				} else if !containsSubstr(err.Error(), tt.expectedErr) {
-					t.Errorf("expected error containing %q, got %q", tt.expectedErr, err.Error())
-				}
+			if tt.wantErr != "" && (err == nil || !contains(err.Error(), tt.wantErr)) {
+				t.Errorf("ValidateLanguages() error = %v, want %q", err, tt.wantErr)
 			}
 		})
 	}
 }
 
-func TestGetEndpointConfig(t *testing.T) {
-	tc := &TransConfig{
-		Default: "llm",
-		LLM:     &TransLLMConfig{ApiKey: "key", URL: "http://test", Model: "m", MaxTokens: 100, Temperature: 0.3},
-		Google:  &TransGoogleConfig{},
+func TestTransMemoryConfig(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("WORDFLOW_HOME", tmpDir)
+	configFile := filepath.Join(tmpDir, "config.yaml")
+	if err := os.WriteFile(configFile, []byte("version: v1\n"), 0644); err != nil {
+		t.Fatal(err)
 	}
-
-	llmCfg, err := tc.GetEndpointConfig("llm")
+	cfg, err := LoadConfig(configFile)
 	if err != nil {
-		t.Fatalf("expected no error for llm, got %v", err)
+		t.Fatal(err)
 	}
-	if _, ok := llmCfg.(*TransLLMConfig); !ok {
-		t.Errorf("expected *TransLLMConfig, got %T", llmCfg)
+	if cfg.Trans.Memory.Disabled {
+		t.Error("expected the translation memory to be enabled by default")
 	}
-
-	googleCfg, err := tc.GetEndpointConfig("google")
-	if err != nil {
-		t.Fatalf("expected no error for google, got %v", err)
+	if cfg.Trans.Memory.DBFilename != filepath.Join(tmpDir, "tm.db") {
+		t.Errorf("expected default db_filename in WORDFLOW_HOME, got %q", cfg.Trans.Memory.DBFilename)
 	}
-	if _, ok := googleCfg.(*TransGoogleConfig); !ok {
-		t.Errorf("expected *TransGoogleConfig, got %T", googleCfg)
+	if cfg.Trans.Memory.FuzzyThreshold != 0.8 {
+		t.Errorf("expected default fuzzy_threshold 0.8, got %f", cfg.Trans.Memory.FuzzyThreshold)
 	}
 
-	_, err = tc.GetEndpointConfig("unknown")
-	if err == nil {
-		t.Error("expected error for unknown endpoint, got nil")
+	tests := []struct {
+		name    string
+		config  *TransMemoryConfig
+		wantErr string
+	}{
+		{name: "nil"},
+		{name: "disabled", config: &TransMemoryConfig{Disabled: true}},
+		{name: "valid", config: &TransMemoryConfig{DBFilename: "tm.db", FuzzyThreshold: 0.9}},
+		{name: "no db", config: &TransMemoryConfig{FuzzyThreshold: 0.9}, wantErr: "db_filename is required"},
+		{name: "threshold", config: &TransMemoryConfig{DBFilename: "tm.db", FuzzyThreshold: 1.5}, wantErr: "fuzzy_threshold must be between 0 and 1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("Validate() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !contains(err.Error(), tt.wantErr)) {
+				t.Errorf("Validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
 	}
 }
 
-func TestTransConfigDefaults(t *testing.T) {
+func TestTransGlossaryConfig(t *testing.T) {
 	tmpDir := t.TempDir()
+	t.Setenv("WORDFLOW_HOME", tmpDir)
 	configFile := filepath.Join(tmpDir, "config.yaml")
 	content := `version: v1
-dict:
-  default: youdao
+trans:
+  default: testtrans
+  glossary: terms.tsv
+  testtrans:
+    api_key: key
 `
 	if err := os.WriteFile(configFile, []byte(content), 0644); err != nil {
 		t.Fatal(err)
@@ -230,19 +199,14 @@ dict:
 	if err != nil {
 		t.Fatal(err)
 	}
-	if cfg.Trans.Default != "baidu" {
-		t.Errorf("expected trans default 'baidu', got %q", cfg.Trans.Default)
-	}
-	if cfg.Trans.LLM == nil {
-		t.Error("expected trans LLM config to be initialized with defaults")
-	}
-	if cfg.Trans.LLM.Timeout != Duration(30*time.Second) {
-		t.Errorf("expected default trans LLM timeout 30s, got %v", cfg.Trans.LLM.Timeout)
+	if cfg.Trans.Glossary != filepath.Join(tmpDir, "terms.tsv") {
+		t.Errorf("expected the glossary relative to WORDFLOW_HOME, got %q", cfg.Trans.Glossary)
 	}
-	if cfg.Trans.LLM.MaxTokens != 2000 {
-		t.Errorf("expected default trans LLM max_tokens 2000, got %d", cfg.Trans.LLM.MaxTokens)
+	if err := ValidateForTrans(cfg); err != nil {
+		t.Errorf("ValidateForTrans() error = %v", err)
 	}
-	if cfg.Trans.LLM.Temperature != 0.3 {
-		t.Errorf("expected default trans LLM temperature 0.3, got %f", cfg.Trans.LLM.Temperature)
+	cfg.Trans.Glossary = "terms.csv"
+	if err := ValidateForTrans(cfg); err == nil || !contains(err.Error(), "trans.glossary must be a .tsv or .tbx file") {
+		t.Errorf("ValidateForTrans() error = %v, want a glossary format error", err)
 	}
 }
\ No newline at end of file
@@ -1,41 +1,80 @@
 package config
 
-import "errors"
+import (
+	"errors"
+	"fmt"
 
-type TransEndpointConfig interface {
-	Validate() error
-}
+	"github.com/gogodjzhu/word-flow/internal/lang"
+)
+
+type TransEndpointConfig = EndpointConfig
 
-type TransLLMConfig struct {
-	ApiKey      string   `yaml:"api_key,omitempty"`
-	URL         string   `yaml:"url,omitempty"`
-	Model       string   `yaml:"model,omitempty"`
-	Timeout     Duration `yaml:"timeout,omitempty"`
-	MaxTokens   int      `yaml:"max_tokens,omitempty"`
-	Temperature float64  `yaml:"temperature,omitempty"`
+// TransMemoryConfig is the translation memory of trans, a SQLite store of
+// translated segments.
+type TransMemoryConfig struct {
+	Disabled   bool   `yaml:"disabled,omitempty"`
+	DBFilename string `yaml:"db_filename,omitempty"`
+	// FuzzyThreshold is the minimum similarity, between 0 and 1, of the
+	// fuzzy matches offered for reuse.
+	FuzzyThreshold float64 `yaml:"fuzzy_threshold,omitempty"`
 }
 
-func (c *TransLLMConfig) Validate() error {
-	if c.ApiKey == "" {
-		return errors.New("trans.llm.api_key is required. Set it via: wordflow config set trans.llm.api_key <key> or env var WORDFLOW_TRANS_LLM_API_KEY")
+func (c *TransMemoryConfig) Validate() error {
+	if c == nil || c.Disabled {
+		return nil
 	}
-	if c.URL == "" {
-		return errors.New("trans.llm.url is required. Set it via: wordflow config set trans.llm.url <url> or env var WORDFLOW_TRANS_LLM_URL")
+	if c.DBFilename == "" {
+		return errors.New("trans.memory.db_filename is required")
 	}
-	if c.Model == "" {
-		return errors.New("trans.llm.model is required. Set it via: wordflow config set trans.llm.model <model> or env var WORDFLOW_TRANS_LLM_MODEL")
+	if c.FuzzyThreshold < 0 || c.FuzzyThreshold > 1 {
+		return errors.New("trans.memory.fuzzy_threshold must be between 0 and 1")
 	}
-	if c.MaxTokens <= 0 {
-		return errors.New("trans.llm.max_tokens must be positive")
+	return nil
+}
+
+// LanguageDefaults is implemented by translator sections with a default source
+// and target language.
+type LanguageDefaults interface {
+	Languages() (from, to string)
+}
+
+// Default languages of translators without configured ones.
+const (
+	DefaultSourceLanguage = lang.Auto
+	DefaultTargetLanguage = "zh"
+)
+
+// DefaultLanguages sets unset default languages of a translator section.
+func DefaultLanguages(from, to *string) {
+	if *from == "" {
+		*from = DefaultSourceLanguage
 	}
-	if c.Temperature < 0 || c.Temperature > 2 {
-		return errors.New("trans.llm.temperature must be between 0 and 2")
+	if *to == "" {
+		*to = DefaultTargetLanguage
 	}
-	return nil
 }
 
-type TransGoogleConfig struct{}
+// DefaultConcurrency sets an unset number of batches translated at once.
+func DefaultConcurrency(concurrency *int, n int) {
+	if *concurrency == 0 {
+		*concurrency = n
+	}
+}
 
-func (c *TransGoogleConfig) Validate() error {
+// ValidateLanguages checks the default languages of a translator section,
+// empty ones are the defaults.
+func ValidateLanguages(section, from, to string) error {
+	DefaultLanguages(&from, &to)
+	source, err := lang.Normalize(from)
+	if err != nil {
+		return fmt.Errorf("invalid %s.from: %w", section, err)
+	}
+	target, err := lang.Normalize(to)
+	if err != nil {
+		return fmt.Errorf("invalid %s.to: %w", section, err)
+	}
+	if err := lang.CheckPair(source, target); err != nil {
+		return fmt.Errorf("invalid %s languages: %w", section, err)
+	}
 	return nil
 }
\ No newline at end of file
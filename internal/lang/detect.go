@@ -0,0 +1,213 @@
+package lang
+
+import (
+	"embed"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// profiles holds sample text of the languages written in the Latin script,
+// one file per language code. Their n-gram profiles tell them apart.
+//
+//go:embed profiles/*.txt
+var profiles embed.FS
+
+const (
+	// profileSize is the number of most frequent n-grams of a profile.
+	profileSize = 400
+	// minConfidence is the confidence of a reliable detection. Single Latin
+	// words usually score below it.
+	minConfidence = 0.2
+	// cjkWeight counts a CJK character as several letters, a character being
+	// closer to a word than a letter.
+	cjkWeight = 3
+)
+
+// Detection is the language Detect found in a text.
+type Detection struct {
+	// Code is a language code, empty when the text has no letters.
+	Code string
+	// Confidence is between 0 and 1. A script used by a single language
+	// gives 1, Latin text the margin of the best n-gram profile over the
+	// second one.
+	Confidence float64
+}
+
+// Reliable reports whether the detection is confident enough to act on.
+func (d Detection) Reliable() bool {
+	return d.Code != "" && d.Confidence >= minConfidence
+}
+
+// IsChinese reports whether code is one of the Chinese languages.
+func IsChinese(code string) bool {
+	return code == "zh" || code == "zh-TW" || code == "lzh"
+}
+
+// Detect returns the language of text. Han, kana, Hangul and Cyrillic text
+// is told by its script, Latin text by the n-gram profiles of English,
+// German, French, Spanish, Italian and Portuguese.
+func Detect(text string) Detection {
+	var han, kana, hangul, cyrillic, latin, traditional, simplified int
+	for _, r := range text {
+		switch {
+		case unicode.In(r, unicode.Hiragana, unicode.Katakana):
+			kana++
+		case unicode.Is(unicode.Han, r):
+			han++
+			if strings.ContainsRune(traditionalOnly, r) {
+				traditional++
+			} else if strings.ContainsRune(simplifiedOnly, r) {
+				simplified++
+			}
+		case unicode.Is(unicode.Hangul, r):
+			hangul++
+		case unicode.Is(unicode.Cyrillic, r):
+			cyrillic++
+		case unicode.Is(unicode.Latin, r):
+			latin++
+		}
+	}
+	scripts := []struct {
+		code  string
+		count int
+	}{
+		{"zh", (han + kana) * cjkWeight},
+		{"ko", hangul * cjkWeight},
+		{"ru", cyrillic},
+		{"", latin},
+	}
+	best := scripts[0]
+	for _, s := range scripts[1:] {
+		if s.count > best.count {
+			best = s
+		}
+	}
+	switch {
+	case best.count == 0:
+		return Detection{}
+	case best.code == "zh" && kana > 0:
+		// Japanese mixes kanji with kana, Chinese has no kana.
+		return Detection{Code: "ja", Confidence: 1}
+	case best.code == "zh" && traditional > simplified:
+		return Detection{Code: "zh-TW", Confidence: 1}
+	case best.code != "":
+		return Detection{Code: best.code, Confidence: 1}
+	}
+	return detectLatin(text)
+}
+
+// Characters written differently in Traditional and Simplified Chinese,
+// among the most frequent ones.
+const (
+	traditionalOnly = "這個們來說會時對麼學國開發還過讓為裡經與於點實體問題樣後現無機關長動麗愛聽寫讀書車門東號電話見氣應該頭給嗎"
+	simplifiedOnly  = "这个们来说会时对么学国开发还过让为里经与于点实体问题样后现无机关长动丽爱听写读书车门东号电话见气应该头给吗"
+)
+
+type profile struct {
+	code  string
+	ranks map[string]int
+}
+
+var latinProfiles = sync.OnceValue(func() []profile {
+	entries, err := profiles.ReadDir("profiles")
+	if err != nil {
+		panic(err)
+	}
+	var result []profile
+	for _, entry := range entries {
+		data, err := profiles.ReadFile(path.Join("profiles", entry.Name()))
+		if err != nil {
+			panic(err)
+		}
+		result = append(result, profile{
+			code:  strings.TrimSuffix(entry.Name(), ".txt"),
+			ranks: rank(ngrams(string(data))),
+		})
+	}
+	return result
+})
+
+// detectLatin classifies text with the out-of-place distance of its n-gram
+// ranks to the ranks of each profile.
+func detectLatin(text string) Detection {
+	ranks := rank(ngrams(text))
+	if len(ranks) == 0 {
+		return Detection{Code: "en"}
+	}
+	best, second := -1, -1
+	var bestCode string
+	for _, p := range latinProfiles() {
+		distance := 0
+		for gram, r := range ranks {
+			if pr, ok := p.ranks[gram]; ok {
+				distance += abs(pr - r)
+			} else {
+				distance += profileSize
+			}
+		}
+		switch {
+		case best < 0 || distance < best:
+			second, best, bestCode = best, distance, p.code
+		case second < 0 || distance < second:
+			second = distance
+		}
+	}
+	confidence := 0.0
+	if second > 0 {
+		confidence = float64(second-best) / float64(second)
+	}
+	return Detection{Code: bestCode, Confidence: confidence}
+}
+
+// ngrams counts the 1- to 3-grams of the lower-cased words of text, padded
+// with a space on both sides.
+func ngrams(text string) map[string]int {
+	counts := make(map[string]int)
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '\''
+	})
+	for _, word := range words {
+		runes := []rune(" " + word + " ")
+		for n := 1; n <= 3; n++ {
+			for i := 0; i+n <= len(runes); i++ {
+				gram := string(runes[i : i+n])
+				if gram != " " {
+					counts[gram]++
+				}
+			}
+		}
+	}
+	return counts
+}
+
+// rank returns the ranks of the profileSize most frequent n-grams.
+func rank(counts map[string]int) map[string]int {
+	grams := make([]string, 0, len(counts))
+	for gram := range counts {
+		grams = append(grams, gram)
+	}
+	sort.Slice(grams, func(i, j int) bool {
+		if counts[grams[i]] != counts[grams[j]] {
+			return counts[grams[i]] > counts[grams[j]]
+		}
+		return grams[i] < grams[j]
+	})
+	if len(grams) > profileSize {
+		grams = grams[:profileSize]
+	}
+	ranks := make(map[string]int, len(grams))
+	for i, gram := range grams {
+		ranks[gram] = i
+	}
+	return ranks
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
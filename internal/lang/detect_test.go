@@ -0,0 +1,30 @@
+package lang
+
+import "testing"
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{text: "", want: ""},
+		{text: "123 !?", want: ""},
+		{text: "你好，世界。这是一个测试。", want: "zh"},
+		{text: "這是一個測試，我們來說說。", want: "zh-TW"},
+		{text: "おはようございます。今日は良い天気ですね。", want: "ja"},
+		{text: "안녕하세요, 만나서 반갑습니다.", want: "ko"},
+		{text: "Привет, как дела?", want: "ru"},
+		{text: "用 Go 写一个 HTTP server", want: "zh"},
+		{text: "The translation memory keeps the results of previous requests.", want: "en"},
+		{text: "Ich habe heute keine Zeit, weil ich arbeiten muss.", want: "de"},
+		{text: "Nous avons besoin de votre aide pour terminer le projet.", want: "fr"},
+		{text: "El perro come la comida en la cocina de la casa.", want: "es"},
+		{text: "Il gatto dorme sempre sul divano della cucina.", want: "it"},
+		{text: "Eu não sei onde ele mora, mas vou perguntar à minha irmã.", want: "pt"},
+	}
+	for _, tt := range tests {
+		if got := Detect(tt.text); got.Code != tt.want {
+			t.Errorf("Detect(%q) = %+v, want %s", tt.text, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+// Package lang names the languages wordflow translates between. Codes are
+// BCP 47 style ("en", "zh", "zh-TW"); translators map them to the codes of
+// their provider.
+package lang
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Auto lets the translator detect the source language.
+const Auto = "auto"
+
+type Language struct {
+	Code string
+	// Name is the English name, used in prompts and messages.
+	Name string
+	// Chinese is the Chinese name, used in the Chinese LLM prompts.
+	Chinese string
+}
+
+var languages = []Language{
+	{Code: "en", Name: "English", Chinese: "英文"},
+	{Code: "zh", Name: "Simplified Chinese", Chinese: "中文"},
+	{Code: "zh-TW", Name: "Traditional Chinese", Chinese: "繁体中文"},
+	{Code: "lzh", Name: "Classical Chinese", Chinese: "文言文"},
+	{Code: "ja", Name: "Japanese", Chinese: "日文"},
+	{Code: "ko", Name: "Korean", Chinese: "韩文"},
+	{Code: "de", Name: "German", Chinese: "德文"},
+	{Code: "fr", Name: "French", Chinese: "法文"},
+	{Code: "es", Name: "Spanish", Chinese: "西班牙文"},
+	{Code: "it", Name: "Italian", Chinese: "意大利文"},
+	{Code: "pt", Name: "Portuguese", Chinese: "葡萄牙文"},
+	{Code: "ru", Name: "Russian", Chinese: "俄文"},
+}
+
+// aliases are other spellings accepted by Normalize, in lower case.
+var aliases = map[string]string{
+	"cn":      "zh",
+	"zh-cn":   "zh",
+	"zh-hans": "zh",
+	"zh-hant": "zh-TW",
+	"zh-hk":   "zh-TW",
+	"cht":     "zh-TW",
+	"wyw":     "lzh",
+	"jp":      "ja",
+	"kr":      "ko",
+}
+
+// Codes returns the supported language codes.
+func Codes() []string {
+	codes := make([]string, len(languages))
+	for i, l := range languages {
+		codes[i] = l.Code
+	}
+	return codes
+}
+
+// Lookup returns the language of a normalized code.
+func Lookup(code string) (Language, bool) {
+	for _, l := range languages {
+		if l.Code == code {
+			return l, true
+		}
+	}
+	return Language{}, false
+}
+
+// Normalize returns the code of a language given by code or alias, ignoring
+// case. An empty code stays empty.
+func Normalize(code string) (string, error) {
+	code = strings.TrimSpace(code)
+	if code == "" || strings.EqualFold(code, Auto) {
+		return strings.ToLower(code), nil
+	}
+	lower := strings.ToLower(strings.ReplaceAll(code, "_", "-"))
+	if alias, ok := aliases[lower]; ok {
+		return alias, nil
+	}
+	for _, l := range languages {
+		if strings.ToLower(l.Code) == lower {
+			return l.Code, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported language %q, use one of %s or auto", code, strings.Join(Codes(), ", "))
+}
+
+// NormalizeTag is Normalize for language tags of files such as TMX and TBX.
+// Tags with a region unknown to wordflow, e.g. en-US, fall back to their
+// language.
+func NormalizeTag(tag string) (string, error) {
+	code, err := Normalize(tag)
+	if err != nil {
+		if i := strings.IndexAny(tag, "-_"); i > 0 {
+			return Normalize(tag[:i])
+		}
+	}
+	return code, err
+}
+
+// CheckPair validates normalized source and target codes: the target must be
+// a language and differ from the source.
+func CheckPair(from, to string) error {
+	if to == "" || to == Auto {
+		return fmt.Errorf("the target language cannot be %q", to)
+	}
+	if from == to {
+		return fmt.Errorf("source and target language are both %s", to)
+	}
+	return nil
+}
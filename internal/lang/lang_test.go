@@ -0,0 +1,67 @@
+package lang
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		code    string
+		want    string
+		wantErr bool
+	}{
+		{code: "", want: ""},
+		{code: "AUTO", want: "auto"},
+		{code: "en", want: "en"},
+		{code: " zh ", want: "zh"},
+		{code: "zh_CN", want: "zh"},
+		{code: "zh-tw", want: "zh-TW"},
+		{code: "zh-Hant", want: "zh-TW"},
+		{code: "wyw", want: "lzh"},
+		{code: "jp", want: "ja"},
+		{code: "klingon", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := Normalize(tt.code)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("Normalize(%q) = %q, %v, want %q", tt.code, got, err, tt.want)
+		}
+	}
+}
+
+func TestNormalizeTag(t *testing.T) {
+	tests := []struct {
+		tag     string
+		want    string
+		wantErr bool
+	}{
+		{tag: "en-US", want: "en"},
+		{tag: "zh-CN", want: "zh"},
+		{tag: "zh-HK", want: "zh-TW"},
+		{tag: "pt_BR", want: "pt"},
+		{tag: "sv-SE", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := NormalizeTag(tt.tag)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("NormalizeTag(%q) = %q, %v, want %q", tt.tag, got, err, tt.want)
+		}
+	}
+}
+
+func TestCheckPair(t *testing.T) {
+	tests := []struct {
+		from, to string
+		wantErr  bool
+	}{
+		{from: "auto", to: "zh"},
+		{from: "", to: "ja"},
+		{from: "en", to: "de"},
+		{from: "en", to: "en", wantErr: true},
+		{from: "en", to: "auto", wantErr: true},
+		{from: "en", to: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		if err := CheckPair(tt.from, tt.to); (err != nil) != tt.wantErr {
+			t.Errorf("CheckPair(%q, %q) error = %v, wantErr %v", tt.from, tt.to, err, tt.wantErr)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package llm
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -20,6 +21,7 @@ type Client struct {
 	timeout     time.Duration
 	maxTokens   int
 	temperature float64
+	policy      *util.Policy
 }
 
 type ChatMessage struct {
@@ -140,7 +142,20 @@ func NewClient(apiKey, url, model string, timeout time.Duration, maxTokens int,
 	}
 }
 
+// WithPolicy sets the retry, rate limit and circuit breaker policy of the
+// requests.
+func (c *Client) WithPolicy(p *util.Policy) *Client {
+	c.policy = p
+	return c
+}
+
 func (c *Client) TranslateAndExplain(text string) (*entity.WordItem, error) {
+	return c.TranslateAndExplainContext(context.Background(), text)
+}
+
+// TranslateAndExplainContext is TranslateAndExplain cancelled with ctx, and
+// at the latest after the timeout of the client.
+func (c *Client) TranslateAndExplainContext(ctx context.Context, text string) (*entity.WordItem, error) {
 	if strings.TrimSpace(text) == "" {
 		return nil, errors.New("empty input text")
 	}
@@ -304,7 +319,10 @@ func (c *Client) TranslateAndExplain(text string) (*entity.WordItem, error) {
 		"Authorization": "Bearer " + c.apiKey,
 	}
 
-	result, err := util.SendPost(c.url, headers, requestBytes, func(response *http.Response) (interface{}, error) {
+	ctx, cancel := util.ContextWithTimeout(ctx, c.timeout)
+	defer cancel()
+	ctx = util.WithPolicy(ctx, c.policy)
+	result, err := util.SendPostContext(ctx, c.url, headers, requestBytes, func(response *http.Response) (interface{}, error) {
 		if response.StatusCode != 200 {
 			return nil, errors.New(fmt.Sprintf("LLM API error: status %d", response.StatusCode))
 		}
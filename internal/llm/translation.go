@@ -8,32 +8,57 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/gogodjzhu/word-flow/internal/lang"
 	"github.com/pkg/errors"
 )
 
 // PromptBuilder constructs translation prompts
-type PromptBuilder struct{}
+type PromptBuilder struct {
+	glossary []GlossaryTerm
+}
+
+// GlossaryTerm is a term the translation must follow. An empty Target keeps
+// the term untranslated.
+type GlossaryTerm struct {
+	Source string
+	Target string
+}
 
 func NewPromptBuilder() *PromptBuilder {
 	return &PromptBuilder{}
 }
 
-func (pb *PromptBuilder) BuildTranslationPrompt(ref bool) string {
-	basePrompt := "你是一个专业的文本翻译工具。请将以下英文文本翻译成中文。"
+// WithGlossary adds the terms to the prompts.
+func (pb *PromptBuilder) WithGlossary(terms []GlossaryTerm) *PromptBuilder {
+	pb.glossary = terms
+	return pb
+}
+
+// BuildTranslationPrompt returns the system prompt translating from one
+// language code of internal/lang to another. An auto source lets the model
+// detect the language.
+func (pb *PromptBuilder) BuildTranslationPrompt(from, to string, ref bool) string {
+	target := languageName(to)
+	basePrompt := "你是一个专业的文本翻译工具。"
+	if from == "" || from == lang.Auto {
+		basePrompt += "请识别以下文本的语言，并将其翻译成" + target + "。"
+	} else {
+		basePrompt += "请将以下" + languageName(from) + "文本翻译成" + target + "。"
+	}
 
 	if ref {
-		return basePrompt + `
+		return basePrompt + pb.glossaryPrompt() + `
 要求：
 1. 请将原文按段落合理分段，每段包含完整的意思
 2. 输出格式必须是 JSON 数组，每个元素包含 'raw' 和 'translation' 字段
-3. 'raw' 字段包含原文段落，'translation' 字段包含对应的中文翻译
+3. 'raw' 字段包含原文段落，'translation' 字段包含对应的` + target + `翻译
 4. 保持段落完整性，不要在句子中间断开
 5. 只输出有效的 JSON 数组，不要包含任何其他内容
 6. 示例格式：[{"raw": "原文段落1", "translation": "翻译段落1"}, {"raw": "原文段落2", "translation": "翻译段落2"}]
 7. 重要：请按顺序输出完整的 JSON 数组，确保格式正确`
 	}
 
-	return basePrompt + `
+	return basePrompt + pb.glossaryPrompt() + `
 要求：
 1. 保持原始格式，包括换行、空格、制表符
 2. 准确翻译内容，不要添加额外解释
@@ -41,6 +66,32 @@ func (pb *PromptBuilder) BuildTranslationPrompt(ref bool) string {
 4. 直接输出翻译结果，不要包含任何其他内容`
 }
 
+// glossaryPrompt lists the terms of the glossary, empty without terms.
+func (pb *PromptBuilder) glossaryPrompt() string {
+	if len(pb.glossary) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("\n术语表（必须严格遵守）：")
+	for _, term := range pb.glossary {
+		if term.Target == "" || term.Target == term.Source {
+			fmt.Fprintf(&b, "\n- \"%s\" 保留原文，不要翻译", term.Source)
+		} else {
+			fmt.Fprintf(&b, "\n- \"%s\" 必须译为 \"%s\"", term.Source, term.Target)
+		}
+	}
+	return b.String()
+}
+
+// languageName returns the Chinese name of a language code, the code itself
+// for unknown ones.
+func languageName(code string) string {
+	if l, ok := lang.Lookup(code); ok {
+		return l.Chinese
+	}
+	return code
+}
+
 // StreamProcessor handles streaming translation responses
 type StreamProcessor struct {
 	output io.Writer
@@ -0,0 +1,43 @@
+package llm
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuildTranslationPrompt(t *testing.T) {
+	pb := NewPromptBuilder()
+	tests := []struct {
+		from, to string
+		ref      bool
+		want     string
+	}{
+		{from: "en", to: "zh", want: "请将以下英文文本翻译成中文。"},
+		{from: "auto", to: "ja", want: "请识别以下文本的语言，并将其翻译成日文。"},
+		{from: "", to: "zh", want: "请识别以下文本的语言"},
+		{from: "de", to: "fr", ref: true, want: "对应的法文翻译"},
+	}
+	for _, tt := range tests {
+		if got := pb.BuildTranslationPrompt(tt.from, tt.to, tt.ref); !strings.Contains(got, tt.want) {
+			t.Errorf("BuildTranslationPrompt(%q, %q, %v) = %q, want it to contain %q", tt.from, tt.to, tt.ref, got, tt.want)
+		}
+	}
+}
+
+func TestBuildTranslationPromptGlossary(t *testing.T) {
+	pb := NewPromptBuilder().WithGlossary([]GlossaryTerm{
+		{Source: "workspace", Target: "工作区"},
+		{Source: "WordFlow"},
+	})
+	for _, ref := range []bool{false, true} {
+		got := pb.BuildTranslationPrompt("en", "zh", ref)
+		for _, want := range []string{"术语表", `"workspace" 必须译为 "工作区"`, `"WordFlow" 保留原文`} {
+			if !strings.Contains(got, want) {
+				t.Errorf("BuildTranslationPrompt(ref=%v) = %q, want it to contain %q", ref, got, want)
+			}
+		}
+	}
+	if got := NewPromptBuilder().BuildTranslationPrompt("en", "zh", false); strings.Contains(got, "术语表") {
+		t.Errorf("expected no glossary without terms, got %q", got)
+	}
+}
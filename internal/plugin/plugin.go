@@ -0,0 +1,324 @@
+// Package plugin runs external processes that act as dictionaries or
+// translators. Requests and responses are JSON over stdin and stdout, either
+// one process per request or a long-lived JSON-RPC 2.0 session with one
+// message per line.
+package plugin
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gogodjzhu/word-flow/internal/buzz_error"
+	"github.com/gogodjzhu/word-flow/internal/config"
+	"github.com/gogodjzhu/word-flow/internal/util"
+	log "github.com/sirupsen/logrus"
+)
+
+// stderrLimit is how much of the plugin's stderr is kept for error messages.
+const stderrLimit = 4096
+
+// Error is the error object of a plugin response. Codes of buzz_error, such
+// as buzz_error.CodeInvalidInput for an unknown word, are passed on as they
+// are; any other code becomes a plugin error.
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *Error) err() error {
+	switch e.Code {
+	case buzz_error.CodeInvalidInput, buzz_error.CodeInvalidEndpoint, buzz_error.CodeHttpError, buzz_error.CodePluginError:
+		return buzz_error.BuzzError{Code: e.Code, Message: e.Message}
+	}
+	return buzz_error.PluginError(fmt.Sprintf("%s (code %d)", e.Message, e.Code))
+}
+
+type rpcRequest struct {
+	JSONRPC string      `json:"jsonrpc"`
+	ID      int64       `json:"id"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params"`
+}
+
+type rpcResponse struct {
+	ID     int64           `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *Error          `json:"error"`
+}
+
+// Plugin is an external process configured by config.ExecConfig.
+type Plugin struct {
+	cfg  *config.ExecConfig
+	name string
+
+	mu      sync.Mutex
+	session *session
+	nextID  int64
+}
+
+func New(cfg *config.ExecConfig) *Plugin {
+	return &Plugin{cfg: cfg, name: filepath.Base(cfg.Command)}
+}
+
+// Call sends params to the plugin and decodes its result into result. The
+// method is only sent in a JSON-RPC session, a stdin plugin receives params
+// alone.
+func (p *Plugin) Call(method string, params interface{}, result interface{}) error {
+	return p.CallContext(context.Background(), method, params, result)
+}
+
+// CallContext is Call cancelled with ctx. A cancelled stdin plugin is killed,
+// a cancelled JSON-RPC session is restarted on the next call.
+func (p *Plugin) CallContext(ctx context.Context, method string, params interface{}, result interface{}) error {
+	if p.cfg.Protocol == "jsonrpc" {
+		return p.callSession(ctx, method, params, result)
+	}
+	return p.callOnce(ctx, params, result)
+}
+
+// Close ends the JSON-RPC session, if any.
+func (p *Plugin) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.session != nil {
+		p.session.close()
+		p.session = nil
+	}
+	return nil
+}
+
+func (p *Plugin) command(ctx context.Context) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, p.cfg.Command, p.cfg.Args...)
+	if len(p.cfg.Env) > 0 {
+		cmd.Env = append(os.Environ(), p.cfg.Env...)
+	}
+	return cmd
+}
+
+func (p *Plugin) timeout() time.Duration {
+	return time.Duration(p.cfg.Timeout)
+}
+
+// callOnce runs the plugin for a single request.
+func (p *Plugin) callOnce(ctx context.Context, params interface{}, result interface{}) error {
+	input, err := json.Marshal(params)
+	if err != nil {
+		return buzz_error.PluginError(fmt.Sprintf("failed to encode request for %s: %v", p.name, err))
+	}
+	callCtx, cancel := util.ContextWithTimeout(ctx, p.timeout())
+	defer cancel()
+	cmd := p.command(callCtx)
+	var stdout bytes.Buffer
+	stderr := &tailBuffer{}
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = stderr
+
+	err = cmd.Run()
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if callCtx.Err() == context.DeadlineExceeded {
+		return buzz_error.PluginError(fmt.Sprintf("%s timed out after %s%s", p.name, p.timeout(), stderr.suffix()))
+	}
+	if err != nil {
+		// A plugin may report a structured error before exiting with a failure.
+		var response struct {
+			Error *Error `json:"error"`
+		}
+		if json.Unmarshal(stdout.Bytes(), &response) == nil && response.Error != nil {
+			return response.Error.err()
+		}
+		return buzz_error.PluginError(fmt.Sprintf("%s failed: %v%s", p.name, err, stderr.suffix()))
+	}
+	if stderr.Len() > 0 {
+		log.Debugf("plugin %s stderr: %s", p.name, stderr.String())
+	}
+	return decodeResult(p.name, stdout.Bytes(), result)
+}
+
+func decodeResult(name string, data []byte, result interface{}) error {
+	var response struct {
+		Error *Error `json:"error"`
+	}
+	if err := json.Unmarshal(data, &response); err == nil && response.Error != nil {
+		return response.Error.err()
+	}
+	if err := json.Unmarshal(data, result); err != nil {
+		return buzz_error.PluginError(fmt.Sprintf("invalid response from %s: %v", name, err))
+	}
+	return nil
+}
+
+func (p *Plugin) callSession(ctx context.Context, method string, params interface{}, result interface{}) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.session == nil {
+		s, err := p.startSession()
+		if err != nil {
+			return err
+		}
+		p.session = s
+	}
+	s := p.session
+
+	p.nextID++
+	request, err := json.Marshal(rpcRequest{JSONRPC: "2.0", ID: p.nextID, Method: method, Params: params})
+	if err != nil {
+		return buzz_error.PluginError(fmt.Sprintf("failed to encode request for %s: %v", p.name, err))
+	}
+	if _, err := s.stdin.Write(append(request, '\n')); err != nil {
+		s.close()
+		p.session = nil
+		return buzz_error.PluginError(fmt.Sprintf("failed to write to %s: %v%s", p.name, err, s.stderr.suffix()))
+	}
+
+	var timeout <-chan time.Time
+	if p.timeout() > 0 {
+		timer := time.NewTimer(p.timeout())
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	for {
+		select {
+		case response, ok := <-s.responses:
+			if !ok {
+				p.session = nil
+				return buzz_error.PluginError(fmt.Sprintf("%s exited%s", p.name, s.stderr.suffix()))
+			}
+			if response.ID != p.nextID {
+				continue
+			}
+			if response.Error != nil {
+				return response.Error.err()
+			}
+			return decodeResult(p.name, response.Result, result)
+		case <-ctx.Done():
+			s.close()
+			p.session = nil
+			return ctx.Err()
+		case <-timeout:
+			// The session is in an unknown state, the next call starts a new one.
+			s.close()
+			p.session = nil
+			return buzz_error.PluginError(fmt.Sprintf("%s timed out after %s%s", p.name, p.timeout(), s.stderr.suffix()))
+		}
+	}
+}
+
+// session is a running JSON-RPC plugin process.
+type session struct {
+	cmd       *exec.Cmd
+	stdin     io.WriteCloser
+	stderr    *tailBuffer
+	responses chan rpcResponse
+	quit      chan struct{}
+	closeOnce sync.Once
+}
+
+func (p *Plugin) startSession() (*session, error) {
+	cmd := p.command(context.Background())
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, buzz_error.PluginError(fmt.Sprintf("failed to start %s: %v", p.name, err))
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, buzz_error.PluginError(fmt.Sprintf("failed to start %s: %v", p.name, err))
+	}
+	s := &session{
+		cmd:       cmd,
+		stdin:     stdin,
+		stderr:    &tailBuffer{},
+		responses: make(chan rpcResponse),
+		quit:      make(chan struct{}),
+	}
+	cmd.Stderr = s.stderr
+	if err := cmd.Start(); err != nil {
+		return nil, buzz_error.PluginError(fmt.Sprintf("failed to start %s: %v", p.name, err))
+	}
+	go s.read(p.name, stdout)
+	return s, nil
+}
+
+func (s *session) read(name string, stdout io.Reader) {
+	defer func() {
+		_ = s.cmd.Wait()
+		close(s.responses)
+	}()
+	reader := bufio.NewReader(stdout)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			var response rpcResponse
+			if jsonErr := json.Unmarshal(line, &response); jsonErr != nil {
+				log.Debugf("plugin %s wrote a line that is not a JSON-RPC response: %s", name, strings.TrimSpace(string(line)))
+			} else {
+				select {
+				case s.responses <- response:
+				case <-s.quit:
+					return
+				}
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (s *session) close() {
+	s.closeOnce.Do(func() {
+		close(s.quit)
+		_ = s.stdin.Close()
+		if s.cmd.Process != nil {
+			_ = s.cmd.Process.Kill()
+		}
+	})
+}
+
+// tailBuffer keeps the last stderrLimit bytes written to it.
+type tailBuffer struct {
+	mu  sync.Mutex
+	buf []byte
+}
+
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.buf = append(b.buf, p...)
+	if len(b.buf) > stderrLimit {
+		b.buf = b.buf[len(b.buf)-stderrLimit:]
+	}
+	return len(p), nil
+}
+
+func (b *tailBuffer) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.buf)
+}
+
+func (b *tailBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return strings.TrimSpace(string(b.buf))
+}
+
+// suffix formats the captured stderr for an error message.
+func (b *tailBuffer) suffix() string {
+	if s := b.String(); s != "" {
+		return ", stderr: " + s
+	}
+	return ""
+}
@@ -0,0 +1,154 @@
+package plugin
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gogodjzhu/word-flow/internal/buzz_error"
+	"github.com/gogodjzhu/word-flow/internal/config"
+)
+
+// TestHelperProcess is the plugin run by the tests, selected with
+// WORDFLOW_TEST_PLUGIN.
+func TestHelperProcess(t *testing.T) {
+	mode := os.Getenv("WORDFLOW_TEST_PLUGIN")
+	if mode == "" {
+		return
+	}
+	defer os.Exit(0)
+	switch mode {
+	case "stdin":
+		var request map[string]string
+		_ = json.NewDecoder(os.Stdin).Decode(&request)
+		switch request["word"] {
+		case "missing":
+			fmt.Println(`{"error": {"code": 1001, "message": "Invalid word: missing"}}`)
+		case "crash":
+			fmt.Fprintln(os.Stderr, "database is locked")
+			os.Exit(2)
+		case "slow":
+			time.Sleep(5 * time.Second)
+		default:
+			fmt.Printf(`{"word": %q, "source": "test"}`, request["word"])
+		}
+	case "jsonrpc":
+		fmt.Fprintln(os.Stderr, "plugin ready")
+		scanner := bufio.NewScanner(os.Stdin)
+		calls := 0
+		for scanner.Scan() {
+			var request struct {
+				ID     int64             `json:"id"`
+				Method string            `json:"method"`
+				Params map[string]string `json:"params"`
+			}
+			if err := json.Unmarshal(scanner.Bytes(), &request); err != nil {
+				continue
+			}
+			calls++
+			switch request.Params["word"] {
+			case "slow":
+				time.Sleep(5 * time.Second)
+			case "exit":
+				os.Exit(3)
+			case "teapot":
+				fmt.Printf(`{"jsonrpc": "2.0", "id": %d, "error": {"code": 418, "message": "teapot"}}`+"\n", request.ID)
+			default:
+				fmt.Println("not json")
+				fmt.Printf(`{"jsonrpc": "2.0", "id": %d, "result": {"word": %q, "source": "%s #%d"}}`+"\n",
+					request.ID, request.Params["word"], request.Method, calls)
+			}
+		}
+	}
+	_, _ = io.Copy(io.Discard, os.Stdin)
+}
+
+func newTestPlugin(t *testing.T, protocol string) *Plugin {
+	t.Helper()
+	p := New(&config.ExecConfig{
+		Command:  os.Args[0],
+		Args:     []string{"-test.run=TestHelperProcess"},
+		Env:      []string{"WORDFLOW_TEST_PLUGIN=" + protocol},
+		Protocol: protocol,
+		Timeout:  config.Duration(10 * time.Second),
+	})
+	t.Cleanup(func() { _ = p.Close() })
+	return p
+}
+
+type testResult struct {
+	Word   string `json:"word"`
+	Source string `json:"source"`
+}
+
+func TestPlugin_Stdin(t *testing.T) {
+	p := newTestPlugin(t, "stdin")
+
+	var result testResult
+	if err := p.Call("search", map[string]string{"word": "hello"}, &result); err != nil {
+		t.Fatalf("Call() error = %v", err)
+	}
+	if result.Word != "hello" || result.Source != "test" {
+		t.Errorf("result = %+v", result)
+	}
+
+	err := p.Call("search", map[string]string{"word": "missing"}, &result)
+	if be, ok := err.(buzz_error.BuzzError); !ok || be.Code != buzz_error.CodeInvalidInput || be.Message != "Invalid word: missing" {
+		t.Errorf("missing word error = %v", err)
+	}
+
+	err = p.Call("search", map[string]string{"word": "crash"}, &result)
+	if be, ok := err.(buzz_error.BuzzError); !ok || be.Code != buzz_error.CodePluginError || !strings.Contains(be.Message, "database is locked") {
+		t.Errorf("crash error = %v, want the captured stderr", err)
+	}
+
+	p.cfg.Timeout = config.Duration(500 * time.Millisecond)
+	err = p.Call("search", map[string]string{"word": "slow"}, &result)
+	if err == nil || !strings.Contains(err.Error(), "timed out after 500ms") {
+		t.Errorf("slow error = %v, want timeout", err)
+	}
+}
+
+func TestPlugin_JSONRPC(t *testing.T) {
+	p := newTestPlugin(t, "jsonrpc")
+
+	for i, word := range []string{"hello", "world"} {
+		var result testResult
+		if err := p.Call("search", map[string]string{"word": word}, &result); err != nil {
+			t.Fatalf("Call(%s) error = %v", word, err)
+		}
+		// Both calls are answered by the same process.
+		if want := fmt.Sprintf("search #%d", i+1); result.Word != word || result.Source != want {
+			t.Errorf("result = %+v, want source %q", result, want)
+		}
+	}
+
+	var result testResult
+	err := p.Call("search", map[string]string{"word": "teapot"}, &result)
+	if be, ok := err.(buzz_error.BuzzError); !ok || be.Code != buzz_error.CodePluginError || !strings.Contains(be.Message, "teapot (code 418)") {
+		t.Errorf("teapot error = %v", err)
+	}
+
+	err = p.Call("search", map[string]string{"word": "exit"}, &result)
+	if err == nil || !strings.Contains(err.Error(), "exited, stderr: plugin ready") {
+		t.Errorf("exit error = %v", err)
+	}
+
+	// A new session is started after the process exited, and again after a timeout.
+	if err := p.Call("search", map[string]string{"word": "again"}, &result); err != nil || result.Source != "search #1" {
+		t.Errorf("restarted session = %+v, %v", result, err)
+	}
+	p.cfg.Timeout = config.Duration(500 * time.Millisecond)
+	if err := p.Call("search", map[string]string{"word": "slow"}, &result); err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("slow error = %v, want timeout", err)
+	}
+	p.cfg.Timeout = config.Duration(10 * time.Second)
+	if err := p.Call("search", map[string]string{"word": "again"}, &result); err != nil || result.Source != "search #1" {
+		t.Errorf("session after timeout = %+v, %v", result, err)
+	}
+}
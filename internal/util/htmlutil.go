@@ -0,0 +1,21 @@
+package util
+
+import (
+	"html"
+	"regexp"
+)
+
+var (
+	hiddenPattern = regexp.MustCompile(`(?is)<(script|style)[^>]*>.*?</(script|style)>`)
+	breakPattern  = regexp.MustCompile(`(?i)<br\s*/?>|</(p|div|li|tr|h[1-6]|blockquote)>`)
+	tagPattern    = regexp.MustCompile(`(?s)<[^>]*>`)
+)
+
+// HTMLToText reduces HTML-like markup (HTML, pango, XDXF) to plain text, turning
+// line breaks and block ends into newlines and dropping scripts and styles.
+func HTMLToText(s string) string {
+	s = hiddenPattern.ReplaceAllString(s, "")
+	s = breakPattern.ReplaceAllString(s, "\n")
+	s = tagPattern.ReplaceAllString(s, "")
+	return html.UnescapeString(s)
+}
@@ -2,6 +2,7 @@ package util
 
 import (
 	"bytes"
+	"context"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -12,12 +13,13 @@ import (
 type HTTPClientBuilder struct {
 	client  *http.Client
 	request *http.Request
+	ctx     context.Context
 }
 
 // NewHTTPClientBuilder creates a new HTTP client builder
 func NewHTTPClientBuilder() *HTTPClientBuilder {
 	return &HTTPClientBuilder{
-		client: &http.Client{Timeout: 60 * time.Second},
+		client: &http.Client{Timeout: 60 * time.Second, Transport: Transport()},
 	}
 }
 
@@ -27,6 +29,13 @@ func (b *HTTPClientBuilder) WithTimeout(timeout time.Duration) *HTTPClientBuilde
 	return b
 }
 
+// WithContext sets the context of the request. A deadline of the context
+// replaces the client timeout.
+func (b *HTTPClientBuilder) WithContext(ctx context.Context) *HTTPClientBuilder {
+	b.ctx = ctx
+	return b
+}
+
 // Get creates a GET request
 func (b *HTTPClientBuilder) Get(url string, headers map[string]string) *HTTPClientBuilder {
 	return b.buildRequest("GET", url, nil, nil, headers)
@@ -85,13 +94,36 @@ func (b *HTTPClientBuilder) buildRequest(method, url string, body []byte, defaul
 	return b
 }
 
+// do sends the request with the context, if any, following the Policy of
+// the context and the network configuration
+func (b *HTTPClientBuilder) do() (*http.Response, error) {
+	req := b.request
+	if b.ctx != nil {
+		req = req.WithContext(b.ctx)
+		if _, ok := b.ctx.Deadline(); ok {
+			b.client.Timeout = 0
+		}
+		if timeout, ok := b.ctx.Value(requestTimeoutKey{}).(time.Duration); ok {
+			b.client.Timeout = timeout
+		}
+	}
+	if rt := transportFrom(b.ctx); rt != nil {
+		b.client.Transport = rt
+	}
+	applyNetwork(b.ctx, req)
+	if p := policyFrom(b.ctx); p != nil {
+		return p.do(b.client, req)
+	}
+	return b.client.Do(req)
+}
+
 // Execute executes the request and processes response
 func (b *HTTPClientBuilder) Execute(processor func(*http.Response) (interface{}, error)) (interface{}, error) {
 	if b.request == nil {
 		return nil, nil
 	}
 
-	resp, err := b.client.Do(b.request)
+	resp, err := b.do()
 	if err != nil {
 		return nil, err
 	}
@@ -105,7 +137,7 @@ func (b *HTTPClientBuilder) ExecuteStream(processor func(*http.Response) error)
 		return nil
 	}
 
-	resp, err := b.client.Do(b.request)
+	resp, err := b.do()
 	if err != nil {
 		return err
 	}
@@ -116,21 +148,39 @@ func (b *HTTPClientBuilder) ExecuteStream(processor func(*http.Response) error)
 
 // SendGet sends GET request (backward compatibility)
 func SendGet(url string, header map[string]string, wrap func(response *http.Response) (interface{}, error)) (interface{}, error) {
+	return SendGetContext(context.Background(), url, header, wrap)
+}
+
+// SendGetContext sends GET request that is cancelled with ctx
+func SendGetContext(ctx context.Context, url string, header map[string]string, wrap func(response *http.Response) (interface{}, error)) (interface{}, error) {
 	return NewHTTPClientBuilder().
+		WithContext(ctx).
 		Get(url, header).
 		Execute(wrap)
 }
 
 // SendPost sends POST request (backward compatibility)
 func SendPost(url string, header map[string]string, body []byte, wrap func(response *http.Response) (interface{}, error)) (interface{}, error) {
+	return SendPostContext(context.Background(), url, header, body, wrap)
+}
+
+// SendPostContext sends POST request that is cancelled with ctx
+func SendPostContext(ctx context.Context, url string, header map[string]string, body []byte, wrap func(response *http.Response) (interface{}, error)) (interface{}, error) {
 	return NewHTTPClientBuilder().
+		WithContext(ctx).
 		Post(url, body, header).
 		Execute(wrap)
 }
 
 // SendPostStream sends streaming POST request (backward compatibility)
 func SendPostStream(url string, header map[string]string, body []byte, wrap func(response *http.Response) error) error {
+	return SendPostStreamContext(context.Background(), url, header, body, wrap)
+}
+
+// SendPostStreamContext sends streaming POST request that is cancelled with ctx
+func SendPostStreamContext(ctx context.Context, url string, header map[string]string, body []byte, wrap func(response *http.Response) error) error {
 	return NewHTTPClientBuilder().
+		WithContext(ctx).
 		PostStream(url, body, header).
 		ExecuteStream(wrap)
 }
@@ -151,6 +201,27 @@ func SendPostStreamWithTimeout(url string, header map[string]string, body []byte
 		ExecuteStream(wrap)
 }
 
+// ContextWithTimeout limits ctx to timeout, a zero timeout leaves it as it is
+func ContextWithTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+type requestTimeoutKey struct{}
+
+// WithRequestTimeout returns a context whose requests sent by
+// HTTPClientBuilder each time out after timeout, reading the response
+// included, while a deadline of ctx bounds them all. A zero timeout leaves
+// ctx as it is.
+func WithRequestTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	if timeout <= 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, requestTimeoutKey{}, timeout)
+}
+
 func hostname(u string) (string, error) {
 	e, err := url.Parse(u)
 	if err != nil {
@@ -0,0 +1,74 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSendGetContext(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			select {
+			case <-release:
+			case <-r.Context().Done():
+			}
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+	defer close(release)
+
+	read := func(response *http.Response) (interface{}, error) {
+		defer response.Body.Close()
+		body, err := io.ReadAll(response.Body)
+		return string(body), err
+	}
+	result, err := SendGetContext(context.Background(), server.URL+"/fast", nil, read)
+	if err != nil || result != "ok" {
+		t.Fatalf("SendGetContext() = %v, %v", result, err)
+	}
+
+	ctx, cancel := ContextWithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := SendGetContext(ctx, server.URL+"/slow", nil, read); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("SendGetContext() error = %v, want deadline exceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("request took %s after its deadline", elapsed)
+	}
+}
+
+func TestWithRequestTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		delay, _ := time.ParseDuration(r.URL.Query().Get("delay"))
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	read := func(response *http.Response) (interface{}, error) {
+		defer response.Body.Close()
+		body, err := io.ReadAll(response.Body)
+		return string(body), err
+	}
+	// The timeout applies to each request, not to the requests together.
+	ctx := WithRequestTimeout(context.Background(), 300*time.Millisecond)
+	for i := 0; i < 3; i++ {
+		if result, err := SendGetContext(ctx, server.URL+"?delay=150ms", nil, read); err != nil || result != "ok" {
+			t.Fatalf("request %d = %v, %v", i, result, err)
+		}
+	}
+	if _, err := SendGetContext(ctx, server.URL+"?delay=5s", nil, read); err == nil {
+		t.Error("SendGetContext() succeeded after the request timeout")
+	}
+}
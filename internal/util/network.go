@@ -0,0 +1,140 @@
+package util
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+
+	"github.com/gogodjzhu/word-flow/internal/config"
+	"github.com/pkg/errors"
+)
+
+// network is the configuration set with SetNetwork, shared by every
+// HTTPClientBuilder.
+var network struct {
+	sync.RWMutex
+	cfg       *config.NetworkConfig
+	transport http.RoundTripper
+}
+
+// SetNetwork applies the proxy, TLS settings, User-Agent and headers of c to
+// all requests sent with HTTPClientBuilder. A nil config restores the defaults.
+func SetNetwork(c *config.NetworkConfig) error {
+	var transport http.RoundTripper
+	if c != nil {
+		if err := c.Validate(); err != nil {
+			return err
+		}
+		t, err := newTransport(c)
+		if err != nil {
+			return err
+		}
+		transport = t
+	}
+	network.Lock()
+	defer network.Unlock()
+	network.cfg = c
+	network.transport = transport
+	return nil
+}
+
+// newTransport returns a transport with the proxy and TLS settings of c.
+func newTransport(c *config.NetworkConfig) (*http.Transport, error) {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	switch c.Proxy {
+	case "":
+	case "direct":
+		t.Proxy = nil
+	default:
+		proxy, err := url.Parse(c.Proxy)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid network.proxy")
+		}
+		t.Proxy = http.ProxyURL(proxy)
+	}
+	if c.CAFile != "" || c.InsecureSkipVerify {
+		t.TLSClientConfig = &tls.Config{InsecureSkipVerify: c.InsecureSkipVerify}
+	}
+	if c.CAFile != "" {
+		pem, err := os.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read network.ca_file")
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.Errorf("no PEM certificates found in network.ca_file %s", c.CAFile)
+		}
+		t.TLSClientConfig.RootCAs = pool
+	}
+	return t, nil
+}
+
+// Transport returns the transport configured with SetNetwork, nil for the
+// default one.
+func Transport() http.RoundTripper {
+	network.RLock()
+	defer network.RUnlock()
+	return network.transport
+}
+
+type (
+	endpointKey  struct{}
+	transportKey struct{}
+)
+
+// WithTransport returns a context whose requests sent by HTTPClientBuilder go
+// through rt instead of the configured transport, e.g. a recorded cassette in
+// tests.
+func WithTransport(ctx context.Context, rt http.RoundTripper) context.Context {
+	return context.WithValue(ctx, transportKey{}, rt)
+}
+
+func transportFrom(ctx context.Context) http.RoundTripper {
+	if ctx == nil {
+		return nil
+	}
+	rt, _ := ctx.Value(transportKey{}).(http.RoundTripper)
+	return rt
+}
+
+// WithEndpoint returns a context whose requests get the headers and
+// User-Agent configured for the endpoint in network.endpoints, e.g.
+// "dict.youdao".
+func WithEndpoint(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, endpointKey{}, name)
+}
+
+// applyNetwork sets the configured User-Agent and headers of the endpoint of
+// the context on req.
+func applyNetwork(ctx context.Context, req *http.Request) {
+	network.RLock()
+	cfg := network.cfg
+	network.RUnlock()
+	if cfg == nil {
+		return
+	}
+	if cfg.UserAgent != "" {
+		req.Header.Set("User-Agent", cfg.UserAgent)
+	}
+	var name string
+	if ctx != nil {
+		name, _ = ctx.Value(endpointKey{}).(string)
+	}
+	endpoint := cfg.Endpoint(name)
+	if endpoint == nil {
+		return
+	}
+	if endpoint.UserAgent != "" {
+		req.Header.Set("User-Agent", endpoint.UserAgent)
+	}
+	for k, v := range endpoint.Headers {
+		req.Header.Set(k, v)
+	}
+}
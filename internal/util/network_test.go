@@ -0,0 +1,114 @@
+package util
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gogodjzhu/word-flow/internal/config"
+)
+
+// testEndpointConfig is the section of trans.llm, registered by the
+// translator outside of tests.
+type testEndpointConfig struct{}
+
+func (c *testEndpointConfig) Validate() error { return nil }
+
+func init() {
+	config.RegisterTransEndpoint(config.EndpointSpec{
+		Name: "llm",
+		New:  func() config.EndpointConfig { return &testEndpointConfig{} },
+	})
+}
+
+func setNetwork(t *testing.T, c *config.NetworkConfig) {
+	t.Helper()
+	if err := SetNetwork(c); err != nil {
+		t.Fatalf("SetNetwork() error = %v", err)
+	}
+	t.Cleanup(func() { _ = SetNetwork(nil) })
+}
+
+func TestSetNetwork_HeadersAndUserAgent(t *testing.T) {
+	var got http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+	}))
+	defer server.Close()
+
+	setNetwork(t, &config.NetworkConfig{
+		UserAgent: "wordflow-test",
+		Endpoints: map[string]*config.EndpointNetworkConfig{
+			"trans.llm": {Headers: map[string]string{"X-Team": "docs"}, UserAgent: "wordflow-llm"},
+		},
+	})
+
+	if _, err := SendGetContext(context.Background(), server.URL, nil, readBody); err != nil {
+		t.Fatal(err)
+	}
+	if got.Get("User-Agent") != "wordflow-test" || got.Get("X-Team") != "" {
+		t.Errorf("headers without endpoint = %v", got)
+	}
+
+	ctx := WithEndpoint(context.Background(), "trans.llm")
+	if _, err := SendPostContext(ctx, server.URL, nil, []byte("{}"), readBody); err != nil {
+		t.Fatal(err)
+	}
+	if got.Get("User-Agent") != "wordflow-llm" || got.Get("X-Team") != "docs" {
+		t.Errorf("headers of trans.llm = %v", got)
+	}
+}
+
+func TestSetNetwork_Proxy(t *testing.T) {
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String()
+		_, _ = w.Write([]byte("via proxy"))
+	}))
+	defer proxy.Close()
+
+	setNetwork(t, &config.NetworkConfig{Proxy: proxy.URL})
+	if _, err := SendGetContext(context.Background(), "http://dict.example.com/word", nil, readBody); err != nil {
+		t.Fatal(err)
+	}
+	if proxied != "http://dict.example.com/word" {
+		t.Errorf("proxy got %q", proxied)
+	}
+
+	if err := SetNetwork(&config.NetworkConfig{Proxy: "ftp://proxy:21"}); err == nil {
+		t.Error("expected error for an ftp proxy")
+	}
+}
+
+func TestSetNetwork_TLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	if _, err := SendGetContext(context.Background(), server.URL, nil, readBody); err == nil || !strings.Contains(err.Error(), "certificate") {
+		t.Fatalf("request to an unknown CA error = %v", err)
+	}
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caFile, cert, 0644); err != nil {
+		t.Fatal(err)
+	}
+	setNetwork(t, &config.NetworkConfig{CAFile: caFile})
+	if _, err := SendGetContext(context.Background(), server.URL, nil, readBody); err != nil {
+		t.Errorf("request with ca_file error = %v", err)
+	}
+
+	setNetwork(t, &config.NetworkConfig{InsecureSkipVerify: true})
+	if _, err := SendGetContext(context.Background(), server.URL, nil, readBody); err != nil {
+		t.Errorf("request with insecure_skip_verify error = %v", err)
+	}
+
+	if err := SetNetwork(&config.NetworkConfig{CAFile: filepath.Join(t.TempDir(), "missing.pem")}); err == nil {
+		t.Error("expected error for a missing ca_file")
+	}
+}
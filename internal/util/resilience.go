@@ -0,0 +1,276 @@
+package util
+
+import (
+	"context"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gogodjzhu/word-flow/internal/config"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// ErrCircuitOpen is returned without sending the request while the circuit of
+// a host is open.
+var ErrCircuitOpen = errors.New("circuit open")
+
+const (
+	maxBackoff = 10 * time.Second
+	// maxRetryAfter is the longest Retry-After that is waited for, a longer
+	// one returns the response as it is.
+	maxRetryAfter = time.Minute
+)
+
+// Policy makes the requests to a host resilient: they are rate limited with a
+// token bucket, retried with exponential backoff and skipped by a circuit
+// breaker once the host keeps failing. The limiter and the breaker are shared
+// by all requests to the same host.
+type Policy struct {
+	// Retries is how often a request failing with a network error, 429 or
+	// 5xx is retried.
+	Retries int
+	// Backoff is the delay before the first retry, doubled for every further
+	// one. A Retry-After header of the response takes precedence.
+	Backoff time.Duration
+	// RateLimit is the number of requests per second, zero is unlimited.
+	RateLimit float64
+	Burst     int
+	// BreakerFailures failed requests in a row open the circuit for
+	// BreakerCooldown, zero disables the breaker.
+	BreakerFailures int
+	BreakerCooldown time.Duration
+}
+
+// PolicyFromConfig returns the policy of an endpoint, nil if it has none.
+func PolicyFromConfig(c *config.HTTPConfig) *Policy {
+	if c == nil {
+		return nil
+	}
+	return &Policy{
+		Retries:         max(c.Retries, 0),
+		Backoff:         time.Duration(c.Backoff),
+		RateLimit:       c.RateLimit,
+		Burst:           c.Burst,
+		BreakerFailures: max(c.BreakerFailures, 0),
+		BreakerCooldown: time.Duration(c.BreakerCooldown),
+	}
+}
+
+type policyKey struct{}
+
+// WithPolicy returns a context whose requests sent by HTTPClientBuilder follow
+// p, a nil policy leaves ctx as it is.
+func WithPolicy(ctx context.Context, p *Policy) context.Context {
+	if p == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, policyKey{}, p)
+}
+
+func policyFrom(ctx context.Context) *Policy {
+	if ctx == nil {
+		return nil
+	}
+	p, _ := ctx.Value(policyKey{}).(*Policy)
+	return p
+}
+
+// hosts holds the *hostState of every host requested with a policy.
+var hosts sync.Map
+
+type hostState struct {
+	mu sync.Mutex
+
+	// token bucket
+	rate   float64
+	burst  int
+	tokens float64
+	last   time.Time
+
+	// circuit breaker
+	failures  int
+	openUntil time.Time
+	probing   bool
+}
+
+func stateOf(host string) *hostState {
+	s, _ := hosts.LoadOrStore(host, &hostState{})
+	return s.(*hostState)
+}
+
+// wait takes a token from the bucket, waiting for it if there is none.
+func (s *hostState) wait(ctx context.Context, rate float64, burst int) error {
+	if rate <= 0 {
+		return nil
+	}
+	burst = max(burst, 1)
+	s.mu.Lock()
+	now := time.Now()
+	if s.rate != rate || s.burst != burst {
+		s.rate, s.burst, s.tokens = rate, burst, float64(burst)
+	} else {
+		s.tokens = math.Min(float64(burst), s.tokens+now.Sub(s.last).Seconds()*rate)
+	}
+	s.last = now
+	// The token is taken right away, later requests queue up behind it.
+	s.tokens--
+	delay := time.Duration(-s.tokens / rate * float64(time.Second))
+	s.mu.Unlock()
+	if delay <= 0 {
+		return nil
+	}
+	return sleep(ctx, delay)
+}
+
+// allow reports whether a request may be sent. After the cooldown of an open
+// circuit a single request probes the host.
+func (s *hostState) allow(threshold int) bool {
+	if threshold <= 0 {
+		return true
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.failures < threshold {
+		return true
+	}
+	if time.Now().Before(s.openUntil) || s.probing {
+		return false
+	}
+	s.probing = true
+	return true
+}
+
+// release ends a probe that was not sent.
+func (s *hostState) release() {
+	s.mu.Lock()
+	s.probing = false
+	s.mu.Unlock()
+}
+
+func (s *hostState) record(ok bool, threshold int, cooldown time.Duration) {
+	if threshold <= 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.probing = false
+	if ok {
+		s.failures = 0
+		return
+	}
+	s.failures++
+	if s.failures >= threshold {
+		s.openUntil = time.Now().Add(cooldown)
+	}
+}
+
+// do sends req following the policy.
+func (p *Policy) do(client *http.Client, req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	host := stateOf(req.URL.Host)
+	for attempt := 0; ; attempt++ {
+		if !host.allow(p.BreakerFailures) {
+			return nil, errors.Wrap(ErrCircuitOpen, req.URL.Host)
+		}
+		if err := host.wait(ctx, p.RateLimit, p.Burst); err != nil {
+			host.release()
+			return nil, err
+		}
+		resp, err := client.Do(req)
+		if err != nil && ctx.Err() != nil {
+			// A cancelled request says nothing about the host.
+			host.release()
+			return nil, err
+		}
+		failed := err != nil || retryable(resp.StatusCode)
+		host.record(!failed, p.BreakerFailures, p.BreakerCooldown)
+		if !failed || attempt >= p.Retries {
+			return resp, err
+		}
+
+		delay := p.backoff(attempt)
+		if resp != nil {
+			if after, ok := retryAfter(resp.Header.Get("Retry-After")); ok {
+				delay = after
+			}
+		}
+		if deadline, ok := ctx.Deadline(); (ok && time.Now().Add(delay).After(deadline)) || delay > maxRetryAfter {
+			return resp, err
+		}
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			resp.Body.Close()
+			log.Debugf("%s %s: %s, retrying in %s", req.Method, req.URL.Redacted(), resp.Status, delay)
+		} else {
+			log.Debugf("%s %s: %v, retrying in %s", req.Method, req.URL.Redacted(), err, delay)
+		}
+		if err := sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+		if req, err = rewind(req); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (p *Policy) backoff(attempt int) time.Duration {
+	if p.Backoff <= 0 {
+		return 0
+	}
+	d := p.Backoff << attempt
+	if d <= 0 || d > maxBackoff {
+		return maxBackoff
+	}
+	return d
+}
+
+// rewind returns a copy of req with a fresh body to send it again.
+func rewind(req *http.Request) (*http.Request, error) {
+	clone := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		clone.Body = body
+	}
+	return clone, nil
+}
+
+func retryable(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// retryAfter parses a Retry-After header, either seconds or an HTTP date.
+func retryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(max(seconds, 0)) * time.Second, true
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return max(time.Until(t), 0), true
+	}
+	return 0, false
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,157 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func readBody(response *http.Response) (interface{}, error) {
+	defer response.Body.Close()
+	_, err := io.Copy(io.Discard, response.Body)
+	return response.StatusCode, err
+}
+
+func TestPolicy_RetryHonoursRetryAfter(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != `{"q":"hello"}` {
+			t.Errorf("attempt %d body = %q", hits.Load()+1, body)
+		}
+		if hits.Add(1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	// The Retry-After of zero seconds replaces the backoff of a minute.
+	ctx := WithPolicy(context.Background(), &Policy{Retries: 2, Backoff: time.Minute})
+	start := time.Now()
+	status, err := SendPostContext(ctx, server.URL, nil, []byte(`{"q":"hello"}`), readBody)
+	if err != nil || status != http.StatusOK {
+		t.Fatalf("SendPostContext() = %v, %v", status, err)
+	}
+	if hits.Load() != 2 {
+		t.Errorf("hits = %d, want 2", hits.Load())
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("retry took %s", elapsed)
+	}
+}
+
+func TestPolicy_RetriesExhausted(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	ctx := WithPolicy(context.Background(), &Policy{Retries: 2, Backoff: time.Millisecond})
+	status, err := SendGetContext(ctx, server.URL, nil, readBody)
+	if err != nil || status != http.StatusBadGateway {
+		t.Fatalf("SendGetContext() = %v, %v", status, err)
+	}
+	if hits.Load() != 3 {
+		t.Errorf("hits = %d, want 3", hits.Load())
+	}
+
+	// A Retry-After beyond the deadline returns the response at once.
+	hits.Store(0)
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+	ctx, cancel := ContextWithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	status, err = SendGetContext(ctx, server.URL, nil, readBody)
+	if err != nil || status != http.StatusTooManyRequests || hits.Load() != 1 {
+		t.Errorf("SendGetContext() = %v, %v after %d hits, want one 429", status, err, hits.Load())
+	}
+}
+
+func TestPolicy_RateLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	ctx := WithPolicy(context.Background(), &Policy{RateLimit: 20, Burst: 1})
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		if _, err := SendGetContext(ctx, server.URL, nil, readBody); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The first request takes the token of the burst, the others wait 50ms each.
+	if elapsed := time.Since(start); elapsed < 140*time.Millisecond {
+		t.Errorf("4 requests at 20/s took %s", elapsed)
+	}
+
+	cancelled, cancel := context.WithCancel(WithPolicy(context.Background(), &Policy{RateLimit: 0.01, Burst: 1}))
+	cancel()
+	if _, err := SendGetContext(cancelled, server.URL, nil, readBody); !errors.Is(err, context.Canceled) {
+		t.Errorf("waiting for a token error = %v, want canceled", err)
+	}
+}
+
+func TestPolicy_CircuitBreaker(t *testing.T) {
+	var hits, healthy atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		if healthy.Load() == 0 {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	ctx := WithPolicy(context.Background(), &Policy{BreakerFailures: 2, BreakerCooldown: 100 * time.Millisecond})
+	for i := 0; i < 2; i++ {
+		if status, err := SendGetContext(ctx, server.URL, nil, readBody); err != nil || status != http.StatusInternalServerError {
+			t.Fatalf("request %d = %v, %v", i, status, err)
+		}
+	}
+	if _, err := SendGetContext(ctx, server.URL, nil, readBody); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("open circuit error = %v, want ErrCircuitOpen", err)
+	}
+	if hits.Load() != 2 {
+		t.Errorf("hits = %d, the open circuit must not send requests", hits.Load())
+	}
+
+	// After the cooldown a probe closes the circuit again.
+	time.Sleep(150 * time.Millisecond)
+	healthy.Store(1)
+	for i := 0; i < 2; i++ {
+		if status, err := SendGetContext(ctx, server.URL, nil, readBody); err != nil || status != http.StatusOK {
+			t.Fatalf("request after cooldown = %v, %v", status, err)
+		}
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Duration
+		ok    bool
+	}{
+		{"", 0, false},
+		{"3", 3 * time.Second, true},
+		{"-1", 0, true},
+		{time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat), 0, true},
+		{"soon", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := retryAfter(tt.value)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("retryAfter(%q) = %v, %v, want %v, %v", tt.value, got, ok, tt.want, tt.ok)
+		}
+	}
+}
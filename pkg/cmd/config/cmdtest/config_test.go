@@ -8,6 +8,8 @@ import (
 
 	"github.com/gogodjzhu/word-flow/internal/config"
 	"github.com/gogodjzhu/word-flow/pkg/cmdutil"
+	_ "github.com/gogodjzhu/word-flow/pkg/dict" // registers the dict sections
+	dict_llm "github.com/gogodjzhu/word-flow/pkg/dict/llm"
 )
 
 func TestConfigViewAndGet(t *testing.T) {
@@ -95,8 +97,9 @@ notebook:
 		t.Fatal(err)
 	}
 
-	if cfg.Dict.LLM.ApiKey != "env-key" {
-		t.Errorf("expected env override 'env-key', got %q", cfg.Dict.LLM.ApiKey)
+	llm := cfg.Dict.Endpoints["llm"].(*dict_llm.Config)
+	if llm.ApiKey != "env-key" {
+		t.Errorf("expected env override 'env-key', got %q", llm.ApiKey)
 	}
 }
 
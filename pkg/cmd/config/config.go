@@ -87,39 +87,12 @@ func newCmdConfigSet(f *cmdutil.Factory) *cobra.Command {
 }
 
 func validateConfigKey(key string) error {
-	parts := strings.Split(key, ".")
-	t := reflect.TypeOf(config.Config{})
-	for i, part := range parts {
-		field, found := findFieldTypeByYAMLTag(t, part)
-		if !found {
-			return fmt.Errorf("config key %q not found. Valid keys can be found with 'wordflow config view'", key)
-		}
-		if i == len(parts)-1 {
-			return nil
-		}
-		if field.Type.Kind() == reflect.Ptr {
-			field.Type = field.Type.Elem()
-		}
-		t = field.Type
+	if err := config.ValidateKey(key); err != nil {
+		return fmt.Errorf("config key %q not found. Valid keys can be found with 'wordflow config view'", key)
 	}
 	return nil
 }
 
-func findFieldTypeByYAMLTag(t reflect.Type, tag string) (reflect.StructField, bool) {
-	for i := 0; i < t.NumField(); i++ {
-		field := t.Field(i)
-		yamlTag := field.Tag.Get("yaml")
-		if yamlTag == "" || yamlTag == "-" {
-			continue
-		}
-		name := strings.SplitN(yamlTag, ",", 2)[0]
-		if name == tag {
-			return field, true
-		}
-	}
-	return reflect.StructField{}, false
-}
-
 func newCmdConfigPath(f *cmdutil.Factory) *cobra.Command {
 	return &cobra.Command{
 		Use:   "path",
@@ -181,6 +154,9 @@ func getConfigValue(cfg *config.Config, key string) (string, error) {
 			return "", fmt.Errorf("config key %q not found", key)
 		}
 		field, found := findFieldByYAMLTag(current, part)
+		if !found {
+			field, found = findEndpoint(current, part)
+		}
 		if !found {
 			return "", fmt.Errorf("config key %q not found", key)
 		}
@@ -202,6 +178,20 @@ func getConfigValue(cfg *config.Config, key string) (string, error) {
 	}
 }
 
+// findEndpoint returns the section of a registered endpoint, kept in the
+// Endpoints map of the dict and trans configs.
+func findEndpoint(v reflect.Value, name string) (reflect.Value, bool) {
+	endpoints := v.FieldByName("Endpoints")
+	if !endpoints.IsValid() || endpoints.Kind() != reflect.Map {
+		return reflect.Value{}, false
+	}
+	section := endpoints.MapIndex(reflect.ValueOf(name))
+	if !section.IsValid() || section.IsNil() {
+		return reflect.Value{}, false
+	}
+	return section.Elem(), true
+}
+
 func findFieldByYAMLTag(v reflect.Value, tag string) (reflect.Value, bool) {
 	t := v.Type()
 	for i := 0; i < v.NumField(); i++ {
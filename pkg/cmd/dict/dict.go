@@ -42,7 +42,7 @@ func NewCmdDict(f *cmdutil.Factory) (*cobra.Command, error) {
 			if err != nil {
 				return err
 			}
-			wordItem, err := dictionary.Search(strings.TrimSpace(strings.Join(args, " ")))
+			wordItem, err := dict.SearchDetected(cmd.Context(), dictionary, strings.TrimSpace(strings.Join(args, " ")))
 			if err != nil {
 				return err
 			}
@@ -81,5 +81,6 @@ func NewCmdDict(f *cmdutil.Factory) (*cobra.Command, error) {
 	cmd.Flags().StringVarP(&notebookDefault, "notebook", "n", cfg.Notebook.Default, "Specify the notebook")
 	cmd.Flags().StringVarP(&dictionaryDefault, "dictionary", "d", cfg.Dict.Default, "Specify the dictionary")
 	cmd.Flags().BoolVarP(&list, "list", "l", false, "List available dictionary types")
+	cmd.AddCommand(newCmdDictInstall(f, cfg))
 	return cmd, nil
 }
@@ -0,0 +1,60 @@
+package dict
+
+import (
+	"fmt"
+
+	"github.com/gogodjzhu/word-flow/internal/config"
+	"github.com/gogodjzhu/word-flow/pkg/cmdutil"
+	dict_ecdict "github.com/gogodjzhu/word-flow/pkg/dict/ecdict"
+	dict_wiktionary "github.com/gogodjzhu/word-flow/pkg/dict/wiktionary"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func newCmdDictInstall(f *cmdutil.Factory, cfg *config.Config) *cobra.Command {
+	var from, lang string
+	cmd := &cobra.Command{
+		Use:   "install <dictionary>",
+		Short: "Install the local database of an offline dictionary",
+		Long: "Install the local database of an offline dictionary. wiktionary is imported once from a kaikki.org " +
+			"JSONL extract (https://kaikki.org/), ecdict is downloaded from GitHub.",
+		Example: `  wordflow dict install wiktionary --from kaikki.org-dictionary-English.jsonl
+  wordflow dict install wiktionary --from raw-wiktextract-data.jsonl.gz --lang en
+  wordflow dict install ecdict`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Unknown endpoints have no section and fall to the default case.
+			endpointConfig, _ := cfg.Dict.GetEndpointConfig(args[0])
+			switch c := endpointConfig.(type) {
+			case *dict_wiktionary.Config:
+				if from == "" {
+					return errors.New("--from is required, download a JSONL extract from https://kaikki.org/")
+				}
+				dbFilename := c.DBFilename
+				n, err := dict_wiktionary.ImportFile(from, dbFilename, dict_wiktionary.ImportOptions{
+					LangCode: lang,
+					Progress: func(imported int) {
+						_, _ = fmt.Fprintf(f.IOStreams.Out, "\rImported %d entries", imported)
+					},
+				})
+				_, _ = fmt.Fprintln(f.IOStreams.Out)
+				if err != nil {
+					return err
+				}
+				_, _ = fmt.Fprintf(f.IOStreams.Out, "Installed %d wiktionary entries into %s\n", n, dbFilename)
+				return nil
+			case *dict_ecdict.Config:
+				if err := dict_ecdict.PrepareDBFile(c.DBFilename); err != nil {
+					return err
+				}
+				_, _ = fmt.Fprintf(f.IOStreams.Out, "Installed ecdict into %s\n", c.DBFilename)
+				return nil
+			default:
+				return errors.Errorf("%s has no local database to install, options: wiktionary, ecdict", args[0])
+			}
+		},
+	}
+	cmd.Flags().StringVar(&from, "from", "", "kaikki.org JSONL file to import, optionally gzipped")
+	cmd.Flags().StringVar(&lang, "lang", "en", "Only import entries of this language code, empty for all")
+	return cmd
+}
@@ -311,7 +311,7 @@ func newCmdNotebookImport(f *cmdutil.Factory, cfg *config.Config) *cobra.Command
 	}
 	cmd.Flags().StringVarP(&importFile, "input", "i", "", "Specify input file for import")
 	cmd.Flags().StringVarP(&importFormat, "format", "f", "tsv", "Specify import format (tsv)")
-	cmd.Flags().StringVarP(&dictionary, "dictionary", "d", string(dict.Youdao), "Specify dictionary for import")
+	cmd.Flags().StringVarP(&dictionary, "dictionary", "d", "youdao", "Specify dictionary for import")
 	return cmd
 }
 
@@ -0,0 +1,229 @@
+package glossary
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/gogodjzhu/word-flow/internal/config"
+	"github.com/gogodjzhu/word-flow/pkg/cmdutil"
+	dict_custom "github.com/gogodjzhu/word-flow/pkg/dict/custom"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+const newGlossaryTemplate = `# Wordflow glossary, looked up by the custom dictionary.
+# - word: wordflow
+#   phonetic: ˈwɜːdfləʊ
+#   tags: [product]
+#   meanings:
+#     - pos: n.
+#       definition: our terminal dictionary and vocabulary tool
+#       examples: ["Look it up with wordflow."]
+[]
+`
+
+func NewCmdGlossary(f *cmdutil.Factory) (*cobra.Command, error) {
+	cfg, err := f.Config()
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := &cobra.Command{
+		Use:   "glossary <subcommand>",
+		Short: "Manage the custom glossary dictionary",
+		Long: "Add, edit and remove words of the custom dictionary. Glossaries are YAML, TSV or CSV files in " +
+			"dict.custom.dir, look them up with: wordflow dict -d custom <word>",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Help()
+		},
+	}
+	cmd.AddCommand(newCmdGlossaryAdd(f, cfg))
+	cmd.AddCommand(newCmdGlossaryEdit(f, cfg))
+	cmd.AddCommand(newCmdGlossaryRm(f, cfg))
+	return cmd, nil
+}
+
+// glossaryFile resolves a file name relative to the glossary directory.
+func glossaryFile(cfg *config.Config, name string) (string, error) {
+	if name == "" {
+		name = dict_custom.DefaultFilename
+	}
+	if !dict_custom.IsGlossaryFile(name) {
+		return "", errors.Errorf("unsupported glossary file %s, use .yaml, .tsv or .csv", name)
+	}
+	if filepath.IsAbs(name) {
+		return name, nil
+	}
+	custom, err := customConfig(cfg)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(custom.Dir, name), nil
+}
+
+// customConfig returns the dict.custom section with the glossary directory.
+func customConfig(cfg *config.Config) (*dict_custom.Config, error) {
+	c, err := cfg.Dict.GetEndpointConfig("custom")
+	if err != nil {
+		return nil, err
+	}
+	return c.(*dict_custom.Config), nil
+}
+
+func newCmdGlossaryAdd(f *cmdutil.Factory, cfg *config.Config) *cobra.Command {
+	var file, pos, definition string
+	var examples, tags []string
+	cmd := &cobra.Command{
+		Use:   "add <word>",
+		Short: "Add a word or a meaning to the glossary",
+		Example: `  wordflow glossary add wordflow -p n. -d "our terminal dictionary tool" -e "Look it up with wordflow." -t product
+  wordflow glossary add LGTM -d "looks good to me" -f review.tsv`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			word := strings.TrimSpace(strings.Join(args, " "))
+			if definition == "" && len(examples) == 0 && len(tags) == 0 {
+				return errors.New("nothing to add, specify a definition with -d, examples with -e or tags with -t")
+			}
+			filename, err := glossaryFile(cfg, file)
+			if err != nil {
+				return err
+			}
+			var meaning *dict_custom.Meaning
+			if definition != "" {
+				meaning = &dict_custom.Meaning{PartOfSpeech: pos, Definition: definition}
+			} else if pos != "" {
+				return errors.New("--pos needs a definition, specify it with -d")
+			}
+			if err := dict_custom.AddMeaning(filename, word, meaning, examples, tags); err != nil {
+				return err
+			}
+			_, _ = fmt.Fprintf(f.IOStreams.Out, "Added %s to %s\n", word, filename)
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&file, "file", "f", "", "Glossary file, relative to dict.custom.dir (default "+dict_custom.DefaultFilename+")")
+	cmd.Flags().StringVarP(&pos, "pos", "p", "", "Part of speech of the definition, e.g. n.")
+	cmd.Flags().StringVarP(&definition, "definition", "d", "", "Definition to add")
+	cmd.Flags().StringArrayVarP(&examples, "example", "e", nil, "Example sentence, can be repeated")
+	cmd.Flags().StringSliceVarP(&tags, "tag", "t", nil, "Tags, comma separated or repeated")
+	return cmd
+}
+
+func newCmdGlossaryEdit(f *cmdutil.Factory, cfg *config.Config) *cobra.Command {
+	var file string
+	cmd := &cobra.Command{
+		Use:   "edit [word]",
+		Short: "Open a glossary file in your editor",
+		Long:  "Open the glossary file that defines the word, or --file, in $VISUAL or $EDITOR. The custom dictionary picks up changes automatically.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			filename, err := glossaryFile(cfg, file)
+			if err != nil {
+				return err
+			}
+			if word := strings.TrimSpace(strings.Join(args, " ")); word != "" && file == "" {
+				files, err := filesDefining(cfg, word)
+				if err != nil {
+					return err
+				}
+				if len(files) > 0 {
+					filename = files[0]
+				}
+			}
+			if _, err := os.Stat(filename); os.IsNotExist(err) {
+				if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+					return errors.Wrap(err, "failed to create glossary directory")
+				}
+				content := ""
+				if ext := strings.ToLower(filepath.Ext(filename)); ext == ".yaml" || ext == ".yml" {
+					content = newGlossaryTemplate
+				}
+				if err := os.WriteFile(filename, []byte(content), 0644); err != nil {
+					return errors.Wrapf(err, "failed to create glossary %s", filename)
+				}
+			}
+			if err := openEditor(filename); err != nil {
+				return err
+			}
+			if _, err := dict_custom.ReadFile(filename); err != nil {
+				return errors.Wrap(err, "the glossary has errors, run edit again to fix them")
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&file, "file", "f", "", "Glossary file, relative to dict.custom.dir (default "+dict_custom.DefaultFilename+")")
+	return cmd
+}
+
+func openEditor(filename string) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+	parts := strings.Fields(editor)
+	c := exec.Command(parts[0], append(parts[1:], filename)...)
+	c.Stdin, c.Stdout, c.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := c.Run(); err != nil {
+		return errors.Wrapf(err, "failed to run editor %s", editor)
+	}
+	return nil
+}
+
+func newCmdGlossaryRm(f *cmdutil.Factory, cfg *config.Config) *cobra.Command {
+	var file string
+	cmd := &cobra.Command{
+		Use:   "rm <word>",
+		Short: "Remove a word from the glossary",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			word := strings.TrimSpace(strings.Join(args, " "))
+			var files []string
+			if file != "" {
+				filename, err := glossaryFile(cfg, file)
+				if err != nil {
+					return err
+				}
+				files = []string{filename}
+			} else {
+				var err error
+				if files, err = filesDefining(cfg, word); err != nil {
+					return err
+				}
+			}
+			removed := false
+			for _, filename := range files {
+				ok, err := dict_custom.Remove(filename, word)
+				if err != nil {
+					return err
+				}
+				if ok {
+					removed = true
+					_, _ = fmt.Fprintf(f.IOStreams.Out, "Removed %s from %s\n", word, filename)
+				}
+			}
+			if !removed {
+				return errors.Errorf("%s is not in the glossary", word)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&file, "file", "f", "", "Only remove the word from this glossary file")
+	return cmd
+}
+
+func filesDefining(cfg *config.Config, word string) ([]string, error) {
+	custom, err := customConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	d, err := dict_custom.NewDictCustom(custom)
+	if err != nil {
+		return nil, err
+	}
+	return d.Files(word)
+}
@@ -4,9 +4,12 @@ import (
 	"fmt"
 
 	"github.com/gogodjzhu/word-flow/internal/config"
+	"github.com/gogodjzhu/word-flow/internal/util"
 	configcmd "github.com/gogodjzhu/word-flow/pkg/cmd/config"
 	"github.com/gogodjzhu/word-flow/pkg/cmd/dict"
+	"github.com/gogodjzhu/word-flow/pkg/cmd/glossary"
 	"github.com/gogodjzhu/word-flow/pkg/cmd/server"
+	tmcmd "github.com/gogodjzhu/word-flow/pkg/cmd/tm"
 	"github.com/gogodjzhu/word-flow/pkg/cmd/trans"
 	versioncmd "github.com/gogodjzhu/word-flow/pkg/cmd/version"
 	"github.com/gogodjzhu/word-flow/pkg/cmdutil"
@@ -39,6 +42,9 @@ func NewCmdRoot(f *cmdutil.Factory) (*cobra.Command, error) {
 			if cfg.Version != config.DefaultConfigVersion {
 				return fmt.Errorf("unsupported config version: %q. Run 'wordflow config init' to regenerate your config", cfg.Version)
 			}
+			if err := util.SetNetwork(cfg.Network); err != nil {
+				return fmt.Errorf("invalid network config: %w", err)
+			}
 			return nil
 		},
 
@@ -65,12 +71,24 @@ func NewCmdRoot(f *cmdutil.Factory) (*cobra.Command, error) {
 		cmd.AddCommand(cmdNotebook)
 	}
 
+	if cmdGlossary, err := glossary.NewCmdGlossary(f); err != nil {
+		return nil, err
+	} else {
+		cmd.AddCommand(cmdGlossary)
+	}
+
 	if cmdTrans, err := trans.NewCmdTrans(f); err != nil {
 		return nil, err
 	} else {
 		cmd.AddCommand(cmdTrans)
 	}
 
+	if cmdTm, err := tmcmd.NewCmdTm(f); err != nil {
+		return nil, err
+	} else {
+		cmd.AddCommand(cmdTm)
+	}
+
 	if cmdServer, err := server.NewCmdServer(f); err != nil {
 		return nil, err
 	} else {
@@ -0,0 +1,243 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/gogodjzhu/word-flow/internal/buzz_error"
+	"github.com/gogodjzhu/word-flow/internal/config"
+	"github.com/gogodjzhu/word-flow/pkg/cmdutil"
+	"github.com/gogodjzhu/word-flow/pkg/dict"
+	dict_dictd "github.com/gogodjzhu/word-flow/pkg/dict/dictd"
+	"github.com/gogodjzhu/word-flow/pkg/dict/entity"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// notebookMu serializes notebook access from concurrent DICT connections.
+var notebookMu sync.Mutex
+
+// dictDatabases exposes every dictionary endpoint whose config is valid, the
+// default one first so that "!" queries prefer it, followed by the notebook.
+func dictDatabases(cfg *config.Config) []dict_dictd.Database {
+	var databases []dict_dictd.Database
+	for _, info := range dict.AvailableDictionaries() {
+		if cfg.Validate(info.Name) != nil {
+			continue
+		}
+		db := &dictDatabase{name: info.Name, description: info.Description, cfg: cfg}
+		if info.Name == cfg.Dict.Default {
+			databases = append([]dict_dictd.Database{db}, databases...)
+		} else {
+			databases = append(databases, db)
+		}
+	}
+	return append(databases, &notebookDatabase{cfg: cfg})
+}
+
+func databaseNames(cfg *config.Config) string {
+	var names []string
+	for _, db := range dictDatabases(cfg) {
+		names = append(names, db.Name())
+	}
+	return strings.Join(names, ", ")
+}
+
+func serveDictProtocol(cfg *config.Config, addr string) error {
+	server := dict_dictd.NewServer(dictDatabases(cfg))
+	server.OnDefine = func(word string, databases []dict_dictd.Database) {
+		if err := markDefined(cfg, word, databases); err != nil {
+			log.Debugf("dict server: failed to mark %q in the notebook: %v", word, err)
+		}
+	}
+	return server.ListenAndServe(addr)
+}
+
+// markDefined records a word defined by a DICT command in the default
+// notebook, with the entry of the first dictionary that defined it.
+func markDefined(cfg *config.Config, word string, databases []dict_dictd.Database) error {
+	for _, db := range databases {
+		d, ok := db.(*dictDatabase)
+		if !ok {
+			continue
+		}
+		wordItem, err := d.search(word)
+		if err != nil || wordItem == nil {
+			return err
+		}
+		notebookMu.Lock()
+		defer notebookMu.Unlock()
+		notebook, err := dict.OpenNotebook(cfg.Notebook.Settings, cfg.Notebook.Default)
+		if err != nil {
+			return err
+		}
+		_, err = notebook.Mark(wordItem.Word, dict.Learning, wordItem)
+		return err
+	}
+	return nil
+}
+
+// dictDatabase serves one dictionary endpoint. The words it defines are
+// recorded in the default notebook by markDefined.
+type dictDatabase struct {
+	name        string
+	description string
+	cfg         *config.Config
+
+	mu         sync.Mutex
+	dictionary dict.Dict
+	lastWord   string
+	last       *entity.WordItem
+}
+
+func (d *dictDatabase) Name() string {
+	return d.name
+}
+
+func (d *dictDatabase) Description() string {
+	return d.description
+}
+
+// search looks word up, creating the dictionary on first use. A word the
+// dictionary does not know yields a nil item. The latest item is kept so that
+// marking it does not look it up again.
+func (d *dictDatabase) search(word string) (*entity.WordItem, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err := d.open(); err != nil {
+		return nil, err
+	}
+	if d.last != nil && d.lastWord == word {
+		return d.last, nil
+	}
+	wordItem, err := d.dictionary.Search(word)
+	if err != nil {
+		if be, ok := err.(buzz_error.BuzzError); ok && be.Code == buzz_error.CodeInvalidInput {
+			return nil, nil
+		}
+		return nil, err
+	}
+	d.lastWord, d.last = word, wordItem
+	return wordItem, nil
+}
+
+// open creates the dictionary on first use, d.mu must be held.
+func (d *dictDatabase) open() error {
+	if d.dictionary != nil {
+		return nil
+	}
+	dictConfig := *d.cfg.Dict
+	dictConfig.Default = d.name
+	dictConfig.Fallback = nil
+	dictionary, err := dict.NewDict(&dictConfig)
+	if err != nil {
+		return err
+	}
+	d.dictionary = dictionary
+	return nil
+}
+
+func (d *dictDatabase) Define(word string) (string, error) {
+	wordItem, err := d.search(word)
+	if err != nil || wordItem == nil {
+		return "", err
+	}
+	return cmdutil.NewRenderer(false).Render(wordItem.Format()), nil
+}
+
+// Match supports the prefix strategy for dictionaries that can list their
+// headwords and the exact one for all, online dictionaries cannot list theirs.
+func (d *dictDatabase) Match(strategy, word string) ([]string, error) {
+	if strategy == "prefix" {
+		return d.matchPrefix(word)
+	}
+	if strategy != "exact" {
+		return nil, nil
+	}
+	wordItem, err := d.search(word)
+	if err != nil || wordItem == nil {
+		return nil, err
+	}
+	return []string{wordItem.Word}, nil
+}
+
+func (d *dictDatabase) matchPrefix(prefix string) ([]string, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err := d.open(); err != nil {
+		return nil, err
+	}
+	pd, ok := d.dictionary.(dict.PrefixDict)
+	if !ok {
+		return nil, nil
+	}
+	words, err := pd.MatchPrefix(prefix)
+	if errors.Is(err, dict.ErrNoHeadwords) {
+		return nil, nil
+	}
+	return words, err
+}
+
+// notebookDatabase serves the words saved in the default notebook.
+type notebookDatabase struct {
+	cfg *config.Config
+}
+
+func (n *notebookDatabase) Name() string {
+	return "notebook"
+}
+
+func (n *notebookDatabase) Description() string {
+	return fmt.Sprintf("Words saved in the wordflow notebook %q", n.cfg.Notebook.Default)
+}
+
+func (n *notebookDatabase) notes() ([]*entity.WordNote, error) {
+	notebookMu.Lock()
+	defer notebookMu.Unlock()
+	notebook, err := dict.OpenNotebook(n.cfg.Notebook.Settings, n.cfg.Notebook.Default)
+	if err != nil {
+		return nil, err
+	}
+	return notebook.ListNotes()
+}
+
+func (n *notebookDatabase) Define(word string) (string, error) {
+	notes, err := n.notes()
+	if err != nil {
+		return "", err
+	}
+	for _, note := range notes {
+		if !strings.EqualFold(note.Word, word) {
+			continue
+		}
+		var b strings.Builder
+		b.WriteString(note.Word + "\n")
+		for _, phonetic := range note.WordPhonetics {
+			if phonetic.Text != "" {
+				b.WriteString(strings.TrimSpace(phonetic.LanguageCode+" ["+phonetic.Text+"]") + "\n")
+			}
+		}
+		if note.Translation != "" {
+			b.WriteString(note.Translation + "\n")
+		}
+		for _, example := range note.Examples {
+			b.WriteString("eg. " + example + "\n")
+		}
+		fmt.Fprintf(&b, "looked up %d times\n", note.LookupTimes)
+		return b.String(), nil
+	}
+	return "", nil
+}
+
+func (n *notebookDatabase) Match(strategy, word string) ([]string, error) {
+	notes, err := n.notes()
+	if err != nil {
+		return nil, err
+	}
+	words := make([]string, len(notes))
+	for i, note := range notes {
+		words[i] = note.Word
+	}
+	return dict_dictd.MatchWords(strategy, word, words), nil
+}
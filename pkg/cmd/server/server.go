@@ -1,11 +1,17 @@
 package server
 
 import (
+	"context"
 	"fmt"
 	"html/template"
+	"mime"
 	"net/http"
+	"net/url"
+	"path"
 	"strings"
+	"sync"
 
+	"github.com/gogodjzhu/word-flow/internal/config"
 	"github.com/gogodjzhu/word-flow/pkg/cmdutil"
 	"github.com/gogodjzhu/word-flow/pkg/dict"
 	"github.com/spf13/cobra"
@@ -175,6 +181,24 @@ var htmlTemplate = `
         .examples .example {
             margin-bottom: 8px;
         }
+        .related {
+            margin-top: 14px;
+            display: flex;
+            flex-wrap: wrap;
+            align-items: center;
+            gap: 6px;
+        }
+        .related a {
+            background: #eef0fd;
+            color: #667eea;
+            padding: 4px 8px;
+            border-radius: 4px;
+            font-size: 13px;
+            text-decoration: none;
+        }
+        .related a:hover {
+            background: #dde1fb;
+        }
         .error {
             background: #ffebee;
             color: #c62828;
@@ -264,7 +288,7 @@ var htmlTemplate = `
                     {{if .IsFavorited}}★{{else}}☆{{end}}
                 </button>
             </div>
-            <div class="source">{{.Source}}</div>
+            <div class="source">{{.Source}}{{if .Language}} · {{.Language}}{{end}}</div>
             
             {{if .Phonetics}}
             <div class="phonetics">
@@ -297,6 +321,25 @@ var htmlTemplate = `
                 {{end}}
             </div>
             {{end}}
+
+            {{if .Synonyms}}
+            <div class="related">
+                <span class="pos">syn.</span>
+                {{range .Synonyms}}<a href="/dict?word={{.}}">{{.}}</a>{{end}}
+            </div>
+            {{end}}
+            {{if .Antonyms}}
+            <div class="related">
+                <span class="pos">ant.</span>
+                {{range .Antonyms}}<a href="/dict?word={{.}}">{{.}}</a>{{end}}
+            </div>
+            {{end}}
+            {{if .Related}}
+            <div class="related">
+                <span class="pos">rel.</span>
+                {{range .Related}}<a href="/dict?word={{.}}">{{.}}</a>{{end}}
+            </div>
+            {{end}}
         </div>
         {{end}}
     </div>
@@ -324,9 +367,13 @@ type TemplateData struct {
 	QueryWord   string
 	Word        string
 	Source      string
+	Language    string
 	Phonetics   []dictPhonetic
 	Meanings    []dictMeaning
 	Examples    []string
+	Synonyms    []string
+	Antonyms    []string
+	Related     []string
 	Error       string
 	Clean       bool
 	IsFavorited bool
@@ -344,21 +391,66 @@ type dictMeaning struct {
 	Examples     []string
 }
 
+// resourceURL points sound:// links of MDict articles at the /mdict/ handler.
+func resourceURL(audio, dictName string) string {
+	if !strings.HasPrefix(audio, "sound://") {
+		return audio
+	}
+	u := "/mdict/" + strings.TrimPrefix(audio, "sound://")
+	if dictName != "" {
+		u += "?dict=" + url.QueryEscape(dictName)
+	}
+	return u
+}
+
 func NewCmdServer(f *cmdutil.Factory) (*cobra.Command, error) {
 	var port int
+	var dictProtocolAddr string
 	cmd := &cobra.Command{
 		Use:   "server",
 		Short: "Start HTTP server for word lookup",
-		Long:  "Start an HTTP server that provides a web interface for dictionary lookups",
+		Long: "Start an HTTP server that provides a web interface for dictionary lookups.\n" +
+			"With --dict-protocol the dictionaries and the notebook are also served over the DICT protocol (RFC 2229), " +
+			"so clients like GoldenDict or dict can query wordflow.",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return startServer(f, port)
+			return startServer(cmd.Context(), f, port, dictProtocolAddr)
 		},
 	}
 	cmd.Flags().IntVarP(&port, "port", "p", 8080, "Port for HTTP server")
+	cmd.Flags().StringVar(&dictProtocolAddr, "dict-protocol", "", "Also serve DICT protocol requests on this address, e.g. :2628")
 	return cmd, nil
 }
 
-func startServer(f *cmdutil.Factory, port int) error {
+// dictCache opens the dictionaries asked for with ?dict= once and keeps them
+// for the server's lifetime, since MDict and StarDict hold their files open.
+type dictCache struct {
+	cfg *config.DictConfig
+
+	mu    sync.Mutex
+	dicts map[string]dict.Dict
+}
+
+func newDictCache(cfg *config.DictConfig, defaultDict dict.Dict) *dictCache {
+	return &dictCache{cfg: cfg, dicts: map[string]dict.Dict{cfg.Default: defaultDict}}
+}
+
+func (c *dictCache) get(name string) (dict.Dict, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if d, ok := c.dicts[name]; ok {
+		return d, nil
+	}
+	newCfg := *c.cfg
+	newCfg.Default = name
+	d, err := dict.NewDict(&newCfg)
+	if err != nil {
+		return nil, err
+	}
+	c.dicts[name] = d
+	return d, nil
+}
+
+func startServer(ctx context.Context, f *cmdutil.Factory, port int, dictProtocolAddr string) error {
 	cfg, err := f.Config()
 	if err != nil {
 		return err
@@ -369,6 +461,7 @@ func startServer(f *cmdutil.Factory, port int) error {
 		return err
 	}
 
+	dicts := newDictCache(cfg.Dict, dictionary)
 	tmpl := template.Must(template.New("dict").Parse(htmlTemplate))
 
 	http.HandleFunc("/dict", func(w http.ResponseWriter, r *http.Request) {
@@ -378,9 +471,7 @@ func startServer(f *cmdutil.Factory, port int) error {
 
 		currentDict := dictionary
 		if dictName != "" {
-			newCfg := *cfg.Dict
-			newCfg.Default = dictName
-			if d, err := dict.NewDict(&newCfg); err == nil {
+			if d, err := dicts.get(dictName); err == nil {
 				currentDict = d
 			}
 		}
@@ -392,7 +483,8 @@ func startServer(f *cmdutil.Factory, port int) error {
 		}
 
 		word = strings.TrimSpace(word)
-		wordItem, err := currentDict.Search(word)
+		// A closed browser tab cancels the lookup.
+		wordItem, err := dict.SearchDetected(r.Context(), currentDict, word)
 		if err != nil {
 			tmpl.Execute(w, TemplateData{QueryWord: word, Error: err.Error(), Clean: clean})
 			return
@@ -445,7 +537,7 @@ func startServer(f *cmdutil.Factory, port int) error {
 			phonetics[i] = dictPhonetic{
 				LanguageCode: p.LanguageCode,
 				Text:         p.Text,
-				Audio:        p.Audio,
+				Audio:        resourceURL(p.Audio, dictName),
 			}
 		}
 
@@ -462,9 +554,13 @@ func startServer(f *cmdutil.Factory, port int) error {
 			QueryWord:   word,
 			Word:        wordItem.Word,
 			Source:      wordItem.Source,
+			Language:    wordItem.Language,
 			Phonetics:   phonetics,
 			Meanings:    meanings,
 			Examples:    wordItem.Examples,
+			Synonyms:    wordItem.Synonyms,
+			Antonyms:    wordItem.Antonyms,
+			Related:     wordItem.Related,
 			Clean:       clean,
 			IsFavorited: isFavorited,
 		}
@@ -515,7 +611,7 @@ func startServer(f *cmdutil.Factory, port int) error {
 				return
 			}
 		} else {
-			wordItem, err := dictionary.Search(word)
+			wordItem, err := dict.SearchDetected(r.Context(), dictionary, word)
 			if err != nil {
 				http.Error(w, err.Error(), http.StatusInternalServerError)
 				return
@@ -530,6 +626,33 @@ func startServer(f *cmdutil.Factory, port int) error {
 		fmt.Fprintf(w, `{"isFavorited": %v}`, !isFavorited)
 	})
 
+	http.HandleFunc("/mdict/", func(w http.ResponseWriter, r *http.Request) {
+		currentDict := dictionary
+		if dictName := r.URL.Query().Get("dict"); dictName != "" {
+			d, err := dicts.get(dictName)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			currentDict = d
+		}
+		provider, ok := currentDict.(dict.ResourceProvider)
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		name := strings.TrimPrefix(r.URL.Path, "/mdict/")
+		data, err := provider.Resource(name)
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		if contentType := mime.TypeByExtension(path.Ext(name)); contentType != "" {
+			w.Header().Set("Content-Type", contentType)
+		}
+		_, _ = w.Write(data)
+	})
+
 	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path != "/" && r.URL.Path != "/dict" {
 			http.Redirect(w, r, "/", http.StatusFound)
@@ -545,5 +668,22 @@ func startServer(f *cmdutil.Factory, port int) error {
 	fmt.Printf("  GET /dict?word=<word>              - Lookup word\n")
 	fmt.Printf("  GET /dict?word=<word>&clean=true   - Clean mode (hide search box)\n")
 	fmt.Printf("  GET /dict?word=<word>&dict=<dict>   - Use specific dictionary\n")
-	return http.ListenAndServe(fmt.Sprintf(":%d", port), nil)
+	fmt.Printf("  GET /mdict/<resource>              - Audio and images from MDict .mdd files\n")
+
+	errs := make(chan error, 2)
+	if dictProtocolAddr != "" {
+		fmt.Printf("Serving DICT protocol on %s (databases: %s)\n", dictProtocolAddr, databaseNames(cfg))
+		go func() {
+			errs <- serveDictProtocol(cfg, dictProtocolAddr)
+		}()
+	}
+	go func() {
+		errs <- http.ListenAndServe(fmt.Sprintf(":%d", port), nil)
+	}()
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+		return nil
+	}
 }
@@ -0,0 +1,114 @@
+package tm
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/gogodjzhu/word-flow/internal/config"
+	"github.com/gogodjzhu/word-flow/pkg/cmdutil"
+	"github.com/gogodjzhu/word-flow/pkg/translator/tm"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func NewCmdTm(f *cmdutil.Factory) (*cobra.Command, error) {
+	cfg, err := f.Config()
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := &cobra.Command{
+		Use:   "tm <subcommand>",
+		Short: "Import and export the translation memory",
+		Long: "The translation memory keeps the segments translated by 'wordflow trans' in trans.memory.db_filename. " +
+			"Share it with other tools as TMX files.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Help()
+		},
+	}
+	cmd.AddCommand(newCmdTmImport(f, cfg))
+	cmd.AddCommand(newCmdTmExport(f, cfg))
+	return cmd, nil
+}
+
+func openStore(cfg *config.Config) (*tm.Store, error) {
+	if cfg.Trans == nil || cfg.Trans.Memory == nil || cfg.Trans.Memory.DBFilename == "" {
+		return nil, errors.New("trans.memory.db_filename is not configured")
+	}
+	return tm.Open(cfg.Trans.Memory.DBFilename)
+}
+
+func newCmdTmImport(f *cmdutil.Factory, cfg *config.Config) *cobra.Command {
+	var translatorName string
+	cmd := &cobra.Command{
+		Use:   "import <file.tmx>",
+		Short: "Add the translation units of a TMX file to the translation memory",
+		Long: "Add the translation units of a TMX file to the translation memory. Units are reused by the " +
+			"translator of their x-translator property, or --translator.",
+		Example: `  wordflow tm import glossary.tmx
+  wordflow tm import legacy.tmx --translator google`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if translatorName == "" && cfg.Trans != nil {
+				translatorName = cfg.Trans.Default
+			}
+			file, err := os.Open(args[0])
+			if err != nil {
+				return errors.Wrap(err, "failed to open TMX file")
+			}
+			defer file.Close()
+			store, err := openStore(cfg)
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+			imported, skipped, err := store.Import(file, translatorName)
+			if err != nil {
+				return err
+			}
+			_, _ = fmt.Fprintf(f.IOStreams.Out, "Imported %d translations", imported)
+			if skipped > 0 {
+				_, _ = fmt.Fprintf(f.IOStreams.Out, ", skipped %d in unsupported languages", skipped)
+			}
+			_, _ = fmt.Fprintln(f.IOStreams.Out)
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&translatorName, "translator", "t", "", "Translator of units without an x-translator property (default trans.default)")
+	return cmd
+}
+
+func newCmdTmExport(f *cmdutil.Factory, cfg *config.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export [file.tmx]",
+		Short: "Write the translation memory as a TMX file",
+		Long:  "Write the translation memory as a TMX 1.4 file, or to stdout without a file.",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := openStore(cfg)
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+			var w io.Writer = f.IOStreams.Out
+			if len(args) > 0 {
+				file, err := os.Create(args[0])
+				if err != nil {
+					return errors.Wrap(err, "failed to create TMX file")
+				}
+				defer file.Close()
+				w = file
+			}
+			n, err := store.Export(w)
+			if err != nil {
+				return err
+			}
+			if len(args) > 0 {
+				_, _ = fmt.Fprintf(f.IOStreams.Out, "Exported %d translations to %s\n", n, args[0])
+			}
+			return nil
+		},
+	}
+	return cmd
+}
@@ -0,0 +1,269 @@
+package trans
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/gogodjzhu/word-flow/internal/buzz_error"
+	"github.com/gogodjzhu/word-flow/internal/config"
+	"github.com/gogodjzhu/word-flow/pkg/cmdutil"
+	"github.com/gogodjzhu/word-flow/pkg/translator"
+	"github.com/gogodjzhu/word-flow/pkg/translator/glossary"
+	"github.com/gogodjzhu/word-flow/pkg/translator/tm"
+	"github.com/pkg/errors"
+)
+
+// compareJob is the comparison of the translators given with --compare.
+type compareJob struct {
+	names       []string
+	translators []translator.Translator
+	// glossary, if any, is the one whose terms the renditions are checked
+	// against.
+	glossary *glossary.Glossary
+	// pick is the translator whose rendition is saved without asking.
+	pick string
+	json bool
+}
+
+func newCompareJob(cfg *config.Config, list, pick string, asJSON, memory bool) (*compareJob, error) {
+	var g *glossary.Glossary
+	if cfg.Trans.Glossary != "" {
+		var err error
+		if g, err = glossary.Load(cfg.Trans.Glossary); err != nil {
+			return nil, err
+		}
+	}
+	job := &compareJob{glossary: g, pick: pick, json: asJSON}
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" || job.index(name) >= 0 {
+			continue
+		}
+		// Each translator is the default of a copy of the configuration.
+		trans := *cfg.Trans
+		trans.Default = name
+		c := *cfg
+		c.Trans = &trans
+		if err := config.ValidateForTrans(&c); err != nil {
+			return nil, errors.Wrap(err, name)
+		}
+		t, err := translator.NewTranslator(&trans)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to create translator %s", name)
+		}
+		if g != nil {
+			t = translator.NewGlossaryTranslator(&trans, t, g)
+		}
+		job.names = append(job.names, name)
+		job.translators = append(job.translators, t)
+	}
+	if len(job.names) < 2 {
+		return nil, buzz_error.InvalidInput("--compare needs at least two translators, e.g. --compare google,baidu")
+	}
+	if pick != "" {
+		if !memory {
+			return nil, buzz_error.InvalidInput("--pick saves to the translation memory, which is disabled")
+		}
+		if job.index(pick) < 0 {
+			return nil, buzz_error.InvalidInput("--pick " + pick + " is not one of the compared translators")
+		}
+	}
+	return job, nil
+}
+
+func (job *compareJob) index(name string) int {
+	for i, n := range job.names {
+		if n == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// checkLanguages validates the languages of the comparison with each
+// translator.
+func (job *compareJob) checkLanguages(from, to string) error {
+	for i, t := range job.translators {
+		if err := translator.CheckLanguages(t, from, to); err != nil {
+			return errors.Wrap(err, job.names[i])
+		}
+	}
+	return nil
+}
+
+// run compares the translations of text. The picked rendition is saved to
+// store, if not nil, as translations of the translator that produced it.
+// Without --pick the user is asked on in when ask is set.
+func (job *compareJob) run(ctx context.Context, text string, opts *translator.TransOptions, store *tm.Store, in io.Reader, ask bool, renderer *cmdutil.Renderer, out io.Writer) error {
+	c := translator.Compare(ctx, text, job.names, job.translators, opts)
+	if job.glossary != nil {
+		c.Check(job.glossary)
+	}
+	failed := 0
+	for _, r := range c.Renditions {
+		if r.Err != nil {
+			failed++
+		}
+	}
+	if failed == len(c.Renditions) {
+		return errors.Wrap(c.Renditions[0].Err, "failed to translate text")
+	}
+
+	picked := job.index(job.pick)
+	if picked >= 0 && c.Renditions[picked].Err != nil {
+		return buzz_error.InvalidInput(job.pick + " failed, its translation cannot be picked")
+	}
+	if job.json {
+		if picked >= 0 && store != nil {
+			if err := c.Remember(store, job.names[picked], picked); err != nil {
+				return err
+			}
+		}
+		return writeComparisonJSON(c, job.pick, out)
+	}
+
+	if err := renderComparison(renderer, c, out); err != nil {
+		return errors.Wrap(err, "failed to render comparison")
+	}
+	if store == nil {
+		return nil
+	}
+	if picked < 0 && ask {
+		var err error
+		if picked, err = askPick(renderer, c, in, out); err != nil || picked < 0 {
+			return err
+		}
+	}
+	if picked < 0 {
+		return nil
+	}
+	if err := c.Remember(store, job.names[picked], picked); err != nil {
+		return err
+	}
+	return renderer.RenderToWriter([]cmdutil.MarkupSegment{
+		{Text: "Saved the translation of " + c.Renditions[picked].Translator + " to the translation memory\n", Type: cmdutil.MarkupComment},
+	}, out)
+}
+
+// renderComparison shows each segment followed by its numbered renditions.
+func renderComparison(renderer *cmdutil.Renderer, c *translator.Comparison, out io.Writer) error {
+	width := 0
+	for _, r := range c.Renditions {
+		if len(r.Translator) > width {
+			width = len(r.Translator)
+		}
+	}
+	var segments []cmdutil.MarkupSegment
+	for i, source := range c.Segments {
+		if i > 0 {
+			segments = append(segments, cmdutil.MarkupSegment{Text: "\n", Type: cmdutil.MarkupText})
+		}
+		segments = append(segments, cmdutil.MarkupSegment{Text: source + "\n", Type: cmdutil.MarkupText})
+		for j, r := range c.Renditions {
+			if r.Err != nil {
+				continue
+			}
+			segments = append(segments,
+				cmdutil.MarkupSegment{Text: fmt.Sprintf("  %d %-*s  ", j+1, width, r.Translator), Type: cmdutil.MarkupNote},
+				cmdutil.MarkupSegment{Text: r.Translations[i] + "\n", Type: cmdutil.MarkupRef},
+			)
+		}
+	}
+	for _, r := range c.Renditions {
+		if r.Err != nil {
+			segments = append(segments, cmdutil.MarkupSegment{Text: "\n" + r.Translator + " failed: " + r.Err.Error() + "\n", Type: cmdutil.MarkupComment})
+		}
+	}
+	for _, r := range c.Renditions {
+		for _, v := range r.Violations {
+			segments = append(segments,
+				cmdutil.MarkupSegment{Text: "\nGlossary, " + r.Translator + ": " + violationRule(v) + " in: ", Type: cmdutil.MarkupComment},
+				cmdutil.MarkupSegment{Text: v.Segment + "\n", Type: cmdutil.MarkupNote},
+			)
+		}
+	}
+	return renderer.RenderToWriter(segments, out)
+}
+
+// askPick asks for the number of the rendition to save, -1 when the user
+// skips it.
+func askPick(renderer *cmdutil.Renderer, c *translator.Comparison, in io.Reader, out io.Writer) (int, error) {
+	prompt := fmt.Sprintf("\nSave a translation to the translation memory [1-%d, Enter to skip]: ", len(c.Renditions))
+	if err := renderer.RenderToWriter([]cmdutil.MarkupSegment{{Text: prompt, Type: cmdutil.MarkupComment}}, out); err != nil {
+		return -1, err
+	}
+	line, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return -1, errors.Wrap(err, "failed to read the choice")
+	}
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return -1, nil
+	}
+	n, err := strconv.Atoi(line)
+	if err != nil || n < 1 || n > len(c.Renditions) {
+		return -1, buzz_error.InvalidInput("Invalid choice " + strconv.Quote(line))
+	}
+	if r := c.Renditions[n-1]; r.Err != nil {
+		return -1, buzz_error.InvalidInput(r.Translator + " failed, its translation cannot be picked")
+	}
+	return n - 1, nil
+}
+
+type comparisonJSON struct {
+	From     string            `json:"from"`
+	To       string            `json:"to"`
+	Segments []comparedSegment `json:"segments"`
+	// Errors are the messages of the translators that failed.
+	Errors map[string]string `json:"errors,omitempty"`
+	// Violations are the glossary terms each translator did not follow.
+	Violations map[string][]comparedViolation `json:"violations,omitempty"`
+	Picked     string                         `json:"picked,omitempty"`
+}
+
+type comparedSegment struct {
+	Source       string            `json:"source"`
+	Translations map[string]string `json:"translations"`
+}
+
+type comparedViolation struct {
+	Segment     string `json:"segment"`
+	Term        string `json:"term"`
+	Translation string `json:"translation"`
+}
+
+func writeComparisonJSON(c *translator.Comparison, picked string, out io.Writer) error {
+	data := comparisonJSON{From: c.From, To: c.To, Segments: []comparedSegment{}, Picked: picked}
+	for i, source := range c.Segments {
+		segment := comparedSegment{Source: source, Translations: map[string]string{}}
+		for _, r := range c.Renditions {
+			if r.Err == nil {
+				segment.Translations[r.Translator] = r.Translations[i]
+			}
+		}
+		data.Segments = append(data.Segments, segment)
+	}
+	for _, r := range c.Renditions {
+		if r.Err != nil {
+			if data.Errors == nil {
+				data.Errors = map[string]string{}
+			}
+			data.Errors[r.Translator] = r.Err.Error()
+		}
+		for _, v := range r.Violations {
+			if data.Violations == nil {
+				data.Violations = map[string][]comparedViolation{}
+			}
+			data.Violations[r.Translator] = append(data.Violations[r.Translator], comparedViolation{Segment: v.Segment, Term: v.Term.Source, Translation: v.Term.Translation()})
+		}
+	}
+	e := json.NewEncoder(out)
+	e.SetEscapeHTML(false)
+	e.SetIndent("", "  ")
+	return errors.Wrap(e.Encode(data), "failed to write comparison")
+}
@@ -0,0 +1,79 @@
+package trans
+
+import (
+	"context"
+	"io"
+	"os"
+
+	"github.com/gogodjzhu/word-flow/internal/buzz_error"
+	"github.com/gogodjzhu/word-flow/pkg/document"
+	"github.com/pkg/errors"
+)
+
+// documentJob is the translation of a document given with --file or
+// --format.
+type documentJob struct {
+	format document.Format
+	src    []byte
+	output string
+	opts   document.Options
+	// mismatches are the entries whose translation lost placeholders.
+	mismatches []document.Mismatch
+}
+
+func newDocumentJob(stdin io.Reader, file, format, output string, opts document.Options, hasArgs, ref bool) (*documentJob, error) {
+	if hasArgs {
+		return nil, buzz_error.InvalidInput("Text arguments cannot be used with --file or --format")
+	}
+	if ref {
+		return nil, buzz_error.InvalidInput("--ref cannot be used with --file or --format")
+	}
+	job := &documentJob{output: output, opts: opts}
+	job.opts.Mismatch = func(m document.Mismatch) {
+		job.mismatches = append(job.mismatches, m)
+	}
+	var ok bool
+	if format != "" {
+		if job.format, ok = document.Lookup(format); !ok {
+			return nil, buzz_error.InvalidInput("Unsupported --format " + format)
+		}
+	} else if job.format, ok = document.ForFile(file); !ok {
+		return nil, buzz_error.InvalidInput("Unknown format of " + file + ", specify it with --format")
+	}
+
+	var err error
+	if file == "" || file == "-" {
+		job.src, err = io.ReadAll(stdin)
+	} else {
+		job.src, err = os.ReadFile(file)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read document")
+	}
+	if len(job.src) == 0 {
+		return nil, buzz_error.InvalidInput("The document is empty")
+	}
+	// The translations of an earlier run are kept, unless --force.
+	if output != "" && !opts.Force {
+		if job.opts.Previous, err = os.ReadFile(output); err != nil && !os.IsNotExist(err) {
+			return nil, errors.Wrap(err, "failed to read previous translation")
+		}
+	}
+	return job, nil
+}
+
+// run translates the document to the output file, or to out.
+func (j *documentJob) run(ctx context.Context, translate document.TranslateFunc, out io.Writer) error {
+	translated, err := j.format.Translate(ctx, j.src, translate, j.opts)
+	if err != nil {
+		return errors.Wrapf(err, "failed to translate %s document", j.format.Name)
+	}
+	if j.output == "" {
+		_, err = out.Write(translated)
+		return err
+	}
+	if err := os.WriteFile(j.output, translated, 0644); err != nil {
+		return errors.Wrap(err, "failed to write translated document")
+	}
+	return nil
+}
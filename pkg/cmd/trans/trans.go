@@ -3,13 +3,20 @@ package trans
 import (
 	"bufio"
 	"bytes"
+	"context"
+	"fmt"
 	"io"
+	"strconv"
 	"strings"
 
 	"github.com/gogodjzhu/word-flow/internal/buzz_error"
 	"github.com/gogodjzhu/word-flow/internal/config"
+	"github.com/gogodjzhu/word-flow/internal/lang"
 	"github.com/gogodjzhu/word-flow/pkg/cmdutil"
+	"github.com/gogodjzhu/word-flow/pkg/document"
 	"github.com/gogodjzhu/word-flow/pkg/translator"
+	"github.com/gogodjzhu/word-flow/pkg/translator/glossary"
+	"github.com/gogodjzhu/word-flow/pkg/translator/tm"
 	"github.com/gogodjzhu/word-flow/pkg/translator/types"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
@@ -19,6 +26,12 @@ func NewCmdTrans(f *cmdutil.Factory) (*cobra.Command, error) {
 	var noStream bool
 	var ref bool
 	var endpoint string
+	var from, to string
+	var noMemory bool
+	var file, format, output string
+	var bilingual, force bool
+	var compare, pick string
+	var asJSON bool
 
 	cfg, err := f.Config()
 	if err != nil {
@@ -28,12 +41,34 @@ func NewCmdTrans(f *cmdutil.Factory) (*cobra.Command, error) {
 
 	cmd := &cobra.Command{
 		Use:   "trans [text]",
-		Short: "Translate English text to Chinese",
-		Long: `Translate English text to Chinese.
+		Example: `  wordflow trans "Hello world, this is a test."
+  wordflow trans --from ja --to en "おはようございます。"
+  wordflow trans --file README.md -o README.zh.md
+  wordflow trans --file book.epub -o book.zh.epub
+  wordflow trans --compare llm,google,baidu "Hello world, this is a test."`,
+		Short: "Translate text, to Chinese by default",
+		Long: `Translate text, from the detected language to Chinese by default.
 Supports both command line arguments and stdin (pipe) input.
+Use --from and --to to choose the languages, e.g. --from en --to ja, or set
+from and to in the translator's configuration. Supported codes are
+` + strings.Join(lang.Codes(), ", ") + ` and auto as source.
 When --ref is enabled, shows original and translation in segment pairs.
 Use --no-stream to get formatted output with --ref.
-Use --endpoint to override the default translator (baidu, google, llm).`,
+Use --endpoint to override the default translator (` + strings.Join(translator.AvailableEndpoints(), ", ") + `).
+Translated segments are kept in the translation memory and reused, skip it
+with --no-memory. The terms of trans.glossary are enforced, and segments whose
+translation does not follow them are listed after the translation.
+Use --file to translate a document with its structure, the format is told by
+the file extension or --format (` + strings.Join(document.Names(), ", ") + `). The translation
+is written to --output, or to stdout. HTML pages and EPUB books come out
+bilingual, each paragraph followed by its translation. Only the values of
+resource files (i18n-json, i18n-yaml, po) are translated, and entries that
+already have a translation, in the PO file or in the --output of an earlier
+run, are skipped unless --force.
+Use --compare to translate with several translators in parallel and see their
+translations sentence by sentence, or as JSON with --json. The translation
+picked, with --pick or when asked, is saved to the translation memory under
+the translator that produced it.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			cfg, err := f.Config()
 			if err != nil {
@@ -44,17 +79,63 @@ Use --endpoint to override the default translator (baidu, google, llm).`,
 				cfg.Trans.Default = endpoint
 			}
 
-			if err := config.ValidateForTrans(cfg); err != nil {
-				return err
+			useMemory := !noMemory && !cfg.Trans.Memory.Disabled
+			var compared *compareJob
+			if compare != "" {
+				if file != "" || format != "" || ref {
+					return buzz_error.InvalidInput("--compare cannot be used with --file, --format or --ref")
+				}
+				if compared, err = newCompareJob(cfg, compare, pick, asJSON, useMemory); err != nil {
+					return err
+				}
+			} else if pick != "" || asJSON {
+				return buzz_error.InvalidInput("--pick and --json need --compare")
 			}
 
-			t, err := translator.NewTranslator(cfg.Trans)
-			if err != nil {
-				return errors.Wrap(err, "failed to create translator")
+			var t translator.Translator
+			var terms *translator.GlossaryTranslator
+			var detector translator.Detector
+			if compared == nil {
+				if err := config.ValidateForTrans(cfg); err != nil {
+					return err
+				}
+				if t, err = translator.NewTranslator(cfg.Trans); err != nil {
+					return errors.Wrap(err, "failed to create translator")
+				}
+				detector, _ = t.(translator.Detector)
+				if cfg.Trans.Glossary != "" {
+					g, err := glossary.Load(cfg.Trans.Glossary)
+					if err != nil {
+						return err
+					}
+					terms = translator.NewGlossaryTranslator(cfg.Trans, t, g)
+					t = terms
+				}
+			}
+			var store *tm.Store
+			var memory *translator.MemoryTranslator
+			if useMemory {
+				if store, err = tm.Open(cfg.Trans.Memory.DBFilename); err != nil {
+					return err
+				}
+				defer store.Close()
+				if compared == nil {
+					memory = translator.NewMemoryTranslator(cfg.Trans, t, store)
+					t = memory
+				}
+			}
+
+			var doc *documentJob
+			if file != "" || format != "" {
+				if doc, err = newDocumentJob(f.IOStreams.In, file, format, output, document.Options{Bilingual: bilingual, Force: force}, len(args) > 0, ref); err != nil {
+					return err
+				}
 			}
 
 			var text string
-			if len(args) > 0 {
+			if doc != nil {
+				text = string(doc.src)
+			} else if len(args) > 0 {
 				text = strings.Join(args, " ")
 			} else {
 				text, err = readFromStdin(f.IOStreams.In)
@@ -75,10 +156,56 @@ Use --endpoint to override the default translator (baidu, google, llm).`,
 				Ref:      ref,
 				NoStream: noStream,
 			}
+			if opts.From, err = lang.Normalize(from); err != nil {
+				return buzz_error.InvalidInput("Invalid --from: " + err.Error())
+			}
+			if opts.To, err = lang.Normalize(to); err != nil {
+				return buzz_error.InvalidInput("Invalid --to: " + err.Error())
+			}
+			var defaults config.LanguageDefaults
+			if endpointConfig, err := cfg.Trans.GetEndpointConfig(cfg.Trans.Default); err == nil {
+				defaults, _ = endpointConfig.(config.LanguageDefaults)
+			}
+			source, target := opts.Languages(defaults)
+			detected := source == lang.Auto
+			if detected {
+				source, target = detectDirection(cmd.Context(), trimmedText, target, opts.To != "", detector)
+				detected = source != lang.Auto
+				if detected {
+					opts.From, opts.To = source, target
+				}
+			}
+			if compared != nil {
+				err = compared.checkLanguages(source, target)
+			} else {
+				err = translator.CheckLanguages(t, source, target)
+			}
+			if err != nil {
+				return err
+			}
+			// Notes go to stderr, stdout has only the translation for pipes
+			// and the JSON of --ref.
+			notes := cmd.ErrOrStderr()
+			if detected {
+				if err := renderDetected(f.IOStreams.Renderer, source, target, notes); err != nil {
+					return errors.Wrap(err, "failed to render detected language")
+				}
+			}
 
-			if noStream {
+			if compared != nil {
+				opts.From, opts.To = source, target
+				// The text of stdin leaves no input to ask for a choice.
+				return compared.run(cmd.Context(), text, opts, store, f.IOStreams.In, len(args) > 0, f.IOStreams.Renderer, f.IOStreams.Out)
+			} else if doc != nil {
+				if err := doc.run(cmd.Context(), document.Translator(t, opts), f.IOStreams.Out); err != nil {
+					return err
+				}
+				if err := renderMismatches(f.IOStreams.Renderer, doc.mismatches, notes); err != nil {
+					return errors.Wrap(err, "failed to render placeholder mismatches")
+				}
+			} else if noStream {
 				var buf bytes.Buffer
-				err := t.Translate(text, &buf, opts)
+				err := translator.Translate(cmd.Context(), t, text, &buf, opts)
 				if err != nil {
 					return errors.Wrap(err, "failed to translate text")
 				}
@@ -96,7 +223,7 @@ Use --endpoint to override the default translator (baidu, google, llm).`,
 				pr, pw := io.Pipe()
 				go func() {
 					defer pw.Close()
-					err := t.Translate(text, pw, opts)
+					err := translator.Translate(cmd.Context(), t, text, pw, opts)
 					if err != nil {
 						_ = pw.CloseWithError(err)
 					}
@@ -108,11 +235,21 @@ Use --endpoint to override the default translator (baidu, google, llm).`,
 					return errors.Wrap(err, "failed to process stream")
 				}
 			} else {
-				err := t.Translate(text, f.IOStreams.Out, opts)
+				err := translator.Translate(cmd.Context(), t, text, f.IOStreams.Out, opts)
 				if err != nil {
 					return errors.Wrap(err, "failed to translate text")
 				}
 			}
+			if memory != nil {
+				if err := renderMemory(f.IOStreams.Renderer, memory, notes); err != nil {
+					return errors.Wrap(err, "failed to render translation memory")
+				}
+			}
+			if terms != nil {
+				if err := renderViolations(f.IOStreams.Renderer, terms.Violations(), notes); err != nil {
+					return errors.Wrap(err, "failed to render glossary violations")
+				}
+			}
 			return nil
 		},
 		PreRunE: func(cmd *cobra.Command, args []string) error {
@@ -125,7 +262,18 @@ Use --endpoint to override the default translator (baidu, google, llm).`,
 
 	cmd.Flags().BoolVar(&noStream, "no-stream", false, "Disable streaming output")
 	cmd.Flags().BoolVar(&ref, "ref", false, "Show original text with translation in segment pairs")
-	cmd.Flags().StringVarP(&endpoint, "endpoint", "e", "", "Override default translator (google, llm)")
+	cmd.Flags().StringVarP(&endpoint, "endpoint", "e", "", "Override default translator ("+strings.Join(translator.AvailableEndpoints(), ", ")+")")
+	cmd.Flags().StringVar(&from, "from", "", "Source language code, or auto to detect it")
+	cmd.Flags().StringVar(&to, "to", "", "Target language code")
+	cmd.Flags().BoolVar(&noMemory, "no-memory", false, "Neither reuse nor remember translations in the translation memory")
+	cmd.Flags().StringVarP(&file, "file", "f", "", "Translate a document file, - for stdin")
+	cmd.Flags().StringVar(&format, "format", "", "Document format: "+strings.Join(document.Names(), ", ")+" (default by file extension)")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Write the translated document to a file instead of stdout")
+	cmd.Flags().BoolVar(&bilingual, "bilingual", false, "Keep the original text next to its translation in documents (srt, vtt)")
+	cmd.Flags().BoolVar(&force, "force", false, "Translate the entries of resource files that already have a translation")
+	cmd.Flags().StringVar(&compare, "compare", "", "Compare the translations of several translators, e.g. llm,google,baidu")
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Print the --compare result as JSON")
+	cmd.Flags().StringVar(&pick, "pick", "", "Save the translation of this --compare translator to the translation memory")
 
 	return cmd, nil
 }
@@ -154,4 +302,117 @@ func readFromStdin(r io.Reader) (string, error) {
 func renderWithRef(renderer *cmdutil.Renderer, translation string, out io.Writer) error {
 	translationRenderer := NewTranslationRenderer(renderer, out)
 	return translationRenderer.RenderTranslationWithRef(translation)
+}
+
+// detectDirection returns the source language detected in text, auto when the
+// detection is not reliable. The translator's own detection, if it has one,
+// is asked when the local one is not. Chinese text is translated to English
+// unless the target was given explicitly.
+func detectDirection(ctx context.Context, text, target string, targetGiven bool, detector translator.Detector) (string, string) {
+	code := lang.Auto
+	if detection := lang.Detect(text); detection.Reliable() {
+		code = detection.Code
+	} else if detector != nil {
+		// Failures leave the detection to the translation request.
+		if detected, err := detector.Detect(ctx, text); err == nil {
+			code = detected
+		}
+	}
+	if code == lang.Auto {
+		return lang.Auto, target
+	}
+	if !targetGiven && lang.IsChinese(code) && lang.IsChinese(target) {
+		target = "en"
+	}
+	return code, target
+}
+
+// renderDetected reports the detected source language before the translation.
+func renderDetected(renderer *cmdutil.Renderer, from, to string, out io.Writer) error {
+	source, _ := lang.Lookup(from)
+	target, _ := lang.Lookup(to)
+	return renderer.RenderToWriter([]cmdutil.MarkupSegment{
+		{Text: "Detected " + source.Name + ", translating to " + target.Name + "\n", Type: cmdutil.MarkupComment},
+	}, out)
+}
+
+// renderMemory reports the segments reused from the translation memory and
+// the fuzzy matches offered for the other ones.
+func renderMemory(renderer *cmdutil.Renderer, memory *translator.MemoryTranslator, out io.Writer) error {
+	var segments []cmdutil.MarkupSegment
+	if hits, total := memory.Hits(); hits > 0 {
+		segments = append(segments, cmdutil.MarkupSegment{
+			Text: fmt.Sprintf("\nTranslation memory: %d of %d segments reused", hits, total),
+			Type: cmdutil.MarkupComment,
+		})
+	}
+	for _, match := range memory.Suggestions() {
+		segments = append(segments,
+			cmdutil.MarkupSegment{Text: fmt.Sprintf("\nFuzzy match %.0f%%: ", match.Similarity*100), Type: cmdutil.MarkupComment},
+			cmdutil.MarkupSegment{Text: match.Source, Type: cmdutil.MarkupNote},
+			cmdutil.MarkupSegment{Text: " → " + match.Translation, Type: cmdutil.MarkupRef},
+		)
+	}
+	if len(segments) == 0 {
+		return nil
+	}
+	segments = append(segments, cmdutil.MarkupSegment{Text: "\n", Type: cmdutil.MarkupText})
+	return renderer.RenderToWriter(segments, out)
+}
+
+// renderMismatches lists the entries of resource files whose translation
+// lost or added placeholders.
+func renderMismatches(renderer *cmdutil.Renderer, mismatches []document.Mismatch, out io.Writer) error {
+	if len(mismatches) == 0 {
+		return nil
+	}
+	var segments []cmdutil.MarkupSegment
+	for _, m := range mismatches {
+		var problems []string
+		if len(m.Missing) > 0 {
+			problems = append(problems, "misses "+quoteAll(m.Missing))
+		}
+		if len(m.Extra) > 0 {
+			problems = append(problems, "adds "+quoteAll(m.Extra))
+		}
+		segments = append(segments,
+			cmdutil.MarkupSegment{Text: "\nPlaceholders: the translation " + strings.Join(problems, " and ") + " in: ", Type: cmdutil.MarkupComment},
+			cmdutil.MarkupSegment{Text: m.Key, Type: cmdutil.MarkupNote},
+		)
+	}
+	segments = append(segments, cmdutil.MarkupSegment{Text: "\n", Type: cmdutil.MarkupText})
+	return renderer.RenderToWriter(segments, out)
+}
+
+func quoteAll(values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = strconv.Quote(v)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// renderViolations lists the segments whose translation does not follow the
+// glossary.
+func renderViolations(renderer *cmdutil.Renderer, violations []glossary.Violation, out io.Writer) error {
+	if len(violations) == 0 {
+		return nil
+	}
+	var segments []cmdutil.MarkupSegment
+	for _, v := range violations {
+		segments = append(segments,
+			cmdutil.MarkupSegment{Text: "\nGlossary: " + violationRule(v) + " in: ", Type: cmdutil.MarkupComment},
+			cmdutil.MarkupSegment{Text: v.Segment, Type: cmdutil.MarkupNote},
+		)
+	}
+	segments = append(segments, cmdutil.MarkupSegment{Text: "\n", Type: cmdutil.MarkupText})
+	return renderer.RenderToWriter(segments, out)
+}
+
+// violationRule describes the term a violation does not follow.
+func violationRule(v glossary.Violation) string {
+	if v.Term.Keep() {
+		return fmt.Sprintf("%q should stay untranslated", v.Term.Source)
+	}
+	return fmt.Sprintf("%q should be translated as %q", v.Term.Source, v.Term.Translation())
 }
\ No newline at end of file
@@ -21,6 +21,7 @@ type keyMap struct {
 	RateEasy  key.Binding
 	ShowDef   key.Binding
 	ShowEx    key.Binding
+	ShowRel   key.Binding
 	Skip      key.Binding
 	Quit      key.Binding
 	Help      key.Binding
@@ -53,6 +54,10 @@ func DefaultKeyMap() keyMap {
 			key.WithKeys("e"),
 			key.WithHelp("e", "Toggle examples"),
 		),
+		ShowRel: key.NewBinding(
+			key.WithKeys("r"),
+			key.WithHelp("r", "Toggle synonyms and antonyms"),
+		),
 		Skip: key.NewBinding(
 			key.WithKeys("s", "tab"),
 			key.WithHelp("s/Tab", "Skip word"),
@@ -127,6 +132,7 @@ type Model struct {
 	currentIdx int
 	showDef    bool
 	showEx     bool
+	showRel    bool
 	showHelp   bool
 	pending    *fsrs.Rating
 	completed  int
@@ -146,6 +152,7 @@ func NewModel(words []*entity.WordNote, scheduler *fsrs.Scheduler) Model {
 		currentIdx: 0,
 		showDef:    false, // Hidden by default
 		showEx:     false, // Hidden by default
+		showRel:    false, // Hidden by default
 		showHelp:   false,
 		pending:    nil,
 		completed:  0,
@@ -181,6 +188,10 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.showEx = !m.showEx
 			return m, nil
 
+		case key.Matches(msg, m.keys.ShowRel):
+			m.showRel = !m.showRel
+			return m, nil
+
 		case key.Matches(msg, m.keys.Skip):
 			m.skipped++
 			m.pending = nil
@@ -331,6 +342,16 @@ func (m Model) View() string {
 		content.WriteString("\n")
 	}
 
+	// Synonyms and antonyms as hints (hidden by default)
+	if hints := currentWord.GetHints(); len(hints) > 0 {
+		if m.showRel {
+			content.WriteString(renderHints(hints))
+		} else {
+			content.WriteString(exampleStyle.Render("[Press 'r' to show Synonyms and Antonyms]"))
+			content.WriteString("\n")
+		}
+	}
+
 	// Separator
 	content.WriteString(renderSeparator(m.width))
 
@@ -361,9 +382,9 @@ func (m Model) View() string {
 
 	// Help line
 	if m.pending != nil {
-		content.WriteString(helpStyle.Render("[1: Next] [2: Reselect] [d: Definition] [e: Examples] [s: Skip] [h: Help] [q: Quit]"))
+		content.WriteString(helpStyle.Render("[1: Next] [2: Reselect] [d: Definition] [e: Examples] [r: Related] [s: Skip] [h: Help] [q: Quit]"))
 	} else {
-		content.WriteString(helpStyle.Render("[1-4: Rate] [d: Definition] [e: Examples] [s: Skip] [h: Help] [q: Quit]"))
+		content.WriteString(helpStyle.Render("[1-4: Rate] [d: Definition] [e: Examples] [r: Related] [s: Skip] [h: Help] [q: Quit]"))
 	}
 
 	// Apply container style
@@ -421,6 +442,7 @@ func (m Model) renderHelp() string {
 	content += ratingStyle.Render("Controls:") + "\n"
 	content += "  [d] Toggle definition visibility\n"
 	content += "  [e] Toggle examples visibility\n"
+	content += "  [r] Toggle synonyms and antonyms as hints\n"
 	content += "  [s] Skip current word (review later)\n"
 	content += "  [h/?] Show this help\n"
 	content += "  [q/Esc] Exit review session\n\n"
@@ -527,6 +549,16 @@ func renderExamples(examples []string) string {
 	return content.String()
 }
 
+func renderHints(hints []string) string {
+	var content strings.Builder
+	for _, hint := range hints {
+		content.WriteString("\n")
+		content.WriteString(phoneticStyle.Render("  " + hint))
+	}
+	content.WriteString("\n")
+	return content.String()
+}
+
 func formatPhonetics(note *entity.WordNote) string {
 	if note == nil || len(note.WordPhonetics) == 0 {
 		return ""
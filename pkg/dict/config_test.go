@@ -0,0 +1,152 @@
+package dict
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gogodjzhu/word-flow/internal/config"
+	dict_ecdict "github.com/gogodjzhu/word-flow/pkg/dict/ecdict"
+	dict_llm "github.com/gogodjzhu/word-flow/pkg/dict/llm"
+	dict_stardict "github.com/gogodjzhu/word-flow/pkg/dict/stardict"
+	dict_youdao "github.com/gogodjzhu/word-flow/pkg/dict/youdao"
+)
+
+func loadTestConfig(t *testing.T, content string) *config.Config {
+	t.Helper()
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(configFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := config.LoadConfig(configFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cfg
+}
+
+func TestLoadConfig_LLMSection(t *testing.T) {
+	cfg := loadTestConfig(t, `version: v1
+dict:
+  default: youdao
+  llm:
+    api_key: test-key
+    url: "https://api.example.com/v1/chat/completions"
+    model: test-model
+    timeout: 20s
+    max_tokens: 1000
+    temperature: 0.5
+`)
+
+	llm := cfg.Dict.Endpoints["llm"].(*dict_llm.Config)
+	if llm.ApiKey != "test-key" || llm.Model != "test-model" {
+		t.Errorf("llm = %+v, want the configured api_key and model", llm)
+	}
+	if time.Duration(llm.Timeout) != 20*time.Second || llm.MaxTokens != 1000 || llm.Temperature != 0.5 {
+		t.Errorf("llm = %+v, want timeout 20s, max_tokens 1000 and temperature 0.5", llm)
+	}
+}
+
+func TestLoadConfig_SectionDefaults(t *testing.T) {
+	cfg := loadTestConfig(t, `version: v1
+dict:
+  default: youdao
+  youdao:
+    http:
+      retries: -1
+      rate_limit: 5
+`)
+
+	for _, registration := range builtinDictionaries {
+		if _, err := cfg.Dict.GetEndpointConfig(registration.Name); err != nil {
+			t.Errorf("section %s: %v", registration.Name, err)
+		}
+	}
+	llm := cfg.Dict.Endpoints["llm"].(*dict_llm.Config)
+	if llm.Timeout != config.Duration(30*time.Second) || llm.MaxTokens != 2000 || llm.Temperature != 0.3 {
+		t.Errorf("llm = %+v, want timeout 30s, max_tokens 2000 and temperature 0.3", llm)
+	}
+	youdao := cfg.Dict.Endpoints["youdao"].(*dict_youdao.Config).HTTP
+	if youdao.Retries != -1 || youdao.RateLimit != 5 || youdao.Backoff != config.Duration(500*time.Millisecond) || youdao.BreakerFailures != 5 {
+		t.Errorf("youdao http = %+v", youdao)
+	}
+	if cfg.Dict.Endpoints["ecdict"].(*dict_ecdict.Config).DBFilename == "" {
+		t.Error("expected ecdict db_filename to be dynamically set")
+	}
+}
+
+func TestLoadConfig_EnvOverride(t *testing.T) {
+	t.Setenv("WORDFLOW_DICT_LLM_API_KEY", "env-key")
+	t.Setenv("WORDFLOW_DICT_LLM_TIMEOUT", "60s")
+	t.Setenv("WORDFLOW_DICT_STARDICT_PATHS", "/data/oxford, /data/collins")
+	cfg := loadTestConfig(t, `version: v1
+dict:
+  default: youdao
+  llm:
+    api_key: file-key
+    model: file-model
+`)
+
+	llm := cfg.Dict.Endpoints["llm"].(*dict_llm.Config)
+	if llm.ApiKey != "env-key" || llm.Model != "file-model" {
+		t.Errorf("llm = %+v, want api_key from env and model from file", llm)
+	}
+	if time.Duration(llm.Timeout) != 60*time.Second {
+		t.Errorf("expected timeout 60s from env, got %v", time.Duration(llm.Timeout))
+	}
+	want := []string{"/data/oxford", "/data/collins"}
+	if paths := cfg.Dict.Endpoints["stardict"].(*dict_stardict.Config).Paths; !reflect.DeepEqual(paths, want) {
+		t.Errorf("expected paths %v from env, got %v", want, paths)
+	}
+}
+
+func TestValidate_LLMSection(t *testing.T) {
+	tests := []struct {
+		name        string
+		section     string
+		expectedErr string
+	}{
+		{name: "valid", section: "api_key: test-key\n    url: https://api.example.com\n    model: test-model"},
+		{name: "missing api_key", section: "url: https://api.example.com\n    model: test-model", expectedErr: "llm.api_key is required"},
+		{name: "missing url", section: "api_key: test-key\n    model: test-model", expectedErr: "llm.url is required"},
+		{name: "invalid temperature", section: "api_key: test-key\n    url: https://api.example.com\n    model: test-model\n    temperature: 5.0", expectedErr: "llm.temperature must be between 0 and 2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := loadTestConfig(t, "version: v1\ndict:\n  default: llm\n  llm:\n    "+tt.section+"\n")
+			err := cfg.Validate("llm")
+			if tt.expectedErr == "" {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+			} else if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+				t.Errorf("expected error containing %q, got %v", tt.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestConfigTemplate_Sections(t *testing.T) {
+	template := config.ConfigTemplate()
+	for _, registration := range builtinDictionaries {
+		if !strings.Contains(template, "\n  "+registration.Name+":\n") && !strings.Contains(template, "\n  # "+registration.Name+":\n") {
+			t.Errorf("template misses the %s section", registration.Name)
+		}
+	}
+
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	if err := config.InitConfig(configFile); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := config.LoadConfig(configFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.Validate("youdao"); err != nil {
+		t.Errorf("the initial config should be valid for youdao: %v", err)
+	}
+}
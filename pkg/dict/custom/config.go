@@ -0,0 +1,37 @@
+package dict_custom
+
+import (
+	"errors"
+	"path/filepath"
+
+	"github.com/gogodjzhu/word-flow/internal/config"
+)
+
+// Config is the dict.custom section.
+type Config struct {
+	// Dir holds the YAML, TSV and CSV glossary files, scanned recursively.
+	Dir string `yaml:"dir,omitempty"`
+}
+
+// ConfigSection registers Config with the dictionary.
+var ConfigSection = config.EndpointSpec{
+	New: func() config.EndpointConfig { return &Config{} },
+	Defaults: func(c config.EndpointConfig, dir string) {
+		if custom := c.(*Config); custom.Dir == "" {
+			custom.Dir = filepath.Join(dir, "glossaries")
+		}
+	},
+	Template: `custom:
+  # Team glossaries in YAML, TSV or CSV, edited by hand or with wordflow glossary add/edit/rm.
+  # Use "default: custom" with a fallback to look up your own terms first.
+  # Defaults to <WORDFLOW_HOME>/glossaries if empty
+  # dir: ""
+`,
+}
+
+func (c *Config) Validate() error {
+	if c.Dir == "" {
+		return errors.New("custom.dir is required when custom is the default dictionary. Set it via: wordflow config set dict.custom.dir <dir>")
+	}
+	return nil
+}
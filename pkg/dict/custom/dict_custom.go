@@ -0,0 +1,140 @@
+package dict_custom
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/gogodjzhu/word-flow/internal/buzz_error"
+	"github.com/gogodjzhu/word-flow/pkg/dict/entity"
+	"github.com/pkg/errors"
+)
+
+type fileEntry struct {
+	*Entry
+	filename string
+}
+
+// DictCustom looks words up in the user maintained glossary files of a
+// directory. Files are reloaded whenever one of them is added, removed or
+// modified, so edits show up without restarting the server.
+type DictCustom struct {
+	dir string
+
+	mu        sync.Mutex
+	signature string
+	entries   map[string][]*fileEntry
+}
+
+func NewDictCustom(config *Config) (*DictCustom, error) {
+	if config == nil || config.Dir == "" {
+		return nil, errors.New("custom config with dir is required")
+	}
+	d := &DictCustom{dir: config.Dir}
+	if err := d.reload(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// reload reads the glossary files again if their names, sizes or modification
+// times changed since the last call.
+func (d *DictCustom) reload() error {
+	files, err := FindFiles(d.dir)
+	if err != nil {
+		return err
+	}
+	var signature strings.Builder
+	for _, filename := range files {
+		info, err := os.Stat(filename)
+		if err != nil {
+			return errors.Wrapf(err, "failed to stat glossary %s", filename)
+		}
+		fmt.Fprintf(&signature, "%s:%d:%d;", filename, info.Size(), info.ModTime().UnixNano())
+	}
+	if d.entries != nil && signature.String() == d.signature {
+		return nil
+	}
+	entries := make(map[string][]*fileEntry)
+	for _, filename := range files {
+		fileEntries, err := ReadFile(filename)
+		if err != nil {
+			return err
+		}
+		for _, entry := range fileEntries {
+			key := strings.ToLower(entry.Word)
+			entries[key] = append(entries[key], &fileEntry{Entry: entry, filename: filename})
+		}
+	}
+	d.entries = entries
+	d.signature = signature.String()
+	return nil
+}
+
+func (d *DictCustom) Search(word string) (*entity.WordItem, error) {
+	word = strings.TrimSpace(word)
+	if word == "" {
+		return nil, buzz_error.InvalidInput("empty word to search")
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err := d.reload(); err != nil {
+		return nil, err
+	}
+	entries := d.entries[strings.ToLower(word)]
+	if len(entries) == 0 {
+		return nil, buzz_error.InvalidInput("Invalid word: " + word)
+	}
+
+	result := &entity.WordItem{
+		ID:            entity.WordId(entries[0].Word),
+		Word:          entries[0].Word,
+		WordPhonetics: make([]*entity.WordPhonetic, 0),
+		WordMeanings:  make([]*entity.WordMeaning, 0),
+	}
+	var files, tags []string
+	for _, entry := range entries {
+		if entry.Phonetic != "" {
+			result.WordPhonetics = append(result.WordPhonetics, &entity.WordPhonetic{HeadWord: entry.Word, Text: entry.Phonetic})
+		}
+		for _, m := range entry.Meanings {
+			result.WordMeanings = append(result.WordMeanings, &entity.WordMeaning{
+				PartOfSpeech: m.PartOfSpeech,
+				Definitions:  m.Definition,
+				Examples:     m.Examples,
+			})
+		}
+		result.Examples = append(result.Examples, entry.Examples...)
+		if name := filepath.Base(entry.filename); !containsFold(files, name) {
+			files = append(files, name)
+		}
+		for _, tag := range entry.Tags {
+			if !containsFold(tags, tag) {
+				tags = append(tags, tag)
+			}
+		}
+	}
+	result.Source = "custom: " + strings.Join(files, ", ")
+	if len(tags) > 0 {
+		result.Source += " [" + strings.Join(tags, ", ") + "]"
+	}
+	return result, nil
+}
+
+// Files returns the glossary files that define word.
+func (d *DictCustom) Files(word string) ([]string, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err := d.reload(); err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, entry := range d.entries[strings.ToLower(strings.TrimSpace(word))] {
+		if !containsFold(files, entry.filename) {
+			files = append(files, entry.filename)
+		}
+	}
+	return files, nil
+}
@@ -0,0 +1,154 @@
+package dict_custom
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const teamYAML = `- word: Wordflow
+  phonetic: ˈwɜːdfləʊ
+  tags: [product]
+  meanings:
+    - pos: n.
+      definition: our terminal dictionary tool
+      examples: ["Look it up with wordflow."]
+    - pos: v.
+      definition: to look a word up with wordflow
+  examples: ["Just wordflow it."]
+`
+
+func writeFile(t *testing.T, filename, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filename, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDictCustom_Search(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "team.yaml"), teamYAML)
+	writeFile(t, filepath.Join(dir, "review", "review.tsv"),
+		"word\tdefinition\tpos\ttags\n"+
+			"LGTM\tlooks good to me\tabbr.\treview\n"+
+			"LGTM\tlet's get this merged\t\treview,slang\n"+
+			"# comment\tignored\n")
+	writeFile(t, filepath.Join(dir, "infra.csv"),
+		`wordflow,n.,"the ""flow"" service, in production",Deploy wordflow | Restart wordflow,infra`+"\n")
+
+	d, err := NewDictCustom(&Config{Dir: dir})
+	if err != nil {
+		t.Fatalf("NewDictCustom() error = %v", err)
+	}
+
+	got, err := d.Search("wordflow")
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if got.Word != "wordflow" || got.Source != "custom: infra.csv, team.yaml [infra, product]" {
+		t.Errorf("Word = %q, Source = %q", got.Word, got.Source)
+	}
+	if len(got.WordMeanings) != 3 {
+		t.Fatalf("got %d meanings, want 3", len(got.WordMeanings))
+	}
+	csvMeaning := got.WordMeanings[0]
+	if csvMeaning.PartOfSpeech != "n." || csvMeaning.Definitions != `the "flow" service, in production` ||
+		strings.Join(csvMeaning.Examples, "|") != "Deploy wordflow|Restart wordflow" {
+		t.Errorf("csv meaning = %+v", csvMeaning)
+	}
+	if got.WordMeanings[2].PartOfSpeech != "v." || len(got.WordPhonetics) != 1 || got.WordPhonetics[0].Text != "ˈwɜːdfləʊ" {
+		t.Errorf("yaml meaning = %+v, phonetics = %+v", got.WordMeanings[2], got.WordPhonetics)
+	}
+	if len(got.Examples) != 1 || got.Examples[0] != "Just wordflow it." {
+		t.Errorf("Examples = %v", got.Examples)
+	}
+
+	lgtm, err := d.Search("lgtm")
+	if err != nil {
+		t.Fatalf("Search(lgtm) error = %v", err)
+	}
+	if len(lgtm.WordMeanings) != 2 || lgtm.WordMeanings[0].PartOfSpeech != "abbr." || lgtm.Source != "custom: review.tsv [review, slang]" {
+		t.Errorf("lgtm = %+v, source %q", lgtm.WordMeanings, lgtm.Source)
+	}
+
+	if _, err := d.Search("comment"); err == nil {
+		t.Error("expected comment rows to be ignored")
+	}
+}
+
+func TestDictCustom_HotReload(t *testing.T) {
+	dir := t.TempDir()
+	d, err := NewDictCustom(&Config{Dir: filepath.Join(dir, "missing")})
+	if err != nil {
+		t.Fatalf("NewDictCustom() on missing dir error = %v", err)
+	}
+	if _, err := d.Search("wordflow"); err == nil {
+		t.Fatal("expected no result from an empty glossary")
+	}
+
+	d, err = NewDictCustom(&Config{Dir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	filename := filepath.Join(dir, "team.yaml")
+	writeFile(t, filename, teamYAML)
+	if _, err := d.Search("wordflow"); err != nil {
+		t.Fatalf("new file not picked up: %v", err)
+	}
+
+	writeFile(t, filename, strings.Replace(teamYAML, "Wordflow", "Buzzflow", 1))
+	// Make sure the modification time differs on file systems with coarse timestamps.
+	later := time.Now().Add(2 * time.Second)
+	if err := os.Chtimes(filename, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.Search("wordflow"); err == nil {
+		t.Error("expected renamed word to be gone")
+	}
+	if _, err := d.Search("buzzflow"); err != nil {
+		t.Errorf("modified file not picked up: %v", err)
+	}
+}
+
+func TestAddMeaningAndRemove(t *testing.T) {
+	for _, name := range []string{"glossary.yaml", "glossary.tsv", "glossary.csv"} {
+		t.Run(name, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), "nested", name)
+			if err := AddMeaning(filename, "wordflow", &Meaning{PartOfSpeech: "n.", Definition: "a tool, for words"}, nil, []string{"product"}); err != nil {
+				t.Fatalf("AddMeaning() error = %v", err)
+			}
+			if err := AddMeaning(filename, "Wordflow", &Meaning{PartOfSpeech: "v.", Definition: "to look up", Examples: []string{"wordflow it"}}, nil, []string{"product", "verb"}); err != nil {
+				t.Fatalf("AddMeaning() error = %v", err)
+			}
+			if err := AddMeaning(filename, "LGTM", &Meaning{Definition: "looks good to me"}, nil, nil); err != nil {
+				t.Fatalf("AddMeaning() error = %v", err)
+			}
+
+			entries, err := ReadFile(filename)
+			if err != nil {
+				t.Fatalf("ReadFile() error = %v", err)
+			}
+			if len(entries) != 2 || len(entries[0].Meanings) != 2 || entries[0].Meanings[0].Definition != "a tool, for words" ||
+				strings.Join(entries[0].Tags, ",") != "product,verb" || entries[0].Meanings[1].Examples[0] != "wordflow it" {
+				t.Fatalf("entries = %+v", entries)
+			}
+
+			removed, err := Remove(filename, "WORDFLOW")
+			if err != nil || !removed {
+				t.Fatalf("Remove() = %v, %v", removed, err)
+			}
+			if removed, _ := Remove(filename, "wordflow"); removed {
+				t.Error("expected second Remove() to find nothing")
+			}
+			entries, err = ReadFile(filename)
+			if err != nil || len(entries) != 1 || entries[0].Word != "LGTM" {
+				t.Errorf("entries after Remove() = %+v, %v", entries, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,306 @@
+package dict_custom
+
+import (
+	"bytes"
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultFilename is the glossary file that new entries are added to.
+const DefaultFilename = "glossary.yaml"
+
+// Entry is one glossary word with its meanings.
+type Entry struct {
+	Word     string     `yaml:"word"`
+	Phonetic string     `yaml:"phonetic,omitempty"`
+	Meanings []*Meaning `yaml:"meanings,omitempty"`
+	Examples []string   `yaml:"examples,omitempty"`
+	Tags     []string   `yaml:"tags,omitempty"`
+}
+
+type Meaning struct {
+	PartOfSpeech string   `yaml:"pos,omitempty"`
+	Definition   string   `yaml:"definition"`
+	Examples     []string `yaml:"examples,omitempty"`
+}
+
+// tableColumns are the columns of TSV and CSV glossaries. The header row is
+// optional, without it columns are read in this order. Multiple examples are
+// separated by " | " and tags by ",".
+var tableColumns = []string{"word", "pos", "definition", "examples", "tags", "phonetic"}
+
+const exampleSeparator = " | "
+
+// IsGlossaryFile reports whether filename has a supported glossary extension.
+func IsGlossaryFile(filename string) bool {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".yaml", ".yml", ".tsv", ".csv":
+		return true
+	}
+	return false
+}
+
+// ReadFile reads the entries of a YAML, TSV or CSV glossary file.
+func ReadFile(filename string) ([]*Entry, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read glossary %s", filename)
+	}
+	var entries []*Entry
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(data, &entries); err != nil {
+			return nil, errors.Wrapf(err, "failed to parse glossary %s", filename)
+		}
+	case ".tsv":
+		entries, err = readTable(data, '\t')
+	case ".csv":
+		entries, err = readTable(data, ',')
+	default:
+		return nil, errors.Errorf("unsupported glossary file %s, use .yaml, .tsv or .csv", filename)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse glossary %s", filename)
+	}
+	valid := entries[:0]
+	for _, entry := range entries {
+		if entry != nil && strings.TrimSpace(entry.Word) != "" {
+			entry.Word = strings.TrimSpace(entry.Word)
+			valid = append(valid, entry)
+		}
+	}
+	return valid, nil
+}
+
+func readTable(data []byte, comma rune) ([]*Entry, error) {
+	records, err := readRecords(data, comma)
+	if err != nil {
+		return nil, err
+	}
+	columns := map[string]int{}
+	for i, name := range tableColumns {
+		columns[name] = i
+	}
+	var entries []*Entry
+	byWord := map[string]*Entry{}
+	for row, record := range records {
+		if row == 0 && len(record) > 0 && strings.EqualFold(strings.TrimSpace(record[0]), "word") {
+			columns = map[string]int{}
+			for i, name := range record {
+				columns[strings.ToLower(strings.TrimSpace(name))] = i
+			}
+			continue
+		}
+		cell := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		word := cell("word")
+		if word == "" || strings.HasPrefix(word, "#") {
+			continue
+		}
+		entry, ok := byWord[strings.ToLower(word)]
+		if !ok {
+			entry = &Entry{Word: word}
+			byWord[strings.ToLower(word)] = entry
+			entries = append(entries, entry)
+		}
+		if definition := cell("definition"); definition != "" {
+			entry.Meanings = append(entry.Meanings, &Meaning{
+				PartOfSpeech: cell("pos"),
+				Definition:   definition,
+				Examples:     splitList(cell("examples"), exampleSeparator),
+			})
+		}
+		for _, tag := range splitList(cell("tags"), ",") {
+			if !containsFold(entry.Tags, tag) {
+				entry.Tags = append(entry.Tags, tag)
+			}
+		}
+		if phonetic := cell("phonetic"); phonetic != "" {
+			entry.Phonetic = phonetic
+		}
+	}
+	return entries, nil
+}
+
+// readRecords splits CSV with encoding/csv rules; TSV cells are taken
+// literally, one record per line.
+func readRecords(data []byte, comma rune) ([][]string, error) {
+	if comma != '\t' {
+		r := csv.NewReader(bytes.NewReader(data))
+		r.FieldsPerRecord = -1
+		r.LazyQuotes = true
+		return r.ReadAll()
+	}
+	var records [][]string
+	for _, line := range strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n") {
+		if strings.TrimSpace(line) != "" {
+			records = append(records, strings.Split(line, "\t"))
+		}
+	}
+	return records, nil
+}
+
+// WriteFile writes entries to filename in the format given by its extension.
+func WriteFile(filename string, entries []*Entry) error {
+	var data []byte
+	var err error
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".yaml", ".yml":
+		data, err = yaml.Marshal(entries)
+	case ".tsv":
+		data, err = writeTable(entries, '\t')
+	case ".csv":
+		data, err = writeTable(entries, ',')
+	default:
+		return errors.Errorf("unsupported glossary file %s, use .yaml, .tsv or .csv", filename)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to encode glossary %s", filename)
+	}
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return errors.Wrap(err, "failed to create glossary directory")
+	}
+	if err := os.WriteFile(filename, data, 0644); err != nil {
+		return errors.Wrapf(err, "failed to write glossary %s", filename)
+	}
+	return nil
+}
+
+func writeTable(entries []*Entry, comma rune) ([]byte, error) {
+	records := [][]string{tableColumns}
+	for _, entry := range entries {
+		tags := strings.Join(entry.Tags, ",")
+		meanings := entry.Meanings
+		if len(meanings) == 0 {
+			meanings = []*Meaning{{}}
+		}
+		for i, m := range meanings {
+			examples := m.Examples
+			if i == 0 {
+				// Tables have no column for entry examples, keep them with the first meaning.
+				examples = append(append([]string{}, examples...), entry.Examples...)
+			}
+			records = append(records, []string{entry.Word, m.PartOfSpeech, m.Definition, strings.Join(examples, exampleSeparator), tags, entry.Phonetic})
+		}
+	}
+	var buf bytes.Buffer
+	if comma == '\t' {
+		cleaner := strings.NewReplacer("\t", " ", "\r", " ", "\n", " ")
+		for _, record := range records {
+			for i := range record {
+				record[i] = cleaner.Replace(record[i])
+			}
+			buf.WriteString(strings.Join(record, "\t") + "\n")
+		}
+		return buf.Bytes(), nil
+	}
+	w := csv.NewWriter(&buf)
+	if err := w.WriteAll(records); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// FindFiles returns the glossary files under dir, a missing dir has none.
+func FindFiles(dir string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == dir {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if !entry.IsDir() && IsGlossaryFile(path) {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to scan glossary directory %s", dir)
+	}
+	return files, nil
+}
+
+// AddMeaning adds a meaning, examples and tags to word in filename, creating
+// the entry and the file when needed.
+func AddMeaning(filename, word string, meaning *Meaning, examples, tags []string) error {
+	entries, err := readIfExists(filename)
+	if err != nil {
+		return err
+	}
+	var entry *Entry
+	for _, e := range entries {
+		if strings.EqualFold(e.Word, word) {
+			entry = e
+			break
+		}
+	}
+	if entry == nil {
+		entry = &Entry{Word: word}
+		entries = append(entries, entry)
+	}
+	if meaning != nil && meaning.Definition != "" {
+		entry.Meanings = append(entry.Meanings, meaning)
+	}
+	entry.Examples = append(entry.Examples, examples...)
+	for _, tag := range tags {
+		if !containsFold(entry.Tags, tag) {
+			entry.Tags = append(entry.Tags, tag)
+		}
+	}
+	return WriteFile(filename, entries)
+}
+
+// Remove deletes word from filename and reports whether it was there.
+func Remove(filename, word string) (bool, error) {
+	entries, err := readIfExists(filename)
+	if err != nil {
+		return false, err
+	}
+	kept := make([]*Entry, 0, len(entries))
+	for _, e := range entries {
+		if !strings.EqualFold(e.Word, word) {
+			kept = append(kept, e)
+		}
+	}
+	if len(kept) == len(entries) {
+		return false, nil
+	}
+	return true, WriteFile(filename, kept)
+}
+
+func readIfExists(filename string) ([]*Entry, error) {
+	if _, err := os.Stat(filename); os.IsNotExist(err) {
+		return nil, nil
+	}
+	return ReadFile(filename)
+}
+
+func splitList(s, sep string) []string {
+	var items []string
+	for _, item := range strings.Split(s, sep) {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func containsFold(items []string, s string) bool {
+	for _, item := range items {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -1,14 +1,24 @@
 package dict
 
 import (
+	"context"
+
 	"github.com/gogodjzhu/word-flow/internal/buzz_error"
 	"github.com/gogodjzhu/word-flow/internal/config"
+	"github.com/gogodjzhu/word-flow/internal/lang"
+	dict_custom "github.com/gogodjzhu/word-flow/pkg/dict/custom"
+	dict_dictd "github.com/gogodjzhu/word-flow/pkg/dict/dictd"
 	dict_ecdict "github.com/gogodjzhu/word-flow/pkg/dict/ecdict"
 	"github.com/gogodjzhu/word-flow/pkg/dict/entity"
 	dict_etymonline "github.com/gogodjzhu/word-flow/pkg/dict/etymonline"
+	dict_exec "github.com/gogodjzhu/word-flow/pkg/dict/exec"
 	dict_google "github.com/gogodjzhu/word-flow/pkg/dict/google"
 	dict_llm "github.com/gogodjzhu/word-flow/pkg/dict/llm"
+	dict_mdict "github.com/gogodjzhu/word-flow/pkg/dict/mdict"
 	dict_mwebster "github.com/gogodjzhu/word-flow/pkg/dict/mwebster"
+	dict_stardict "github.com/gogodjzhu/word-flow/pkg/dict/stardict"
+	dict_wiktionary "github.com/gogodjzhu/word-flow/pkg/dict/wiktionary"
+	dict_wordnet "github.com/gogodjzhu/word-flow/pkg/dict/wordnet"
 	dict_youdao "github.com/gogodjzhu/word-flow/pkg/dict/youdao"
 	"github.com/pkg/errors"
 )
@@ -17,49 +27,202 @@ type Dict interface {
 	Search(word string) (*entity.WordItem, error)
 }
 
-type Endpoint string
+// ResourceProvider is implemented by dictionaries that bundle media files, such
+// as the audio stored in MDict .mdd resources.
+type ResourceProvider interface {
+	Resource(name string) ([]byte, error)
+}
 
-const (
-	Youdao     Endpoint = "youdao"
-	Etymonline Endpoint = "etymonline"
-	Ecdict     Endpoint = "ecdict"
-	MWebster   Endpoint = "mwebster"
-	LLM        Endpoint = "llm"
-	Google     Endpoint = "google"
-)
+// PrefixDict is implemented by dictionaries that can list their headwords,
+// such as the offline StarDict and MDict bundles and DICT servers.
+type PrefixDict interface {
+	Dict
+	// MatchPrefix returns the headwords starting with prefix, compared case
+	// insensitively.
+	MatchPrefix(prefix string) ([]string, error)
+}
+
+// ErrNoHeadwords is returned by MatchPrefix of dictionaries wrapping one that
+// cannot list its headwords.
+var ErrNoHeadwords = errors.New("dictionary cannot list its headwords")
+
+// ContextDict is implemented by dictionaries whose lookups can be cancelled,
+// such as the online ones. Their deadline is the timeout of the endpoint.
+type ContextDict interface {
+	Dict
+	SearchContext(ctx context.Context, word string) (*entity.WordItem, error)
+}
+
+// Search looks word up in d. The lookup is cancelled with ctx if d is a
+// ContextDict, other dictionaries are only checked before the lookup.
+func Search(ctx context.Context, d Dict, word string) (*entity.WordItem, error) {
+	if cd, ok := d.(ContextDict); ok {
+		return cd.SearchContext(ctx, word)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return d.Search(word)
+}
+
+// ReverseDict is implemented by dictionaries that find the English words of
+// a Chinese query.
+type ReverseDict interface {
+	Dict
+	SearchReverse(ctx context.Context, query string) (*entity.WordItem, error)
+}
+
+// SearchDetected searches word in d after detecting its language. Chinese words go to
+// the reverse lookup of d if it has one, other words to Search. The result
+// reports the detected language when the detection is reliable.
+func SearchDetected(ctx context.Context, d Dict, word string) (*entity.WordItem, error) {
+	detection := lang.Detect(word)
+	var wordItem *entity.WordItem
+	var err error
+	if rd, ok := d.(ReverseDict); ok && lang.IsChinese(detection.Code) {
+		wordItem, err = rd.SearchReverse(ctx, word)
+	} else {
+		wordItem, err = Search(ctx, d, word)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if detection.Reliable() {
+		wordItem.Language = detection.Code
+	}
+	return wordItem, nil
+}
 
 type DictInfo struct {
 	Name        string
 	Description string
 }
 
-func AvailableDictionaries() []DictInfo {
-	return []DictInfo{
-		{
-			Name:        string(Youdao),
-			Description: "[Free] Online dictionary providing concise definitions and translations. @See https://www.youdao.com/",
+// builtinDictionaries are registered in this order, which is also the order
+// of `dict -l` and of their sections in the config template.
+var builtinDictionaries = []Registration{
+	{
+		Name:        "youdao",
+		Description: "[Free] Online dictionary providing concise definitions and translations. @See https://www.youdao.com/",
+		Config:      &dict_youdao.ConfigSection,
+		New: func(c config.DictEndpointConfig) (Dict, error) {
+			return dict_youdao.NewDictYoudao(c.(*dict_youdao.Config))
 		},
-		{
-			Name:        string(Etymonline),
-			Description: "[Free] Online etymology dictionary for word origins and history. See https://www.etymonline.com/",
+	},
+	{
+		Name:        "etymonline",
+		Description: "[Free] Online etymology dictionary for word origins and history. See https://www.etymonline.com/",
+		Config:      &dict_etymonline.ConfigSection,
+		New: func(c config.DictEndpointConfig) (Dict, error) {
+			return dict_etymonline.NewDictEtymonline(c.(*dict_etymonline.Config))
 		},
-		{
-			Name:        string(Ecdict),
-			Description: "[Free] Offline dictionary with a massive local database. See https://github.com/skywind3000/ECDICT/",
+	},
+	{
+		Name:        "ecdict",
+		Description: "[Free] Offline dictionary with a massive local database. See https://github.com/skywind3000/ECDICT/",
+		Config:      &dict_ecdict.ConfigSection,
+		New: func(c config.DictEndpointConfig) (Dict, error) {
+			return dict_ecdict.NewDictEcdit(c.(*dict_ecdict.Config))
 		},
-		{
-			Name:        string(MWebster),
-			Description: "Authoritative English dictionary from Merriam-Webster, requires Dictionary API key. See https://dictionaryapi.com/",
+	},
+	{
+		Name:        "mwebster",
+		Description: "Authoritative English dictionary from Merriam-Webster, requires Dictionary API key. See https://dictionaryapi.com/",
+		Config:      &dict_mwebster.ConfigSection,
+		New: func(c config.DictEndpointConfig) (Dict, error) {
+			return dict_mwebster.NewDictMWebster(c.(*dict_mwebster.Config))
 		},
-		{
-			Name:        string(LLM),
-			Description: "AI-powered definitions and explanations using Large Language Models, requires LLM API key and endpoint. See your LLM provider for details.",
+	},
+	{
+		Name:        "llm",
+		Description: "AI-powered definitions and explanations using Large Language Models, requires LLM API key and endpoint. See your LLM provider for details.",
+		Config:      &dict_llm.ConfigSection,
+		New: func(c config.DictEndpointConfig) (Dict, error) {
+			return dict_llm.NewDictLLM(c.(*dict_llm.Config))
 		},
-		{
-			Name:        string(Google),
-			Description: "[Free] Online dictionary and translation powered by Google Translate.",
+	},
+	{
+		Name:        "google",
+		Description: "[Free] Online dictionary and translation powered by Google Translate.",
+		Config:      &dict_google.ConfigSection,
+		New: func(c config.DictEndpointConfig) (Dict, error) {
+			return dict_google.NewDictGoogle(c.(*dict_google.Config))
 		},
+	},
+	{
+		Name:        "stardict",
+		Description: "[Free] Offline StarDict bundles (.ifo/.idx/.dict[.dz]), e.g. Oxford, Longman or Collins bilingual dictionaries.",
+		Config:      &dict_stardict.ConfigSection,
+		New: func(c config.DictEndpointConfig) (Dict, error) {
+			return dict_stardict.NewDictStardict(c.(*dict_stardict.Config))
+		},
+	},
+	{
+		Name:        "mdict",
+		Description: "[Free] Offline MDict dictionaries (.mdx/.mdd), e.g. learner's dictionaries with audio resources.",
+		Config:      &dict_mdict.ConfigSection,
+		New: func(c config.DictEndpointConfig) (Dict, error) {
+			return dict_mdict.NewDictMdict(c.(*dict_mdict.Config))
+		},
+	},
+	{
+		Name:        "dictd",
+		Description: "[Free] Any DICT protocol (RFC 2229) server, e.g. a local dictd serving WordNet or GCIDE.",
+		Config:      &dict_dictd.ConfigSection,
+		New: func(c config.DictEndpointConfig) (Dict, error) {
+			return dict_dictd.NewDictDictd(c.(*dict_dictd.Config))
+		},
+	},
+	{
+		Name:        "custom",
+		Description: "[Free] Your own glossary of jargon and product names in YAML, TSV or CSV files, managed with `wordflow glossary`.",
+		Config:      &dict_custom.ConfigSection,
+		New: func(c config.DictEndpointConfig) (Dict, error) {
+			return dict_custom.NewDictCustom(c.(*dict_custom.Config))
+		},
+	},
+	{
+		Name:        "wiktionary",
+		Description: "[Free] Offline Wiktionary with senses, IPA, etymology and inflections, imported from a kaikki.org JSONL extract with `wordflow dict install wiktionary`. See https://kaikki.org/",
+		Config:      &dict_wiktionary.ConfigSection,
+		New: func(c config.DictEndpointConfig) (Dict, error) {
+			return dict_wiktionary.NewDictWiktionary(c.(*dict_wiktionary.Config))
+		},
+	},
+	{
+		Name:        "mwthesaurus",
+		Description: "Synonyms, antonyms and related words from the Merriam-Webster Thesaurus, requires Thesaurus API key. See https://dictionaryapi.com/",
+		Config:      &dict_mwebster.ThesaurusConfigSection,
+		New: func(c config.DictEndpointConfig) (Dict, error) {
+			return dict_mwebster.NewDictMWThesaurus(c.(*dict_mwebster.ThesaurusConfig))
+		},
+	},
+	{
+		Name:        "wordnet",
+		Description: "[Free] Offline WordNet database with definitions, synonyms, antonyms and related words. See https://wordnet.princeton.edu/",
+		Config:      &dict_wordnet.ConfigSection,
+		New: func(c config.DictEndpointConfig) (Dict, error) {
+			return dict_wordnet.NewDictWordNet(c.(*dict_wordnet.Config))
+		},
+	},
+	{
+		Name:        "exec",
+		Description: "Your own dictionary as an external plugin process speaking JSON over stdin/stdout, configured in dict.exec.",
+		Config:      &dict_exec.ConfigSection,
+		New: func(c config.DictEndpointConfig) (Dict, error) {
+			return dict_exec.NewDictExec(c.(*dict_exec.Config))
+		},
+	},
+}
+
+// AvailableDictionaries returns the registered dictionaries in order.
+func AvailableDictionaries() []DictInfo {
+	regs := registered()
+	infos := make([]DictInfo, len(regs))
+	for i, r := range regs {
+		infos[i] = DictInfo{Name: r.Name, Description: r.Description}
 	}
+	return infos
 }
 
 func AvailableEndpoints() []string {
@@ -71,27 +234,37 @@ func AvailableEndpoints() []string {
 	return endpoints
 }
 
+// NewDict creates the default dictionary. When fallback endpoints are
+// configured, they are tried in order whenever the previous one fails. When a
+// WordNet database is configured, it fills in missing synonyms and antonyms.
 func NewDict(conf *config.DictConfig) (Dict, error) {
-	endpoint := conf.Default
+	dictionary, err := newEndpointDict(conf, conf.Default)
+	if err != nil {
+		return nil, err
+	}
+	if len(conf.Fallback) > 0 {
+		dictionary = newFallbackDict(conf, dictionary)
+	}
+	wordnetConfig, ok := conf.Endpoints["wordnet"].(*dict_wordnet.Config)
+	if !ok || wordnetConfig.Dir == "" || conf.Default == "wordnet" {
+		return dictionary, nil
+	}
+	wordnet, err := dict_wordnet.NewDictWordNet(wordnetConfig)
+	if err != nil {
+		return nil, err
+	}
+	return &thesaurusDict{Dict: dictionary, wordnet: wordnet}, nil
+}
+
+func newEndpointDict(conf *config.DictConfig, endpoint string) (Dict, error) {
 	endpointConfig, err := conf.GetEndpointConfig(endpoint)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to get config for endpoint %s", endpoint)
 	}
 
-	switch Endpoint(endpoint) {
-	case Youdao:
-		return dict_youdao.NewDictYoudao(endpointConfig.(*config.YoudaoConfig))
-	case Etymonline:
-		return dict_etymonline.NewDictEtymonline(endpointConfig.(*config.EtymonlineConfig))
-	case Ecdict:
-		return dict_ecdict.NewDictEcdit(endpointConfig.(*config.EcdictConfig))
-	case MWebster:
-		return dict_mwebster.NewDictMWebster(endpointConfig.(*config.MWebsterConfig))
-	case LLM:
-		return dict_llm.NewDictLLM(endpointConfig.(*config.LLMConfig))
-	case Google:
-		return dict_google.NewDictGoogle(endpointConfig.(*config.GoogleConfig))
-	default:
+	r, ok := Lookup(endpoint)
+	if !ok {
 		return nil, buzz_error.InvalidEndpoint(endpoint)
 	}
+	return r.New(endpointConfig)
 }
\ No newline at end of file
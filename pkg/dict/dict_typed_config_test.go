@@ -1,13 +1,25 @@
 package dict
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/gogodjzhu/word-flow/internal/config"
+	dict_ecdict "github.com/gogodjzhu/word-flow/pkg/dict/ecdict"
+	dict_etymonline "github.com/gogodjzhu/word-flow/pkg/dict/etymonline"
+	dict_llm "github.com/gogodjzhu/word-flow/pkg/dict/llm"
+	dict_mwebster "github.com/gogodjzhu/word-flow/pkg/dict/mwebster"
+	dict_youdao "github.com/gogodjzhu/word-flow/pkg/dict/youdao"
 )
 
 func TestNewDict_WithTypedConfigs(t *testing.T) {
+	// An existing database file keeps ecdict from downloading it.
+	ecdictDB := filepath.Join(t.TempDir(), "stardict.db")
+	if err := os.WriteFile(ecdictDB, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
 	tests := []struct {
 		name       string
 		dictConfig *config.DictConfig
@@ -17,7 +29,9 @@ func TestNewDict_WithTypedConfigs(t *testing.T) {
 			name: "youdao config",
 			dictConfig: &config.DictConfig{
 				Default: "youdao",
-				Youdao:  &config.YoudaoConfig{},
+				Endpoints: map[string]config.EndpointConfig{
+					"youdao": &dict_youdao.Config{},
+				},
 			},
 			wantErr: false,
 		},
@@ -25,15 +39,19 @@ func TestNewDict_WithTypedConfigs(t *testing.T) {
 			name: "ecdict config",
 			dictConfig: &config.DictConfig{
 				Default: "ecdict",
-				Ecdict:  &config.EcdictConfig{DBFilename: "/tmp/test.db"},
+				Endpoints: map[string]config.EndpointConfig{
+					"ecdict": &dict_ecdict.Config{DBFilename: ecdictDB},
+				},
 			},
 			wantErr: false,
 		},
 		{
 			name: "mwebster config",
 			dictConfig: &config.DictConfig{
-				Default:   "mwebster",
-				MWebster: &config.MWebsterConfig{Key: "test-api-key"},
+				Default: "mwebster",
+				Endpoints: map[string]config.EndpointConfig{
+					"mwebster": &dict_mwebster.Config{Key: "test-api-key"},
+				},
 			},
 			wantErr: false,
 		},
@@ -41,13 +59,15 @@ func TestNewDict_WithTypedConfigs(t *testing.T) {
 			name: "llm config with all parameters",
 			dictConfig: &config.DictConfig{
 				Default: "llm",
-				LLM: &config.LLMConfig{
-					ApiKey:      "test-api-key",
-					URL:         "https://test.com/v1/chat/completions",
-					Model:       "gpt-4",
-					Timeout:     config.Duration(60 * time.Second),
-					MaxTokens:   4000,
-					Temperature: 0.7,
+				Endpoints: map[string]config.EndpointConfig{
+					"llm": &dict_llm.Config{
+						ApiKey:      "test-api-key",
+						URL:         "https://test.com/v1/chat/completions",
+						Model:       "gpt-4",
+						Timeout:     config.Duration(60 * time.Second),
+						MaxTokens:   4000,
+						Temperature: 0.7,
+					},
 				},
 			},
 			wantErr: false,
@@ -56,12 +76,14 @@ func TestNewDict_WithTypedConfigs(t *testing.T) {
 			name: "llm config with missing api_key",
 			dictConfig: &config.DictConfig{
 				Default: "llm",
-				LLM: &config.LLMConfig{
-					URL:         "https://test.com/v1/chat/completions",
-					Model:       "gpt-4",
-					Timeout:     config.Duration(30 * time.Second),
-					MaxTokens:   2000,
-					Temperature: 0.3,
+				Endpoints: map[string]config.EndpointConfig{
+					"llm": &dict_llm.Config{
+						URL:         "https://test.com/v1/chat/completions",
+						Model:       "gpt-4",
+						Timeout:     config.Duration(30 * time.Second),
+						MaxTokens:   2000,
+						Temperature: 0.3,
+					},
 				},
 			},
 			wantErr: false, // NewDict doesn't validate; validation happens separately via cfg.Validate()
@@ -69,8 +91,10 @@ func TestNewDict_WithTypedConfigs(t *testing.T) {
 		{
 			name: "etymonline config",
 			dictConfig: &config.DictConfig{
-				Default:   "etymonline",
-				Etymoline: &config.EtymonlineConfig{},
+				Default: "etymonline",
+				Endpoints: map[string]config.EndpointConfig{
+					"etymonline": &dict_etymonline.Config{},
+				},
 			},
 			wantErr: false,
 		},
@@ -0,0 +1,70 @@
+package dict_dictd
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gogodjzhu/word-flow/internal/config"
+)
+
+// Config is the dict.dictd section.
+type Config struct {
+	Host string `yaml:"host,omitempty"`
+	Port int    `yaml:"port,omitempty"`
+	// Database is a database name on the server, "*" for all of them or "!"
+	// for the first one that has a match.
+	Database string `yaml:"database,omitempty"`
+	// Strategy is used to MATCH a headword when the word itself is not defined.
+	Strategy string          `yaml:"strategy,omitempty"`
+	Timeout  config.Duration `yaml:"timeout,omitempty"`
+}
+
+// ConfigSection registers Config with the dictionary.
+var ConfigSection = config.EndpointSpec{
+	New: func() config.EndpointConfig { return &Config{} },
+	Defaults: func(c config.EndpointConfig, dir string) {
+		dictd := c.(*Config)
+		if dictd.Host == "" {
+			dictd.Host = "localhost"
+		}
+		if dictd.Port == 0 {
+			dictd.Port = 2628
+		}
+		if dictd.Database == "" {
+			dictd.Database = "*"
+		}
+		if dictd.Strategy == "" {
+			dictd.Strategy = "exact"
+		}
+		config.DefaultTimeout(&dictd.Timeout, 10*time.Second)
+	},
+	Template: `dictd:
+  # DICT protocol (RFC 2229) server, e.g. a local dictd serving WordNet or GCIDE
+  host: localhost
+  port: 2628
+  database: "*"           # Database name, "*" for all databases or "!" for the first one with a match
+  strategy: exact         # MATCH strategy used when the word has no definition. Options: exact, prefix, soundex, lev
+  timeout: 10s
+`,
+}
+
+func (c *Config) Validate() error {
+	if c.Host == "" {
+		return errors.New("dictd.host is required. Set it via: wordflow config set dict.dictd.host <host>")
+	}
+	if c.Port <= 0 || c.Port > 65535 {
+		return fmt.Errorf("dictd.port must be between 1 and 65535, got %d", c.Port)
+	}
+	if c.Database == "" {
+		return errors.New("dictd.database is required, use \"*\" to search all databases")
+	}
+	if IsStrategy(c.Strategy) {
+		return nil
+	}
+	names := make([]string, len(Strategies))
+	for i, strategy := range Strategies {
+		names[i] = strategy.Name
+	}
+	return fmt.Errorf("dictd.strategy must be one of %v, got %q", names, c.Strategy)
+}
@@ -0,0 +1,239 @@
+package dict_dictd
+
+import (
+	"context"
+	"net"
+	"net/textproto"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gogodjzhu/word-flow/internal/buzz_error"
+	"github.com/gogodjzhu/word-flow/pkg/dict/entity"
+	"github.com/pkg/errors"
+)
+
+var (
+	// WordNet style senses: "n 1: a dwelling ...", "2: a building ...", "v : contain".
+	wordnetSensePattern = regexp.MustCompile(`^(?:(n|v|adj|adv)\s*)?(\d+)?\s*:\s*(.*)$`)
+	// Numbered senses as used by GCIDE and most other databases: "1. A structure ...".
+	numberedSensePattern = regexp.MustCompile(`^(\d+)\.\s+(.*)$`)
+	// Part of speech in a GCIDE style header: "House \House\ (hous), n.; pl. {Houses}".
+	headerPosPattern = regexp.MustCompile(`,\s*(n|v\. t|v\. i|v|a|adj|adv|prep|conj|pron|interj)\.`)
+	crossRefPattern  = regexp.MustCompile(`\[(?:syn|ant|also|see also):[^\]]*\]`)
+	examplePattern   = regexp.MustCompile(`;\s*"([^"]+)"`)
+)
+
+var posNames = map[string]string{
+	"n":      "n.",
+	"v":      "v.",
+	"v. t":   "vt.",
+	"v. i":   "vi.",
+	"a":      "adj.",
+	"adj":    "adj.",
+	"adv":    "adv.",
+	"prep":   "prep.",
+	"conj":   "conj.",
+	"pron":   "pron.",
+	"interj": "interj.",
+}
+
+type DictDictd struct {
+	addr     string
+	database string
+	strategy string
+	timeout  time.Duration
+}
+
+func NewDictDictd(config *Config) (*DictDictd, error) {
+	if config == nil {
+		return nil, errors.New("dictd config is required")
+	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return &DictDictd{
+		addr:     net.JoinHostPort(config.Host, strconv.Itoa(config.Port)),
+		database: config.Database,
+		strategy: config.Strategy,
+		timeout:  time.Duration(config.Timeout),
+	}, nil
+}
+
+func (d *DictDictd) Search(word string) (*entity.WordItem, error) {
+	return d.SearchContext(context.Background(), word)
+}
+
+func (d *DictDictd) SearchContext(ctx context.Context, word string) (*entity.WordItem, error) {
+	word = strings.TrimSpace(word)
+	if word == "" {
+		return nil, buzz_error.InvalidInput("empty word to search")
+	}
+	client, err := DialContext(ctx, d.addr, d.timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+
+	headword := word
+	definitions, err := client.Define(d.database, word)
+	if err != nil {
+		return nil, d.wrapError(err)
+	}
+	if len(definitions) == 0 && d.strategy != "exact" {
+		matches, err := client.Match(d.database, d.strategy, word)
+		if err != nil {
+			return nil, d.wrapError(err)
+		}
+		if len(matches) > 0 {
+			headword = matches[0].Word
+			if definitions, err = client.Define(matches[0].Database, headword); err != nil {
+				return nil, d.wrapError(err)
+			}
+		}
+	}
+	if len(definitions) == 0 {
+		return nil, buzz_error.InvalidInput("Invalid word: " + word)
+	}
+
+	result := &entity.WordItem{
+		ID:            entity.WordId(headword),
+		Word:          headword,
+		WordPhonetics: make([]*entity.WordPhonetic, 0),
+		WordMeanings:  make([]*entity.WordMeaning, 0),
+	}
+	var sources []string
+	for _, definition := range definitions {
+		appendDefinition(result, definition)
+		source := definition.Description
+		if source == "" {
+			source = definition.Database
+		}
+		if !contains(sources, source) {
+			sources = append(sources, source)
+		}
+	}
+	result.Source = "dictd: " + strings.Join(sources, ", ")
+	return result, nil
+}
+
+// MatchPrefix asks the server for the headwords of the database that start
+// with prefix.
+func (d *DictDictd) MatchPrefix(prefix string) ([]string, error) {
+	client, err := Dial(d.addr, d.timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+
+	matches, err := client.Match(d.database, "prefix", prefix)
+	if err != nil {
+		return nil, d.wrapError(err)
+	}
+	words := make([]string, 0, len(matches))
+	for _, m := range matches {
+		if !contains(words, m.Word) {
+			words = append(words, m.Word)
+		}
+	}
+	return words, nil
+}
+
+func (d *DictDictd) wrapError(err error) error {
+	var protoErr *textproto.Error
+	if errors.As(err, &protoErr) {
+		switch protoErr.Code {
+		case CodeInvalidDatabase:
+			return buzz_error.InvalidInput("Invalid dictd database: " + d.database)
+		case CodeInvalidStrategy:
+			return buzz_error.InvalidInput("Invalid dictd strategy: " + d.strategy)
+		}
+	}
+	return errors.Wrap(err, "dictd request failed")
+}
+
+// appendDefinition maps the plain text of a definition into item. WordNet and
+// numbered (GCIDE style) senses become one meaning each, quoted WordNet usage
+// samples become examples; other text becomes one meaning per paragraph.
+func appendDefinition(item *entity.WordItem, definition *Definition) {
+	lines := strings.Split(strings.ReplaceAll(definition.Text, "\r", ""), "\n")
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	pos := ""
+	if len(lines) > 0 && isHeader(lines[0], definition.Word) {
+		if m := headerPosPattern.FindStringSubmatch(lines[0]); m != nil {
+			pos = posNames[m[1]]
+		}
+		lines = lines[1:]
+	}
+
+	var senses []*entity.WordMeaning
+	var paragraphs []string
+	var paragraph []string
+	flushParagraph := func() {
+		if len(paragraph) > 0 {
+			paragraphs = append(paragraphs, strings.Join(paragraph, " "))
+			paragraph = nil
+		}
+	}
+	var current *entity.WordMeaning
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			flushParagraph()
+			current = nil
+			continue
+		}
+		paragraph = append(paragraph, line)
+		if m := wordnetSensePattern.FindStringSubmatch(line); m != nil && (m[1] != "" || m[2] != "") {
+			if m[1] != "" {
+				pos = posNames[m[1]]
+			}
+			current = &entity.WordMeaning{PartOfSpeech: pos, Definitions: m[3]}
+			senses = append(senses, current)
+			continue
+		}
+		if m := numberedSensePattern.FindStringSubmatch(line); m != nil {
+			current = &entity.WordMeaning{PartOfSpeech: pos, Definitions: m[2]}
+			senses = append(senses, current)
+			continue
+		}
+		if current != nil {
+			current.Definitions += " " + line
+		}
+	}
+	flushParagraph()
+
+	if len(senses) == 0 {
+		for _, p := range paragraphs {
+			senses = append(senses, &entity.WordMeaning{PartOfSpeech: pos, Definitions: p})
+		}
+	}
+	for _, sense := range senses {
+		text := crossRefPattern.ReplaceAllString(sense.Definitions, "")
+		for _, m := range examplePattern.FindAllStringSubmatch(text, -1) {
+			item.Examples = append(item.Examples, m[1])
+		}
+		text = examplePattern.ReplaceAllString(text, "")
+		sense.Definitions = strings.Join(strings.Fields(text), " ")
+		if sense.Definitions != "" {
+			item.WordMeanings = append(item.WordMeanings, sense)
+		}
+	}
+}
+
+func isHeader(line, word string) bool {
+	line = strings.TrimSpace(line)
+	return word != "" && len(line) >= len(word) && strings.EqualFold(line[:len(word)], word)
+}
+
+func contains(items []string, s string) bool {
+	for _, item := range items {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,331 @@
+package dict_dictd
+
+import (
+	"context"
+	"net"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gogodjzhu/word-flow/internal/buzz_error"
+	"github.com/gogodjzhu/word-flow/internal/config"
+)
+
+const wordnetHouse = `house
+    n 1: a dwelling that serves as living quarters for one or more
+         families; "he has a house on Cape Cod"; "she felt she had to
+         get out of the house"
+    2: a building in which something is sheltered or located; "they
+       had a large carriage house"
+    v 1: contain or cover; "This box houses the gears" [syn: {house},
+         {contain}]`
+
+const gcideHouse = `House \House\ (hous), n.; pl. {Houses}. [OE. hous, hus, AS.
+   h[=u]s.]
+   1. A structure intended or used for a habitation or shelter
+      for animals of any kind.
+
+   2. A household; a family living in the same house.`
+
+// fakeServer answers DEFINE, MATCH, SHOW DB and SHOW STRAT from canned data.
+type fakeServer struct {
+	listener net.Listener
+	commands chan string
+}
+
+func startFakeServer(t *testing.T) *fakeServer {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &fakeServer{listener: listener, commands: make(chan string, 100)}
+	t.Cleanup(func() { _ = listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *fakeServer) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (s *fakeServer) serve(conn net.Conn) {
+	text := textproto.NewConn(conn)
+	defer text.Close()
+	_ = text.PrintfLine("220 fake dictd <auth.mime> <1.2@fake>")
+	for {
+		line, err := text.ReadLine()
+		if err != nil {
+			return
+		}
+		s.commands <- line
+		params := SplitParams(line)
+		switch strings.ToUpper(params[0]) {
+		case "CLIENT":
+			_ = text.PrintfLine("250 ok")
+		case "DEFINE":
+			database, word := params[1], strings.ToLower(params[2])
+			if database != "*" && database != "wn" && database != "gcide" {
+				_ = text.PrintfLine("550 invalid database, use \"SHOW DB\" for list of databases")
+				continue
+			}
+			var defs []string
+			if word == "house" && (database == "*" || database == "wn") {
+				defs = append(defs, `151 "house" wn "WordNet (r) 3.0 (2006)"`, wordnetHouse)
+			}
+			if word == "house" && (database == "*" || database == "gcide") {
+				defs = append(defs, `151 "House" gcide "The Collaborative International Dictionary of English v.0.48"`, gcideHouse)
+			}
+			if len(defs) == 0 {
+				_ = text.PrintfLine("552 no match")
+				continue
+			}
+			_ = text.PrintfLine("150 %d definitions retrieved", len(defs)/2)
+			for i := 0; i < len(defs); i += 2 {
+				_ = text.PrintfLine("%s", defs[i])
+				w := text.DotWriter()
+				_, _ = w.Write([]byte(defs[i+1]))
+				_ = w.Close()
+			}
+			_ = text.PrintfLine("250 ok")
+		case "MATCH":
+			if params[2] == "bogus" {
+				_ = text.PrintfLine("551 invalid strategy")
+				continue
+			}
+			if params[3] != "hous" {
+				_ = text.PrintfLine("552 no match")
+				continue
+			}
+			_ = text.PrintfLine("152 2 matches found")
+			w := text.DotWriter()
+			_, _ = w.Write([]byte("wn \"house\"\ngcide \"House boat\"\n"))
+			_ = w.Close()
+			_ = text.PrintfLine("250 ok")
+		case "SHOW":
+			if strings.ToUpper(params[1]) == "DB" {
+				_ = text.PrintfLine("110 2 databases present")
+				w := text.DotWriter()
+				_, _ = w.Write([]byte("wn \"WordNet (r) 3.0 (2006)\"\ngcide \"GCIDE\"\n"))
+				_ = w.Close()
+			} else {
+				_ = text.PrintfLine("111 1 strategies present")
+				w := text.DotWriter()
+				_, _ = w.Write([]byte("lev \"Match headwords within Levenshtein distance one\"\n"))
+				_ = w.Close()
+			}
+			_ = text.PrintfLine("250 ok")
+		case "QUIT":
+			_ = text.PrintfLine("221 bye")
+			return
+		default:
+			_ = text.PrintfLine("500 unknown command")
+		}
+	}
+}
+
+func newTestDict(t *testing.T, s *fakeServer, database, strategy string) *DictDictd {
+	t.Helper()
+	d, err := NewDictDictd(&Config{
+		Host:     "127.0.0.1",
+		Port:     s.port(),
+		Database: database,
+		Strategy: strategy,
+		Timeout:  config.Duration(5 * time.Second),
+	})
+	if err != nil {
+		t.Fatalf("NewDictDictd() error = %v", err)
+	}
+	return d
+}
+
+func TestDictDictd_SearchWordNet(t *testing.T) {
+	s := startFakeServer(t)
+	d := newTestDict(t, s, "wn", "exact")
+
+	got, err := d.Search("house")
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if got.Source != "dictd: WordNet (r) 3.0 (2006)" {
+		t.Errorf("Source = %q", got.Source)
+	}
+	want := []string{
+		"n.|a dwelling that serves as living quarters for one or more families",
+		"n.|a building in which something is sheltered or located",
+		"v.|contain or cover",
+	}
+	if len(got.WordMeanings) != len(want) {
+		t.Fatalf("got %d meanings, want %d: %+v", len(got.WordMeanings), len(want), got.WordMeanings)
+	}
+	for i, m := range got.WordMeanings {
+		if m.PartOfSpeech+"|"+m.Definitions != want[i] {
+			t.Errorf("meaning[%d] = %q, want %q", i, m.PartOfSpeech+"|"+m.Definitions, want[i])
+		}
+	}
+	wantExamples := []string{
+		"he has a house on Cape Cod",
+		"she felt she had to get out of the house",
+		"they had a large carriage house",
+		"This box houses the gears",
+	}
+	if strings.Join(got.Examples, "|") != strings.Join(wantExamples, "|") {
+		t.Errorf("Examples = %q", got.Examples)
+	}
+
+	if cmd := <-s.commands; cmd != "CLIENT wordflow" {
+		t.Errorf("first command = %q", cmd)
+	}
+	if cmd := <-s.commands; cmd != "DEFINE wn house" {
+		t.Errorf("second command = %q", cmd)
+	}
+}
+
+func TestDictDictd_SearchAllDatabases(t *testing.T) {
+	s := startFakeServer(t)
+	d := newTestDict(t, s, "*", "exact")
+
+	got, err := d.Search("house")
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if !strings.Contains(got.Source, "WordNet") || !strings.Contains(got.Source, "Collaborative") {
+		t.Errorf("Source = %q", got.Source)
+	}
+	last := got.WordMeanings[len(got.WordMeanings)-2:]
+	if last[0].PartOfSpeech != "n." || last[0].Definitions != "A structure intended or used for a habitation or shelter for animals of any kind." {
+		t.Errorf("gcide meaning = %+v", last[0])
+	}
+	if last[1].Definitions != "A household; a family living in the same house." {
+		t.Errorf("gcide meaning = %+v", last[1])
+	}
+}
+
+func TestDictDictd_SearchMatchStrategy(t *testing.T) {
+	s := startFakeServer(t)
+
+	if _, err := newTestDict(t, s, "wn", "exact").Search("hous"); err == nil {
+		t.Fatal("expected no match with exact strategy")
+	} else if be, ok := err.(buzz_error.BuzzError); !ok || be.Code != buzz_error.CodeInvalidInput {
+		t.Errorf("expected invalid input error, got %v", err)
+	}
+
+	got, err := newTestDict(t, s, "wn", "lev").Search("hous")
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if got.Word != "house" || len(got.WordMeanings) != 3 {
+		t.Errorf("got word %q with %d meanings", got.Word, len(got.WordMeanings))
+	}
+}
+
+func TestDictDictd_MatchPrefix(t *testing.T) {
+	s := startFakeServer(t)
+
+	words, err := newTestDict(t, s, "*", "exact").MatchPrefix("hous")
+	if err != nil || strings.Join(words, ",") != "house,House boat" {
+		t.Errorf("MatchPrefix() = %v, %v", words, err)
+	}
+	var match string
+	for len(s.commands) > 0 {
+		if command := <-s.commands; strings.HasPrefix(command, "MATCH") {
+			match = command
+		}
+	}
+	if match != `MATCH * prefix hous` {
+		t.Errorf("MATCH command = %q", match)
+	}
+}
+
+func TestDictDictd_InvalidDatabase(t *testing.T) {
+	s := startFakeServer(t)
+	_, err := newTestDict(t, s, "foldoc", "exact").Search("house")
+	be, ok := err.(buzz_error.BuzzError)
+	if !ok || !strings.Contains(be.Message, "foldoc") {
+		t.Errorf("expected invalid database error, got %v", err)
+	}
+}
+
+func TestDictDictd_SearchContextCancelled(t *testing.T) {
+	// The server accepts the connection but never sends its banner.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+	go func() {
+		conn, err := listener.Accept()
+		if err == nil {
+			t.Cleanup(func() { _ = conn.Close() })
+		}
+	}()
+	d, err := NewDictDictd(&Config{
+		Host:     "127.0.0.1",
+		Port:     listener.Addr().(*net.TCPAddr).Port,
+		Database: "*",
+		Strategy: "exact",
+		Timeout:  config.Duration(time.Minute),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := d.SearchContext(ctx, "house"); err == nil {
+		t.Fatal("expected error for a cancelled search")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("cancelled search took %s", elapsed)
+	}
+}
+
+func TestClient_ShowAndMatch(t *testing.T) {
+	s := startFakeServer(t)
+	c, err := Dial("127.0.0.1:"+strconv.Itoa(s.port()), 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	dbs, err := c.ShowDatabases()
+	if err != nil || len(dbs) != 2 || dbs[0].Name != "wn" || dbs[0].Description != "WordNet (r) 3.0 (2006)" {
+		t.Errorf("ShowDatabases() = %+v, %v", dbs, err)
+	}
+	strategies, err := c.ShowStrategies()
+	if err != nil || len(strategies) != 1 || strategies[0].Name != "lev" {
+		t.Errorf("ShowStrategies() = %+v, %v", strategies, err)
+	}
+	matches, err := c.Match("*", "prefix", "hous")
+	if err != nil || len(matches) != 2 || matches[1].Database != "gcide" || matches[1].Word != "House boat" {
+		t.Errorf("Match() = %+v, %v", matches, err)
+	}
+	if _, err := c.Match("*", "bogus", "hous"); err == nil {
+		t.Error("expected error for invalid strategy")
+	}
+	matches, err = c.Match("*", "prefix", "zzz")
+	if err != nil || len(matches) != 0 {
+		t.Errorf("Match() = %+v, %v", matches, err)
+	}
+}
+
+func TestQuoteAndSplitParams(t *testing.T) {
+	params := SplitParams(`DEFINE wn ` + Quote(`ice "cream"`) + ` '' plain`)
+	want := []string{"DEFINE", "wn", `ice "cream"`, "", "plain"}
+	if strings.Join(params, "|") != strings.Join(want, "|") {
+		t.Errorf("SplitParams() = %q, want %q", params, want)
+	}
+	if Quote("house") != "house" || Quote("") != `""` {
+		t.Errorf("Quote() = %q, %q", Quote("house"), Quote(""))
+	}
+}
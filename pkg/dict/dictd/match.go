@@ -0,0 +1,122 @@
+package dict_dictd
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Strategies lists the MATCH strategies implemented by MatchWords.
+var Strategies = []*Item{
+	{Name: "exact", Description: "Match headwords exactly"},
+	{Name: "prefix", Description: "Match prefixes"},
+	{Name: "soundex", Description: "Match using SOUNDEX algorithm"},
+	{Name: "lev", Description: "Match headwords within Levenshtein distance one"},
+}
+
+// DefaultStrategy is used for MATCH requests with the "." strategy.
+const DefaultStrategy = "lev"
+
+// IsStrategy reports whether MatchWords implements strategy.
+func IsStrategy(strategy string) bool {
+	for _, s := range Strategies {
+		if s.Name == strategy {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchWords returns the words that match word using strategy, compared case
+// insensitively.
+func MatchWords(strategy, word string, words []string) []string {
+	word = strings.ToLower(word)
+	var code string
+	if strategy == "soundex" {
+		code = Soundex(word)
+	}
+	matches := make([]string, 0)
+	for _, w := range words {
+		lower := strings.ToLower(w)
+		var ok bool
+		switch strategy {
+		case "exact":
+			ok = lower == word
+		case "prefix":
+			ok = strings.HasPrefix(lower, word)
+		case "soundex":
+			ok = code != "" && Soundex(lower) == code
+		case "lev":
+			ok = withinOneEdit(lower, word)
+		}
+		if ok {
+			matches = append(matches, w)
+		}
+	}
+	return matches
+}
+
+// Soundex returns the four character American Soundex code of word, or "" if
+// it has no letters.
+func Soundex(word string) string {
+	codes := map[rune]byte{
+		'b': '1', 'f': '1', 'p': '1', 'v': '1',
+		'c': '2', 'g': '2', 'j': '2', 'k': '2', 'q': '2', 's': '2', 'x': '2', 'z': '2',
+		'd': '3', 't': '3',
+		'l': '4',
+		'm': '5', 'n': '5',
+		'r': '6',
+	}
+	var result []byte
+	var last byte
+	for _, r := range strings.ToLower(word) {
+		if r > unicode.MaxASCII || !unicode.IsLetter(r) {
+			continue
+		}
+		code := codes[r]
+		if len(result) == 0 {
+			result = append(result, byte(unicode.ToUpper(r)))
+			last = code
+			continue
+		}
+		if code != 0 && code != last {
+			result = append(result, code)
+			if len(result) == 4 {
+				break
+			}
+		}
+		// h and w do not separate letters with the same code, vowels do.
+		if r != 'h' && r != 'w' {
+			last = code
+		}
+	}
+	if len(result) == 0 {
+		return ""
+	}
+	for len(result) < 4 {
+		result = append(result, '0')
+	}
+	return string(result)
+}
+
+// withinOneEdit reports whether a and b differ by at most one insertion,
+// deletion or substitution.
+func withinOneEdit(a, b string) bool {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) < len(rb) {
+		ra, rb = rb, ra
+	}
+	if len(ra)-len(rb) > 1 {
+		return false
+	}
+	i := 0
+	for i < len(rb) && ra[i] == rb[i] {
+		i++
+	}
+	if i == len(rb) {
+		return true
+	}
+	if len(ra) == len(rb) {
+		return string(ra[i+1:]) == string(rb[i+1:])
+	}
+	return string(ra[i+1:]) == string(rb[i:])
+}
@@ -0,0 +1,319 @@
+package dict_dictd
+
+import (
+	"context"
+	"net"
+	"net/textproto"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Status codes of the DICT protocol, see RFC 2229 section 3.
+const (
+	CodeDatabasesPresent   = 110
+	CodeStrategiesPresent  = 111
+	CodeDatabaseInfo       = 112
+	CodeHelp               = 113
+	CodeServerInfo         = 114
+	CodeDefinitionsFound   = 150
+	CodeDefinitionFollows  = 151
+	CodeMatchesFound       = 152
+	CodeStatus             = 210
+	CodeBanner             = 220
+	CodeClosingConnection  = 221
+	CodeOk                 = 250
+	CodeSyntaxError        = 500
+	CodeSyntaxErrorParams  = 501
+	CodeNotImplemented     = 502
+	CodeInvalidDatabase    = 550
+	CodeInvalidStrategy    = 551
+	CodeNoMatch            = 552
+	CodeNoDatabasesPresent = 554
+	CodeNoStrategies       = 555
+)
+
+// Definition is one DEFINE result.
+type Definition struct {
+	Word        string
+	Database    string
+	Description string
+	Text        string
+}
+
+// Match is one MATCH result.
+type Match struct {
+	Database string
+	Word     string
+}
+
+// Item is a name with its description, as listed by SHOW DB and SHOW STRAT.
+type Item struct {
+	Name        string
+	Description string
+}
+
+// Client is a connection to a DICT server. It is not safe for concurrent use.
+type Client struct {
+	conn    net.Conn
+	text    *textproto.Conn
+	timeout time.Duration
+
+	// ctx cancels the commands of the client by moving the deadline of conn
+	// into the past, mu keeps extendDeadline from moving it back.
+	ctx  context.Context
+	mu   sync.Mutex
+	stop func() bool
+}
+
+// Dial connects to addr, reads the banner and announces the client.
+func Dial(addr string, timeout time.Duration) (*Client, error) {
+	return DialContext(context.Background(), addr, timeout)
+}
+
+// DialContext is Dial with a context that cancels the connection and all
+// commands of the client.
+func DialContext(ctx context.Context, addr string, timeout time.Duration) (*Client, error) {
+	dialer := &net.Dialer{Timeout: timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to connect to dict server %s", addr)
+	}
+	c := &Client{conn: conn, text: textproto.NewConn(conn), timeout: timeout, ctx: ctx}
+	c.stop = context.AfterFunc(ctx, func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		_ = c.conn.SetDeadline(time.Unix(1, 0))
+	})
+	c.extendDeadline()
+	if _, _, err := c.text.ReadCodeLine(CodeBanner); err != nil {
+		_ = c.text.Close()
+		return nil, errors.Wrapf(err, "unexpected banner from dict server %s", addr)
+	}
+	if _, err := c.cmd(CodeOk, "CLIENT %s", Quote("wordflow")); err != nil {
+		_ = c.text.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *Client) extendDeadline() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.ctx.Err() != nil {
+		return
+	}
+	var deadline time.Time
+	if c.timeout > 0 {
+		deadline = time.Now().Add(c.timeout)
+	}
+	if d, ok := c.ctx.Deadline(); ok && (deadline.IsZero() || d.Before(deadline)) {
+		deadline = d
+	}
+	if !deadline.IsZero() {
+		_ = c.conn.SetDeadline(deadline)
+	}
+}
+
+// cmd sends a command and reads its status line, which must have expectCode.
+func (c *Client) cmd(expectCode int, format string, args ...interface{}) (string, error) {
+	c.extendDeadline()
+	if err := c.text.PrintfLine(format, args...); err != nil {
+		return "", errors.Wrap(err, "failed to send dict command")
+	}
+	_, msg, err := c.text.ReadCodeLine(expectCode)
+	return msg, err
+}
+
+// Define returns the definitions of word in database. A word without
+// definitions yields an empty slice rather than an error.
+func (c *Client) Define(database, word string) ([]*Definition, error) {
+	c.extendDeadline()
+	if err := c.text.PrintfLine("DEFINE %s %s", Quote(database), Quote(word)); err != nil {
+		return nil, errors.Wrap(err, "failed to send dict command")
+	}
+	code, msg, err := c.text.ReadCodeLine(0)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read dict response")
+	}
+	switch code {
+	case CodeNoMatch:
+		return []*Definition{}, nil
+	case CodeDefinitionsFound:
+	default:
+		return nil, &textproto.Error{Code: code, Msg: msg}
+	}
+
+	var definitions []*Definition
+	for {
+		code, msg, err := c.text.ReadCodeLine(0)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read dict response")
+		}
+		if code == CodeOk {
+			return definitions, nil
+		}
+		if code != CodeDefinitionFollows {
+			return nil, &textproto.Error{Code: code, Msg: msg}
+		}
+		params := SplitParams(msg)
+		definition := &Definition{}
+		if len(params) > 0 {
+			definition.Word = params[0]
+		}
+		if len(params) > 1 {
+			definition.Database = params[1]
+		}
+		if len(params) > 2 {
+			definition.Description = params[2]
+		}
+		lines, err := c.text.ReadDotLines()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read dict definition")
+		}
+		definition.Text = strings.Join(lines, "\n")
+		definitions = append(definitions, definition)
+	}
+}
+
+// Match returns the headwords in database that match word using strategy.
+func (c *Client) Match(database, strategy, word string) ([]*Match, error) {
+	lines, err := c.list(CodeMatchesFound, "MATCH %s %s %s", Quote(database), Quote(strategy), Quote(word))
+	if err != nil {
+		return nil, err
+	}
+	matches := make([]*Match, 0, len(lines))
+	for _, line := range lines {
+		if params := SplitParams(line); len(params) >= 2 {
+			matches = append(matches, &Match{Database: params[0], Word: params[1]})
+		}
+	}
+	return matches, nil
+}
+
+// ShowDatabases lists the databases of the server.
+func (c *Client) ShowDatabases() ([]*Item, error) {
+	return c.items(CodeDatabasesPresent, "SHOW DB")
+}
+
+// ShowStrategies lists the MATCH strategies of the server.
+func (c *Client) ShowStrategies() ([]*Item, error) {
+	return c.items(CodeStrategiesPresent, "SHOW STRAT")
+}
+
+func (c *Client) items(expectCode int, command string) ([]*Item, error) {
+	lines, err := c.list(expectCode, command)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]*Item, 0, len(lines))
+	for _, line := range lines {
+		params := SplitParams(line)
+		if len(params) == 0 {
+			continue
+		}
+		item := &Item{Name: params[0]}
+		if len(params) > 1 {
+			item.Description = params[1]
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// list runs a command answered by a text block, treating "no match", "no
+// databases" and "no strategies" as an empty list.
+func (c *Client) list(expectCode int, format string, args ...interface{}) ([]string, error) {
+	c.extendDeadline()
+	if err := c.text.PrintfLine(format, args...); err != nil {
+		return nil, errors.Wrap(err, "failed to send dict command")
+	}
+	code, msg, err := c.text.ReadCodeLine(0)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read dict response")
+	}
+	switch code {
+	case expectCode:
+	case CodeNoMatch, CodeNoDatabasesPresent, CodeNoStrategies:
+		return []string{}, nil
+	default:
+		return nil, &textproto.Error{Code: code, Msg: msg}
+	}
+	lines, err := c.text.ReadDotLines()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read dict response")
+	}
+	if _, _, err := c.text.ReadCodeLine(CodeOk); err != nil {
+		return nil, err
+	}
+	return lines, nil
+}
+
+// Close sends QUIT and closes the connection.
+func (c *Client) Close() error {
+	defer c.stop()
+	_, _ = c.cmd(CodeClosingConnection, "QUIT")
+	return c.text.Close()
+}
+
+// Quote returns s as a DICT protocol parameter, quoting it when it is empty or
+// contains spaces, quotes or backslashes.
+func Quote(s string) string {
+	if s != "" && !strings.ContainsAny(s, " \t\"'\\") {
+		return s
+	}
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		if r == '"' || r == '\\' {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// SplitParams splits a command or status line into its parameters, honoring
+// single and double quotes and backslash escapes.
+func SplitParams(line string) []string {
+	var params []string
+	var current strings.Builder
+	inParam := false
+	var quote rune
+	escaped := false
+	for _, r := range line {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+			inParam = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote = r
+			inParam = true
+		case r == ' ' || r == '\t':
+			if inParam {
+				params = append(params, current.String())
+				current.Reset()
+				inParam = false
+			}
+		default:
+			current.WriteRune(r)
+			inParam = true
+		}
+	}
+	if inParam {
+		params = append(params, current.String())
+	}
+	return params
+}
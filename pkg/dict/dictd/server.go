@@ -0,0 +1,347 @@
+package dict_dictd
+
+import (
+	"fmt"
+	"net"
+	"net/textproto"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// Database is a dictionary served over the DICT protocol.
+type Database interface {
+	Name() string
+	Description() string
+	// Define returns the definition of word, or "" when it has none.
+	Define(word string) (string, error)
+	// Match returns the headwords that match word using one of Strategies.
+	Match(strategy, word string) ([]string, error)
+}
+
+// Server answers DICT protocol (RFC 2229) requests from a set of databases.
+type Server struct {
+	// OnDefine, if set, is called once per DEFINE command that found
+	// definitions, with the databases that returned them.
+	OnDefine func(word string, databases []Database)
+
+	databases []Database
+	idleTime  time.Duration
+	nextID    atomic.Int64
+
+	mu        sync.Mutex
+	listeners []net.Listener
+}
+
+func NewServer(databases []Database) *Server {
+	return &Server{databases: databases, idleTime: 10 * time.Minute}
+}
+
+// ListenAndServe listens on the TCP address addr and serves connections.
+func (s *Server) ListenAndServe(addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return errors.Wrapf(err, "failed to listen on %s", addr)
+	}
+	return s.Serve(listener)
+}
+
+// Serve accepts connections on listener until it is closed.
+func (s *Server) Serve(listener net.Listener) error {
+	s.mu.Lock()
+	s.listeners = append(s.listeners, listener)
+	s.mu.Unlock()
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return errors.Wrap(err, "failed to accept dict connection")
+		}
+		go s.serveConn(conn)
+	}
+}
+
+// Close stops all listeners. Open connections finish their current command.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, listener := range s.listeners {
+		_ = listener.Close()
+	}
+	s.listeners = nil
+	return nil
+}
+
+// session is a client connection.
+type session struct {
+	*textproto.Conn
+	// mime is set by OPTION MIME, text blocks then start with a MIME header.
+	mime bool
+}
+
+func (s *Server) serveConn(conn net.Conn) {
+	text := &session{Conn: textproto.NewConn(conn)}
+	defer text.Close()
+
+	hostname, _ := os.Hostname()
+	if hostname == "" {
+		hostname = "localhost"
+	}
+	msgID := fmt.Sprintf("<%d.%d@%s>", os.Getpid(), s.nextID.Add(1), hostname)
+	if err := text.PrintfLine("%d %s wordflow DICT server <mime> %s", CodeBanner, hostname, msgID); err != nil {
+		return
+	}
+	for {
+		_ = conn.SetDeadline(time.Now().Add(s.idleTime))
+		line, err := text.ReadLine()
+		if err != nil {
+			return
+		}
+		params := SplitParams(line)
+		if len(params) == 0 {
+			_ = text.PrintfLine("%d syntax error, command not recognized", CodeSyntaxError)
+			continue
+		}
+		if !s.handle(text, params) {
+			return
+		}
+	}
+}
+
+// handle answers one command and reports whether the connection stays open.
+func (s *Server) handle(text *session, params []string) bool {
+	command := strings.ToUpper(params[0])
+	args := params[1:]
+	var err error
+	switch command {
+	case "CLIENT":
+		err = text.PrintfLine("%d ok", CodeOk)
+	case "DEFINE", "D":
+		if len(args) != 2 {
+			err = text.PrintfLine("%d syntax error, illegal parameters", CodeSyntaxErrorParams)
+			break
+		}
+		err = s.define(text, args[0], args[1])
+	case "MATCH", "M":
+		if len(args) != 3 {
+			err = text.PrintfLine("%d syntax error, illegal parameters", CodeSyntaxErrorParams)
+			break
+		}
+		err = s.match(text, args[0], args[1], args[2])
+	case "SHOW":
+		err = s.show(text, args)
+	case "OPTION":
+		if len(args) == 1 && strings.EqualFold(args[0], "MIME") {
+			text.mime = true
+			err = text.PrintfLine("%d ok - using MIME headers", CodeOk)
+		} else {
+			err = text.PrintfLine("%d syntax error, illegal parameters", CodeSyntaxErrorParams)
+		}
+	case "STATUS":
+		err = text.PrintfLine("%d status: %d databases", CodeStatus, len(s.databases))
+	case "HELP":
+		if err = text.PrintfLine("%d help text follows", CodeHelp); err == nil {
+			err = writeTextBlock(text, strings.Join([]string{
+				"DEFINE database word         -- look up word in database",
+				"MATCH database strategy word -- match word in database using strategy",
+				"SHOW DB                      -- list all accessible databases",
+				"SHOW STRAT                   -- list available matching strategies",
+				"SHOW INFO database           -- provide information about the database",
+				"SHOW SERVER                  -- provide site-specific information",
+				"CLIENT info                  -- identify client to server",
+				"OPTION MIME                  -- use MIME headers",
+				"STATUS                       -- display timing information",
+				"HELP                         -- display this help information",
+				"QUIT                         -- terminate connection",
+			}, "\n"))
+		}
+	case "AUTH", "SASLAUTH":
+		err = text.PrintfLine("%d command not implemented", CodeNotImplemented)
+	case "QUIT", "Q":
+		_ = text.PrintfLine("%d bye", CodeClosingConnection)
+		return false
+	default:
+		err = text.PrintfLine("%d unknown command", CodeSyntaxError)
+	}
+	return err == nil
+}
+
+// lookup resolves a database parameter: "*" and "!" select all databases.
+func (s *Server) lookup(name string) ([]Database, bool) {
+	if name == "*" || name == "!" {
+		return s.databases, true
+	}
+	for _, db := range s.databases {
+		if db.Name() == name {
+			return []Database{db}, true
+		}
+	}
+	return nil, false
+}
+
+func (s *Server) define(text *session, database, word string) error {
+	databases, ok := s.lookup(database)
+	if !ok {
+		return text.PrintfLine("%d invalid database, use \"SHOW DB\" for list of databases", CodeInvalidDatabase)
+	}
+	type result struct {
+		db         Database
+		definition string
+	}
+	var results []result
+	for _, db := range databases {
+		definition, err := db.Define(word)
+		if err != nil {
+			log.Debugf("dict server: define %q in %s failed: %v", word, db.Name(), err)
+			continue
+		}
+		if definition == "" {
+			continue
+		}
+		results = append(results, result{db: db, definition: definition})
+		if database == "!" {
+			break
+		}
+	}
+	if len(results) == 0 {
+		return text.PrintfLine("%d no match", CodeNoMatch)
+	}
+	if s.OnDefine != nil {
+		defined := make([]Database, len(results))
+		for i, r := range results {
+			defined[i] = r.db
+		}
+		s.OnDefine(word, defined)
+	}
+	if err := text.PrintfLine("%d %d definitions retrieved", CodeDefinitionsFound, len(results)); err != nil {
+		return err
+	}
+	for _, r := range results {
+		if err := text.PrintfLine("%d %s %s %s", CodeDefinitionFollows, Quote(word), Quote(r.db.Name()), Quote(r.db.Description())); err != nil {
+			return err
+		}
+		if err := writeTextBlock(text, r.definition); err != nil {
+			return err
+		}
+	}
+	return text.PrintfLine("%d ok", CodeOk)
+}
+
+func (s *Server) match(text *session, database, strategy, word string) error {
+	if strategy == "." {
+		strategy = DefaultStrategy
+	}
+	if !IsStrategy(strategy) {
+		return text.PrintfLine("%d invalid strategy, use \"SHOW STRAT\" for a list of strategies", CodeInvalidStrategy)
+	}
+	databases, ok := s.lookup(database)
+	if !ok {
+		return text.PrintfLine("%d invalid database, use \"SHOW DB\" for list of databases", CodeInvalidDatabase)
+	}
+	var lines []string
+	for _, db := range databases {
+		matches, err := db.Match(strategy, word)
+		if err != nil {
+			log.Debugf("dict server: match %q in %s failed: %v", word, db.Name(), err)
+			continue
+		}
+		for _, m := range matches {
+			lines = append(lines, Quote(db.Name())+" "+Quote(m))
+		}
+		if database == "!" && len(matches) > 0 {
+			break
+		}
+	}
+	if len(lines) == 0 {
+		return text.PrintfLine("%d no match", CodeNoMatch)
+	}
+	if err := text.PrintfLine("%d %d matches found", CodeMatchesFound, len(lines)); err != nil {
+		return err
+	}
+	if err := writeTextBlock(text, strings.Join(lines, "\n")); err != nil {
+		return err
+	}
+	return text.PrintfLine("%d ok", CodeOk)
+}
+
+func (s *Server) show(text *session, args []string) error {
+	if len(args) == 0 {
+		return text.PrintfLine("%d syntax error, illegal parameters", CodeSyntaxErrorParams)
+	}
+	switch strings.ToUpper(args[0]) {
+	case "DB", "DATABASES":
+		if len(s.databases) == 0 {
+			return text.PrintfLine("%d no databases present", CodeNoDatabasesPresent)
+		}
+		lines := make([]string, len(s.databases))
+		for i, db := range s.databases {
+			lines[i] = Quote(db.Name()) + " " + Quote(db.Description())
+		}
+		return s.writeList(text, CodeDatabasesPresent, "databases present", lines)
+	case "STRAT", "STRATEGIES":
+		lines := make([]string, len(Strategies))
+		for i, strategy := range Strategies {
+			lines[i] = Quote(strategy.Name) + " " + Quote(strategy.Description)
+		}
+		return s.writeList(text, CodeStrategiesPresent, "strategies present", lines)
+	case "INFO":
+		if len(args) != 2 {
+			return text.PrintfLine("%d syntax error, illegal parameters", CodeSyntaxErrorParams)
+		}
+		databases, ok := s.lookup(args[1])
+		if !ok || len(databases) != 1 {
+			return text.PrintfLine("%d invalid database, use \"SHOW DB\" for list of databases", CodeInvalidDatabase)
+		}
+		if err := text.PrintfLine("%d database information follows", CodeDatabaseInfo); err != nil {
+			return err
+		}
+		if err := writeTextBlock(text, databases[0].Description()); err != nil {
+			return err
+		}
+		return text.PrintfLine("%d ok", CodeOk)
+	case "SERVER":
+		if err := text.PrintfLine("%d server information follows", CodeServerInfo); err != nil {
+			return err
+		}
+		if err := writeTextBlock(text, "wordflow DICT server, lookups are recorded in the wordflow notebook."); err != nil {
+			return err
+		}
+		return text.PrintfLine("%d ok", CodeOk)
+	default:
+		return text.PrintfLine("%d syntax error, illegal parameters", CodeSyntaxErrorParams)
+	}
+}
+
+func (s *Server) writeList(text *session, code int, message string, lines []string) error {
+	if err := text.PrintfLine("%d %d %s", code, len(lines), message); err != nil {
+		return err
+	}
+	if err := writeTextBlock(text, strings.Join(lines, "\n")); err != nil {
+		return err
+	}
+	return text.PrintfLine("%d ok", CodeOk)
+}
+
+// mimeHeader starts the text blocks of sessions that asked for OPTION MIME.
+const mimeHeader = "Content-Type: text/plain; charset=utf-8\nContent-Transfer-Encoding: 8bit\n\n"
+
+// writeTextBlock sends s as a dot-stuffed text block terminated by ".".
+func writeTextBlock(text *session, s string) error {
+	s = strings.TrimRight(s, "\n") + "\n"
+	if text.mime {
+		s = mimeHeader + s
+	}
+	w := text.DotWriter()
+	if _, err := w.Write([]byte(s)); err != nil {
+		_ = w.Close()
+		return err
+	}
+	return w.Close()
+}
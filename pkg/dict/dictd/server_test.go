@@ -0,0 +1,233 @@
+package dict_dictd
+
+import (
+	"net"
+	"net/textproto"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type memoryDatabase struct {
+	name        string
+	definitions map[string]string
+	defined     []string
+}
+
+func (m *memoryDatabase) Name() string        { return m.name }
+func (m *memoryDatabase) Description() string { return "In memory " + m.name }
+
+func (m *memoryDatabase) Define(word string) (string, error) {
+	m.defined = append(m.defined, word)
+	return m.definitions[word], nil
+}
+
+func (m *memoryDatabase) Match(strategy, word string) ([]string, error) {
+	var words []string
+	for w := range m.definitions {
+		words = append(words, w)
+	}
+	return MatchWords(strategy, word, words), nil
+}
+
+func startServer(t *testing.T, databases ...Database) *Client {
+	t.Helper()
+	return dialServer(t, NewServer(databases))
+}
+
+// serve serves server on a local port and returns its address.
+func serve(t *testing.T, server *Server) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(func() { _ = server.Close() })
+	return listener.Addr().String()
+}
+
+func dialServer(t *testing.T, server *Server) *Client {
+	t.Helper()
+	client, err := Dial(serve(t, server), 5*time.Second)
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	t.Cleanup(func() { _ = client.Close() })
+	return client
+}
+
+func TestServer_Define(t *testing.T) {
+	wn := &memoryDatabase{name: "wn", definitions: map[string]string{"house": "house\n  n. a dwelling\n.hidden dot"}}
+	notebook := &memoryDatabase{name: "notebook", definitions: map[string]string{"house": "house\nlooked up 2 times"}}
+	client := startServer(t, wn, notebook)
+
+	definitions, err := client.Define("*", "house")
+	if err != nil {
+		t.Fatalf("Define() error = %v", err)
+	}
+	if len(definitions) != 2 {
+		t.Fatalf("got %d definitions, want 2", len(definitions))
+	}
+	if definitions[0].Database != "wn" || definitions[0].Description != "In memory wn" || definitions[0].Text != "house\n  n. a dwelling\n.hidden dot" {
+		t.Errorf("definition = %+v", definitions[0])
+	}
+
+	definitions, err = client.Define("!", "house")
+	if err != nil || len(definitions) != 1 || definitions[0].Database != "wn" {
+		t.Errorf("Define(!) = %+v, %v", definitions, err)
+	}
+
+	definitions, err = client.Define("notebook", "house")
+	if err != nil || len(definitions) != 1 || definitions[0].Database != "notebook" {
+		t.Errorf("Define(notebook) = %+v, %v", definitions, err)
+	}
+
+	definitions, err = client.Define("wn", "garden")
+	if err != nil || len(definitions) != 0 {
+		t.Errorf("Define(garden) = %+v, %v", definitions, err)
+	}
+
+	_, err = client.Define("foldoc", "house")
+	if protoErr, ok := err.(*textproto.Error); !ok || protoErr.Code != CodeInvalidDatabase {
+		t.Errorf("expected invalid database, got %v", err)
+	}
+	if strings.Join(wn.defined, ",") != "house,house,garden" {
+		t.Errorf("wn lookups = %v", wn.defined)
+	}
+}
+
+func TestServer_MatchAndShow(t *testing.T) {
+	client := startServer(t, &memoryDatabase{name: "notebook", definitions: map[string]string{
+		"house": "", "horse": "", "housing": "", "mouse": "",
+	}})
+
+	matches, err := client.Match("notebook", "prefix", "hous")
+	if err != nil {
+		t.Fatalf("Match() error = %v", err)
+	}
+	var words []string
+	for _, m := range matches {
+		words = append(words, m.Word)
+	}
+	if len(words) != 2 || !strings.Contains(strings.Join(words, ","), "housing") {
+		t.Errorf("prefix matches = %v", words)
+	}
+
+	matches, err = client.Match("*", ".", "hause")
+	if err != nil || len(matches) != 1 || matches[0].Word != "house" {
+		t.Errorf("default strategy matches = %+v, %v", matches, err)
+	}
+
+	_, err = client.Match("*", "regexp", "h.*")
+	if protoErr, ok := err.(*textproto.Error); !ok || protoErr.Code != CodeInvalidStrategy {
+		t.Errorf("expected invalid strategy, got %v", err)
+	}
+
+	databases, err := client.ShowDatabases()
+	if err != nil || len(databases) != 1 || databases[0].Name != "notebook" || databases[0].Description != "In memory notebook" {
+		t.Errorf("ShowDatabases() = %+v, %v", databases, err)
+	}
+	strategies, err := client.ShowStrategies()
+	if err != nil || len(strategies) != len(Strategies) {
+		t.Errorf("ShowStrategies() = %+v, %v", strategies, err)
+	}
+}
+
+func TestMatchWords(t *testing.T) {
+	words := []string{"House", "horse", "housing", "mouse", "hose", "Robert", "Rupert"}
+	tests := []struct {
+		strategy string
+		word     string
+		want     string
+	}{
+		{"exact", "house", "House"},
+		{"prefix", "hou", "House,housing"},
+		{"lev", "house", "House,horse,mouse,hose"},
+		{"soundex", "Robert", "Robert,Rupert"},
+		{"unknown", "house", ""},
+	}
+	for _, tt := range tests {
+		if got := strings.Join(MatchWords(tt.strategy, tt.word, words), ","); got != tt.want {
+			t.Errorf("MatchWords(%s, %s) = %q, want %q", tt.strategy, tt.word, got, tt.want)
+		}
+	}
+}
+
+func TestSoundex(t *testing.T) {
+	for word, want := range map[string]string{
+		"Robert": "R163", "Rupert": "R163", "Ashcraft": "A261", "Tymczak": "T522", "Pfister": "P236", "Lee": "L000", "": "",
+	} {
+		if got := Soundex(word); got != want {
+			t.Errorf("Soundex(%q) = %q, want %q", word, got, want)
+		}
+	}
+}
+
+func TestServer_OptionMIME(t *testing.T) {
+	server := NewServer([]Database{&memoryDatabase{name: "wn", definitions: map[string]string{"house": "a dwelling"}}})
+	text, err := textproto.Dial("tcp", serve(t, server))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer text.Close()
+	if _, msg, err := text.ReadCodeLine(CodeBanner); err != nil || !strings.Contains(msg, "<mime>") {
+		t.Fatalf("banner = %q, %v", msg, err)
+	}
+	if _, err := text.Cmd("OPTION MIME"); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := text.ReadCodeLine(CodeOk); err != nil {
+		t.Fatalf("OPTION MIME error = %v", err)
+	}
+	if _, err := text.Cmd("DEFINE wn house"); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := text.ReadCodeLine(CodeDefinitionsFound); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := text.ReadCodeLine(CodeDefinitionFollows); err != nil {
+		t.Fatal(err)
+	}
+	lines, err := text.ReadDotLines()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"Content-Type: text/plain; charset=utf-8", "Content-Transfer-Encoding: 8bit", "", "a dwelling"}
+	if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+		t.Errorf("definition with MIME header = %q", lines)
+	}
+}
+
+func TestServer_OnDefine(t *testing.T) {
+	wn := &memoryDatabase{name: "wn", definitions: map[string]string{"house": "a dwelling"}}
+	gcide := &memoryDatabase{name: "gcide", definitions: map[string]string{"house": "a building"}}
+	server := NewServer([]Database{wn, gcide})
+	var mu sync.Mutex
+	var defined []string
+	server.OnDefine = func(word string, databases []Database) {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, db := range databases {
+			defined = append(defined, word+"@"+db.Name())
+		}
+		defined = append(defined, "|")
+	}
+	client := dialServer(t, server)
+
+	if _, err := client.Define("*", "house"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Define("*", "garden"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Define("!", "house"); err != nil {
+		t.Fatal(err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if got := strings.Join(defined, " "); got != "house@wn house@gcide | house@wn |" {
+		t.Errorf("OnDefine calls = %q", got)
+	}
+}
@@ -0,0 +1,34 @@
+package dict_ecdict
+
+import (
+	"errors"
+	"path/filepath"
+
+	"github.com/gogodjzhu/word-flow/internal/config"
+)
+
+// Config is the dict.ecdict section.
+type Config struct {
+	DBFilename string `yaml:"db_filename,omitempty"`
+}
+
+// ConfigSection registers Config with the dictionary.
+var ConfigSection = config.EndpointSpec{
+	New: func() config.EndpointConfig { return &Config{} },
+	Defaults: func(c config.EndpointConfig, dir string) {
+		if ecdict := c.(*Config); ecdict.DBFilename == "" {
+			ecdict.DBFilename = filepath.Join(dir, "stardict.db")
+		}
+	},
+	Template: `ecdict:
+  # Local dictionary database path. Defaults to <WORDFLOW_HOME>/stardict.db if empty
+  # db_filename: ""
+`,
+}
+
+func (c *Config) Validate() error {
+	if c.DBFilename == "" {
+		return errors.New("ecdict.db_filename is required when ecdict is the default dictionary")
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package dict_ecdict
 
 import (
 	"archive/zip"
+	"context"
 	"fmt"
 	"io"
 	"net/http"
@@ -10,7 +11,8 @@ import (
 	"strings"
 	"time"
 
-	"github.com/gogodjzhu/word-flow/internal/config"
+	"github.com/gogodjzhu/word-flow/internal/buzz_error"
+	"github.com/gogodjzhu/word-flow/internal/util"
 	"github.com/gogodjzhu/word-flow/pkg/dict/entity"
 	"github.com/pkg/errors"
 	"gorm.io/driver/sqlite"
@@ -23,7 +25,7 @@ type DictEcdict struct {
 	db *gorm.DB
 }
 
-func NewDictEcdit(config *config.EcdictConfig) (*DictEcdict, error) {
+func NewDictEcdit(config *Config) (*DictEcdict, error) {
 	if config == nil {
 		return nil, errors.New("ecdict config is required")
 	}
@@ -87,6 +89,39 @@ func (d *DictEcdict) Search(word string) (*entity.WordItem, error) {
 	return result, nil
 }
 
+// reverseLimit is the number of English words SearchReverse returns.
+const reverseLimit = 10
+
+// SearchReverse returns the most frequent English words whose translation
+// contains the Chinese query, one meaning per word.
+func (d *DictEcdict) SearchReverse(ctx context.Context, query string) (*entity.WordItem, error) {
+	var words []Word
+	err := d.db.WithContext(ctx).Raw(
+		"select * from stardict where translation like ? order by frq = 0, frq, length(word) limit ?",
+		"%"+query+"%", reverseLimit,
+	).Scan(&words).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to search ecdict")
+	}
+	if len(words) == 0 {
+		return nil, buzz_error.InvalidInput("Invalid word: " + query)
+	}
+	result := &entity.WordItem{
+		ID:            entity.WordId(query),
+		Word:          query,
+		Source:        "ecdict",
+		WordPhonetics: make([]*entity.WordPhonetic, 0),
+		WordMeanings:  make([]*entity.WordMeaning, 0, len(words)),
+	}
+	for _, word := range words {
+		result.WordMeanings = append(result.WordMeanings, &entity.WordMeaning{
+			PartOfSpeech: word.Word,
+			Definitions:  strings.ReplaceAll(word.Translation, "\n", "; "),
+		})
+	}
+	return result, nil
+}
+
 type Word struct {
 	gorm.Model
 	Id          int64  `gorm:"column:id"`
@@ -113,15 +148,20 @@ func (Word) TableName() string {
 // downloadWithProgress downloads a file from url to dest with inline progress.
 func downloadWithProgress(url, dest string) error {
 	fmt.Printf("Download %s to %s\n", url, dest)
-	resp, err := http.Get(url)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("unexpected status: %s", resp.Status)
-	}
+	// The download may take a while, it has no timeout.
+	return util.NewHTTPClientBuilder().
+		WithTimeout(0).
+		WithContext(util.WithEndpoint(context.Background(), "dict.ecdict")).
+		Get(url, nil).
+		ExecuteStream(func(resp *http.Response) error {
+			if resp.StatusCode != http.StatusOK {
+				return fmt.Errorf("unexpected status: %s", resp.Status)
+			}
+			return writeWithProgress(resp, dest)
+		})
+}
 
+func writeWithProgress(resp *http.Response, dest string) error {
 	out, err := os.Create(dest)
 	if err != nil {
 		return err
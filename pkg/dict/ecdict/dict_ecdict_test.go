@@ -1,10 +1,14 @@
 package dict_ecdict
 
 import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
 	"github.com/gogodjzhu/word-flow/pkg/dict/entity"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
-	"testing"
 )
 
 func TestDictEcdict_Search(t *testing.T) {
@@ -58,3 +62,36 @@ func TestDictEcdict_Search(t *testing.T) {
 		})
 	}
 }
+
+func TestDictEcdict_SearchReverse(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "stardict.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	statements := []string{
+		"create table stardict (id integer primary key, word text, sw text, phonetic text, definition text, translation text, pos text, collins text, oxford text, tag text, bnc text, frq integer, exchange text, detail text, audio text)",
+		"insert into stardict (word, translation, frq) values ('apple', 'n. 苹果, 苹果树', 1800), ('pineapple', 'n. 凤梨, 菠萝', 9000), ('pomme', 'n. 苹果(法语)', 0), ('pear', 'n. 梨', 3000)",
+	}
+	for _, statement := range statements {
+		if err := db.Exec(statement).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	d := &DictEcdict{db: db}
+
+	got, err := d.SearchReverse(context.Background(), "苹果")
+	if err != nil {
+		t.Fatalf("SearchReverse() error = %v", err)
+	}
+	var words []string
+	for _, meaning := range got.WordMeanings {
+		words = append(words, meaning.PartOfSpeech)
+	}
+	if got.Word != "苹果" || strings.Join(words, ",") != "apple,pomme" {
+		t.Errorf("SearchReverse() = %s with %v, want apple before the word without frequency", got.Word, words)
+	}
+
+	if _, err := d.SearchReverse(context.Background(), "香蕉"); err == nil {
+		t.Error("expected an error for a query without matches")
+	}
+}
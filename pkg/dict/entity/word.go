@@ -17,6 +17,12 @@ type WordItem struct {
 	WordMeanings  []*WordMeaning  `json:"word_meanings" yaml:"-"`
 	// mixed examples
 	Examples []string `json:"examples,omitempty" yaml:"examples,omitempty"`
+	// thesaurus
+	Synonyms []string `json:"synonyms,omitempty" yaml:"synonyms,omitempty"`
+	Antonyms []string `json:"antonyms,omitempty" yaml:"antonyms,omitempty"`
+	Related  []string `json:"related,omitempty" yaml:"related,omitempty"`
+	// detected language of the looked up word, empty when unknown
+	Language string `json:"language,omitempty" yaml:"language,omitempty"`
 }
 
 type WordPhonetic struct {
@@ -41,6 +47,8 @@ type WordNote struct {
 	Translation    string          `json:"translation,omitempty" yaml:"translation,omitempty"`
 	Examples       []string        `json:"examples,omitempty" yaml:"examples,omitempty"`
 	WordPhonetics  []*WordPhonetic `json:"word_phonetics,omitempty" yaml:"word_phonetics,omitempty"`
+	Synonyms       []string        `json:"synonyms,omitempty" yaml:"synonyms,omitempty"`
+	Antonyms       []string        `json:"antonyms,omitempty" yaml:"antonyms,omitempty"`
 	// FSRS fields
 	FSRSCard   *FSRSCard `json:"fsrs_card,omitempty" yaml:"fsrs_card,omitempty"`
 	LastRating int       `json:"last_rating,omitempty" yaml:"last_rating"`
@@ -62,8 +70,12 @@ func (w *WordItem) Format() []cmdutil.MarkupSegment {
 		Type: cmdutil.MarkupTitle,
 	})
 	if len(w.Source) > 0 {
+		note := w.Source
+		if len(w.Language) > 0 {
+			note += ", " + w.Language
+		}
 		segments = append(segments, cmdutil.MarkupSegment{
-			Text: "  (" + w.Source + ")",
+			Text: "  (" + note + ")",
 			Type: cmdutil.MarkupNote,
 		})
 	}
@@ -139,6 +151,10 @@ func (w *WordItem) Format() []cmdutil.MarkupSegment {
 			})
 		}
 	}
+	// 近义词、反义词和相关词
+	segments = append(segments, formatRelated("syn.", w.Synonyms)...)
+	segments = append(segments, formatRelated("ant.", w.Antonyms)...)
+	segments = append(segments, formatRelated("rel.", w.Related)...)
 	// 结尾换行
 	segments = append(segments, cmdutil.MarkupSegment{
 		Text: "\n",
@@ -148,6 +164,17 @@ func (w *WordItem) Format() []cmdutil.MarkupSegment {
 	return segments
 }
 
+func formatRelated(label string, words []string) []cmdutil.MarkupSegment {
+	if len(words) == 0 {
+		return nil
+	}
+	return []cmdutil.MarkupSegment{
+		{Text: "\n", Type: cmdutil.MarkupText},
+		{Text: label + " ", Type: cmdutil.MarkupNote},
+		{Text: strings.Join(words, ", "), Type: cmdutil.MarkupRef},
+	}
+}
+
 func (f *WordItem) RenderString() string {
 	// 使用默认渲染器保持向后兼容
 	defaultRenderer := cmdutil.NewRenderer(true)
@@ -190,3 +217,15 @@ func (n *WordNote) GetDefinition() string {
 func (n *WordNote) GetExamples() []string {
 	return n.Examples
 }
+
+// GetHints returns the synonyms and antonyms of the word as recall hints
+func (n *WordNote) GetHints() []string {
+	var hints []string
+	if len(n.Synonyms) > 0 {
+		hints = append(hints, "syn. "+strings.Join(n.Synonyms, ", "))
+	}
+	if len(n.Antonyms) > 0 {
+		hints = append(hints, "ant. "+strings.Join(n.Antonyms, ", "))
+	}
+	return hints
+}
@@ -0,0 +1,28 @@
+package dict_etymonline
+
+import (
+	"github.com/gogodjzhu/word-flow/internal/config"
+)
+
+// Config is the dict.etymonline section.
+type Config struct {
+	Timeout config.Duration    `yaml:"timeout,omitempty"`
+	HTTP    *config.HTTPConfig `yaml:"http,omitempty"`
+}
+
+// ConfigSection registers Config with the dictionary.
+var ConfigSection = config.EndpointSpec{
+	New: func() config.EndpointConfig { return &Config{} },
+	Defaults: func(c config.EndpointConfig, dir string) {
+		etymonline := c.(*Config)
+		config.DefaultTimeout(&etymonline.Timeout, config.OnlineTimeout)
+		config.DefaultHTTP(&etymonline.HTTP, 0)
+	},
+	Template: `etymonline:
+  timeout: 10s            # Request timeout
+`,
+}
+
+func (c *Config) Validate() error {
+	return nil
+}
@@ -1,12 +1,13 @@
 package dict_etymonline
 
 import (
+	"context"
 	"net/http"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/PuerkitoBio/goquery"
-	"github.com/gogodjzhu/word-flow/internal/config"
 	"github.com/gogodjzhu/word-flow/internal/util"
 	"github.com/gogodjzhu/word-flow/pkg/dict/entity"
 	"github.com/pkg/errors"
@@ -15,16 +16,30 @@ import (
 const Host = "https://www.etymonline.com"
 
 type DictEtymonline struct {
+	timeout time.Duration
+	policy  *util.Policy
 }
 
-func NewDictEtymonline(config *config.EtymonlineConfig) (*DictEtymonline, error) {
-	return &DictEtymonline{}, nil
+func NewDictEtymonline(config *Config) (*DictEtymonline, error) {
+	d := &DictEtymonline{}
+	if config != nil {
+		d.timeout = time.Duration(config.Timeout)
+		d.policy = util.PolicyFromConfig(config.HTTP)
+	}
+	return d, nil
 }
 
 func (d *DictEtymonline) Search(word string) (*entity.WordItem, error) {
+	return d.SearchContext(context.Background(), word)
+}
+
+func (d *DictEtymonline) SearchContext(ctx context.Context, word string) (*entity.WordItem, error) {
+	ctx, cancel := util.ContextWithTimeout(ctx, d.timeout)
+	defer cancel()
+	ctx = util.WithEndpoint(util.WithPolicy(ctx, d.policy), "dict.etymonline")
 	url := Host + "/word/" + word
 	pattern, _ := regexp.Compile(`\(([^)]+)\)`)
-	result, err := util.SendGet(url, nil, func(response *http.Response) (interface{}, error) {
+	result, err := util.SendGetContext(ctx, url, nil, func(response *http.Response) (interface{}, error) {
 		if response.StatusCode != 200 {
 			return nil, errors.New("failed to sendGet")
 		}
@@ -0,0 +1,52 @@
+package dict_etymonline
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/gogodjzhu/word-flow/internal/cassette"
+)
+
+func TestDictEtymonline_Search(t *testing.T) {
+	c := cassette.Load(t, "etymonline")
+	d, err := NewDictEtymonline(&Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		word     string
+		wantErr  bool
+		meanings []string // part of speech and definitions joined by "|"
+	}{
+		{
+			word: "hello",
+			meanings: []string{
+				"interj.|greeting between persons, 1846, from hallo, itself an alteration of hollo.\nAs a noun from 1854.\n > Hello, Central, give me heaven.\n",
+				"v.|\"to say hello,\" 1885, from hello (interj.).\n",
+			},
+		},
+		{word: "qwzxv", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.word, func(t *testing.T) {
+			got, err := d.SearchContext(c.Context(context.Background()), tt.word)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SearchContext() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.Word != tt.word || got.Source != "etymonline" {
+				t.Errorf("word = %q, source = %q", got.Word, got.Source)
+			}
+			var meanings []string
+			for _, m := range got.WordMeanings {
+				meanings = append(meanings, m.PartOfSpeech+"|"+m.Definitions)
+			}
+			if strings.Join(meanings, "\n") != strings.Join(tt.meanings, "\n") {
+				t.Errorf("meanings = %q, want %q", meanings, tt.meanings)
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package dict_exec
+
+import (
+	"github.com/gogodjzhu/word-flow/internal/config"
+)
+
+// Config is the dict.exec section, a plugin sent {"word": "..."} that
+// answers with a WordItem.
+type Config config.ExecConfig
+
+// ConfigSection registers Config with the dictionary.
+var ConfigSection = config.EndpointSpec{
+	New:      func() config.EndpointConfig { return &Config{} },
+	Defaults: func(c config.EndpointConfig, dir string) { (*config.ExecConfig)(c.(*Config)).SetDefaults() },
+	Template: `exec:
+  # External dictionary plugin. Wordflow runs the command, sends {"word": "..."} as JSON and
+  # reads a WordItem JSON back. See the README for the protocol
+  # command: ""
+  # args: []
+  protocol: stdin         # Options: stdin (one process per lookup), jsonrpc (long-lived JSON-RPC 2.0 session over stdio)
+  timeout: 10s
+`,
+}
+
+func (c *Config) Validate() error {
+	return (*config.ExecConfig)(c).ValidateSection("dict")
+}
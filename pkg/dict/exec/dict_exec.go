@@ -0,0 +1,64 @@
+package dict_exec
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gogodjzhu/word-flow/internal/buzz_error"
+	"github.com/gogodjzhu/word-flow/internal/config"
+	"github.com/gogodjzhu/word-flow/internal/plugin"
+	"github.com/gogodjzhu/word-flow/pkg/dict/entity"
+	"github.com/pkg/errors"
+)
+
+// searchRequest is sent to the plugin, which answers with an entity.WordItem.
+type searchRequest struct {
+	Word string `json:"word"`
+}
+
+// DictExec looks words up with an external plugin process.
+type DictExec struct {
+	plugin *plugin.Plugin
+}
+
+func NewDictExec(cfg *Config) (*DictExec, error) {
+	if cfg == nil {
+		return nil, errors.New("exec config is required")
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return &DictExec{plugin: plugin.New((*config.ExecConfig)(cfg))}, nil
+}
+
+func (d *DictExec) Search(word string) (*entity.WordItem, error) {
+	return d.SearchContext(context.Background(), word)
+}
+
+func (d *DictExec) SearchContext(ctx context.Context, word string) (*entity.WordItem, error) {
+	word = strings.TrimSpace(word)
+	if word == "" {
+		return nil, buzz_error.InvalidInput("Invalid word: " + word)
+	}
+	var item entity.WordItem
+	if err := d.plugin.CallContext(ctx, "search", searchRequest{Word: word}, &item); err != nil {
+		return nil, err
+	}
+	if len(item.WordMeanings) == 0 {
+		return nil, buzz_error.InvalidInput("Invalid word: " + word)
+	}
+	if item.Word == "" {
+		item.Word = word
+	}
+	if item.ID == "" {
+		item.ID = entity.WordId(item.Word)
+	}
+	if item.Source == "" {
+		item.Source = "exec"
+	}
+	return &item, nil
+}
+
+func (d *DictExec) Close() error {
+	return d.plugin.Close()
+}
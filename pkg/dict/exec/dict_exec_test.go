@@ -0,0 +1,55 @@
+package dict_exec
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+)
+
+// TestHelperProcess is a stdin plugin that knows a single word.
+func TestHelperProcess(t *testing.T) {
+	if os.Getenv("WORDFLOW_TEST_PLUGIN") == "" {
+		return
+	}
+	defer os.Exit(0)
+	var request map[string]string
+	_ = json.NewDecoder(os.Stdin).Decode(&request)
+	if request["word"] == "kubelet" {
+		fmt.Print(`{"word": "kubelet", "source": "acme-terms", "word_meanings": [{"part_of_speech": "n.", "definitions": "the node agent of Kubernetes"}]}`)
+		return
+	}
+	fmt.Print(`{"word": "` + request["word"] + `"}`)
+}
+
+func TestDictExec_Search(t *testing.T) {
+	d, err := NewDictExec(&Config{
+		Command:  os.Args[0],
+		Args:     []string{"-test.run=TestHelperProcess"},
+		Env:      []string{"WORDFLOW_TEST_PLUGIN=1"},
+		Protocol: "stdin",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := d.Search(" kubelet ")
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if got.Source != "acme-terms" || got.ID == "" || len(got.WordMeanings) != 1 || got.WordMeanings[0].Definitions != "the node agent of Kubernetes" {
+		t.Errorf("got %+v", got)
+	}
+	if _, err := d.Search("pod"); err == nil || !strings.Contains(err.Error(), "Invalid word: pod") {
+		t.Errorf("Search(pod) error = %v, want invalid word", err)
+	}
+}
+
+func TestNewDictExec_Validate(t *testing.T) {
+	if _, err := NewDictExec(&Config{Protocol: "stdin"}); err == nil {
+		t.Error("expected error without command")
+	}
+	if _, err := NewDictExec(&Config{Command: "plugin", Protocol: "grpc"}); err == nil {
+		t.Error("expected error for an unknown protocol")
+	}
+}
@@ -0,0 +1,124 @@
+package dict
+
+import (
+	"context"
+	"os"
+	"sync"
+
+	"github.com/gogodjzhu/word-flow/internal/config"
+	"github.com/gogodjzhu/word-flow/pkg/dict/entity"
+)
+
+// fallbackDict searches a chain of endpoints and returns the first result.
+// Endpoints after the first are only created when they are needed.
+type fallbackDict struct {
+	conf      *config.DictConfig
+	endpoints []string
+
+	mu    sync.Mutex
+	dicts []Dict
+}
+
+func newFallbackDict(conf *config.DictConfig, first Dict) *fallbackDict {
+	endpoints := []string{conf.Default}
+	for _, endpoint := range conf.Fallback {
+		duplicate := false
+		for _, e := range endpoints {
+			duplicate = duplicate || e == endpoint
+		}
+		if !duplicate {
+			endpoints = append(endpoints, endpoint)
+		}
+	}
+	dicts := make([]Dict, len(endpoints))
+	dicts[0] = first
+	return &fallbackDict{conf: conf, endpoints: endpoints, dicts: dicts}
+}
+
+func (f *fallbackDict) link(i int) (Dict, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.dicts[i] == nil {
+		d, err := newEndpointDict(f.conf, f.endpoints[i])
+		if err != nil {
+			return nil, err
+		}
+		f.dicts[i] = d
+	}
+	return f.dicts[i], nil
+}
+
+// Search returns the result of the first endpoint that finds word, or the
+// error of the last one.
+func (f *fallbackDict) Search(word string) (*entity.WordItem, error) {
+	return f.SearchContext(context.Background(), word)
+}
+
+// SearchContext stops at the first endpoint when ctx is done.
+func (f *fallbackDict) SearchContext(ctx context.Context, word string) (*entity.WordItem, error) {
+	var lastErr error
+	for i := range f.endpoints {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		d, err := f.link(i)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		wordItem, err := Search(ctx, d, word)
+		if err == nil {
+			return wordItem, nil
+		}
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+// SearchReverse returns the result of the first endpoint with a reverse
+// lookup that finds query. Without such an endpoint the chain is searched as
+// usual.
+func (f *fallbackDict) SearchReverse(ctx context.Context, query string) (*entity.WordItem, error) {
+	var lastErr error
+	reversible := false
+	for i := range f.endpoints {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		d, err := f.link(i)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		rd, ok := d.(ReverseDict)
+		if !ok {
+			continue
+		}
+		reversible = true
+		wordItem, err := rd.SearchReverse(ctx, query)
+		if err == nil {
+			return wordItem, nil
+		}
+		lastErr = err
+	}
+	if !reversible {
+		return f.SearchContext(ctx, query)
+	}
+	return nil, lastErr
+}
+
+// Resource looks name up in every endpoint of the chain that bundles media.
+func (f *fallbackDict) Resource(name string) ([]byte, error) {
+	for i := range f.endpoints {
+		d, err := f.link(i)
+		if err != nil {
+			continue
+		}
+		if provider, ok := d.(ResourceProvider); ok {
+			if data, err := provider.Resource(name); err == nil {
+				return data, nil
+			}
+		}
+	}
+	return nil, os.ErrNotExist
+}
@@ -0,0 +1,77 @@
+package dict
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gogodjzhu/word-flow/internal/buzz_error"
+	"github.com/gogodjzhu/word-flow/internal/config"
+	dict_custom "github.com/gogodjzhu/word-flow/pkg/dict/custom"
+	"github.com/gogodjzhu/word-flow/pkg/dict/entity"
+	dict_stardict "github.com/gogodjzhu/word-flow/pkg/dict/stardict"
+)
+
+type fakeDict struct {
+	source string
+	words  map[string]bool
+	calls  int
+}
+
+func (f *fakeDict) Search(word string) (*entity.WordItem, error) {
+	f.calls++
+	if !f.words[word] {
+		return nil, buzz_error.InvalidInput("Invalid word: " + word)
+	}
+	return &entity.WordItem{Word: word, Source: f.source}, nil
+}
+
+func TestFallbackDict_Search(t *testing.T) {
+	custom := &fakeDict{source: "custom", words: map[string]bool{"wordflow": true}}
+	youdao := &fakeDict{source: "youdao", words: map[string]bool{"hello": true}}
+	d := newFallbackDict(&config.DictConfig{Default: "custom", Fallback: []string{"youdao", "custom"}}, custom)
+	d.dicts[1] = youdao
+
+	if len(d.endpoints) != 2 {
+		t.Fatalf("endpoints = %v, duplicates should be dropped", d.endpoints)
+	}
+	got, err := d.Search("wordflow")
+	if err != nil || got.Source != "custom" || youdao.calls != 0 {
+		t.Errorf("Search(wordflow) = %+v, %v, youdao calls %d", got, err, youdao.calls)
+	}
+	got, err = d.Search("hello")
+	if err != nil || got.Source != "youdao" {
+		t.Errorf("Search(hello) = %+v, %v", got, err)
+	}
+	if _, err := d.Search("missing"); err == nil {
+		t.Error("expected error when no endpoint knows the word")
+	}
+}
+
+func TestNewDict_CustomWithFallback(t *testing.T) {
+	dir := t.TempDir()
+	glossary := "- word: wordflow\n  meanings:\n    - definition: our terminal dictionary tool\n"
+	if err := os.WriteFile(filepath.Join(dir, "glossary.yaml"), []byte(glossary), 0644); err != nil {
+		t.Fatal(err)
+	}
+	conf := &config.DictConfig{
+		Default:  "custom",
+		Fallback: []string{"stardict"},
+		Endpoints: map[string]config.EndpointConfig{
+			"custom":   &dict_custom.Config{Dir: dir},
+			"stardict": &dict_stardict.Config{Paths: []string{filepath.Join(dir, "stardict")}},
+		},
+	}
+	d, err := NewDict(conf)
+	if err != nil {
+		t.Fatalf("NewDict() error = %v", err)
+	}
+	got, err := d.Search("wordflow")
+	if err != nil || got.Source != "custom: glossary.yaml" {
+		t.Errorf("Search(wordflow) = %+v, %v", got, err)
+	}
+	// The stardict link has no bundles, so the chain reports its error.
+	if _, err := d.Search("hello"); err == nil {
+		t.Error("expected error from the fallback endpoint")
+	}
+}
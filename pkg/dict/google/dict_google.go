@@ -1,25 +1,33 @@
 package dict_google
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	neturl "net/url"
 	"strings"
+	"time"
 
-	"github.com/gogodjzhu/word-flow/internal/config"
 	httputil "github.com/gogodjzhu/word-flow/internal/util"
 	"github.com/gogodjzhu/word-flow/pkg/dict/entity"
 	"github.com/pkg/errors"
 )
 
 type DictGoogle struct {
-	cfg *config.GoogleConfig
+	cfg     *Config
+	timeout time.Duration
+	policy  *httputil.Policy
 }
 
-func NewDictGoogle(cfg *config.GoogleConfig) (*DictGoogle, error) {
-	return &DictGoogle{cfg: cfg}, nil
+func NewDictGoogle(cfg *Config) (*DictGoogle, error) {
+	d := &DictGoogle{cfg: cfg}
+	if cfg != nil {
+		d.timeout = time.Duration(cfg.Timeout)
+		d.policy = httputil.PolicyFromConfig(cfg.HTTP)
+	}
+	return d, nil
 }
 
 func parseDictResponse(body []byte, word string) (*entity.WordItem, error) {
@@ -90,11 +98,18 @@ func parseDictResponse(body []byte, word string) (*entity.WordItem, error) {
 }
 
 func (d *DictGoogle) Search(word string) (*entity.WordItem, error) {
+	return d.SearchContext(context.Background(), word)
+}
+
+func (d *DictGoogle) SearchContext(ctx context.Context, word string) (*entity.WordItem, error) {
+	ctx, cancel := httputil.ContextWithTimeout(ctx, d.timeout)
+	defer cancel()
+	ctx = httputil.WithEndpoint(httputil.WithPolicy(ctx, d.policy), "dict.google")
 	url := fmt.Sprintf(
 		"https://translate.googleapis.com/translate_a/single?client=gtx&sl=en&tl=zh-CN&dt=t&dt=bd&q=%s",
 		neturl.QueryEscape(word),
 	)
-	result, err := httputil.SendGet(url, nil, func(response *http.Response) (interface{}, error) {
+	result, err := httputil.SendGetContext(ctx, url, nil, func(response *http.Response) (interface{}, error) {
 		if response.StatusCode != 200 {
 			return nil, fmt.Errorf("unexpected status: %s", response.Status)
 		}
@@ -1,8 +1,12 @@
 package dict_google
 
 import (
+	"context"
 	"encoding/json"
+	"strings"
 	"testing"
+
+	"github.com/gogodjzhu/word-flow/internal/cassette"
 )
 
 func TestAbbreviatePos(t *testing.T) {
@@ -120,4 +124,39 @@ func TestParseDictResponse_InvalidJSON(t *testing.T) {
 	if err == nil {
 		t.Error("expected error for invalid JSON, got nil")
 	}
+}
+
+func TestDictGoogle_Search(t *testing.T) {
+	c := cassette.Load(t, "google")
+	d, err := NewDictGoogle(&Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		word     string
+		wantErr  bool
+		meanings []string // part of speech and definitions joined by "|"
+	}{
+		{word: "hello", meanings: []string{"int.|你好!; 喂!", "n.|打招呼"}},
+		{word: "wordflow", meanings: []string{"|词流"}},
+		{word: "limited", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.word, func(t *testing.T) {
+			got, err := d.SearchContext(c.Context(context.Background()), tt.word)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SearchContext() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			var meanings []string
+			for _, m := range got.WordMeanings {
+				meanings = append(meanings, m.PartOfSpeech+"|"+m.Definitions)
+			}
+			if strings.Join(meanings, "\n") != strings.Join(tt.meanings, "\n") {
+				t.Errorf("meanings = %q, want %q", meanings, tt.meanings)
+			}
+		})
+	}
 }
\ No newline at end of file
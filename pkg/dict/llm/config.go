@@ -0,0 +1,63 @@
+package dict_llm
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gogodjzhu/word-flow/internal/config"
+)
+
+// Config is the dict.llm section.
+type Config struct {
+	ApiKey      string             `yaml:"api_key,omitempty"`
+	URL         string             `yaml:"url,omitempty"`
+	Model       string             `yaml:"model,omitempty"`
+	Timeout     config.Duration    `yaml:"timeout,omitempty"`
+	MaxTokens   int                `yaml:"max_tokens,omitempty"`
+	Temperature float64            `yaml:"temperature,omitempty"`
+	HTTP        *config.HTTPConfig `yaml:"http,omitempty"`
+}
+
+// ConfigSection registers Config with the dictionary.
+var ConfigSection = config.EndpointSpec{
+	New: func() config.EndpointConfig { return &Config{} },
+	Defaults: func(c config.EndpointConfig, dir string) {
+		llm := c.(*Config)
+		config.DefaultTimeout(&llm.Timeout, 30*time.Second)
+		config.DefaultHTTP(&llm.HTTP, 0)
+		if llm.MaxTokens == 0 {
+			llm.MaxTokens = 2000
+		}
+		if llm.Temperature == 0 {
+			llm.Temperature = 0.3
+		}
+	},
+	Template: `llm:
+  # LLM provider settings (required if dict.default is llm or using trans command)
+  # api_key: ""           # Required. Set via WORDFLOW_DICT_LLM_API_KEY or wordflow config set dict.llm.api_key
+  # url: ""               # Required. Full API endpoint URL, not base URL. e.g. https://dashscope.aliyuncs.com/compatible-mode/v1/chat/completions
+  # model: ""             # Required. LLM model name, e.g. glm-4
+  timeout: 30s
+  max_tokens: 2000
+  temperature: 0.3
+`,
+}
+
+func (c *Config) Validate() error {
+	if c.ApiKey == "" {
+		return errors.New("llm.api_key is required. Set it via: wordflow config set dict.llm.api_key <key> or env var WORDFLOW_DICT_LLM_API_KEY")
+	}
+	if c.URL == "" {
+		return errors.New("llm.url is required. Set it via: wordflow config set dict.llm.url <url> or env var WORDFLOW_DICT_LLM_URL")
+	}
+	if c.Model == "" {
+		return errors.New("llm.model is required. Set it via: wordflow config set dict.llm.model <model> or env var WORDFLOW_DICT_LLM_MODEL")
+	}
+	if c.MaxTokens <= 0 {
+		return errors.New("llm.max_tokens must be positive")
+	}
+	if c.Temperature < 0 || c.Temperature > 2 {
+		return errors.New("llm.temperature must be between 0 and 2")
+	}
+	return nil
+}
@@ -1,24 +1,25 @@
 package dict_llm
 
 import (
+	"context"
 	"fmt"
 	"time"
 
-	"github.com/gogodjzhu/word-flow/internal/config"
 	"github.com/gogodjzhu/word-flow/internal/llm"
+	"github.com/gogodjzhu/word-flow/internal/util"
 	"github.com/gogodjzhu/word-flow/pkg/dict/entity"
 	"github.com/pkg/errors"
 )
 
 type LLMClient interface {
-	TranslateAndExplain(text string) (*entity.WordItem, error)
+	TranslateAndExplainContext(ctx context.Context, text string) (*entity.WordItem, error)
 }
 
 type DictLLM struct {
 	client LLMClient
 }
 
-func NewDictLLM(config *config.LLMConfig) (*DictLLM, error) {
+func NewDictLLM(config *Config) (*DictLLM, error) {
 	if config == nil {
 		return nil, errors.New("llm config is required")
 	}
@@ -31,7 +32,7 @@ func NewDictLLM(config *config.LLMConfig) (*DictLLM, error) {
 		time.Duration(config.Timeout),
 		config.MaxTokens,
 		config.Temperature,
-	)
+	).WithPolicy(util.PolicyFromConfig(config.HTTP))
 
 	return &DictLLM{
 		client: client,
@@ -39,11 +40,15 @@ func NewDictLLM(config *config.LLMConfig) (*DictLLM, error) {
 }
 
 func (d *DictLLM) Search(word string) (*entity.WordItem, error) {
+	return d.SearchContext(context.Background(), word)
+}
+
+func (d *DictLLM) SearchContext(ctx context.Context, word string) (*entity.WordItem, error) {
 	if word == "" {
 		return nil, errors.New("empty word to search")
 	}
 
-	result, err := d.client.TranslateAndExplain(word)
+	result, err := d.client.TranslateAndExplainContext(util.WithEndpoint(ctx, "dict.llm"), word)
 	if err != nil {
 		return nil, errors.Wrap(err, fmt.Sprintf("failed to translate and explain word: %s", word))
 	}
@@ -0,0 +1,37 @@
+package dict_mdict
+
+import (
+	"errors"
+	"path/filepath"
+
+	"github.com/gogodjzhu/word-flow/internal/config"
+)
+
+// Config is the dict.mdict section.
+type Config struct {
+	// Paths lists .mdx files or directories that are scanned for them. Resource
+	// files (<name>.mdd, <name>.1.mdd, ...) next to an .mdx file are loaded too.
+	Paths []string `yaml:"paths,omitempty"`
+}
+
+// ConfigSection registers Config with the dictionary.
+var ConfigSection = config.EndpointSpec{
+	New: func() config.EndpointConfig { return &Config{} },
+	Defaults: func(c config.EndpointConfig, dir string) {
+		if mdict := c.(*Config); len(mdict.Paths) == 0 {
+			mdict.Paths = []string{filepath.Join(dir, "mdict")}
+		}
+	},
+	Template: `mdict:
+  # MDict dictionaries (.mdx, with optional .mdd resources). Each path is an .mdx file or a directory scanned recursively.
+  # Defaults to <WORDFLOW_HOME>/mdict if empty
+  # paths: []
+`,
+}
+
+func (c *Config) Validate() error {
+	if len(c.Paths) == 0 {
+		return errors.New("mdict.paths is required when mdict is the default dictionary. Set it via: wordflow config set dict.mdict.paths <dir1>,<dir2>")
+	}
+	return nil
+}
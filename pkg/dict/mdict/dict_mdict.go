@@ -0,0 +1,321 @@
+package dict_mdict
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/gogodjzhu/word-flow/internal/buzz_error"
+	"github.com/gogodjzhu/word-flow/internal/util"
+	"github.com/gogodjzhu/word-flow/pkg/dict/entity"
+	"github.com/pkg/errors"
+)
+
+const (
+	linkPrefix   = "@@@LINK="
+	maxLinkDepth = 5
+	soundScheme  = "sound://"
+)
+
+var (
+	posPattern      = regexp.MustCompile(`^((?:n|v|vt|vi|adj|adv|prep|conj|pron|int|interj|abbr|art|num|aux|pl)\.)\s*(.*)$`)
+	phoneticPattern = regexp.MustCompile(`^[/\[](.+)[/\]]$`)
+)
+
+const (
+	headwordSelector = "h1, h2, h3, .hw, .headword"
+	phoneticSelector = ".phon, .phonetic, .pron, .ipa, [class*=phon]"
+	exampleSelector  = ".example, .examp, .exa, .eg, .x, [class*=example]"
+)
+
+// Dictionary is an .mdx file together with the .mdd resource files next to it.
+type Dictionary struct {
+	Title     string
+	mdx       *File
+	resources []*File
+}
+
+type DictMdict struct {
+	dictionaries []*Dictionary
+}
+
+func NewDictMdict(config *Config) (*DictMdict, error) {
+	if config == nil {
+		return nil, errors.New("mdict config is required")
+	}
+	var mdxFiles []string
+	for _, path := range config.Paths {
+		files, err := findMdxFiles(path)
+		if err != nil {
+			return nil, err
+		}
+		mdxFiles = append(mdxFiles, files...)
+	}
+	if len(mdxFiles) == 0 {
+		return nil, errors.Errorf("no mdx file found in %s", strings.Join(config.Paths, ", "))
+	}
+	d := &DictMdict{}
+	for _, filename := range mdxFiles {
+		dictionary, err := OpenDictionary(filename)
+		if err != nil {
+			_ = d.Close()
+			return nil, errors.Wrapf(err, "failed to open mdict file %s", filename)
+		}
+		d.dictionaries = append(d.dictionaries, dictionary)
+	}
+	return d, nil
+}
+
+func findMdxFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to stat mdict path %s", path)
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+	var files []string
+	err = filepath.WalkDir(path, func(p string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.IsDir() && strings.EqualFold(filepath.Ext(p), ".mdx") {
+			files = append(files, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to scan mdict path %s", path)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// OpenDictionary opens an .mdx file and any <name>.mdd, <name>.1.mdd, ... files.
+func OpenDictionary(mdxFilename string) (*Dictionary, error) {
+	mdx, err := OpenFile(mdxFilename, false)
+	if err != nil {
+		return nil, err
+	}
+	d := &Dictionary{mdx: mdx, Title: mdx.Header["Title"]}
+	base := strings.TrimSuffix(mdxFilename, filepath.Ext(mdxFilename))
+	if d.Title == "" || strings.Contains(d.Title, "Title (No HTML code allowed)") {
+		d.Title = filepath.Base(base)
+	}
+	candidates, _ := filepath.Glob(base + ".*.mdd")
+	sort.Strings(candidates)
+	candidates = append([]string{base + ".mdd"}, candidates...)
+	for _, candidate := range candidates {
+		if _, err := os.Stat(candidate); err != nil {
+			continue
+		}
+		mdd, err := OpenFile(candidate, true)
+		if err != nil {
+			_ = d.Close()
+			return nil, errors.Wrapf(err, "failed to open mdd file %s", candidate)
+		}
+		d.resources = append(d.resources, mdd)
+	}
+	return d, nil
+}
+
+// Definitions returns the HTML articles of word, following @@@LINK= redirects.
+func (d *Dictionary) Definitions(word string) ([]string, error) {
+	return d.definitions(word, 0)
+}
+
+func (d *Dictionary) definitions(word string, depth int) ([]string, error) {
+	records, err := d.mdx.Lookup(word)
+	if err != nil {
+		return nil, err
+	}
+	articles := make([]string, 0, len(records))
+	for _, record := range records {
+		text, err := d.mdx.DecodeText(record)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to decode record")
+		}
+		if strings.HasPrefix(text, linkPrefix) {
+			if depth >= maxLinkDepth {
+				continue
+			}
+			target := strings.TrimSpace(strings.TrimPrefix(text, linkPrefix))
+			linked, err := d.definitions(target, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			articles = append(articles, linked...)
+			continue
+		}
+		articles = append(articles, text)
+	}
+	return articles, nil
+}
+
+// Resource returns the content of a file stored in the .mdd resources, e.g.
+// "hello.mp3" or "/img/logo.png".
+func (d *Dictionary) Resource(name string) ([]byte, error) {
+	key := strings.ReplaceAll(strings.TrimPrefix(name, soundScheme), "/", `\`)
+	if !strings.HasPrefix(key, `\`) {
+		key = `\` + key
+	}
+	for _, mdd := range d.resources {
+		records, err := mdd.Lookup(key)
+		if err != nil {
+			return nil, err
+		}
+		if len(records) > 0 {
+			return records[0], nil
+		}
+	}
+	return nil, os.ErrNotExist
+}
+
+func (d *Dictionary) Close() error {
+	if d.mdx != nil {
+		_ = d.mdx.Close()
+	}
+	for _, mdd := range d.resources {
+		_ = mdd.Close()
+	}
+	return nil
+}
+
+func (d *DictMdict) Search(word string) (*entity.WordItem, error) {
+	word = strings.TrimSpace(word)
+	if word == "" {
+		return nil, buzz_error.InvalidInput("empty word to search")
+	}
+	result := &entity.WordItem{
+		ID:            entity.WordId(word),
+		Word:          word,
+		WordPhonetics: make([]*entity.WordPhonetic, 0),
+		WordMeanings:  make([]*entity.WordMeaning, 0),
+	}
+	var titles []string
+	for _, dictionary := range d.dictionaries {
+		articles, err := dictionary.Definitions(word)
+		if err != nil {
+			return nil, err
+		}
+		if len(articles) == 0 {
+			continue
+		}
+		titles = append(titles, dictionary.Title)
+		for _, article := range articles {
+			if err := appendArticle(result, article); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if len(titles) == 0 {
+		return nil, buzz_error.InvalidInput("Invalid word: " + word)
+	}
+	result.Source = "mdict: " + strings.Join(titles, ", ")
+	return result, nil
+}
+
+// Resource looks a file up in the .mdd resources of every dictionary.
+func (d *DictMdict) Resource(name string) ([]byte, error) {
+	for _, dictionary := range d.dictionaries {
+		data, err := dictionary.Resource(name)
+		if err == nil {
+			return data, nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+	}
+	return nil, os.ErrNotExist
+}
+
+// MatchPrefix returns the keys of all dictionaries that start with prefix.
+func (d *DictMdict) MatchPrefix(prefix string) ([]string, error) {
+	words := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, dictionary := range d.dictionaries {
+		keys, err := dictionary.mdx.KeysWithPrefix(prefix)
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range keys {
+			if !seen[key] {
+				seen[key] = true
+				words = append(words, key)
+			}
+		}
+	}
+	return words, nil
+}
+
+func (d *DictMdict) Close() error {
+	for _, dictionary := range d.dictionaries {
+		_ = dictionary.Close()
+	}
+	return nil
+}
+
+// appendArticle maps an HTML article into item: sound:// links and phonetic
+// elements become phonetics, example elements become examples and the
+// remaining text is split into one meaning per line.
+func appendArticle(item *entity.WordItem, article string) error {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(article))
+	if err != nil {
+		return errors.Wrap(err, "failed to parse article")
+	}
+	doc.Find("script, style, link").Remove()
+	doc.Find(headwordSelector).Remove()
+
+	var audios []string
+	doc.Find(`a[href^="` + soundScheme + `"]`).Each(func(i int, s *goquery.Selection) {
+		href, _ := s.Attr("href")
+		audios = append(audios, href)
+	}).Remove()
+	var phonetics []*entity.WordPhonetic
+	doc.Find(phoneticSelector).Each(func(i int, s *goquery.Selection) {
+		text := strings.Trim(strings.TrimSpace(s.Text()), "/[]")
+		if text != "" {
+			phonetics = append(phonetics, &entity.WordPhonetic{HeadWord: item.Word, Text: text})
+		}
+	}).Remove()
+	for i, audio := range audios {
+		if i < len(phonetics) {
+			phonetics[i].Audio = audio
+		} else {
+			phonetics = append(phonetics, &entity.WordPhonetic{HeadWord: item.Word, Audio: audio})
+		}
+	}
+	item.WordPhonetics = append(item.WordPhonetics, phonetics...)
+
+	doc.Find(exampleSelector).Each(func(i int, s *goquery.Selection) {
+		if text := strings.Join(strings.Fields(s.Text()), " "); text != "" {
+			item.Examples = append(item.Examples, text)
+		}
+	}).Remove()
+
+	body, err := doc.Find("body").Html()
+	if err != nil {
+		return errors.Wrap(err, "failed to render article")
+	}
+	for _, line := range strings.Split(util.HTMLToText(body), "\n") {
+		line = strings.Join(strings.Fields(line), " ")
+		if line == "" || line == item.Word {
+			continue
+		}
+		if m := phoneticPattern.FindStringSubmatch(line); m != nil && len(item.WordMeanings) == 0 {
+			item.WordPhonetics = append(item.WordPhonetics, &entity.WordPhonetic{HeadWord: item.Word, Text: m[1]})
+			continue
+		}
+		meaning := &entity.WordMeaning{Definitions: line}
+		if m := posPattern.FindStringSubmatch(line); m != nil && m[2] != "" {
+			meaning.PartOfSpeech = m[1]
+			meaning.Definitions = m[2]
+		}
+		item.WordMeanings = append(item.WordMeanings, meaning)
+	}
+	return nil
+}
@@ -0,0 +1,330 @@
+package dict_mdict
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"encoding/hex"
+	"hash/adler32"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"unicode/utf16"
+)
+
+func TestRipemd128(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"", "cdf26213a150dc3ecb610f18f6b38b46"},
+		{"a", "86be7afa339d0fc7cfc785e72f578d33"},
+		{"abc", "c14a12199c66e4ba84636b0f69144c77"},
+		{"message digest", "9e327b3d6e523062afc1132d7df9d1b8"},
+		{"12345678901234567890123456789012345678901234567890123456789012345678901234567890", "3f45ef194732c2dbb2c4a2c769795fa3"},
+	}
+	for _, tt := range tests {
+		if got := hex.EncodeToString(ripemd128([]byte(tt.input))); got != tt.want {
+			t.Errorf("ripemd128(%q) = %s, want %s", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestLzo1xDecompress(t *testing.T) {
+	// 4 literals, an 8 byte match at distance 4, then the end of stream marker.
+	stream := []byte{17 + 4, 'a', 'b', 'c', 'd', 32 | 6, 3 << 2, 0, 0x11, 0, 0}
+	got, err := lzo1xDecompress(stream, 12)
+	if err != nil {
+		t.Fatalf("lzo1xDecompress() error = %v", err)
+	}
+	if string(got) != "abcdabcdabcd" {
+		t.Errorf("lzo1xDecompress() = %q", got)
+	}
+
+	if _, err := lzo1xDecompress([]byte{17 + 4, 'a'}, 4); err == nil {
+		t.Error("expected error for truncated stream")
+	}
+}
+
+type mdictEntry struct {
+	key  string
+	data []byte
+}
+
+type mdictWriter struct {
+	utf16     bool
+	encrypted bool
+}
+
+func (w mdictWriter) encodeKey(key string) []byte {
+	if !w.utf16 {
+		return []byte(key)
+	}
+	var buf bytes.Buffer
+	for _, u := range utf16.Encode([]rune(key)) {
+		_ = binary.Write(&buf, binary.LittleEndian, u)
+	}
+	return buf.Bytes()
+}
+
+func (w mdictWriter) terminator() []byte {
+	if w.utf16 {
+		return []byte{0, 0}
+	}
+	return []byte{0}
+}
+
+func zlibBlock(data []byte) []byte {
+	var compressed bytes.Buffer
+	zw := zlib.NewWriter(&compressed)
+	_, _ = zw.Write(data)
+	_ = zw.Close()
+	block := make([]byte, 8)
+	binary.LittleEndian.PutUint32(block, compressionZlib)
+	binary.BigEndian.PutUint32(block[4:], adler32.Checksum(data))
+	return append(block, compressed.Bytes()...)
+}
+
+// lzoLiteralBlock encodes data as a single LZO1X literal run.
+func lzoLiteralBlock(data []byte) []byte {
+	block := make([]byte, 8)
+	binary.LittleEndian.PutUint32(block, compressionLZO)
+	binary.BigEndian.PutUint32(block[4:], adler32.Checksum(data))
+	block = append(block, byte(17+len(data)))
+	block = append(block, data...)
+	return append(block, 0x11, 0, 0)
+}
+
+func encryptBlock(block []byte) []byte {
+	key := ripemd128(append(append([]byte{}, block[4:8]...), 0x95, 0x36, 0x00, 0x00))
+	out := append([]byte{}, block...)
+	previous := byte(0x36)
+	for i := 8; i < len(out); i++ {
+		v := block[i] ^ previous ^ byte(i-8) ^ key[(i-8)%len(key)]
+		out[i] = v>>4 | v<<4
+		previous = out[i]
+	}
+	return out
+}
+
+func (w mdictWriter) write(t *testing.T, filename string, header string, entries []mdictEntry) {
+	t.Helper()
+	sort.Slice(entries, func(i, j int) bool {
+		return strings.ToLower(entries[i].key) < strings.ToLower(entries[j].key)
+	})
+
+	var out bytes.Buffer
+	headerBytes := mdictWriter{utf16: true}.encodeKey(header + "\r\n\x00")
+	_ = binary.Write(&out, binary.BigEndian, uint32(len(headerBytes)))
+	out.Write(headerBytes)
+	_ = binary.Write(&out, binary.LittleEndian, adler32.Checksum(headerBytes))
+
+	var records bytes.Buffer
+	offsets := make([]uint64, len(entries))
+	for i, e := range entries {
+		offsets[i] = uint64(records.Len())
+		records.Write(e.data)
+	}
+
+	var info, keyBlocks bytes.Buffer
+	const perBlock = 2
+	numBlocks := 0
+	for start := 0; start < len(entries); start += perBlock {
+		end := start + perBlock
+		if end > len(entries) {
+			end = len(entries)
+		}
+		var block bytes.Buffer
+		for i := start; i < end; i++ {
+			_ = binary.Write(&block, binary.BigEndian, offsets[i])
+			block.Write(w.encodeKey(entries[i].key))
+			block.Write(w.terminator())
+		}
+		compressed := zlibBlock(block.Bytes())
+		keyBlocks.Write(compressed)
+		numBlocks++
+
+		_ = binary.Write(&info, binary.BigEndian, uint64(end-start))
+		for _, key := range []string{entries[start].key, entries[end-1].key} {
+			_ = binary.Write(&info, binary.BigEndian, uint16(len([]rune(key))))
+			info.Write(w.encodeKey(key))
+			info.Write(w.terminator())
+		}
+		_ = binary.Write(&info, binary.BigEndian, uint64(len(compressed)))
+		_ = binary.Write(&info, binary.BigEndian, uint64(block.Len()))
+	}
+	infoBlock := zlibBlock(info.Bytes())
+	if w.encrypted {
+		infoBlock = encryptBlock(infoBlock)
+	}
+
+	var keyHeader bytes.Buffer
+	for _, n := range []int{numBlocks, len(entries), info.Len(), len(infoBlock), keyBlocks.Len()} {
+		_ = binary.Write(&keyHeader, binary.BigEndian, uint64(n))
+	}
+	out.Write(keyHeader.Bytes())
+	_ = binary.Write(&out, binary.BigEndian, adler32.Checksum(keyHeader.Bytes()))
+	out.Write(infoBlock)
+	out.Write(keyBlocks.Bytes())
+
+	// Small record blocks so records span blocks, alternating zlib and LZO.
+	const recordBlockSize = 40
+	var recordInfo, recordBlocks bytes.Buffer
+	data := records.Bytes()
+	numRecordBlocks := 0
+	for start := 0; start < len(data); start += recordBlockSize {
+		end := start + recordBlockSize
+		if end > len(data) {
+			end = len(data)
+		}
+		block := zlibBlock(data[start:end])
+		if numRecordBlocks%2 == 1 {
+			block = lzoLiteralBlock(data[start:end])
+		}
+		recordBlocks.Write(block)
+		_ = binary.Write(&recordInfo, binary.BigEndian, uint64(len(block)))
+		_ = binary.Write(&recordInfo, binary.BigEndian, uint64(end-start))
+		numRecordBlocks++
+	}
+	for _, n := range []int{numRecordBlocks, len(entries), recordInfo.Len(), recordBlocks.Len()} {
+		_ = binary.Write(&out, binary.BigEndian, uint64(n))
+	}
+	out.Write(recordInfo.Bytes())
+	out.Write(recordBlocks.Bytes())
+
+	if err := os.WriteFile(filename, out.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+const testMdxHeader = `<Dictionary GeneratedByEngineVersion="2.0" RequiredEngineVersion="2.0" Encrypted="2" Encoding="UTF-8" Format="Html" KeyCaseSensitive="No" StripKey="Yes" Title="Test &amp; Learn"/>`
+
+func writeTestDictionary(t *testing.T, dir string) {
+	t.Helper()
+	mdictWriter{encrypted: true}.write(t, filepath.Join(dir, "learner.mdx"), testMdxHeader, []mdictEntry{
+		{key: "apple", data: []byte(`<div class="entry"><h2>apple</h2><span class="phon">/ˈæp.əl/</span><a href="sound://apple.mp3">play</a>` +
+			`<div class="def">n. a round fruit with red or green skin</div><div class="example">An apple a day keeps the doctor away.</div>` +
+			`<div class="def">adj. of the colour of apples</div></div>` + "\r\n\x00")},
+		{key: "Apples", data: []byte("@@@LINK=apple\r\n\x00")},
+		{key: "banana", data: []byte("<p>n. a long curved fruit</p>\x00")},
+		{key: "cherry", data: []byte("<p>n. a small round fruit</p>\x00")},
+		{key: "date", data: []byte("<p>n. the fruit of a palm</p>\x00")},
+	})
+	mdictWriter{utf16: true}.write(t, filepath.Join(dir, "learner.mdd"),
+		`<Library_Data GeneratedByEngineVersion="2.0" RequiredEngineVersion="2.0" Encrypted="0" Format="" KeyCaseSensitive="No" StripKey="Yes"/>`,
+		[]mdictEntry{
+			{key: `\apple.mp3`, data: []byte("ID3-apple-audio")},
+			{key: `\img\logo.png`, data: []byte("PNG")},
+		})
+}
+
+func TestDictMdict_Search(t *testing.T) {
+	dir := t.TempDir()
+	writeTestDictionary(t, dir)
+
+	d, err := NewDictMdict(&Config{Paths: []string{dir}})
+	if err != nil {
+		t.Fatalf("NewDictMdict() error = %v", err)
+	}
+	defer d.Close()
+
+	got, err := d.Search("apple")
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if got.Source != "mdict: Test & Learn" {
+		t.Errorf("Source = %q", got.Source)
+	}
+	if len(got.WordPhonetics) != 1 || got.WordPhonetics[0].Text != "ˈæp.əl" || got.WordPhonetics[0].Audio != "sound://apple.mp3" {
+		t.Errorf("WordPhonetics = %+v", got.WordPhonetics)
+	}
+	if len(got.Examples) != 1 || got.Examples[0] != "An apple a day keeps the doctor away." {
+		t.Errorf("Examples = %v", got.Examples)
+	}
+	var meanings []string
+	for _, m := range got.WordMeanings {
+		meanings = append(meanings, m.PartOfSpeech+" "+m.Definitions)
+	}
+	want := []string{"n. a round fruit with red or green skin", "adj. of the colour of apples"}
+	if strings.Join(meanings, "|") != strings.Join(want, "|") {
+		t.Errorf("meanings = %q, want %q", meanings, want)
+	}
+
+	linked, err := d.Search("apples")
+	if err != nil {
+		t.Fatalf("Search(apples) error = %v", err)
+	}
+	if len(linked.WordMeanings) == 0 || linked.WordMeanings[0].Definitions != "a round fruit with red or green skin" {
+		t.Errorf("linked meanings = %+v", linked.WordMeanings)
+	}
+
+	for word, def := range map[string]string{"banana": "a long curved fruit", "date": "the fruit of a palm"} {
+		item, err := d.Search(word)
+		if err != nil {
+			t.Fatalf("Search(%s) error = %v", word, err)
+		}
+		if len(item.WordMeanings) != 1 || item.WordMeanings[0].Definitions != def {
+			t.Errorf("Search(%s) meanings = %+v", word, item.WordMeanings)
+		}
+	}
+
+	if _, err := d.Search("grape"); err == nil {
+		t.Error("expected error for missing word")
+	}
+}
+
+func TestDictMdict_MatchPrefix(t *testing.T) {
+	dir := t.TempDir()
+	writeTestDictionary(t, dir)
+
+	d, err := NewDictMdict(&Config{Paths: []string{dir}})
+	if err != nil {
+		t.Fatalf("NewDictMdict() error = %v", err)
+	}
+	defer d.Close()
+
+	words, err := d.MatchPrefix("APP")
+	if err != nil || strings.Join(words, ",") != "apple,Apples" {
+		t.Errorf("MatchPrefix(APP) = %v, %v", words, err)
+	}
+	if words, _ := d.MatchPrefix("grape"); len(words) != 0 {
+		t.Errorf("MatchPrefix(grape) = %v", words)
+	}
+}
+
+func TestDictMdict_Resource(t *testing.T) {
+	dir := t.TempDir()
+	writeTestDictionary(t, dir)
+
+	d, err := NewDictMdict(&Config{Paths: []string{filepath.Join(dir, "learner.mdx")}})
+	if err != nil {
+		t.Fatalf("NewDictMdict() error = %v", err)
+	}
+	defer d.Close()
+
+	for name, want := range map[string]string{"sound://apple.mp3": "ID3-apple-audio", "img/logo.png": "PNG"} {
+		data, err := d.Resource(name)
+		if err != nil {
+			t.Fatalf("Resource(%s) error = %v", name, err)
+		}
+		if string(data) != want {
+			t.Errorf("Resource(%s) = %q, want %q", name, data, want)
+		}
+	}
+	if _, err := d.Resource("missing.mp3"); !os.IsNotExist(err) {
+		t.Errorf("expected not exist error, got %v", err)
+	}
+}
+
+func TestOpenFile_EncryptedHeaderUnsupported(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "locked.mdx")
+	mdictWriter{}.write(t, filename, strings.Replace(testMdxHeader, `Encrypted="2"`, `Encrypted="1"`, 1), []mdictEntry{
+		{key: "apple", data: []byte("<p>apple</p>\x00")},
+	})
+	if _, err := OpenFile(filename, false); err == nil {
+		t.Error("expected error for registration code encrypted file")
+	}
+}
@@ -0,0 +1,114 @@
+package dict_mdict
+
+import (
+	"github.com/pkg/errors"
+)
+
+var errLZOCorrupt = errors.New("corrupt lzo data")
+
+// lzo1xDecompress inflates a raw LZO1X stream, as used by MDict blocks with
+// compression type 1. size is the expected decompressed length.
+func lzo1xDecompress(in []byte, size int) (out []byte, err error) {
+	defer func() {
+		// Index errors on malformed input surface as a corrupt stream.
+		if r := recover(); r != nil {
+			out, err = nil, errLZOCorrupt
+		}
+	}()
+
+	out = make([]byte, 0, size)
+	ip := 0
+	state := 0
+
+	copyLiterals := func(n int) {
+		out = append(out, in[ip:ip+n]...)
+		ip += n
+	}
+	copyMatch := func(dist, n int) {
+		pos := len(out) - dist
+		if pos < 0 {
+			panic(errLZOCorrupt)
+		}
+		for i := 0; i < n; i++ {
+			out = append(out, out[pos+i])
+		}
+	}
+	extendLength := func(t, base int) int {
+		for in[ip] == 0 {
+			t += 255
+			ip++
+		}
+		t += base + int(in[ip])
+		ip++
+		return t
+	}
+
+	if in[ip] > 17 {
+		t := int(in[ip]) - 17
+		ip++
+		copyLiterals(t)
+		if t < 4 {
+			state = t
+		} else {
+			state = 4
+		}
+	}
+
+	for {
+		t := int(in[ip])
+		ip++
+		var dist, length, next int
+		switch {
+		case t < 16:
+			if state == 0 {
+				if t == 0 {
+					t = extendLength(t, 15)
+				}
+				copyLiterals(t + 3)
+				state = 4
+				continue
+			}
+			next = t & 3
+			if state != 4 {
+				dist = 1 + (t >> 2) + int(in[ip])<<2
+				length = 2
+			} else {
+				dist = 1 + 0x800 + (t >> 2) + int(in[ip])<<2
+				length = 3
+			}
+			ip++
+		case t >= 64:
+			next = t & 3
+			dist = 1 + ((t >> 2) & 7) + int(in[ip])<<3
+			ip++
+			length = (t >> 5) + 1
+		case t >= 32:
+			length = t & 31
+			if length == 0 {
+				length = extendLength(length, 31)
+			}
+			length += 2
+			v := int(in[ip]) | int(in[ip+1])<<8
+			ip += 2
+			dist = 1 + v>>2
+			next = v & 3
+		default:
+			length = t & 7
+			if length == 0 {
+				length = extendLength(length, 7)
+			}
+			length += 2
+			v := int(in[ip]) | int(in[ip+1])<<8
+			ip += 2
+			dist = (t&8)<<11 + v>>2
+			if dist == 0 {
+				return out, nil
+			}
+			dist += 0x4000
+			next = v & 3
+		}
+		copyMatch(dist, length)
+		copyLiterals(next)
+		state = next
+	}
+}
@@ -0,0 +1,598 @@
+package dict_mdict
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"html"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf16"
+
+	"github.com/pkg/errors"
+	"golang.org/x/text/encoding/htmlindex"
+)
+
+const (
+	compressionNone = 0
+	compressionLZO  = 1
+	compressionZlib = 2
+
+	encryptedHeader    = 1 // keyword header is salsa20 encrypted with a user registration code
+	encryptedBlockInfo = 2 // keyword block info is scrambled with a key derived from the block itself
+)
+
+var headerAttrPattern = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+// keyBlockInfo describes one keyword block of an MDict file.
+type keyBlockInfo struct {
+	firstKey         string
+	lastKey          string
+	compressedSize   int64
+	decompressedSize int64
+	offset           int64 // absolute file offset of the compressed block
+}
+
+type recordBlockInfo struct {
+	compressedSize   int64
+	decompressedSize int64
+	offset           int64 // absolute file offset of the compressed block
+	start            int64 // offset of the block in the decompressed record stream
+}
+
+type keyEntry struct {
+	key    string
+	offset int64
+}
+
+// File is an opened .mdx or .mdd file. Keyword and record blocks are read and
+// decompressed on demand, so opening a large dictionary is cheap.
+type File struct {
+	Header map[string]string
+
+	file           *os.File
+	version        float64
+	numberWidth    int
+	utf16          bool
+	decoder        func([]byte) (string, error)
+	caseSensitive  bool
+	stripKey       bool
+	keyBlocks      []keyBlockInfo
+	recordBlocks   []recordBlockInfo
+	recordSize     int64
+	mu             sync.Mutex
+	keyBlockCache  map[int][]keyEntry
+	lastRecordIdx  int
+	lastRecordData []byte
+}
+
+// OpenFile parses the header, keyword index and record index of an MDict
+// file. Resource files (.mdd) always store keys as UTF-16.
+func OpenFile(filename string, resource bool) (*File, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open mdict file")
+	}
+	m := &File{file: file, keyBlockCache: make(map[int][]keyEntry), lastRecordIdx: -1}
+	if err := m.readHeader(resource); err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	if err := m.readKeySection(); err != nil {
+		_ = file.Close()
+		return nil, errors.Wrap(err, "failed to read keyword section")
+	}
+	if err := m.readRecordSection(); err != nil {
+		_ = file.Close()
+		return nil, errors.Wrap(err, "failed to read record section")
+	}
+	return m, nil
+}
+
+func (m *File) Close() error {
+	return m.file.Close()
+}
+
+func (m *File) readHeader(resource bool) error {
+	var size uint32
+	if err := binary.Read(m.file, binary.BigEndian, &size); err != nil {
+		return errors.Wrap(err, "failed to read header size")
+	}
+	raw := make([]byte, size)
+	if _, err := io.ReadFull(m.file, raw); err != nil {
+		return errors.Wrap(err, "failed to read header")
+	}
+	// adler32 checksum of the header
+	if _, err := m.file.Seek(4, io.SeekCurrent); err != nil {
+		return errors.Wrap(err, "failed to skip header checksum")
+	}
+
+	text := decodeUTF16(raw)
+	m.Header = make(map[string]string)
+	for _, match := range headerAttrPattern.FindAllStringSubmatch(text, -1) {
+		m.Header[match[1]] = html.UnescapeString(match[2])
+	}
+
+	m.version, _ = strconv.ParseFloat(m.Header["GeneratedByEngineVersion"], 64)
+	if m.version >= 3 {
+		return errors.Errorf("mdict engine version %s is not supported", m.Header["GeneratedByEngineVersion"])
+	}
+	m.numberWidth = 4
+	if m.version >= 2 {
+		m.numberWidth = 8
+	}
+
+	encoding := strings.ToUpper(m.Header["Encoding"])
+	switch {
+	case resource, strings.HasPrefix(encoding, "UTF-16"):
+		m.utf16 = true
+		m.decoder = func(b []byte) (string, error) { return decodeUTF16(b), nil }
+	case encoding == "" || encoding == "UTF-8":
+		m.decoder = func(b []byte) (string, error) { return string(b), nil }
+	default:
+		if encoding == "GBK" || encoding == "GB2312" {
+			encoding = "GB18030"
+		}
+		enc, err := htmlindex.Get(encoding)
+		if err != nil {
+			return errors.Errorf("unsupported mdict encoding %q", m.Header["Encoding"])
+		}
+		m.decoder = func(b []byte) (string, error) { return enc.NewDecoder().String(string(b)) }
+	}
+	m.caseSensitive = strings.EqualFold(m.Header["KeyCaseSensitive"], "Yes")
+	m.stripKey = m.Header["StripKey"] == "" || strings.EqualFold(m.Header["StripKey"], "Yes")
+	return nil
+}
+
+func (m *File) encrypted() int {
+	switch value := m.Header["Encrypted"]; value {
+	case "", "No":
+		return 0
+	case "Yes":
+		return encryptedHeader
+	default:
+		n, _ := strconv.Atoi(value)
+		return n
+	}
+}
+
+func (m *File) readNumber(r io.Reader) (int64, error) {
+	buf := make([]byte, m.numberWidth)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return 0, err
+	}
+	if m.numberWidth == 8 {
+		return int64(binary.BigEndian.Uint64(buf)), nil
+	}
+	return int64(binary.BigEndian.Uint32(buf)), nil
+}
+
+func (m *File) readKeySection() error {
+	if m.encrypted()&encryptedHeader != 0 {
+		return errors.New("keyword header is encrypted with a registration code, which is not supported")
+	}
+	count := 4
+	if m.version >= 2 {
+		count = 5
+	}
+	headerBytes := make([]byte, count*m.numberWidth)
+	if _, err := io.ReadFull(m.file, headerBytes); err != nil {
+		return err
+	}
+	if m.version >= 2 {
+		// adler32 checksum of the keyword header
+		if _, err := m.file.Seek(4, io.SeekCurrent); err != nil {
+			return err
+		}
+	}
+	r := bytes.NewReader(headerBytes)
+	numbers := make([]int64, count)
+	for i := range numbers {
+		numbers[i], _ = m.readNumber(r)
+	}
+	numBlocks := numbers[0]
+	infoSize, keyBlocksSize := numbers[count-2], numbers[count-1]
+
+	info := make([]byte, infoSize)
+	if _, err := io.ReadFull(m.file, info); err != nil {
+		return err
+	}
+	if m.version >= 2 {
+		if m.encrypted()&encryptedBlockInfo != 0 {
+			info = decryptBlock(info)
+		}
+		var err error
+		if info, err = decompressBlock(info, numbers[2]); err != nil {
+			return errors.Wrap(err, "failed to decompress keyword block info")
+		}
+	}
+
+	pos, err := m.file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	m.keyBlocks, err = m.parseKeyBlockInfo(info, pos)
+	if err != nil {
+		return err
+	}
+	if int64(len(m.keyBlocks)) != numBlocks {
+		return errors.Errorf("expected %d keyword blocks, found %d", numBlocks, len(m.keyBlocks))
+	}
+	_, err = m.file.Seek(pos+keyBlocksSize, io.SeekStart)
+	return err
+}
+
+func (m *File) parseKeyBlockInfo(info []byte, offset int64) ([]keyBlockInfo, error) {
+	sizeWidth, terminator := 1, 0
+	if m.version >= 2 {
+		sizeWidth, terminator = 2, 1
+	}
+	charWidth := 1
+	if m.utf16 {
+		charWidth = 2
+	}
+	r := bytes.NewReader(info)
+	readKey := func() (string, error) {
+		var n int
+		if sizeWidth == 2 {
+			var v uint16
+			if err := binary.Read(r, binary.BigEndian, &v); err != nil {
+				return "", err
+			}
+			n = int(v)
+		} else {
+			v, err := r.ReadByte()
+			if err != nil {
+				return "", err
+			}
+			n = int(v)
+		}
+		buf := make([]byte, (n+terminator)*charWidth)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return "", err
+		}
+		return m.decoder(buf[:n*charWidth])
+	}
+
+	blocks := make([]keyBlockInfo, 0)
+	for r.Len() > 0 {
+		var block keyBlockInfo
+		if _, err := m.readNumber(r); err != nil { // number of entries
+			return nil, errors.Wrap(err, "truncated keyword block info")
+		}
+		var err error
+		if block.firstKey, err = readKey(); err != nil {
+			return nil, errors.Wrap(err, "truncated keyword block info")
+		}
+		if block.lastKey, err = readKey(); err != nil {
+			return nil, errors.Wrap(err, "truncated keyword block info")
+		}
+		if block.compressedSize, err = m.readNumber(r); err != nil {
+			return nil, errors.Wrap(err, "truncated keyword block info")
+		}
+		if block.decompressedSize, err = m.readNumber(r); err != nil {
+			return nil, errors.Wrap(err, "truncated keyword block info")
+		}
+		block.offset = offset
+		offset += block.compressedSize
+		blocks = append(blocks, block)
+	}
+	return blocks, nil
+}
+
+func (m *File) readRecordSection() error {
+	numbers := make([]int64, 4)
+	for i := range numbers {
+		n, err := m.readNumber(m.file)
+		if err != nil {
+			return err
+		}
+		numbers[i] = n
+	}
+	numBlocks := numbers[0]
+	m.recordBlocks = make([]recordBlockInfo, 0, numBlocks)
+	for i := int64(0); i < numBlocks; i++ {
+		compressed, err := m.readNumber(m.file)
+		if err != nil {
+			return err
+		}
+		decompressed, err := m.readNumber(m.file)
+		if err != nil {
+			return err
+		}
+		m.recordBlocks = append(m.recordBlocks, recordBlockInfo{compressedSize: compressed, decompressedSize: decompressed})
+	}
+	offset, err := m.file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	var start int64
+	for i := range m.recordBlocks {
+		m.recordBlocks[i].offset = offset
+		m.recordBlocks[i].start = start
+		offset += m.recordBlocks[i].compressedSize
+		start += m.recordBlocks[i].decompressedSize
+	}
+	m.recordSize = start
+	return nil
+}
+
+// NormalizeKey folds a key the way MDict compares keys: case-insensitively
+// unless KeyCaseSensitive is set, ignoring punctuation and spaces when StripKey
+// is set.
+func (m *File) NormalizeKey(key string) string {
+	if !m.caseSensitive {
+		key = strings.ToLower(key)
+	}
+	if m.stripKey {
+		key = strings.Map(func(r rune) rune {
+			if unicode.IsPunct(r) || unicode.IsSpace(r) {
+				return -1
+			}
+			return r
+		}, key)
+	}
+	return key
+}
+
+// Lookup returns the records of every key that matches key after
+// normalisation. Exact matches are returned before folded ones.
+func (m *File) Lookup(key string) ([][]byte, error) {
+	target := m.NormalizeKey(key)
+	var exact, folded [][]byte
+	for i, block := range m.keyBlocks {
+		first, last := m.NormalizeKey(block.firstKey), m.NormalizeKey(block.lastKey)
+		if target < first || target > last {
+			continue
+		}
+		entries, err := m.keyBlock(i)
+		if err != nil {
+			return nil, err
+		}
+		for j, entry := range entries {
+			if m.NormalizeKey(entry.key) != target {
+				continue
+			}
+			end, err := m.recordEnd(i, j)
+			if err != nil {
+				return nil, err
+			}
+			record, err := m.record(entry.offset, end)
+			if err != nil {
+				return nil, err
+			}
+			if entry.key == key {
+				exact = append(exact, record)
+			} else {
+				folded = append(folded, record)
+			}
+		}
+	}
+	return append(exact, folded...), nil
+}
+
+// KeysWithPrefix returns the keys that start with prefix after normalisation.
+func (m *File) KeysWithPrefix(prefix string) ([]string, error) {
+	target := m.NormalizeKey(prefix)
+	keys := make([]string, 0)
+	for i, block := range m.keyBlocks {
+		first, last := m.NormalizeKey(block.firstKey), m.NormalizeKey(block.lastKey)
+		if last < target || (first > target && !strings.HasPrefix(first, target)) {
+			continue
+		}
+		entries, err := m.keyBlock(i)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			if strings.HasPrefix(m.NormalizeKey(entry.key), target) {
+				keys = append(keys, entry.key)
+			}
+		}
+	}
+	return keys, nil
+}
+
+// Keys returns the keywords of every block, mainly useful for listing and tests.
+func (m *File) Keys() ([]string, error) {
+	keys := make([]string, 0)
+	for i := range m.keyBlocks {
+		entries, err := m.keyBlock(i)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			keys = append(keys, entry.key)
+		}
+	}
+	return keys, nil
+}
+
+func (m *File) recordEnd(block, entry int) (int64, error) {
+	entries, err := m.keyBlock(block)
+	if err != nil {
+		return 0, err
+	}
+	if entry+1 < len(entries) {
+		return entries[entry+1].offset, nil
+	}
+	if block+1 < len(m.keyBlocks) {
+		next, err := m.keyBlock(block + 1)
+		if err != nil {
+			return 0, err
+		}
+		if len(next) > 0 {
+			return next[0].offset, nil
+		}
+	}
+	return m.recordSize, nil
+}
+
+func (m *File) keyBlock(index int) ([]keyEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if entries, ok := m.keyBlockCache[index]; ok {
+		return entries, nil
+	}
+	info := m.keyBlocks[index]
+	raw := make([]byte, info.compressedSize)
+	if _, err := m.file.ReadAt(raw, info.offset); err != nil {
+		return nil, errors.Wrap(err, "failed to read keyword block")
+	}
+	data, err := decompressBlock(raw, info.decompressedSize)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decompress keyword block")
+	}
+
+	entries := make([]keyEntry, 0)
+	for pos := 0; pos < len(data); {
+		if pos+m.numberWidth > len(data) {
+			return nil, errors.New("truncated keyword block")
+		}
+		var offset int64
+		if m.numberWidth == 8 {
+			offset = int64(binary.BigEndian.Uint64(data[pos:]))
+		} else {
+			offset = int64(binary.BigEndian.Uint32(data[pos:]))
+		}
+		pos += m.numberWidth
+		end := terminatorIndex(data[pos:], m.utf16)
+		if end < 0 {
+			return nil, errors.New("unterminated key in keyword block")
+		}
+		key, err := m.decoder(data[pos : pos+end])
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to decode key")
+		}
+		entries = append(entries, keyEntry{key: key, offset: offset})
+		if m.utf16 {
+			pos += end + 2
+		} else {
+			pos += end + 1
+		}
+	}
+	m.keyBlockCache[index] = entries
+	return entries, nil
+}
+
+func (m *File) record(start, end int64) ([]byte, error) {
+	if start < 0 || end > m.recordSize || start > end {
+		return nil, errors.New("record out of range")
+	}
+	var buf bytes.Buffer
+	i := sort.Search(len(m.recordBlocks), func(i int) bool {
+		return m.recordBlocks[i].start+m.recordBlocks[i].decompressedSize > start
+	})
+	for ; i < len(m.recordBlocks) && m.recordBlocks[i].start < end; i++ {
+		data, err := m.recordBlock(i)
+		if err != nil {
+			return nil, err
+		}
+		block := m.recordBlocks[i]
+		from, to := int64(0), int64(len(data))
+		if start > block.start {
+			from = start - block.start
+		}
+		if end < block.start+int64(len(data)) {
+			to = end - block.start
+		}
+		buf.Write(data[from:to])
+	}
+	return buf.Bytes(), nil
+}
+
+func (m *File) recordBlock(index int) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if index == m.lastRecordIdx {
+		return m.lastRecordData, nil
+	}
+	info := m.recordBlocks[index]
+	raw := make([]byte, info.compressedSize)
+	if _, err := m.file.ReadAt(raw, info.offset); err != nil {
+		return nil, errors.Wrap(err, "failed to read record block")
+	}
+	data, err := decompressBlock(raw, info.decompressedSize)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decompress record block")
+	}
+	m.lastRecordIdx, m.lastRecordData = index, data
+	return data, nil
+}
+
+// DecodeText turns a record of an MDX file into text.
+func (m *File) DecodeText(record []byte) (string, error) {
+	text, err := m.decoder(record)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(text, "\x00\r\n"), nil
+}
+
+// decompressBlock inflates a block laid out as a little endian compression type,
+// a big endian adler32 checksum and the payload.
+func decompressBlock(block []byte, size int64) ([]byte, error) {
+	if len(block) < 8 {
+		return nil, errors.New("truncated block")
+	}
+	payload := block[8:]
+	switch binary.LittleEndian.Uint32(block[:4]) {
+	case compressionNone:
+		return payload, nil
+	case compressionLZO:
+		return lzo1xDecompress(payload, int(size))
+	case compressionZlib:
+		r, err := zlib.NewReader(bytes.NewReader(payload))
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		return io.ReadAll(r)
+	default:
+		return nil, errors.Errorf("unknown compression type %d", binary.LittleEndian.Uint32(block[:4]))
+	}
+}
+
+// decryptBlock undoes the keyword block info scrambling of Encrypted=2 files.
+func decryptBlock(block []byte) []byte {
+	if len(block) < 8 {
+		return block
+	}
+	seed := append(append([]byte{}, block[4:8]...), 0x95, 0x36, 0x00, 0x00)
+	key := ripemd128(seed)
+	out := append([]byte{}, block...)
+	previous := byte(0x36)
+	for i := 8; i < len(out); i++ {
+		b := block[i]
+		t := (b>>4 | b<<4) ^ previous ^ byte(i-8) ^ key[(i-8)%len(key)]
+		previous = b
+		out[i] = t
+	}
+	return out
+}
+
+func decodeUTF16(b []byte) string {
+	units := make([]uint16, 0, len(b)/2)
+	for i := 0; i+1 < len(b); i += 2 {
+		units = append(units, binary.LittleEndian.Uint16(b[i:]))
+	}
+	return strings.TrimRight(string(utf16.Decode(units)), "\x00")
+}
+
+func terminatorIndex(b []byte, wide bool) int {
+	if !wide {
+		return bytes.IndexByte(b, 0)
+	}
+	for i := 0; i+1 < len(b); i += 2 {
+		if b[i] == 0 && b[i+1] == 0 {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,65 @@
+package dict_mwebster
+
+import (
+	"errors"
+
+	"github.com/gogodjzhu/word-flow/internal/config"
+)
+
+// Config is the dict.mwebster section.
+type Config struct {
+	Key     string             `yaml:"key,omitempty"`
+	Timeout config.Duration    `yaml:"timeout,omitempty"`
+	HTTP    *config.HTTPConfig `yaml:"http,omitempty"`
+}
+
+// ConfigSection registers Config with the dictionary.
+var ConfigSection = config.EndpointSpec{
+	New: func() config.EndpointConfig { return &Config{} },
+	Defaults: func(c config.EndpointConfig, dir string) {
+		mwebster := c.(*Config)
+		config.DefaultTimeout(&mwebster.Timeout, config.OnlineTimeout)
+		config.DefaultHTTP(&mwebster.HTTP, 0)
+	},
+	Template: `mwebster:
+  # Merriam-Webster API key (required if using mwebster)
+  # key: ""
+  timeout: 10s
+`,
+}
+
+func (c *Config) Validate() error {
+	if c.Key == "" {
+		return errors.New("mwebster.key is required when mwebster is the default dictionary")
+	}
+	return nil
+}
+
+// ThesaurusConfig is the dict.mwthesaurus section.
+type ThesaurusConfig struct {
+	Key     string             `yaml:"key,omitempty"`
+	Timeout config.Duration    `yaml:"timeout,omitempty"`
+	HTTP    *config.HTTPConfig `yaml:"http,omitempty"`
+}
+
+// ThesaurusConfigSection registers ThesaurusConfig with the dictionary.
+var ThesaurusConfigSection = config.EndpointSpec{
+	New: func() config.EndpointConfig { return &ThesaurusConfig{} },
+	Defaults: func(c config.EndpointConfig, dir string) {
+		mwthesaurus := c.(*ThesaurusConfig)
+		config.DefaultTimeout(&mwthesaurus.Timeout, config.OnlineTimeout)
+		config.DefaultHTTP(&mwthesaurus.HTTP, 0)
+	},
+	Template: `mwthesaurus:
+  # Merriam-Webster Collegiate Thesaurus API key, requested separately from the dictionary key
+  # key: ""
+  timeout: 10s
+`,
+}
+
+func (c *ThesaurusConfig) Validate() error {
+	if c.Key == "" {
+		return errors.New("mwthesaurus.key is required when mwthesaurus is the default dictionary. Set it via: wordflow config set dict.mwthesaurus.key <key>")
+	}
+	return nil
+}
@@ -1,8 +1,8 @@
 package dict_mwebster
 
 import (
+	"context"
 	"encoding/json"
-	"github.com/gogodjzhu/word-flow/internal/config"
 	"github.com/gogodjzhu/word-flow/internal/util"
 	"github.com/gogodjzhu/word-flow/pkg/dict/entity"
 	"github.com/pkg/errors"
@@ -11,22 +11,32 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 )
 
 type DictMWebster struct {
-	key string
+	key     string
+	timeout time.Duration
+	policy  *util.Policy
 }
 
-func NewDictMWebster(config *config.MWebsterConfig) (*DictMWebster, error) {
+func NewDictMWebster(config *Config) (*DictMWebster, error) {
 	if config == nil || config.Key == "" {
 		return nil, errors.New("mwebster config with key is required")
 	}
-	return &DictMWebster{key: config.Key}, nil
+	return &DictMWebster{key: config.Key, timeout: time.Duration(config.Timeout), policy: util.PolicyFromConfig(config.HTTP)}, nil
 }
 
 func (d *DictMWebster) Search(word string) (*entity.WordItem, error) {
+	return d.SearchContext(context.Background(), word)
+}
+
+func (d *DictMWebster) SearchContext(ctx context.Context, word string) (*entity.WordItem, error) {
+	ctx, cancel := util.ContextWithTimeout(ctx, d.timeout)
+	defer cancel()
+	ctx = util.WithEndpoint(util.WithPolicy(ctx, d.policy), "dict.mwebster")
 	url := "https://www.dictionaryapi.com/api/v3/references/collegiate/json/" + word + "?key=" + d.key
-	result, err := util.SendGet(url, nil, func(response *http.Response) (interface{}, error) {
+	result, err := util.SendGetContext(ctx, url, nil, func(response *http.Response) (interface{}, error) {
 		if response.StatusCode != 200 {
 			return nil, errors.New("failed to sendGet")
 		}
@@ -0,0 +1,65 @@
+package dict_mwebster
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/gogodjzhu/word-flow/internal/cassette"
+)
+
+func TestDictMWebster_Search(t *testing.T) {
+	c := cassette.Load(t, "mwebster", "key")
+	d, err := NewDictMWebster(&Config{Key: "test-key"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		word      string
+		wantErr   bool
+		meanings  []string // part of speech and definitions joined by "|"
+		phonetics []string // text and audio joined by "|"
+	}{
+		{
+			word:      "hello",
+			meanings:  []string{"noun.|an expression or gesture of greeting —used interjectionally in greeting, in answering the telephone, or to express surprise; "},
+			phonetics: []string{"hə-ˈlō|https://media.merriam-webster.com/audio/prons/en/us/mp3/h/hello001.mp3"},
+		},
+		{
+			word: "lead",
+			meanings: []string{
+				"verb.|to guide on a way especially by going in advance; to direct on a course or in a direction; ",
+				"noun.|a heavy soft malleable ductile plastic but inelastic bluish-white metallic element; ",
+			},
+			phonetics: []string{
+				"ˈlēd|https://media.merriam-webster.com/audio/prons/en/us/mp3/l/lead0001.mp3",
+				"ˈled|https://media.merriam-webster.com/audio/prons/en/us/mp3/l/lead0002.mp3",
+			},
+		},
+		{word: "qwzxv", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.word, func(t *testing.T) {
+			got, err := d.SearchContext(c.Context(context.Background()), tt.word)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SearchContext() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			var meanings, phonetics []string
+			for _, m := range got.WordMeanings {
+				meanings = append(meanings, m.PartOfSpeech+"|"+m.Definitions)
+			}
+			for _, p := range got.WordPhonetics {
+				phonetics = append(phonetics, p.Text+"|"+p.Audio)
+			}
+			if strings.Join(meanings, "\n") != strings.Join(tt.meanings, "\n") {
+				t.Errorf("meanings = %q, want %q", meanings, tt.meanings)
+			}
+			if strings.Join(phonetics, "\n") != strings.Join(tt.phonetics, "\n") {
+				t.Errorf("phonetics = %q, want %q", phonetics, tt.phonetics)
+			}
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package dict_mwebster
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gogodjzhu/word-flow/internal/buzz_error"
+	"github.com/gogodjzhu/word-flow/internal/util"
+	"github.com/gogodjzhu/word-flow/pkg/dict/entity"
+	"github.com/pkg/errors"
+)
+
+const thesaurusURL = "https://www.dictionaryapi.com/api/v3/references/thesaurus/json/"
+
+// DictMWThesaurus looks words up in the Merriam-Webster Collegiate Thesaurus,
+// which takes its own key from https://dictionaryapi.com/ like the dictionary.
+type DictMWThesaurus struct {
+	key     string
+	baseURL string
+	timeout time.Duration
+	policy  *util.Policy
+}
+
+func NewDictMWThesaurus(config *ThesaurusConfig) (*DictMWThesaurus, error) {
+	if config == nil || config.Key == "" {
+		return nil, errors.New("mwthesaurus config with key is required")
+	}
+	return &DictMWThesaurus{key: config.Key, baseURL: thesaurusURL, timeout: time.Duration(config.Timeout), policy: util.PolicyFromConfig(config.HTTP)}, nil
+}
+
+func (d *DictMWThesaurus) Search(word string) (*entity.WordItem, error) {
+	return d.SearchContext(context.Background(), word)
+}
+
+func (d *DictMWThesaurus) SearchContext(ctx context.Context, word string) (*entity.WordItem, error) {
+	ctx, cancel := util.ContextWithTimeout(ctx, d.timeout)
+	defer cancel()
+	ctx = util.WithEndpoint(util.WithPolicy(ctx, d.policy), "dict.mwthesaurus")
+	word = strings.TrimSpace(word)
+	requestURL := d.baseURL + url.PathEscape(word) + "?key=" + url.QueryEscape(d.key)
+	result, err := util.SendGetContext(ctx, requestURL, nil, func(response *http.Response) (interface{}, error) {
+		defer response.Body.Close()
+		if response.StatusCode != 200 {
+			return nil, errors.Errorf("failed to query mwthesaurus, status: %s", response.Status)
+		}
+		bs, err := io.ReadAll(response.Body)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read from response body")
+		}
+		// Unknown words return a list of spelling suggestions instead of entries.
+		var entries []json.RawMessage
+		if err := json.Unmarshal(bs, &entries); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal response body:'"+string(bs)+"'")
+		}
+		if len(entries) == 0 || (len(entries[0]) > 0 && entries[0][0] == '"') {
+			return nil, buzz_error.InvalidInput("Invalid word: " + word)
+		}
+		var thesaurusEntries []ThesaurusEntry
+		if err := json.Unmarshal(bs, &thesaurusEntries); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal response body:'"+string(bs)+"'")
+		}
+		return newThesaurusItem(word, thesaurusEntries)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.(*entity.WordItem), nil
+}
+
+func newThesaurusItem(word string, entries []ThesaurusEntry) (*entity.WordItem, error) {
+	wordItem := &entity.WordItem{
+		ID:            entity.WordId(word),
+		Word:          word,
+		Source:        "mwthesaurus",
+		WordPhonetics: make([]*entity.WordPhonetic, 0),
+		WordMeanings:  make([]*entity.WordMeaning, 0),
+	}
+	seen := map[string]bool{strings.ToLower(word): true}
+	add := func(list []string, words ...string) []string {
+		for _, w := range words {
+			if key := strings.ToLower(w); !seen[key] {
+				seen[key] = true
+				list = append(list, w)
+			}
+		}
+		return list
+	}
+	for _, entry := range entries {
+		// Entries of other headwords, e.g. phrases containing the word, are skipped.
+		id := entry.Meta.ID
+		if entry.Homograph != 0 {
+			id = strings.TrimSuffix(id, ":"+strconv.Itoa(entry.Homograph))
+		}
+		if !strings.EqualFold(id, word) {
+			continue
+		}
+		for i, definition := range entry.ShortDefinitions {
+			meaning := &entity.WordMeaning{PartOfSpeech: entry.FunctionLabel + ".", Definitions: definition}
+			if i < len(entry.Meta.Synonyms) && len(entry.Meta.Synonyms[i]) > 0 {
+				meaning.Definitions += " (syn. " + strings.Join(entry.Meta.Synonyms[i], ", ") + ")"
+			}
+			wordItem.WordMeanings = append(wordItem.WordMeanings, meaning)
+		}
+		for _, synonyms := range entry.Meta.Synonyms {
+			wordItem.Synonyms = add(wordItem.Synonyms, synonyms...)
+		}
+		for _, antonyms := range entry.Meta.Antonyms {
+			wordItem.Antonyms = add(wordItem.Antonyms, antonyms...)
+		}
+		related, err := entry.relatedWords()
+		if err != nil {
+			return nil, err
+		}
+		wordItem.Related = add(wordItem.Related, related...)
+	}
+	if len(wordItem.WordMeanings) == 0 {
+		return nil, buzz_error.InvalidInput("Invalid word: " + word)
+	}
+	return wordItem, nil
+}
+
+type ThesaurusEntry struct {
+	Meta             ThesaurusMeta         `json:"meta"`
+	Homograph        int                   `json:"hom"`
+	FunctionLabel    string                `json:"fl"`
+	ShortDefinitions []string              `json:"shortdef"`
+	Definitions      []ThesaurusDefinition `json:"def"`
+}
+
+type ThesaurusMeta struct {
+	ID       string     `json:"id"`
+	Synonyms [][]string `json:"syns"`
+	Antonyms [][]string `json:"ants"`
+}
+
+// ThesaurusDefinition holds the sense sequence, a list of ["sense", {...}]
+// pairs, see https://www.dictionaryapi.com/products/json#sec-2.sseq
+type ThesaurusDefinition struct {
+	SenseSequence [][][]json.RawMessage `json:"sseq"`
+}
+
+type ThesaurusSense struct {
+	RelatedList [][]ThesaurusWord `json:"rel_list"`
+	NearList    [][]ThesaurusWord `json:"near_list"`
+}
+
+type ThesaurusWord struct {
+	Word string `json:"wd"`
+}
+
+// relatedWords collects the related and near words of all senses.
+func (e *ThesaurusEntry) relatedWords() ([]string, error) {
+	var words []string
+	for _, definition := range e.Definitions {
+		for _, senses := range definition.SenseSequence {
+			for _, pair := range senses {
+				var kind string
+				if len(pair) != 2 || json.Unmarshal(pair[0], &kind) != nil || kind != "sense" {
+					continue
+				}
+				var sense ThesaurusSense
+				if err := json.Unmarshal(pair[1], &sense); err != nil {
+					return nil, errors.Wrap(err, "failed to unmarshal thesaurus sense")
+				}
+				for _, list := range append(sense.RelatedList, sense.NearList...) {
+					for _, w := range list {
+						words = append(words, w.Word)
+					}
+				}
+			}
+		}
+	}
+	return words, nil
+}
@@ -0,0 +1,77 @@
+package dict_mwebster
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const thesaurusHappy = `[{
+  "meta": {"id": "happy", "syns": [["glad", "joyful"], ["fortunate", "lucky"]], "ants": [["sad", "unhappy"]]},
+  "hom": 0,
+  "fl": "adjective",
+  "shortdef": ["feeling or showing pleasure", "favored by luck"],
+  "def": [{"sseq": [[["sense", {"sn": "1", "rel_list": [[{"wd": "cheerful"}, {"wd": "glad"}]], "near_list": [[{"wd": "content"}]]}]],
+                    [["sense", {"sn": "2"}]]]}]
+}, {
+  "meta": {"id": "happy-go-lucky", "syns": [["carefree"]], "ants": []},
+  "fl": "adjective",
+  "shortdef": ["showing a lack of concern"]
+}]`
+
+func newTestThesaurus(t *testing.T) *DictMWThesaurus {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("key") != "test-key" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch strings.TrimPrefix(r.URL.Path, "/") {
+		case "happy":
+			_, _ = w.Write([]byte(thesaurusHappy))
+		default:
+			_, _ = w.Write([]byte(`["hapy", "harpy"]`))
+		}
+	}))
+	t.Cleanup(server.Close)
+	d, err := NewDictMWThesaurus(&ThesaurusConfig{Key: "test-key"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.baseURL = server.URL + "/"
+	return d
+}
+
+func TestDictMWThesaurus_Search(t *testing.T) {
+	d := newTestThesaurus(t)
+	got, err := d.Search("happy")
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if got.Source != "mwthesaurus" || len(got.WordMeanings) != 2 {
+		t.Fatalf("got %+v", got)
+	}
+	if got.WordMeanings[0].PartOfSpeech != "adjective." || got.WordMeanings[0].Definitions != "feeling or showing pleasure (syn. glad, joyful)" {
+		t.Errorf("meaning = %+v", got.WordMeanings[0])
+	}
+	if strings.Join(got.Synonyms, ",") != "glad,joyful,fortunate,lucky" {
+		t.Errorf("Synonyms = %v", got.Synonyms)
+	}
+	if strings.Join(got.Antonyms, ",") != "sad,unhappy" {
+		t.Errorf("Antonyms = %v", got.Antonyms)
+	}
+	if strings.Join(got.Related, ",") != "cheerful,content" {
+		t.Errorf("Related = %v", got.Related)
+	}
+
+	if _, err := d.Search("hapy"); err == nil || !strings.Contains(err.Error(), "Invalid word") {
+		t.Errorf("Search(hapy) error = %v, want invalid word", err)
+	}
+}
+
+func TestNewDictMWThesaurus_RequiresKey(t *testing.T) {
+	if _, err := NewDictMWThesaurus(&ThesaurusConfig{}); err == nil {
+		t.Error("expected error without key")
+	}
+}
@@ -84,6 +84,10 @@ func (f *fileNotebook) Mark(word string, action Action, translation *entity.Word
 	}
 	examples := collectExamples(translation)
 	phonetics := collectPhonetics(translation)
+	var synonyms, antonyms []string
+	if translation != nil {
+		synonyms, antonyms = translation.Synonyms, translation.Antonyms
+	}
 	wordID := entity.WordId(word)
 	now := time.Now().Unix()
 
@@ -96,6 +100,8 @@ func (f *fileNotebook) Mark(word string, action Action, translation *entity.Word
 		Translation:    translationStr,
 		Examples:       examples,
 		WordPhonetics:  phonetics,
+		Synonyms:       synonyms,
+		Antonyms:       antonyms,
 	}
 	isOld := false
 	for _, n := range notes {
@@ -111,6 +117,9 @@ func (f *fileNotebook) Mark(word string, action Action, translation *entity.Word
 			if len(phonetics) > 0 {
 				note.WordPhonetics = phonetics
 			}
+			if len(synonyms) > 0 || len(antonyms) > 0 {
+				note.Synonyms, note.Antonyms = synonyms, antonyms
+			}
 			break
 		}
 	}
@@ -7,6 +7,7 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -212,6 +213,8 @@ func TestFileNotebook_MarkWithTranslation(t *testing.T) {
 				Definitions:  "a test definition",
 			},
 		},
+		Synonyms: []string{"trial", "exam"},
+		Antonyms: []string{"guess"},
 	}
 	expectedTranslationStr := testTranslation.RawString()
 
@@ -300,6 +303,11 @@ func TestFileNotebook_MarkWithTranslation(t *testing.T) {
 	} else if testNote.Translation != expectedTranslationStr {
 		t.Errorf("Expected translation '%s', got '%s'", expectedTranslationStr, testNote.Translation)
 	}
+	if testNote != nil {
+		if hints := testNote.GetHints(); strings.Join(hints, "|") != "syn. trial, exam|ant. guess" {
+			t.Errorf("Expected synonyms and antonyms as hints, got %v", hints)
+		}
+	}
 
 	if exampleNote == nil {
 		t.Error("Example note not found")
@@ -0,0 +1,70 @@
+package dict
+
+import (
+	"sync"
+
+	"github.com/gogodjzhu/word-flow/internal/config"
+)
+
+// Registration describes a dictionary endpoint: its name and description for
+// `dict -l`, its configuration section and the factory creating it.
+type Registration struct {
+	Name        string
+	Description string
+	// Config is the configuration section of the endpoint. It may be left nil
+	// when the section is already registered with config.RegisterDictEndpoint.
+	Config *config.EndpointSpec
+	New    func(endpointConfig config.DictEndpointConfig) (Dict, error)
+}
+
+var (
+	registryMu    sync.RWMutex
+	registrations []Registration
+)
+
+func init() {
+	for _, r := range builtinDictionaries {
+		Register(r)
+	}
+}
+
+// Register adds a dictionary endpoint. It panics if the name is already taken
+// or the endpoint has no configuration section.
+func Register(r Registration) {
+	if r.Name == "" || r.New == nil {
+		panic("dict: endpoint needs a name and a factory")
+	}
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	for _, existing := range registrations {
+		if existing.Name == r.Name {
+			panic("dict: endpoint " + r.Name + " registered twice")
+		}
+	}
+	if r.Config != nil {
+		spec := *r.Config
+		spec.Name = r.Name
+		config.RegisterDictEndpoint(spec)
+	} else if _, ok := config.DictEndpoint(r.Name); !ok {
+		panic("dict: endpoint " + r.Name + " has no config section")
+	}
+	registrations = append(registrations, r)
+}
+
+// Lookup returns the registration of a dictionary endpoint.
+func Lookup(name string) (Registration, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	for _, r := range registrations {
+		if r.Name == name {
+			return r, true
+		}
+	}
+	return Registration{}, false
+}
+
+func registered() []Registration {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	return append([]Registration(nil), registrations...)
+}
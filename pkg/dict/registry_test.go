@@ -0,0 +1,57 @@
+package dict
+
+import (
+	"testing"
+
+	"github.com/gogodjzhu/word-flow/internal/config"
+)
+
+type fakeDictConfig struct {
+	Words []string `yaml:"words"`
+}
+
+func (c *fakeDictConfig) Validate() error { return nil }
+
+func TestRegister(t *testing.T) {
+	Register(Registration{
+		Name:        "fake",
+		Description: "A dictionary that knows the configured words.",
+		Config: &config.EndpointSpec{
+			New: func() config.EndpointConfig { return &fakeDictConfig{} },
+		},
+		New: func(c config.DictEndpointConfig) (Dict, error) {
+			words := map[string]bool{}
+			for _, w := range c.(*fakeDictConfig).Words {
+				words[w] = true
+			}
+			return &fakeDict{source: "fake", words: words}, nil
+		},
+	})
+
+	if _, ok := Lookup("fake"); !ok {
+		t.Fatal("fake endpoint not registered")
+	}
+	infos := AvailableDictionaries()
+	if last := infos[len(infos)-1]; last.Name != "fake" {
+		t.Errorf("last dictionary = %+v", last)
+	}
+
+	conf := &config.DictConfig{
+		Default:   "fake",
+		Endpoints: map[string]config.EndpointConfig{"fake": &fakeDictConfig{Words: []string{"hello"}}},
+	}
+	d, err := NewDict(conf)
+	if err != nil {
+		t.Fatalf("NewDict() error = %v", err)
+	}
+	if got, err := d.Search("hello"); err != nil || got.Source != "fake" {
+		t.Errorf("Search(hello) = %+v, %v", got, err)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic for a duplicate registration")
+		}
+	}()
+	Register(Registration{Name: "youdao", New: func(config.DictEndpointConfig) (Dict, error) { return nil, nil }})
+}
@@ -0,0 +1,116 @@
+package dict
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gogodjzhu/word-flow/pkg/dict/entity"
+)
+
+// contextDict records the context of its lookups.
+type contextDict struct {
+	fakeDict
+	ctx context.Context
+}
+
+func (c *contextDict) SearchContext(ctx context.Context, word string) (*entity.WordItem, error) {
+	c.ctx = ctx
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return c.Search(word)
+}
+
+func TestSearch_Context(t *testing.T) {
+	type ctxKey struct{}
+	ctx := context.WithValue(context.Background(), ctxKey{}, "request")
+	cd := &contextDict{fakeDict: fakeDict{source: "ctx", words: map[string]bool{"hello": true}}}
+	if _, err := Search(ctx, cd, "hello"); err != nil || cd.ctx.Value(ctxKey{}) != "request" {
+		t.Errorf("Search() error = %v, context not passed on", err)
+	}
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	plain := &fakeDict{source: "plain", words: map[string]bool{"hello": true}}
+	if _, err := Search(cancelled, plain, "hello"); !errors.Is(err, context.Canceled) || plain.calls != 0 {
+		t.Errorf("Search() on a cancelled context = %v after %d calls", err, plain.calls)
+	}
+}
+
+func TestFallbackDict_SearchContextCancelled(t *testing.T) {
+	first := &contextDict{fakeDict: fakeDict{source: "first", words: map[string]bool{}}}
+	second := &fakeDict{source: "second", words: map[string]bool{"hello": true}}
+	f := &fallbackDict{endpoints: []string{"first", "second"}, dicts: []Dict{first, second}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := f.SearchContext(ctx, "hello"); !errors.Is(err, context.Canceled) {
+		t.Errorf("SearchContext() error = %v, want context.Canceled", err)
+	}
+	if second.calls != 0 {
+		t.Error("fallback endpoint searched after cancellation")
+	}
+	if got, err := f.SearchContext(context.Background(), "hello"); err != nil || got.Source != "second" {
+		t.Errorf("SearchContext() = %+v, %v", got, err)
+	}
+}
+
+// reverseDict answers Chinese queries with the English word in reverse.
+type reverseDict struct {
+	fakeDict
+	reverse map[string]string
+}
+
+func (r *reverseDict) SearchReverse(ctx context.Context, query string) (*entity.WordItem, error) {
+	word, ok := r.reverse[query]
+	if !ok {
+		return nil, errors.New("no English word for " + query)
+	}
+	return &entity.WordItem{Word: query, Source: r.source, WordMeanings: []*entity.WordMeaning{{PartOfSpeech: word}}}, nil
+}
+
+func TestSearchDetected(t *testing.T) {
+	rd := &reverseDict{
+		fakeDict: fakeDict{source: "reverse", words: map[string]bool{"hello": true, "the cat": true}},
+		reverse:  map[string]string{"你好": "hello"},
+	}
+	got, err := SearchDetected(context.Background(), rd, "你好")
+	if err != nil || got.WordMeanings[0].PartOfSpeech != "hello" || got.Language != "zh" {
+		t.Errorf("SearchDetected(你好) = %+v, %v, want the reverse lookup in zh", got, err)
+	}
+	if got, err := SearchDetected(context.Background(), rd, "the cat"); err != nil || got.Language != "en" || rd.calls != 1 {
+		t.Errorf("SearchDetected(the cat) = %+v, %v", got, err)
+	}
+	// A single word is too short to tell its language.
+	if got, err := SearchDetected(context.Background(), rd, "hello"); err != nil || got.Language != "" {
+		t.Errorf("SearchDetected(hello) = %+v, %v, want no language", got, err)
+	}
+
+	plain := &fakeDict{source: "plain", words: map[string]bool{"你好": true}}
+	if got, err := SearchDetected(context.Background(), plain, "你好"); err != nil || got.Source != "plain" {
+		t.Errorf("SearchDetected() without reverse lookup = %+v, %v", got, err)
+	}
+}
+
+func TestThesaurusDict_SearchReverse(t *testing.T) {
+	rd := &reverseDict{fakeDict: fakeDict{source: "reverse"}, reverse: map[string]string{"你好": "hello"}}
+	got, err := SearchDetected(context.Background(), &thesaurusDict{Dict: rd}, "你好")
+	if err != nil || got.Source != "reverse" || got.WordMeanings[0].PartOfSpeech != "hello" {
+		t.Errorf("SearchDetected(你好) = %+v, %v, want the reverse lookup of the wrapped dictionary", got, err)
+	}
+}
+
+func TestFallbackDict_SearchReverse(t *testing.T) {
+	plain := &fakeDict{source: "plain", words: map[string]bool{"你好": true}}
+	rd := &reverseDict{fakeDict: fakeDict{source: "reverse"}, reverse: map[string]string{"你好": "hello"}}
+	f := &fallbackDict{endpoints: []string{"plain", "reverse"}, dicts: []Dict{plain, rd}}
+	if got, err := f.SearchReverse(context.Background(), "你好"); err != nil || got.Source != "reverse" || plain.calls != 0 {
+		t.Errorf("SearchReverse() = %+v, %v, want the dictionary with a reverse lookup", got, err)
+	}
+
+	f = &fallbackDict{endpoints: []string{"plain"}, dicts: []Dict{plain}}
+	if got, err := f.SearchReverse(context.Background(), "你好"); err != nil || got.Source != "plain" {
+		t.Errorf("SearchReverse() without reverse lookups = %+v, %v", got, err)
+	}
+}
@@ -0,0 +1,36 @@
+package dict_stardict
+
+import (
+	"errors"
+	"path/filepath"
+
+	"github.com/gogodjzhu/word-flow/internal/config"
+)
+
+// Config is the dict.stardict section.
+type Config struct {
+	// Paths lists .ifo files or directories that are scanned for StarDict bundles.
+	Paths []string `yaml:"paths,omitempty"`
+}
+
+// ConfigSection registers Config with the dictionary.
+var ConfigSection = config.EndpointSpec{
+	New: func() config.EndpointConfig { return &Config{} },
+	Defaults: func(c config.EndpointConfig, dir string) {
+		if stardict := c.(*Config); len(stardict.Paths) == 0 {
+			stardict.Paths = []string{filepath.Join(dir, "stardict")}
+		}
+	},
+	Template: `stardict:
+  # StarDict bundles (.ifo/.idx/.dict[.dz]). Each path is an .ifo file or a directory scanned recursively.
+  # Defaults to <WORDFLOW_HOME>/stardict if empty
+  # paths: []
+`,
+}
+
+func (c *Config) Validate() error {
+	if len(c.Paths) == 0 {
+		return errors.New("stardict.paths is required when stardict is the default dictionary. Set it via: wordflow config set dict.stardict.paths <dir1>,<dir2>")
+	}
+	return nil
+}
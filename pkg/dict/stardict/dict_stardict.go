@@ -0,0 +1,518 @@
+package dict_stardict
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gogodjzhu/word-flow/internal/buzz_error"
+	"github.com/gogodjzhu/word-flow/internal/util"
+	"github.com/gogodjzhu/word-flow/pkg/dict/entity"
+	"github.com/pkg/errors"
+)
+
+type DictStardict struct {
+	bundles []*Bundle
+}
+
+func NewDictStardict(config *Config) (*DictStardict, error) {
+	if config == nil {
+		return nil, errors.New("stardict config is required")
+	}
+	var ifoFiles []string
+	for _, path := range config.Paths {
+		files, err := findIfoFiles(path)
+		if err != nil {
+			return nil, err
+		}
+		ifoFiles = append(ifoFiles, files...)
+	}
+	if len(ifoFiles) == 0 {
+		return nil, errors.Errorf("no stardict bundle found in %s", strings.Join(config.Paths, ", "))
+	}
+	d := &DictStardict{}
+	for _, ifo := range ifoFiles {
+		bundle, err := OpenBundle(ifo)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to open stardict bundle %s", ifo)
+		}
+		d.bundles = append(d.bundles, bundle)
+	}
+	return d, nil
+}
+
+func findIfoFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to stat stardict path %s", path)
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+	var files []string
+	err = filepath.WalkDir(path, func(p string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.IsDir() && strings.EqualFold(filepath.Ext(p), ".ifo") {
+			files = append(files, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to scan stardict path %s", path)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+func (d *DictStardict) Search(word string) (*entity.WordItem, error) {
+	word = strings.TrimSpace(word)
+	if word == "" {
+		return nil, buzz_error.InvalidInput("empty word to search")
+	}
+	result := &entity.WordItem{
+		ID:            entity.WordId(word),
+		Word:          word,
+		WordPhonetics: make([]*entity.WordPhonetic, 0),
+		WordMeanings:  make([]*entity.WordMeaning, 0),
+	}
+	var books []string
+	for _, bundle := range d.bundles {
+		articles, err := bundle.Lookup(word)
+		if err != nil {
+			return nil, err
+		}
+		if len(articles) == 0 {
+			continue
+		}
+		books = append(books, bundle.Info.BookName)
+		for _, article := range articles {
+			appendArticle(result, article)
+		}
+	}
+	if len(books) == 0 {
+		return nil, buzz_error.InvalidInput("Invalid word: " + word)
+	}
+	result.Source = "stardict: " + strings.Join(books, ", ")
+	return result, nil
+}
+
+// MatchPrefix returns the headwords of all bundles that start with prefix.
+func (d *DictStardict) MatchPrefix(prefix string) ([]string, error) {
+	words := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, bundle := range d.bundles {
+		for _, word := range bundle.MatchPrefix(prefix) {
+			if !seen[word] {
+				seen[word] = true
+				words = append(words, word)
+			}
+		}
+	}
+	return words, nil
+}
+
+func (d *DictStardict) Close() error {
+	for _, bundle := range d.bundles {
+		_ = bundle.Close()
+	}
+	return nil
+}
+
+// Info holds the keys of a StarDict .ifo file.
+type Info struct {
+	Version          string
+	BookName         string
+	WordCount        int
+	SynWordCount     int
+	IdxOffsetBits    int
+	SameTypeSequence string
+}
+
+// Field is one typed part of an article, e.g. 'm' plain text or 'h' HTML.
+type Field struct {
+	Type byte
+	Data []byte
+}
+
+// Article is the content of one index entry.
+type Article struct {
+	Word   string
+	Fields []Field
+}
+
+// Bundle is an opened .ifo/.idx/.dict(.dz) triple, with an optional .syn file.
+type Bundle struct {
+	Info Info
+
+	index   []byte
+	entries []int // start offsets of the entries in index
+	synonym []byte
+	syns    []int
+	dict    dictReader
+}
+
+func OpenBundle(ifoFilename string) (*Bundle, error) {
+	info, err := readInfo(ifoFilename)
+	if err != nil {
+		return nil, err
+	}
+	base := strings.TrimSuffix(ifoFilename, filepath.Ext(ifoFilename))
+
+	index, err := readMaybeGzip(base, ".idx")
+	if err != nil {
+		return nil, err
+	}
+	b := &Bundle{Info: info, index: index}
+	b.entries, err = scanEntries(index, info.IdxOffsetBits/8+4)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse idx file")
+	}
+
+	if synonym, err := readMaybeGzip(base, ".syn"); err == nil {
+		b.synonym = synonym
+		b.syns, err = scanEntries(synonym, 4)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse syn file")
+		}
+	}
+
+	var dictFilename string
+	for _, candidate := range []string{base + ".dict.dz", base + ".dict"} {
+		if _, err := os.Stat(candidate); err == nil {
+			dictFilename = candidate
+			break
+		}
+	}
+	if dictFilename == "" {
+		return nil, errors.Errorf("no .dict or .dict.dz file next to %s", ifoFilename)
+	}
+	b.dict, err = openDictFile(dictFilename)
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func readInfo(filename string) (Info, error) {
+	info := Info{IdxOffsetBits: 32}
+	file, err := os.Open(filename)
+	if err != nil {
+		return info, errors.Wrap(err, "failed to open ifo file")
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	if !scanner.Scan() || !strings.HasPrefix(strings.TrimPrefix(scanner.Text(), "\ufeff"), "StarDict's dict ifo file") {
+		return info, errors.Errorf("%s is not a stardict ifo file", filename)
+	}
+	for scanner.Scan() {
+		key, value, found := strings.Cut(scanner.Text(), "=")
+		if !found {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch strings.TrimSpace(key) {
+		case "version":
+			info.Version = value
+		case "bookname":
+			info.BookName = value
+		case "wordcount":
+			info.WordCount, _ = strconv.Atoi(value)
+		case "synwordcount":
+			info.SynWordCount, _ = strconv.Atoi(value)
+		case "idxoffsetbits":
+			if bits, err := strconv.Atoi(value); err == nil && (bits == 32 || bits == 64) {
+				info.IdxOffsetBits = bits
+			}
+		case "sametypesequence":
+			info.SameTypeSequence = value
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return info, errors.Wrap(err, "failed to read ifo file")
+	}
+	if info.BookName == "" {
+		info.BookName = filepath.Base(strings.TrimSuffix(filename, filepath.Ext(filename)))
+	}
+	return info, nil
+}
+
+func readMaybeGzip(base, ext string) ([]byte, error) {
+	if data, err := os.ReadFile(base + ext); err == nil {
+		return data, nil
+	}
+	file, err := os.Open(base + ext + ".gz")
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to open %s file", ext)
+	}
+	defer file.Close()
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to open %s.gz file", ext)
+	}
+	data, err := io.ReadAll(gz)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to inflate %s.gz file", ext)
+	}
+	return data, nil
+}
+
+// scanEntries returns the start of every "word\0<trailer>" record.
+func scanEntries(data []byte, trailer int) ([]int, error) {
+	entries := make([]int, 0)
+	for pos := 0; pos < len(data); {
+		end := bytes.IndexByte(data[pos:], 0)
+		if end < 0 || pos+end+1+trailer > len(data) {
+			return nil, errors.New("truncated entry")
+		}
+		entries = append(entries, pos)
+		pos += end + 1 + trailer
+	}
+	return entries, nil
+}
+
+func entryWord(data []byte, start int) (string, int) {
+	end := start + bytes.IndexByte(data[start:], 0)
+	return string(data[start:end]), end + 1
+}
+
+// Lookup returns the articles whose headword matches word. Exact matches win;
+// case-insensitive matches are returned only when there is no exact one.
+func (b *Bundle) Lookup(word string) ([]*Article, error) {
+	exact, folded := b.lookupIndex(word)
+	for _, idx := range b.lookupSynonyms(word) {
+		exact = appendUnique(exact, idx)
+	}
+	positions := exact
+	if len(positions) == 0 {
+		positions = folded
+	}
+	articles := make([]*Article, 0, len(positions))
+	for _, position := range positions {
+		article, err := b.readArticle(position)
+		if err != nil {
+			return nil, err
+		}
+		articles = append(articles, article)
+	}
+	return articles, nil
+}
+
+// MatchPrefix returns the headwords that start with prefix, ignoring ASCII
+// case like the StarDict ordering, so that they follow each other.
+func (b *Bundle) MatchPrefix(prefix string) []string {
+	first := sort.Search(len(b.entries), func(i int) bool {
+		w, _ := entryWord(b.index, b.entries[i])
+		return asciiCaseCompare(w, prefix) >= 0
+	})
+	var words []string
+	for i := first; i < len(b.entries); i++ {
+		w, _ := entryWord(b.index, b.entries[i])
+		if len(w) < len(prefix) || asciiCaseCompare(w[:len(prefix)], prefix) != 0 {
+			break
+		}
+		words = append(words, w)
+	}
+	return words
+}
+
+func (b *Bundle) lookupIndex(word string) (exact, folded []int) {
+	return searchSorted(b.index, b.entries, word, func(i int) int { return i })
+}
+
+func (b *Bundle) lookupSynonyms(word string) []int {
+	if len(b.syns) == 0 {
+		return nil
+	}
+	exact, _ := searchSorted(b.synonym, b.syns, word, func(i int) int {
+		_, next := entryWord(b.synonym, b.syns[i])
+		return int(binary.BigEndian.Uint32(b.synonym[next:]))
+	})
+	return exact
+}
+
+// searchSorted binary searches the StarDict ordering (ASCII case folded, then
+// byte order) and maps the matching entries through resolve.
+func searchSorted(data []byte, entries []int, word string, resolve func(int) int) (exact, folded []int) {
+	first := sort.Search(len(entries), func(i int) bool {
+		w, _ := entryWord(data, entries[i])
+		return asciiCaseCompare(w, word) >= 0
+	})
+	for i := first; i < len(entries); i++ {
+		w, _ := entryWord(data, entries[i])
+		if asciiCaseCompare(w, word) != 0 {
+			break
+		}
+		if w == word {
+			exact = appendUnique(exact, resolve(i))
+		} else {
+			folded = appendUnique(folded, resolve(i))
+		}
+	}
+	return exact, folded
+}
+
+func appendUnique(list []int, v int) []int {
+	for _, existing := range list {
+		if existing == v {
+			return list
+		}
+	}
+	return append(list, v)
+}
+
+func asciiCaseCompare(a, b string) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		ca, cb := asciiLower(a[i]), asciiLower(b[i])
+		if ca != cb {
+			if ca < cb {
+				return -1
+			}
+			return 1
+		}
+	}
+	switch {
+	case len(a) < len(b):
+		return -1
+	case len(a) > len(b):
+		return 1
+	}
+	return 0
+}
+
+func asciiLower(c byte) byte {
+	if c >= 'A' && c <= 'Z' {
+		return c + 'a' - 'A'
+	}
+	return c
+}
+
+func (b *Bundle) readArticle(position int) (*Article, error) {
+	if position < 0 || position >= len(b.entries) {
+		return nil, errors.New("index entry out of range")
+	}
+	word, next := entryWord(b.index, b.entries[position])
+	var offset, size int64
+	if b.Info.IdxOffsetBits == 64 {
+		offset = int64(binary.BigEndian.Uint64(b.index[next:]))
+		size = int64(binary.BigEndian.Uint32(b.index[next+8:]))
+	} else {
+		offset = int64(binary.BigEndian.Uint32(b.index[next:]))
+		size = int64(binary.BigEndian.Uint32(b.index[next+4:]))
+	}
+	data, err := b.dict.ReadAt(offset, size)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read article of %s", word)
+	}
+	fields, err := parseFields(data, b.Info.SameTypeSequence)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse article of %s", word)
+	}
+	return &Article{Word: word, Fields: fields}, nil
+}
+
+func (b *Bundle) Close() error {
+	return b.dict.Close()
+}
+
+// parseFields splits article data into typed fields. Lower case types are text
+// terminated by '\0', upper case types are prefixed with a 32-bit size. With a
+// sametypesequence the type bytes are omitted and the last field has neither
+// terminator nor size.
+func parseFields(data []byte, sameTypeSequence string) ([]Field, error) {
+	fields := make([]Field, 0)
+	if sameTypeSequence != "" {
+		for i := 0; i < len(sameTypeSequence); i++ {
+			t := sameTypeSequence[i]
+			if i == len(sameTypeSequence)-1 {
+				fields = append(fields, Field{Type: t, Data: data})
+				break
+			}
+			field, rest, err := readField(t, data)
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, field)
+			data = rest
+		}
+		return fields, nil
+	}
+	for len(data) > 0 {
+		field, rest, err := readField(data[0], data[1:])
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, field)
+		data = rest
+	}
+	return fields, nil
+}
+
+func readField(t byte, data []byte) (Field, []byte, error) {
+	if t >= 'a' && t <= 'z' {
+		end := bytes.IndexByte(data, 0)
+		if end < 0 {
+			return Field{Type: t, Data: data}, nil, nil
+		}
+		return Field{Type: t, Data: data[:end]}, data[end+1:], nil
+	}
+	if len(data) < 4 {
+		return Field{}, nil, errors.New("truncated field size")
+	}
+	size := int(binary.BigEndian.Uint32(data))
+	if 4+size > len(data) {
+		return Field{}, nil, errors.New("truncated field data")
+	}
+	return Field{Type: t, Data: data[4 : 4+size]}, data[4+size:], nil
+}
+
+var (
+	posPattern     = regexp.MustCompile(`^((?:n|v|vt|vi|adj|adv|a|ad|prep|conj|pron|int|interj|abbr|art|num|aux|pl)\.)\s*(.*)$`)
+	phoneticInText = regexp.MustCompile(`^[/\[](.+)[/\]]$`)
+)
+
+func appendArticle(item *entity.WordItem, article *Article) {
+	for _, field := range article.Fields {
+		switch field.Type {
+		case 't', 'y':
+			text := strings.TrimSpace(string(field.Data))
+			if text != "" {
+				item.WordPhonetics = append(item.WordPhonetics, &entity.WordPhonetic{HeadWord: article.Word, Text: text})
+			}
+		case 'm', 'l':
+			appendDefinitionLines(item, article.Word, string(field.Data))
+		case 'h', 'g', 'x', 'k', 'w', 'n':
+			appendDefinitionLines(item, article.Word, util.HTMLToText(string(field.Data)))
+		}
+	}
+}
+
+func appendDefinitionLines(item *entity.WordItem, headword, text string) {
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || line == headword {
+			continue
+		}
+		if m := phoneticInText.FindStringSubmatch(line); m != nil && len(item.WordMeanings) == 0 {
+			item.WordPhonetics = append(item.WordPhonetics, &entity.WordPhonetic{HeadWord: headword, Text: m[1]})
+			continue
+		}
+		meaning := &entity.WordMeaning{Definitions: line}
+		if m := posPattern.FindStringSubmatch(line); m != nil && m[2] != "" {
+			meaning.PartOfSpeech = m[1]
+			meaning.Definitions = m[2]
+		}
+		item.WordMeanings = append(item.WordMeanings, meaning)
+	}
+}
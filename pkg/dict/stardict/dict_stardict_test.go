@@ -0,0 +1,266 @@
+package dict_stardict
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+type testEntry struct {
+	word string
+	data []byte
+}
+
+// writeBundle writes a StarDict bundle into dir. When chunkLen is positive the
+// article file is written as dictzip with chunks of that size.
+func writeBundle(t *testing.T, dir, name, sameTypeSequence string, entries []testEntry, synonyms map[string]string, chunkLen int) string {
+	t.Helper()
+	sort.SliceStable(entries, func(i, j int) bool {
+		c := asciiCaseCompare(entries[i].word, entries[j].word)
+		if c == 0 {
+			return entries[i].word < entries[j].word
+		}
+		return c < 0
+	})
+
+	var idx, articles bytes.Buffer
+	positions := map[string]int{}
+	for i, e := range entries {
+		positions[e.word] = i
+		idx.WriteString(e.word)
+		idx.WriteByte(0)
+		_ = binary.Write(&idx, binary.BigEndian, uint32(articles.Len()))
+		_ = binary.Write(&idx, binary.BigEndian, uint32(len(e.data)))
+		articles.Write(e.data)
+	}
+
+	base := filepath.Join(dir, name)
+	ifo := fmt.Sprintf("StarDict's dict ifo file\nversion=2.4.2\nwordcount=%d\nidxfilesize=%d\nbookname=%s\n", len(entries), idx.Len(), name)
+	if sameTypeSequence != "" {
+		ifo += "sametypesequence=" + sameTypeSequence + "\n"
+	}
+	writeFile(t, base+".ifo", []byte(ifo))
+	writeFile(t, base+".idx", idx.Bytes())
+
+	if len(synonyms) > 0 {
+		words := make([]string, 0, len(synonyms))
+		for w := range synonyms {
+			words = append(words, w)
+		}
+		sort.Slice(words, func(i, j int) bool { return asciiCaseCompare(words[i], words[j]) < 0 })
+		var syn bytes.Buffer
+		for _, w := range words {
+			syn.WriteString(w)
+			syn.WriteByte(0)
+			_ = binary.Write(&syn, binary.BigEndian, uint32(positions[synonyms[w]]))
+		}
+		writeFile(t, base+".syn", syn.Bytes())
+	}
+
+	if chunkLen <= 0 {
+		writeFile(t, base+".dict", articles.Bytes())
+	} else {
+		writeFile(t, base+".dict.dz", dictzipBytes(t, articles.Bytes(), chunkLen))
+	}
+	return base + ".ifo"
+}
+
+func dictzipBytes(t *testing.T, data []byte, chunkLen int) []byte {
+	t.Helper()
+	var chunks [][]byte
+	for start := 0; start < len(data); start += chunkLen {
+		end := start + chunkLen
+		if end > len(data) {
+			end = len(data)
+		}
+		var buf bytes.Buffer
+		w, err := flate.NewWriter(&buf, flate.BestCompression)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = w.Write(data[start:end])
+		_ = w.Flush()
+		chunks = append(chunks, buf.Bytes())
+	}
+
+	var ra bytes.Buffer
+	_ = binary.Write(&ra, binary.LittleEndian, uint16(1))
+	_ = binary.Write(&ra, binary.LittleEndian, uint16(chunkLen))
+	_ = binary.Write(&ra, binary.LittleEndian, uint16(len(chunks)))
+	for _, c := range chunks {
+		_ = binary.Write(&ra, binary.LittleEndian, uint16(len(c)))
+	}
+
+	var out bytes.Buffer
+	out.Write([]byte{0x1f, 0x8b, 8, gzipFlagExtra | gzipFlagName, 0, 0, 0, 0, 2, 3})
+	_ = binary.Write(&out, binary.LittleEndian, uint16(4+ra.Len()))
+	out.WriteString("RA")
+	_ = binary.Write(&out, binary.LittleEndian, uint16(ra.Len()))
+	out.Write(ra.Bytes())
+	out.WriteString("test.dict")
+	out.WriteByte(0)
+	for _, c := range chunks {
+		out.Write(c)
+	}
+	return out.Bytes()
+}
+
+func writeFile(t *testing.T, filename string, data []byte) {
+	t.Helper()
+	if err := os.WriteFile(filename, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDictStardict_SearchPlainText(t *testing.T) {
+	dir := t.TempDir()
+	writeBundle(t, dir, "plain", "tm", []testEntry{
+		{word: "apple", data: []byte("ˈæpl\x00n. 苹果\nadj. 苹果的")},
+		{word: "Banana", data: []byte("bəˈnɑːnə\x00n. 香蕉")},
+	}, map[string]string{"apples": "apple"}, 0)
+
+	d, err := NewDictStardict(&Config{Paths: []string{dir}})
+	if err != nil {
+		t.Fatalf("NewDictStardict() error = %v", err)
+	}
+	defer d.Close()
+
+	got, err := d.Search("apple")
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if got.Source != "stardict: plain" {
+		t.Errorf("Source = %q", got.Source)
+	}
+	if len(got.WordPhonetics) != 1 || got.WordPhonetics[0].Text != "ˈæpl" {
+		t.Errorf("WordPhonetics = %+v", got.WordPhonetics)
+	}
+	if len(got.WordMeanings) != 2 {
+		t.Fatalf("expected 2 meanings, got %d", len(got.WordMeanings))
+	}
+	if got.WordMeanings[0].PartOfSpeech != "n." || got.WordMeanings[0].Definitions != "苹果" {
+		t.Errorf("first meaning = %+v", got.WordMeanings[0])
+	}
+
+	// case-insensitive fallback
+	got, err = d.Search("banana")
+	if err != nil {
+		t.Fatalf("Search(banana) error = %v", err)
+	}
+	if len(got.WordMeanings) != 1 || got.WordMeanings[0].Definitions != "香蕉" {
+		t.Errorf("banana meanings = %+v", got.WordMeanings)
+	}
+
+	// synonym lookup
+	got, err = d.Search("apples")
+	if err != nil {
+		t.Fatalf("Search(apples) error = %v", err)
+	}
+	if len(got.WordMeanings) != 2 {
+		t.Errorf("apples meanings = %+v", got.WordMeanings)
+	}
+
+	if _, err := d.Search("cherry"); err == nil {
+		t.Error("expected error for missing word")
+	}
+}
+
+func TestDictStardict_MatchPrefix(t *testing.T) {
+	dir := t.TempDir()
+	writeBundle(t, dir, "plain", "m", []testEntry{
+		{word: "apple", data: []byte("n. 苹果")},
+		{word: "Applet", data: []byte("n. 小程序")},
+		{word: "apply", data: []byte("v. 申请")},
+		{word: "banana", data: []byte("n. 香蕉")},
+	}, nil, 0)
+
+	d, err := NewDictStardict(&Config{Paths: []string{dir}})
+	if err != nil {
+		t.Fatalf("NewDictStardict() error = %v", err)
+	}
+	defer d.Close()
+
+	words, err := d.MatchPrefix("APPLE")
+	if err != nil || strings.Join(words, ",") != "apple,Applet" {
+		t.Errorf("MatchPrefix(APPLE) = %v, %v", words, err)
+	}
+	if words, _ := d.MatchPrefix("cherry"); len(words) != 0 {
+		t.Errorf("MatchPrefix(cherry) = %v", words)
+	}
+}
+
+func TestDictStardict_SearchDictzipHTML(t *testing.T) {
+	dir := t.TempDir()
+	var entries []testEntry
+	for i := 0; i < 50; i++ {
+		word := fmt.Sprintf("word%02d", i)
+		entries = append(entries, testEntry{
+			word: word,
+			data: []byte(fmt.Sprintf("<div><b>%s</b><br/>/wɜːd/<br>n. definition &amp; number %d</div><script>x()</script>", word, i)),
+		})
+	}
+	writeBundle(t, dir, "html", "h", entries, nil, 64)
+
+	d, err := NewDictStardict(&Config{Paths: []string{filepath.Join(dir, "html.ifo")}})
+	if err != nil {
+		t.Fatalf("NewDictStardict() error = %v", err)
+	}
+	defer d.Close()
+
+	for _, i := range []int{0, 17, 49} {
+		word := fmt.Sprintf("word%02d", i)
+		got, err := d.Search(word)
+		if err != nil {
+			t.Fatalf("Search(%s) error = %v", word, err)
+		}
+		if len(got.WordMeanings) != 1 {
+			t.Fatalf("Search(%s) meanings = %+v", word, got.WordMeanings)
+		}
+		want := fmt.Sprintf("definition & number %d", i)
+		if got.WordMeanings[0].PartOfSpeech != "n." || got.WordMeanings[0].Definitions != want {
+			t.Errorf("Search(%s) meaning = %+v, want %q", word, got.WordMeanings[0], want)
+		}
+		if len(got.WordPhonetics) != 1 || got.WordPhonetics[0].Text != "wɜːd" {
+			t.Errorf("Search(%s) phonetics = %+v", word, got.WordPhonetics)
+		}
+	}
+}
+
+func TestParseFields_WithoutSameTypeSequence(t *testing.T) {
+	var data bytes.Buffer
+	data.WriteString("tfəˈnetɪk\x00")
+	data.WriteString("mplain text\x00")
+	data.WriteByte('W')
+	_ = binary.Write(&data, binary.BigEndian, uint32(3))
+	data.Write([]byte{1, 2, 3})
+	data.WriteString("hlast")
+
+	fields, err := parseFields(data.Bytes(), "")
+	if err != nil {
+		t.Fatalf("parseFields() error = %v", err)
+	}
+	if len(fields) != 4 {
+		t.Fatalf("expected 4 fields, got %d", len(fields))
+	}
+	wantTypes := "tmWh"
+	for i, f := range fields {
+		if f.Type != wantTypes[i] {
+			t.Errorf("field %d type = %c, want %c", i, f.Type, wantTypes[i])
+		}
+	}
+	if string(fields[1].Data) != "plain text" || len(fields[2].Data) != 3 || string(fields[3].Data) != "last" {
+		t.Errorf("unexpected field data: %+v", fields)
+	}
+}
+
+func TestNewDictStardict_NoBundle(t *testing.T) {
+	if _, err := NewDictStardict(&Config{Paths: []string{t.TempDir()}}); err == nil {
+		t.Error("expected error for directory without bundles")
+	}
+}
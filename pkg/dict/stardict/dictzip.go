@@ -0,0 +1,265 @@
+package dict_stardict
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"encoding/binary"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	gzipFlagHCRC    = 1 << 1
+	gzipFlagExtra   = 1 << 2
+	gzipFlagName    = 1 << 3
+	gzipFlagComment = 1 << 4
+)
+
+// dictReader gives random access to the article data of a bundle.
+type dictReader interface {
+	ReadAt(offset, size int64) ([]byte, error)
+	Close() error
+}
+
+// plainDict reads an uncompressed .dict file.
+type plainDict struct {
+	file *os.File
+}
+
+func (d *plainDict) ReadAt(offset, size int64) ([]byte, error) {
+	buf := make([]byte, size)
+	if _, err := d.file.ReadAt(buf, offset); err != nil {
+		return nil, errors.Wrap(err, "failed to read dict file")
+	}
+	return buf, nil
+}
+
+func (d *plainDict) Close() error {
+	return d.file.Close()
+}
+
+// memoryDict holds a fully decompressed article file, used for .dz files that
+// were gzipped without the dictzip random access table.
+type memoryDict struct {
+	data []byte
+}
+
+func (d *memoryDict) ReadAt(offset, size int64) ([]byte, error) {
+	if offset < 0 || size < 0 || offset+size > int64(len(d.data)) {
+		return nil, errors.New("article out of range")
+	}
+	return d.data[offset : offset+size], nil
+}
+
+func (d *memoryDict) Close() error {
+	return nil
+}
+
+// dictzip reads a .dict.dz file. Dictzip is gzip with a "RA" extra field that
+// lists the compressed size of every chunk; each chunk inflates independently to
+// chunkLen bytes, so any article can be read by inflating only its chunks.
+type dictzip struct {
+	file       *os.File
+	chunkLen   int64
+	dataOffset int64
+	offsets    []int64 // compressed offset of chunk i, len(chunks)+1 entries
+
+	mu        sync.Mutex
+	lastIndex int
+	lastChunk []byte
+}
+
+func openDictFile(filename string) (dictReader, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open dict file")
+	}
+	if !isGzipFile(filename) {
+		return &plainDict{file: file}, nil
+	}
+	dz, err := newDictzip(file)
+	if err == nil {
+		return dz, nil
+	}
+	// Not a dictzip file, fall back to inflating the whole file once.
+	if _, seekErr := file.Seek(0, io.SeekStart); seekErr != nil {
+		_ = file.Close()
+		return nil, errors.Wrap(seekErr, "failed to rewind dict file")
+	}
+	defer file.Close()
+	gz, gzErr := gzip.NewReader(file)
+	if gzErr != nil {
+		return nil, errors.Wrap(gzErr, "failed to open gzip dict file")
+	}
+	data, readErr := io.ReadAll(gz)
+	if readErr != nil {
+		return nil, errors.Wrap(readErr, "failed to inflate dict file")
+	}
+	return &memoryDict{data: data}, nil
+}
+
+func newDictzip(file *os.File) (*dictzip, error) {
+	header := make([]byte, 10)
+	if _, err := io.ReadFull(file, header); err != nil {
+		return nil, errors.Wrap(err, "failed to read gzip header")
+	}
+	if header[0] != 0x1f || header[1] != 0x8b || header[2] != 8 {
+		return nil, errors.New("not a gzip file")
+	}
+	flags := header[3]
+	if flags&gzipFlagExtra == 0 {
+		return nil, errors.New("gzip file has no extra field")
+	}
+	pos := int64(10)
+
+	xlenBuf := make([]byte, 2)
+	if _, err := io.ReadFull(file, xlenBuf); err != nil {
+		return nil, errors.Wrap(err, "failed to read gzip extra length")
+	}
+	extra := make([]byte, binary.LittleEndian.Uint16(xlenBuf))
+	if _, err := io.ReadFull(file, extra); err != nil {
+		return nil, errors.Wrap(err, "failed to read gzip extra field")
+	}
+	pos += 2 + int64(len(extra))
+
+	dz := &dictzip{file: file, lastIndex: -1}
+	var chunkSizes []uint16
+	for len(extra) >= 4 {
+		id := string(extra[:2])
+		length := int(binary.LittleEndian.Uint16(extra[2:4]))
+		if 4+length > len(extra) {
+			break
+		}
+		sub := extra[4 : 4+length]
+		extra = extra[4+length:]
+		if id != "RA" || len(sub) < 6 {
+			continue
+		}
+		dz.chunkLen = int64(binary.LittleEndian.Uint16(sub[2:4]))
+		count := int(binary.LittleEndian.Uint16(sub[4:6]))
+		if len(sub) < 6+count*2 {
+			return nil, errors.New("truncated dictzip chunk table")
+		}
+		for i := 0; i < count; i++ {
+			chunkSizes = append(chunkSizes, binary.LittleEndian.Uint16(sub[6+i*2:]))
+		}
+	}
+	if dz.chunkLen == 0 || len(chunkSizes) == 0 {
+		return nil, errors.New("gzip file is not a dictzip file")
+	}
+
+	reader := headerReader{file: file}
+	if flags&gzipFlagName != 0 {
+		n, err := reader.skipCString()
+		if err != nil {
+			return nil, err
+		}
+		pos += n
+	}
+	if flags&gzipFlagComment != 0 {
+		n, err := reader.skipCString()
+		if err != nil {
+			return nil, err
+		}
+		pos += n
+	}
+	if flags&gzipFlagHCRC != 0 {
+		pos += 2
+	}
+
+	dz.dataOffset = pos
+	dz.offsets = make([]int64, len(chunkSizes)+1)
+	for i, size := range chunkSizes {
+		dz.offsets[i+1] = dz.offsets[i] + int64(size)
+	}
+	return dz, nil
+}
+
+func (d *dictzip) ReadAt(offset, size int64) ([]byte, error) {
+	if offset < 0 || size < 0 {
+		return nil, errors.New("article out of range")
+	}
+	first := int(offset / d.chunkLen)
+	last := int((offset + size - 1) / d.chunkLen)
+	if size == 0 {
+		last = first
+	}
+	if last >= len(d.offsets)-1 {
+		return nil, errors.New("article out of range")
+	}
+	var buf bytes.Buffer
+	for i := first; i <= last; i++ {
+		chunk, err := d.chunk(i)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(chunk)
+	}
+	start := offset - int64(first)*d.chunkLen
+	data := buf.Bytes()
+	if start+size > int64(len(data)) {
+		return nil, errors.New("article out of range")
+	}
+	return data[start : start+size], nil
+}
+
+func (d *dictzip) chunk(index int) ([]byte, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if index == d.lastIndex {
+		return d.lastChunk, nil
+	}
+	compressed := make([]byte, d.offsets[index+1]-d.offsets[index])
+	if _, err := d.file.ReadAt(compressed, d.dataOffset+d.offsets[index]); err != nil {
+		return nil, errors.Wrap(err, "failed to read dictzip chunk")
+	}
+	inflater := flate.NewReader(bytes.NewReader(compressed))
+	defer inflater.Close()
+	data, err := io.ReadAll(inflater)
+	// Chunks end with a sync flush rather than a final block.
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, errors.Wrap(err, "failed to inflate dictzip chunk")
+	}
+	d.lastIndex, d.lastChunk = index, data
+	return data, nil
+}
+
+func (d *dictzip) Close() error {
+	return d.file.Close()
+}
+
+type headerReader struct {
+	file *os.File
+}
+
+// skipCString consumes a zero terminated string and returns the bytes read.
+func (r headerReader) skipCString() (int64, error) {
+	var n int64
+	b := make([]byte, 1)
+	for {
+		if _, err := io.ReadFull(r.file, b); err != nil {
+			return n, errors.Wrap(err, "failed to read gzip header string")
+		}
+		n++
+		if b[0] == 0 {
+			return n, nil
+		}
+	}
+}
+
+func isGzipFile(filename string) bool {
+	file, err := os.Open(filename)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+	magic := make([]byte, 2)
+	if _, err := io.ReadFull(file, magic); err != nil {
+		return false
+	}
+	return magic[0] == 0x1f && magic[1] == 0x8b
+}
@@ -0,0 +1,56 @@
+package dict
+
+import (
+	"context"
+	"os"
+
+	"github.com/gogodjzhu/word-flow/pkg/dict/entity"
+	dict_wordnet "github.com/gogodjzhu/word-flow/pkg/dict/wordnet"
+	log "github.com/sirupsen/logrus"
+)
+
+// thesaurusDict adds the synonyms, antonyms and related words of WordNet to
+// the results of a dictionary that did not provide them.
+type thesaurusDict struct {
+	Dict
+	wordnet *dict_wordnet.DictWordNet
+}
+
+func (t *thesaurusDict) Search(word string) (*entity.WordItem, error) {
+	return t.SearchContext(context.Background(), word)
+}
+
+func (t *thesaurusDict) SearchContext(ctx context.Context, word string) (*entity.WordItem, error) {
+	wordItem, err := Search(ctx, t.Dict, word)
+	if err != nil {
+		return nil, err
+	}
+	// The lookup itself succeeded, a broken WordNet database only loses the extras.
+	if err := t.wordnet.Enrich(wordItem); err != nil {
+		log.Debugf("failed to look %s up in wordnet: %v", word, err)
+	}
+	return wordItem, nil
+}
+
+// SearchReverse runs the reverse lookup of the dictionary, or a plain search
+// when it has none. WordNet has nothing to add to Chinese queries.
+func (t *thesaurusDict) SearchReverse(ctx context.Context, query string) (*entity.WordItem, error) {
+	if rd, ok := t.Dict.(ReverseDict); ok {
+		return rd.SearchReverse(ctx, query)
+	}
+	return t.SearchContext(ctx, query)
+}
+
+func (t *thesaurusDict) Resource(name string) ([]byte, error) {
+	if provider, ok := t.Dict.(ResourceProvider); ok {
+		return provider.Resource(name)
+	}
+	return nil, os.ErrNotExist
+}
+
+func (t *thesaurusDict) MatchPrefix(prefix string) ([]string, error) {
+	if pd, ok := t.Dict.(PrefixDict); ok {
+		return pd.MatchPrefix(prefix)
+	}
+	return nil, ErrNoHeadwords
+}
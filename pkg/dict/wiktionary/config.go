@@ -0,0 +1,36 @@
+package dict_wiktionary
+
+import (
+	"errors"
+	"path/filepath"
+
+	"github.com/gogodjzhu/word-flow/internal/config"
+)
+
+// Config is the dict.wiktionary section.
+type Config struct {
+	DBFilename string `yaml:"db_filename,omitempty"`
+}
+
+// ConfigSection registers Config with the dictionary.
+var ConfigSection = config.EndpointSpec{
+	New: func() config.EndpointConfig { return &Config{} },
+	Defaults: func(c config.EndpointConfig, dir string) {
+		if wiktionary := c.(*Config); wiktionary.DBFilename == "" {
+			wiktionary.DBFilename = filepath.Join(dir, "wiktionary.db")
+		}
+	},
+	Template: `wiktionary:
+  # Offline Wiktionary index, built once from a kaikki.org JSONL extract with:
+  #   wordflow dict install wiktionary --from kaikki.org-dictionary-English.jsonl
+  # Defaults to <WORDFLOW_HOME>/wiktionary.db if empty
+  # db_filename: ""
+`,
+}
+
+func (c *Config) Validate() error {
+	if c.DBFilename == "" {
+		return errors.New("wiktionary.db_filename is required when wiktionary is the default dictionary")
+	}
+	return nil
+}
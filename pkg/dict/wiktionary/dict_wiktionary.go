@@ -0,0 +1,200 @@
+package dict_wiktionary
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+
+	"github.com/gogodjzhu/word-flow/internal/buzz_error"
+	"github.com/gogodjzhu/word-flow/pkg/dict/entity"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+var posNames = map[string]string{
+	"noun":     "n.",
+	"verb":     "v.",
+	"adj":      "adj.",
+	"adv":      "adv.",
+	"prep":     "prep.",
+	"conj":     "conj.",
+	"pron":     "pron.",
+	"intj":     "interj.",
+	"num":      "num.",
+	"det":      "det.",
+	"article":  "art.",
+	"abbrev":   "abbr.",
+	"particle": "particle",
+	"name":     "name",
+	"phrase":   "phrase",
+	"prefix":   "prefix",
+	"suffix":   "suffix",
+}
+
+const (
+	// Part of speech labels of the etymology and inflection meanings.
+	etymologyLabel = "etym."
+	formsLabel     = "forms"
+)
+
+type DictWiktionary struct {
+	db *gorm.DB
+}
+
+func NewDictWiktionary(config *Config) (*DictWiktionary, error) {
+	if config == nil {
+		return nil, errors.New("wiktionary config is required")
+	}
+	if _, err := os.Stat(config.DBFilename); errors.Is(err, os.ErrNotExist) {
+		return nil, errors.Errorf("wiktionary index %s not found. Download a JSONL extract from https://kaikki.org/ and run: wordflow dict install wiktionary --from <file.jsonl>", config.DBFilename)
+	}
+	db, err := openDB(config.DBFilename)
+	if err != nil {
+		return nil, err
+	}
+	return &DictWiktionary{db: db}, nil
+}
+
+func (d *DictWiktionary) Search(word string) (*entity.WordItem, error) {
+	word = strings.TrimSpace(word)
+	if word == "" {
+		return nil, buzz_error.InvalidInput("empty word to search")
+	}
+	var entries []*Entry
+	// Exact spellings first, so "Turkey" does not hide "turkey".
+	err := d.db.Where("key = ?", strings.ToLower(word)).
+		Order(gorm.Expr("word = ? DESC, id", word)).
+		Find(&entries).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query wiktionary index")
+	}
+	if len(entries) == 0 {
+		return nil, buzz_error.InvalidInput("Invalid word: " + word)
+	}
+
+	result := &entity.WordItem{
+		ID:            entity.WordId(entries[0].Word),
+		Word:          entries[0].Word,
+		Source:        "wiktionary",
+		WordPhonetics: make([]*entity.WordPhonetic, 0),
+		WordMeanings:  make([]*entity.WordMeaning, 0),
+	}
+	etymologies := map[string]bool{}
+	for _, entry := range entries {
+		if err := appendEntry(result, entry, etymologies); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// appendEntry maps senses to meanings, IPA to phonetics and adds the
+// etymology and the inflected forms as meanings of their own. Entries of the
+// same etymology share its text, which is only added once.
+func appendEntry(item *entity.WordItem, entry *Entry, etymologies map[string]bool) error {
+	var senses []*Sense
+	var sounds []*Sound
+	var forms []*Form
+	for _, field := range []struct {
+		data string
+		v    interface{}
+	}{{entry.Senses, &senses}, {entry.Sounds, &sounds}, {entry.Forms, &forms}} {
+		if field.data == "" {
+			continue
+		}
+		if err := json.Unmarshal([]byte(field.data), field.v); err != nil {
+			return errors.Wrapf(err, "corrupt wiktionary entry %d, run the install again", entry.ID)
+		}
+	}
+
+	for _, sound := range sounds {
+		if sound.IPA == "" || hasPhonetic(item, sound.IPA) {
+			continue
+		}
+		phonetic := &entity.WordPhonetic{
+			HeadWord: entry.Word,
+			Text:     strings.Trim(sound.IPA, "/[]"),
+		}
+		if len(sound.Tags) > 0 {
+			phonetic.LanguageCode = sound.Tags[0]
+		}
+		item.WordPhonetics = append(item.WordPhonetics, phonetic)
+	}
+	for _, sound := range sounds {
+		audio := sound.Mp3URL
+		if audio == "" {
+			audio = sound.OggURL
+		}
+		if sound.IPA != "" || audio == "" {
+			continue
+		}
+		// Audio comes in separate sounds, attach it to the first phonetic without one.
+		for _, phonetic := range item.WordPhonetics {
+			if phonetic.Audio == "" {
+				phonetic.Audio = audio
+				break
+			}
+		}
+	}
+
+	pos := posNames[entry.Pos]
+	if pos == "" {
+		pos = entry.Pos
+	}
+	for _, sense := range senses {
+		definition := ""
+		if len(sense.RawGlosses) > 0 {
+			definition = strings.Join(sense.RawGlosses, "; ")
+		} else {
+			definition = strings.Join(sense.Glosses, "; ")
+		}
+		if definition == "" {
+			continue
+		}
+		meaning := &entity.WordMeaning{PartOfSpeech: pos, Definitions: definition}
+		for _, example := range sense.Examples {
+			text := strings.TrimSpace(example.Text)
+			if text == "" {
+				continue
+			}
+			if example.English != "" {
+				text += " — " + example.English
+			}
+			meaning.Examples = append(meaning.Examples, text)
+		}
+		item.WordMeanings = append(item.WordMeanings, meaning)
+	}
+
+	if len(forms) > 0 {
+		var parts []string
+		for _, form := range forms {
+			if len(form.Tags) > 0 {
+				parts = append(parts, strings.Join(form.Tags, " ")+": "+form.Form)
+			} else {
+				parts = append(parts, form.Form)
+			}
+		}
+		item.WordMeanings = append(item.WordMeanings, &entity.WordMeaning{
+			PartOfSpeech: formsLabel,
+			Definitions:  pos + " " + strings.Join(parts, "; "),
+		})
+	}
+	if etymology := strings.TrimSpace(entry.Etymology); etymology != "" && !etymologies[etymology] {
+		etymologies[etymology] = true
+		item.WordMeanings = append(item.WordMeanings, &entity.WordMeaning{
+			PartOfSpeech: etymologyLabel,
+			Definitions:  etymology,
+		})
+	}
+	return nil
+}
+
+func hasPhonetic(item *entity.WordItem, ipa string) bool {
+	text := strings.Trim(ipa, "/[]")
+	for _, p := range item.WordPhonetics {
+		if p.Text == text {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,79 @@
+package dict_wiktionary
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const kaikkiJSONL = `{"word": "house", "pos": "noun", "lang_code": "en", "etymology_text": "From Middle English hous, from Old English hūs.", "sounds": [{"ipa": "/haʊs/", "tags": ["UK"]}, {"ipa": "[hʌʊs]", "tags": ["Scotland"]}, {"audio": "en-us-house.ogg", "ogg_url": "https://example.org/house.ogg"}], "forms": [{"form": "houses", "tags": ["plural"]}, {"form": "en-noun", "tags": ["inflection-template"]}], "senses": [{"glosses": ["A structure serving as an abode of human beings."], "examples": [{"text": "This is my house."}]}, {"raw_glosses": ["(astrology) One of the twelve divisions of the sky."], "glosses": ["One of the twelve divisions of the sky."]}]}
+{"word": "house", "pos": "verb", "lang_code": "en", "etymology_text": "From Middle English hous, from Old English hūs.", "sounds": [{"ipa": "/haʊz/"}], "forms": [{"form": "houses", "tags": ["present", "singular", "third-person"]}, {"form": "housed", "tags": ["past"]}], "senses": [{"glosses": ["To keep within a structure."], "examples": [{"text": "Das Haus", "english": "the house"}]}]}
+{"word": "Haus", "pos": "noun", "lang_code": "de", "senses": [{"glosses": ["house"]}]}
+`
+
+func TestDictWiktionary_Search(t *testing.T) {
+	dir := t.TempDir()
+	jsonl := filepath.Join(dir, "kaikki.jsonl")
+	if err := os.WriteFile(jsonl, []byte(kaikkiJSONL), 0644); err != nil {
+		t.Fatal(err)
+	}
+	dbFilename := filepath.Join(dir, "db", "wiktionary.db")
+	n, err := ImportFile(jsonl, dbFilename, ImportOptions{LangCode: "en"})
+	if err != nil || n != 2 {
+		t.Fatalf("ImportFile() = %d, %v, want 2 English entries", n, err)
+	}
+
+	d, err := NewDictWiktionary(&Config{DBFilename: dbFilename})
+	if err != nil {
+		t.Fatalf("NewDictWiktionary() error = %v", err)
+	}
+	got, err := d.Search("House")
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if got.Word != "house" || got.Source != "wiktionary" {
+		t.Errorf("Word = %q, Source = %q", got.Word, got.Source)
+	}
+
+	if len(got.WordPhonetics) != 3 {
+		t.Fatalf("phonetics = %+v, want 3", got.WordPhonetics)
+	}
+	uk := got.WordPhonetics[0]
+	if uk.Text != "haʊs" || uk.LanguageCode != "UK" || uk.Audio != "https://example.org/house.ogg" {
+		t.Errorf("phonetic = %+v", uk)
+	}
+
+	var definitions []string
+	for _, m := range got.WordMeanings {
+		definitions = append(definitions, m.PartOfSpeech+" "+m.Definitions)
+	}
+	want := []string{
+		"n. A structure serving as an abode of human beings.",
+		"n. (astrology) One of the twelve divisions of the sky.",
+		"forms n. plural: houses",
+		"etym. From Middle English hous, from Old English hūs.",
+		"v. To keep within a structure.",
+		"forms v. present singular third-person: houses; past: housed",
+	}
+	if strings.Join(definitions, "\n") != strings.Join(want, "\n") {
+		t.Errorf("meanings:\n%s\nwant:\n%s", strings.Join(definitions, "\n"), strings.Join(want, "\n"))
+	}
+	if examples := got.WordMeanings[0].Examples; len(examples) != 1 || examples[0] != "This is my house." {
+		t.Errorf("examples = %v", examples)
+	}
+	if examples := got.WordMeanings[4].Examples; len(examples) != 1 || examples[0] != "Das Haus — the house" {
+		t.Errorf("translated examples = %v", examples)
+	}
+
+	if _, err := d.Search("haus"); err == nil {
+		t.Error("expected entries of other languages to be skipped")
+	}
+}
+
+func TestNewDictWiktionary_NotInstalled(t *testing.T) {
+	_, err := NewDictWiktionary(&Config{DBFilename: filepath.Join(t.TempDir(), "wiktionary.db")})
+	if err == nil || !strings.Contains(err.Error(), "dict install wiktionary") {
+		t.Errorf("error = %v, want an install hint", err)
+	}
+}
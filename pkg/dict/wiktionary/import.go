@@ -0,0 +1,239 @@
+package dict_wiktionary
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	_ "modernc.org/sqlite"
+)
+
+// kaikkiEntry is the subset of a kaikki.org (wiktextract) JSONL line that is
+// kept, see https://kaikki.org/dictionary/rawdata.html
+type kaikkiEntry struct {
+	Word          string   `json:"word"`
+	Pos           string   `json:"pos"`
+	LangCode      string   `json:"lang_code"`
+	EtymologyText string   `json:"etymology_text"`
+	Senses        []*Sense `json:"senses"`
+	Sounds        []*Sound `json:"sounds"`
+	Forms         []*Form  `json:"forms"`
+}
+
+type Sense struct {
+	Glosses    []string   `json:"glosses,omitempty"`
+	RawGlosses []string   `json:"raw_glosses,omitempty"`
+	Tags       []string   `json:"tags,omitempty"`
+	Examples   []*Example `json:"examples,omitempty"`
+}
+
+type Example struct {
+	Text    string `json:"text"`
+	English string `json:"english,omitempty"`
+}
+
+type Sound struct {
+	IPA    string   `json:"ipa,omitempty"`
+	Tags   []string `json:"tags,omitempty"`
+	Mp3URL string   `json:"mp3_url,omitempty"`
+	OggURL string   `json:"ogg_url,omitempty"`
+}
+
+type Form struct {
+	Form string   `json:"form"`
+	Tags []string `json:"tags,omitempty"`
+}
+
+// Entry is one word and part of speech in the local index. Senses, sounds and
+// forms are stored as JSON since they are only read back together.
+type Entry struct {
+	ID        uint   `gorm:"primaryKey"`
+	Word      string `gorm:"not null"`
+	Key       string `gorm:"index;not null"`
+	LangCode  string `gorm:"index"`
+	Pos       string
+	Etymology string
+	Senses    string
+	Sounds    string
+	Forms     string
+}
+
+func (Entry) TableName() string {
+	return "wiktionary"
+}
+
+const importBatchSize = 500
+
+// ImportOptions controls which kaikki.org entries are imported.
+type ImportOptions struct {
+	// LangCode keeps only entries of this language, e.g. "en". Empty keeps all.
+	LangCode string
+	// Progress is called with the number of imported entries every batch.
+	Progress func(imported int)
+}
+
+// ImportFile imports a kaikki.org JSONL file (optionally gzipped) into a new
+// SQLite index at dbFilename. The index is built next to dbFilename and only
+// replaces it once the import succeeded.
+func ImportFile(jsonlFilename, dbFilename string, opts ImportOptions) (int, error) {
+	f, err := os.Open(jsonlFilename)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to open %s", jsonlFilename)
+	}
+	defer f.Close()
+	var r io.Reader = bufio.NewReaderSize(f, 1<<20)
+	if strings.HasSuffix(strings.ToLower(jsonlFilename), ".gz") {
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return 0, errors.Wrapf(err, "failed to read gzip file %s", jsonlFilename)
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dbFilename), 0755); err != nil {
+		return 0, errors.Wrap(err, "failed to create wiktionary directory")
+	}
+	tmpFilename := dbFilename + ".importing"
+	_ = os.Remove(tmpFilename)
+	n, err := importInto(r, tmpFilename, opts)
+	if err != nil {
+		_ = os.Remove(tmpFilename)
+		return 0, err
+	}
+	if err := os.Rename(tmpFilename, dbFilename); err != nil {
+		return 0, errors.Wrap(err, "failed to move wiktionary index into place")
+	}
+	return n, nil
+}
+
+func importInto(r io.Reader, dbFilename string, opts ImportOptions) (int, error) {
+	db, err := openDB(dbFilename)
+	if err != nil {
+		return 0, err
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to open wiktionary index")
+	}
+	defer sqlDB.Close()
+	// The file is discarded if the import fails, so durability is not needed.
+	db.Exec("PRAGMA synchronous = OFF")
+	db.Exec("PRAGMA journal_mode = OFF")
+	if err := db.AutoMigrate(&Entry{}); err != nil {
+		return 0, errors.Wrap(err, "failed to create wiktionary index")
+	}
+
+	decoder := json.NewDecoder(r)
+	batch := make([]*Entry, 0, importBatchSize)
+	imported := 0
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := db.CreateInBatches(batch, importBatchSize).Error; err != nil {
+			return errors.Wrap(err, "failed to write wiktionary entries")
+		}
+		imported += len(batch)
+		batch = batch[:0]
+		if opts.Progress != nil {
+			opts.Progress(imported)
+		}
+		return nil
+	}
+	for line := 1; ; line++ {
+		var raw kaikkiEntry
+		if err := decoder.Decode(&raw); err == io.EOF {
+			break
+		} else if err != nil {
+			return 0, errors.Wrapf(err, "invalid kaikki.org JSON at entry %d", line)
+		}
+		if raw.Word == "" || (opts.LangCode != "" && raw.LangCode != opts.LangCode) {
+			continue
+		}
+		entry, err := newEntry(&raw)
+		if err != nil {
+			return 0, err
+		}
+		batch = append(batch, entry)
+		if len(batch) == importBatchSize {
+			if err := flush(); err != nil {
+				return 0, err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return 0, err
+	}
+	if imported == 0 {
+		return 0, errors.New("no wiktionary entries found, is this a kaikki.org JSONL file?")
+	}
+	return imported, nil
+}
+
+func newEntry(raw *kaikkiEntry) (*Entry, error) {
+	senses, err := json.Marshal(raw.Senses)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encode senses")
+	}
+	var sounds []*Sound
+	for _, s := range raw.Sounds {
+		if s.IPA != "" || s.Mp3URL != "" || s.OggURL != "" {
+			sounds = append(sounds, s)
+		}
+	}
+	soundsJSON, err := json.Marshal(sounds)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encode sounds")
+	}
+	var forms []*Form
+	for _, f := range raw.Forms {
+		// Skip table headers and inflection class markers.
+		if f.Form == "" || f.Form == raw.Word || containsTag(f.Tags, "table-tags") || containsTag(f.Tags, "inflection-template") {
+			continue
+		}
+		forms = append(forms, f)
+	}
+	formsJSON, err := json.Marshal(forms)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encode forms")
+	}
+	return &Entry{
+		Word:      raw.Word,
+		Key:       strings.ToLower(raw.Word),
+		LangCode:  raw.LangCode,
+		Pos:       raw.Pos,
+		Etymology: raw.EtymologyText,
+		Senses:    string(senses),
+		Sounds:    string(soundsJSON),
+		Forms:     string(formsJSON),
+	}, nil
+}
+
+func openDB(dbFilename string) (*gorm.DB, error) {
+	db, err := gorm.Open(sqlite.New(sqlite.Config{DriverName: "sqlite", DSN: dbFilename}), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open db file")
+	}
+	return db, nil
+}
+
+func containsTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
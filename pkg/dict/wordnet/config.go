@@ -0,0 +1,29 @@
+package dict_wordnet
+
+import (
+	"errors"
+
+	"github.com/gogodjzhu/word-flow/internal/config"
+)
+
+// Config is the dict.wordnet section.
+type Config struct {
+	Dir string `yaml:"dir,omitempty"`
+}
+
+// ConfigSection registers Config with the dictionary.
+var ConfigSection = config.EndpointSpec{
+	New: func() config.EndpointConfig { return &Config{} },
+	Template: `wordnet:
+  # WordNet 3.x database directory (index.noun, data.noun, ...). When set, synonyms, antonyms
+  # and related words are also filled in for the other dictionaries. Empty disables WordNet
+  # dir: ""
+`,
+}
+
+func (c *Config) Validate() error {
+	if c.Dir == "" {
+		return errors.New("wordnet.dir is required when wordnet is the default dictionary. Set it via: wordflow config set dict.wordnet.dir <dir>")
+	}
+	return nil
+}
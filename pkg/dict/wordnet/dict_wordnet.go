@@ -0,0 +1,103 @@
+package dict_wordnet
+
+import (
+	"strings"
+
+	"github.com/gogodjzhu/word-flow/internal/buzz_error"
+	"github.com/gogodjzhu/word-flow/pkg/dict/entity"
+	"github.com/pkg/errors"
+)
+
+var posNames = map[string]string{
+	"n": "n.",
+	"v": "v.",
+	"a": "adj.",
+	"s": "adj.",
+	"r": "adv.",
+}
+
+type DictWordNet struct {
+	db *Database
+}
+
+func NewDictWordNet(config *Config) (*DictWordNet, error) {
+	if config == nil || config.Dir == "" {
+		return nil, errors.New("wordnet config with dir is required")
+	}
+	db, err := Open(config.Dir)
+	if err != nil {
+		return nil, err
+	}
+	return &DictWordNet{db: db}, nil
+}
+
+func (d *DictWordNet) Search(word string) (*entity.WordItem, error) {
+	word = strings.TrimSpace(word)
+	synsets, err := d.db.Lookup(word)
+	if err != nil {
+		return nil, err
+	}
+	if len(synsets) == 0 {
+		return nil, buzz_error.InvalidInput("Invalid word: " + word)
+	}
+	result := &entity.WordItem{
+		ID:            entity.WordId(word),
+		Word:          word,
+		Source:        "wordnet",
+		WordPhonetics: make([]*entity.WordPhonetic, 0),
+		WordMeanings:  make([]*entity.WordMeaning, 0),
+	}
+	for _, synset := range synsets {
+		definition, examples := splitGloss(synset.Gloss)
+		result.WordMeanings = append(result.WordMeanings, &entity.WordMeaning{
+			PartOfSpeech: posNames[synset.Pos],
+			Definitions:  definition,
+			Examples:     examples,
+		})
+	}
+	result.Synonyms, result.Antonyms, result.Related, err = d.db.Relations(word, synsets)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Enrich fills the synonyms, antonyms and related words of an item from
+// WordNet, keeping those the dictionary already provided.
+func (d *DictWordNet) Enrich(item *entity.WordItem) error {
+	if len(item.Synonyms) > 0 && len(item.Antonyms) > 0 && len(item.Related) > 0 {
+		return nil
+	}
+	synsets, err := d.db.Lookup(item.Word)
+	if err != nil || len(synsets) == 0 {
+		return err
+	}
+	synonyms, antonyms, related, err := d.db.Relations(item.Word, synsets)
+	if err != nil {
+		return err
+	}
+	if len(item.Synonyms) == 0 {
+		item.Synonyms = synonyms
+	}
+	if len(item.Antonyms) == 0 {
+		item.Antonyms = antonyms
+	}
+	if len(item.Related) == 0 {
+		item.Related = related
+	}
+	return nil
+}
+
+// splitGloss splits a gloss like `a dwelling; "he built a house"` into the
+// definition and its quoted examples.
+func splitGloss(gloss string) (string, []string) {
+	parts := strings.Split(gloss, "; \"")
+	var examples []string
+	for _, part := range parts[1:] {
+		example := strings.TrimSpace(strings.TrimRight(strings.TrimSpace(part), ";"))
+		if example = strings.Trim(example, "\""); example != "" {
+			examples = append(examples, example)
+		}
+	}
+	return strings.TrimSpace(parts[0]), examples
+}
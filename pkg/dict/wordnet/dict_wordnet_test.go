@@ -0,0 +1,159 @@
+package dict_wordnet
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gogodjzhu/word-flow/pkg/dict/entity"
+)
+
+type testSynset struct {
+	id       string
+	file     string
+	pos      string
+	words    []string
+	pointers [][3]string // symbol, target id, source/target
+	gloss    string
+}
+
+var testSynsets = []testSynset{
+	{id: "house", file: "noun", pos: "n", words: []string{"house"},
+		pointers: [][3]string{{"@", "building", "0000"}},
+		gloss:    `a dwelling that serves as living quarters for one or more families; "he has a house on the cape"`},
+	{id: "building", file: "noun", pos: "n", words: []string{"building", "edifice"},
+		gloss: "a structure that has a roof and walls"},
+	{id: "goodness", file: "noun", pos: "n", words: []string{"good", "goodness"},
+		gloss: "moral excellence or admirableness"},
+	{id: "good", file: "adj", pos: "a", words: []string{"good"},
+		pointers: [][3]string{{"!", "bad", "0101"}, {"&", "estimable", "0000"}},
+		gloss:    `having desirable or positive qualities; "a good report card"; "good news"`},
+	{id: "bad", file: "adj", pos: "a", words: []string{"bad"},
+		pointers: [][3]string{{"!", "good", "0101"}},
+		gloss:    "having undesirable or negative qualities"},
+	{id: "estimable", file: "adj", pos: "s", words: []string{"estimable", "good(p)", "honorable"},
+		pointers: [][3]string{{"&", "good", "0000"}},
+		gloss:    "deserving of esteem and respect"},
+}
+
+var testIndex = map[string][]string{
+	"noun": {"building n 1 0 1 0 @building", "good n 1 0 1 0 @goodness", "house n 1 1 @ 1 0 @house"},
+	"adj":  {"bad a 1 1 ! 1 0 @bad", "good a 2 2 ! & 2 0 @good @estimable"},
+}
+
+// writeWordNet writes a WordNet database with byte offsets computed from the
+// rendered lines, references are written as @id.
+func writeWordNet(t *testing.T, dir string) {
+	t.Helper()
+	offsets := map[string]int{}
+	render := func(file string) string {
+		var b strings.Builder
+		b.WriteString("  1 This software and database is being provided to you, the LICENSEE\n")
+		for _, s := range testSynsets {
+			if s.file != file {
+				continue
+			}
+			offsets[s.id] = b.Len()
+			words := make([]string, 0, len(s.words))
+			for _, w := range s.words {
+				words = append(words, w+" 0")
+			}
+			pointers := make([]string, 0, len(s.pointers))
+			for _, p := range s.pointers {
+				target := testSynsets[0]
+				for _, ts := range testSynsets {
+					if ts.id == p[1] {
+						target = ts
+					}
+				}
+				pointers = append(pointers, fmt.Sprintf("%s %08d %s %s", p[0], offsets[p[1]], target.pos, p[2]))
+			}
+			fmt.Fprintf(&b, "%08d 00 %s %02x %s %03d %s | %s  \n", offsets[s.id], s.pos, len(s.words),
+				strings.Join(words, " "), len(s.pointers), strings.Join(pointers, " "), s.gloss)
+		}
+		return b.String()
+	}
+	// The first pass finds the offsets, which all have the same width.
+	for _, file := range []string{"noun", "adj"} {
+		render(file)
+	}
+	for _, file := range []string{"noun", "adj"} {
+		if err := os.WriteFile(filepath.Join(dir, "data."+file), []byte(render(file)), 0644); err != nil {
+			t.Fatal(err)
+		}
+		var index strings.Builder
+		for _, line := range testIndex[file] {
+			fields := strings.Fields(line)
+			for i, f := range fields {
+				if strings.HasPrefix(f, "@") {
+					fields[i] = fmt.Sprintf("%08d", offsets[f[1:]])
+				}
+			}
+			index.WriteString(strings.Join(fields, " ") + "  \n")
+		}
+		if err := os.WriteFile(filepath.Join(dir, "index."+file), []byte(index.String()), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestDictWordNet_Search(t *testing.T) {
+	dir := t.TempDir()
+	writeWordNet(t, dir)
+	d, err := NewDictWordNet(&Config{Dir: dir})
+	if err != nil {
+		t.Fatalf("NewDictWordNet() error = %v", err)
+	}
+
+	got, err := d.Search("good")
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if len(got.WordMeanings) != 3 || got.WordMeanings[0].PartOfSpeech != "n." || got.WordMeanings[1].PartOfSpeech != "adj." {
+		t.Fatalf("meanings = %+v", got.WordMeanings)
+	}
+	adj := got.WordMeanings[1]
+	if adj.Definitions != "having desirable or positive qualities" || strings.Join(adj.Examples, "|") != "a good report card|good news" {
+		t.Errorf("adj meaning = %+v", adj)
+	}
+	if strings.Join(got.Synonyms, ",") != "goodness,estimable,honorable" {
+		t.Errorf("Synonyms = %v", got.Synonyms)
+	}
+	if strings.Join(got.Antonyms, ",") != "bad" {
+		t.Errorf("Antonyms = %v", got.Antonyms)
+	}
+
+	house, err := d.Search("House")
+	if err != nil {
+		t.Fatalf("Search(House) error = %v", err)
+	}
+	if strings.Join(house.Related, ",") != "building,edifice" || len(house.Synonyms) != 0 {
+		t.Errorf("house synonyms = %v, related = %v", house.Synonyms, house.Related)
+	}
+
+	if _, err := d.Search("missing"); err == nil {
+		t.Error("expected error for unknown word")
+	}
+}
+
+func TestDictWordNet_Enrich(t *testing.T) {
+	dir := t.TempDir()
+	writeWordNet(t, dir)
+	d, err := NewDictWordNet(&Config{Dir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	item := &entity.WordItem{Word: "bad", Synonyms: []string{"poor"}}
+	if err := d.Enrich(item); err != nil {
+		t.Fatalf("Enrich() error = %v", err)
+	}
+	if strings.Join(item.Synonyms, ",") != "poor" || strings.Join(item.Antonyms, ",") != "good" {
+		t.Errorf("Synonyms = %v, Antonyms = %v", item.Synonyms, item.Antonyms)
+	}
+
+	if _, err := NewDictWordNet(&Config{Dir: t.TempDir()}); err == nil {
+		t.Error("expected error for a directory without WordNet files")
+	}
+}
@@ -0,0 +1,307 @@
+package dict_wordnet
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Parts of speech in the order synsets are returned, with the file suffix of
+// their index.* and data.* files.
+var partsOfSpeech = []struct {
+	pos  string
+	file string
+}{{"n", "noun"}, {"v", "verb"}, {"a", "adj"}, {"r", "adv"}}
+
+// Pointer symbols whose targets are reported as related words: similar to,
+// also see, derivationally related form and hypernym.
+var relatedPointers = map[string]bool{"&": true, "^": true, "+": true, "@": true}
+
+const antonymPointer = "!"
+
+// Synset is a set of synonyms read from a WordNet data.* file, see
+// https://wordnet.princeton.edu/documentation/wndb5wn
+type Synset struct {
+	Offset   int64
+	Pos      string
+	Words    []string
+	Pointers []*Pointer
+	Gloss    string
+}
+
+// Pointer links a synset, or one of its words when Source is not 0, to a
+// word or synset of another.
+type Pointer struct {
+	Symbol string
+	Offset int64
+	Pos    string
+	Source int
+	Target int
+}
+
+// Database reads a WordNet 3.x database directory (index.noun, data.noun and
+// so on). Index files are loaded on the first lookup, synsets are read from the
+// data files by offset.
+type Database struct {
+	dir   string
+	once  sync.Once
+	err   error
+	index map[string]map[string][]int64
+}
+
+func Open(dir string) (*Database, error) {
+	if _, err := os.Stat(filepath.Join(dir, "data.noun")); err != nil {
+		return nil, errors.Errorf("no WordNet database in %s, download the database files from https://wordnet.princeton.edu/download", dir)
+	}
+	return &Database{dir: dir}, nil
+}
+
+// Lookup returns the synsets of the word, nouns first.
+func (db *Database) Lookup(word string) ([]*Synset, error) {
+	db.once.Do(func() {
+		db.err = db.loadIndex()
+	})
+	if db.err != nil {
+		return nil, db.err
+	}
+	lemma := Lemma(word)
+	var synsets []*Synset
+	for _, p := range partsOfSpeech {
+		offsets := db.index[p.pos][lemma]
+		if len(offsets) == 0 {
+			continue
+		}
+		read, err := db.readSynsets(p.file, offsets)
+		if err != nil {
+			return nil, err
+		}
+		synsets = append(synsets, read...)
+	}
+	return synsets, nil
+}
+
+// Relations collects the synonyms, antonyms and related words of word from
+// its synsets.
+func (db *Database) Relations(word string, synsets []*Synset) (synonyms, antonyms, related []string, err error) {
+	lemma := Lemma(word)
+	seen := map[string]bool{lemma: true}
+	add := func(list []string, w string) []string {
+		key := Lemma(w)
+		if seen[key] {
+			return list
+		}
+		seen[key] = true
+		return append(list, DisplayWord(w))
+	}
+	for _, synset := range synsets {
+		for _, w := range synset.Words {
+			synonyms = add(synonyms, w)
+		}
+	}
+	for _, synset := range synsets {
+		for _, ptr := range synset.Pointers {
+			if ptr.Symbol != antonymPointer && !relatedPointers[ptr.Symbol] {
+				continue
+			}
+			// Lexical pointers only apply to the word they start from.
+			if ptr.Source > 0 && (ptr.Source > len(synset.Words) || Lemma(synset.Words[ptr.Source-1]) != lemma) {
+				continue
+			}
+			target, err := db.readSynset(fileOf(ptr.Pos), ptr.Offset)
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			words := target.Words
+			if ptr.Target > 0 && ptr.Target <= len(words) {
+				words = words[ptr.Target-1 : ptr.Target]
+			}
+			for _, w := range words {
+				if ptr.Symbol == antonymPointer {
+					antonyms = add(antonyms, w)
+				} else {
+					related = add(related, w)
+				}
+			}
+		}
+	}
+	return synonyms, antonyms, related, nil
+}
+
+func (db *Database) loadIndex() error {
+	db.index = make(map[string]map[string][]int64)
+	for _, p := range partsOfSpeech {
+		entries := make(map[string][]int64)
+		db.index[p.pos] = entries
+		f, err := os.Open(filepath.Join(db.dir, "index."+p.file))
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return errors.Wrap(err, "failed to open WordNet index")
+		}
+		scanner := bufio.NewScanner(f)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			lemma, offsets, ok := parseIndexLine(scanner.Text())
+			if ok {
+				entries[lemma] = offsets
+			}
+		}
+		err = scanner.Err()
+		_ = f.Close()
+		if err != nil {
+			return errors.Wrapf(err, "failed to read index.%s", p.file)
+		}
+	}
+	return nil
+}
+
+// parseIndexLine parses "lemma pos synset_cnt p_cnt [ptr_symbol...]
+// sense_cnt tagsense_cnt synset_offset [synset_offset...]".
+func parseIndexLine(line string) (string, []int64, bool) {
+	// License lines start with spaces.
+	if line == "" || line[0] == ' ' {
+		return "", nil, false
+	}
+	fields := strings.Fields(line)
+	if len(fields) < 6 {
+		return "", nil, false
+	}
+	synsetCount, err := strconv.Atoi(fields[2])
+	if err != nil || synsetCount <= 0 || synsetCount > len(fields)-4 {
+		return "", nil, false
+	}
+	offsets := make([]int64, 0, synsetCount)
+	for _, field := range fields[len(fields)-synsetCount:] {
+		offset, err := strconv.ParseInt(field, 10, 64)
+		if err != nil {
+			return "", nil, false
+		}
+		offsets = append(offsets, offset)
+	}
+	return fields[0], offsets, true
+}
+
+func (db *Database) readSynsets(file string, offsets []int64) ([]*Synset, error) {
+	f, err := os.Open(filepath.Join(db.dir, "data."+file))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open WordNet data")
+	}
+	defer f.Close()
+	synsets := make([]*Synset, 0, len(offsets))
+	for _, offset := range offsets {
+		synset, err := readSynsetAt(f, offset)
+		if err != nil {
+			return nil, err
+		}
+		synsets = append(synsets, synset)
+	}
+	return synsets, nil
+}
+
+func (db *Database) readSynset(file string, offset int64) (*Synset, error) {
+	synsets, err := db.readSynsets(file, []int64{offset})
+	if err != nil {
+		return nil, err
+	}
+	return synsets[0], nil
+}
+
+func readSynsetAt(f *os.File, offset int64) (*Synset, error) {
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return nil, errors.Wrap(err, "failed to seek WordNet data")
+	}
+	line, err := bufio.NewReader(f).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return nil, errors.Wrap(err, "failed to read WordNet data")
+	}
+	synset, err := parseDataLine(line)
+	if err != nil || synset.Offset != offset {
+		return nil, errors.Errorf("corrupt WordNet data at offset %d in %s", offset, f.Name())
+	}
+	return synset, nil
+}
+
+// parseDataLine parses "synset_offset lex_filenum ss_type w_cnt word lex_id
+// [word lex_id...] p_cnt [ptr...] [frames...] | gloss".
+func parseDataLine(line string) (*Synset, error) {
+	data, gloss, _ := strings.Cut(line, "|")
+	fields := strings.Fields(data)
+	if len(fields) < 5 {
+		return nil, errors.New("too few fields")
+	}
+	offset, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	synset := &Synset{Offset: offset, Pos: fields[2], Gloss: strings.TrimSpace(gloss)}
+	wordCount, err := strconv.ParseInt(fields[3], 16, 32)
+	if err != nil {
+		return nil, err
+	}
+	i := 4
+	for n := 0; n < int(wordCount) && i+1 < len(fields); n++ {
+		synset.Words = append(synset.Words, fields[i])
+		i += 2
+	}
+	if i >= len(fields) {
+		return synset, nil
+	}
+	pointerCount, err := strconv.Atoi(fields[i])
+	if err != nil {
+		return nil, err
+	}
+	i++
+	for n := 0; n < pointerCount && i+3 < len(fields); n++ {
+		ptrOffset, err := strconv.ParseInt(fields[i+1], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		sourceTarget, err := strconv.ParseUint(fields[i+3], 16, 16)
+		if err != nil {
+			return nil, err
+		}
+		synset.Pointers = append(synset.Pointers, &Pointer{
+			Symbol: fields[i],
+			Offset: ptrOffset,
+			Pos:    fields[i+2],
+			Source: int(sourceTarget >> 8),
+			Target: int(sourceTarget & 0xff),
+		})
+		i += 4
+	}
+	return synset, nil
+}
+
+func fileOf(pos string) string {
+	switch pos {
+	case "n":
+		return "noun"
+	case "v":
+		return "verb"
+	case "r":
+		return "adv"
+	default:
+		return "adj"
+	}
+}
+
+// Lemma returns the WordNet spelling of a word: lower case with underscores
+// for spaces.
+func Lemma(word string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(DisplayWord(word))), " ", "_")
+}
+
+// DisplayWord undoes the WordNet spelling of a word and drops adjective
+// markers such as "(a)".
+func DisplayWord(word string) string {
+	if i := strings.IndexByte(word, '('); i > 0 && strings.HasSuffix(word, ")") {
+		word = word[:i]
+	}
+	return strings.ReplaceAll(word, "_", " ")
+}
@@ -0,0 +1,36 @@
+package dict_youdao
+
+import (
+	"github.com/gogodjzhu/word-flow/internal/config"
+)
+
+// Config is the dict.youdao section.
+type Config struct {
+	Timeout config.Duration    `yaml:"timeout,omitempty"`
+	HTTP    *config.HTTPConfig `yaml:"http,omitempty"`
+}
+
+// ConfigSection registers Config with the dictionary.
+var ConfigSection = config.EndpointSpec{
+	New: func() config.EndpointConfig { return &Config{} },
+	Defaults: func(c config.EndpointConfig, dir string) {
+		youdao := c.(*Config)
+		config.DefaultTimeout(&youdao.Timeout, config.OnlineTimeout)
+		config.DefaultHTTP(&youdao.HTTP, 0)
+	},
+	Template: `youdao:
+  timeout: 10s            # Request timeout
+  # Retries, rate limiting and circuit breaking, available in every online endpoint section
+  # http:
+  #   retries: 2          # Retries of failed requests (network errors, 429 and 5xx), -1 disables them
+  #   backoff: 500ms      # Delay before the first retry, doubled for every further one. Retry-After is honoured
+  #   rate_limit: 0       # Requests per second to the host, 0 is unlimited
+  #   burst: 1            # Requests allowed at once before rate_limit applies
+  #   breaker_failures: 5 # Failed requests in a row that open the circuit and skip to the fallback, -1 disables it
+  #   breaker_cooldown: 30s
+`,
+}
+
+func (c *Config) Validate() error {
+	return nil
+}
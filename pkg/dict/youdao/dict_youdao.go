@@ -1,14 +1,15 @@
 package dict_youdao
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	neturl "net/url"
 	"strings"
+	"time"
 
 	"github.com/PuerkitoBio/goquery"
 	"github.com/gogodjzhu/word-flow/internal/buzz_error"
-	"github.com/gogodjzhu/word-flow/internal/config"
 	"github.com/gogodjzhu/word-flow/internal/util"
 	"github.com/gogodjzhu/word-flow/pkg/dict/entity"
 	"github.com/pkg/errors"
@@ -17,17 +18,31 @@ import (
 const Host = "https://dict.youdao.com"
 
 type DictYoudao struct {
+	timeout time.Duration
+	policy  *util.Policy
 }
 
-func NewDictYoudao(config *config.YoudaoConfig) (*DictYoudao, error) {
-	return &DictYoudao{}, nil
+func NewDictYoudao(config *Config) (*DictYoudao, error) {
+	d := &DictYoudao{}
+	if config != nil {
+		d.timeout = time.Duration(config.Timeout)
+		d.policy = util.PolicyFromConfig(config.HTTP)
+	}
+	return d, nil
 }
 
 func (d *DictYoudao) Search(word string) (*entity.WordItem, error) {
+	return d.SearchContext(context.Background(), word)
+}
+
+func (d *DictYoudao) SearchContext(ctx context.Context, word string) (*entity.WordItem, error) {
+	ctx, cancel := util.ContextWithTimeout(ctx, d.timeout)
+	defer cancel()
+	ctx = util.WithEndpoint(util.WithPolicy(ctx, d.policy), "dict.youdao")
 	params := neturl.Values{}
 	params.Add("q", word)
 	url := Host + "/search?" + params.Encode()
-	result, err := util.SendGet(url, nil, func(response *http.Response) (interface{}, error) {
+	result, err := util.SendGetContext(ctx, url, nil, func(response *http.Response) (interface{}, error) {
 		if response.StatusCode != 200 {
 			return nil, errors.New("failed to sendGet")
 		}
@@ -101,6 +116,12 @@ func (d *DictYoudao) Search(word string) (*entity.WordItem, error) {
 	return result.(*entity.WordItem), nil
 }
 
+// SearchReverse looks up a Chinese query, which Youdao answers with its
+// English words like any other search.
+func (d *DictYoudao) SearchReverse(ctx context.Context, query string) (*entity.WordItem, error) {
+	return d.SearchContext(ctx, query)
+}
+
 func formatPhonetic(word, enPhonetic, usPhonetic string) []*entity.WordPhonetic {
 	if strings.Contains(usPhonetic, "英") {
 		enPhonetic, usPhonetic = usPhonetic, enPhonetic
@@ -0,0 +1,77 @@
+package dict_youdao
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/gogodjzhu/word-flow/internal/cassette"
+)
+
+func TestDictYoudao_Search(t *testing.T) {
+	c := cassette.Load(t, "youdao")
+	d, err := NewDictYoudao(&Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		word      string
+		wantErr   string
+		meanings  []string // part of speech and definitions joined by "|"
+		phonetics string   // en and us joined by ","
+		examples  int
+	}{
+		{
+			word: "hello",
+			meanings: []string{
+				"int.|喂，你好（用于问候或打招呼）；喂，你好（打电话时的招呼语）",
+				"n.|招呼，问候；（Hello）（法）埃洛（人名）",
+				"v.|说（或大声说）“喂”；打招呼",
+			},
+			phonetics: "həˈləʊ,həˈloʊ",
+			examples:  2,
+		},
+		{
+			word:      "你好",
+			meanings:  []string{"|hello; hi; how do you do"},
+			phonetics: ",",
+		},
+		{
+			word:    "qwzxv",
+			wantErr: "Invalid word: qwzxv",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.word, func(t *testing.T) {
+			got, err := d.SearchContext(c.Context(context.Background()), tt.word)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("SearchContext() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SearchContext() error = %v", err)
+			}
+			if got.Word != tt.word || got.Source != "youdao" {
+				t.Errorf("word = %q, source = %q", got.Word, got.Source)
+			}
+			var meanings []string
+			for _, m := range got.WordMeanings {
+				meanings = append(meanings, m.PartOfSpeech+"|"+m.Definitions)
+			}
+			if strings.Join(meanings, "\n") != strings.Join(tt.meanings, "\n") {
+				t.Errorf("meanings = %q, want %q", meanings, tt.meanings)
+			}
+			if len(got.WordPhonetics) != 2 {
+				t.Fatalf("phonetics = %+v", got.WordPhonetics)
+			}
+			if phonetics := got.WordPhonetics[0].Text + "," + got.WordPhonetics[1].Text; phonetics != tt.phonetics {
+				t.Errorf("phonetics = %q, want %q", phonetics, tt.phonetics)
+			}
+			if len(got.Examples) != tt.examples {
+				t.Errorf("examples = %q, want %d", got.Examples, tt.examples)
+			}
+		})
+	}
+}
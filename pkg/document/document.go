@@ -0,0 +1,150 @@
+// Package document translates documents with their structure: only the prose
+// of a document goes through the translator, markup and code are kept.
+package document
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/gogodjzhu/word-flow/pkg/translator"
+)
+
+// TranslateFunc translates a piece of prose of a document.
+type TranslateFunc func(ctx context.Context, text string) (string, error)
+
+// Translator returns the TranslateFunc of t, translating each piece without
+// streaming.
+func Translator(t translator.Translator, opts *translator.TransOptions) TranslateFunc {
+	return func(ctx context.Context, text string) (string, error) {
+		pieceOpts := translator.TransOptions{NoStream: true}
+		if opts != nil {
+			pieceOpts.From, pieceOpts.To = opts.From, opts.To
+		}
+		var buf bytes.Buffer
+		if err := translator.Translate(ctx, t, text, &buf, &pieceOpts); err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(buf.String()), nil
+	}
+}
+
+// Options of a document translation.
+type Options struct {
+	// Bilingual keeps the original text next to its translation, in the
+	// formats that support it.
+	Bilingual bool
+	// Force translates the entries of resource files that already have a
+	// translation.
+	Force bool
+	// Previous is an earlier translation of a JSON or YAML resource file,
+	// whose translations are kept.
+	Previous []byte
+	// Mismatch is called with the entries of resource files whose
+	// translation does not keep their placeholders.
+	Mismatch func(Mismatch)
+}
+
+// Format is a document format.
+type Format struct {
+	Name        string
+	Description string
+	// Extensions are the file extensions of the format, with the dot.
+	Extensions []string
+	Translate  func(ctx context.Context, src []byte, translate TranslateFunc, opts Options) ([]byte, error)
+}
+
+var formats = []Format{
+	{
+		Name:        "markdown",
+		Description: "Markdown and GitHub Flavored Markdown, code, URLs and front matter are kept.",
+		Extensions:  []string{".md", ".markdown"},
+		Translate:   TranslateMarkdown,
+	},
+	{
+		Name:        "srt",
+		Description: "SubRip subtitles, numbering and timing are kept.",
+		Extensions:  []string{".srt"},
+		Translate:   TranslateSubtitles,
+	},
+	{
+		Name:        "vtt",
+		Description: "WebVTT subtitles, identifiers, timing and cue settings are kept.",
+		Extensions:  []string{".vtt"},
+		Translate:   TranslateSubtitles,
+	},
+	{
+		Name:        "html",
+		Description: "HTML pages, into bilingual pages with each paragraph followed by its translation.",
+		Extensions:  []string{".html", ".htm", ".xhtml"},
+		Translate:   TranslateHTML,
+	},
+	{
+		Name:        "epub",
+		Description: "EPUB books, into bilingual books with each paragraph followed by its translation.",
+		Extensions:  []string{".epub"},
+		Translate:   TranslateEPUB,
+	},
+	{
+		Name:        "i18n-json",
+		Description: "JSON resource files, values are translated and keys and placeholders kept.",
+		Extensions:  []string{".json"},
+		Translate:   TranslateJSON,
+	},
+	{
+		Name:        "i18n-yaml",
+		Description: "YAML resource files, values are translated and keys, comments and placeholders kept.",
+		Extensions:  []string{".yaml", ".yml"},
+		Translate:   TranslateYAML,
+	},
+	{
+		Name:        "po",
+		Description: "gettext PO and POT files, untranslated messages are translated and placeholders kept.",
+		Extensions:  []string{".po", ".pot"},
+		Translate:   TranslatePO,
+	},
+}
+
+// Formats returns the supported formats.
+func Formats() []Format {
+	return append([]Format(nil), formats...)
+}
+
+// Names returns the names of the supported formats.
+func Names() []string {
+	names := make([]string, len(formats))
+	for i, f := range formats {
+		names[i] = f.Name
+	}
+	return names
+}
+
+// Lookup returns the format of a name.
+func Lookup(name string) (Format, bool) {
+	for _, f := range formats {
+		if strings.EqualFold(f.Name, name) {
+			return f, true
+		}
+	}
+	return Format{}, false
+}
+
+// ForFile returns the format of a file name by its extension.
+func ForFile(filename string) (Format, bool) {
+	ext := strings.ToLower(filepath.Ext(filename))
+	for _, f := range formats {
+		for _, e := range f.Extensions {
+			if e == ext {
+				return f, true
+			}
+		}
+	}
+	return Format{}, false
+}
+
+// hasLetters reports whether text has something to translate.
+func hasLetters(text string) bool {
+	return strings.IndexFunc(text, unicode.IsLetter) >= 0
+}
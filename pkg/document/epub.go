@@ -0,0 +1,182 @@
+package document
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"io"
+	"net/url"
+	"path"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	epubContainer = "META-INF/container.xml"
+	epubMimetype  = "mimetype"
+	xhtmlMedia    = "application/xhtml+xml"
+)
+
+type epubContainerXML struct {
+	Rootfiles []struct {
+		FullPath string `xml:"full-path,attr"`
+	} `xml:"rootfiles>rootfile"`
+}
+
+type epubPackage struct {
+	Items []struct {
+		ID        string `xml:"id,attr"`
+		Href      string `xml:"href,attr"`
+		MediaType string `xml:"media-type,attr"`
+	} `xml:"manifest>item"`
+	Itemrefs []struct {
+		IDRef string `xml:"idref,attr"`
+	} `xml:"spine>itemref"`
+}
+
+// TranslateEPUB translates the XHTML documents of the spine of an EPUB book
+// like TranslateHTML, into a bilingual book. The other files of the book are
+// copied as they are.
+func TranslateEPUB(ctx context.Context, src []byte, translate TranslateFunc, _ Options) ([]byte, error) {
+	book, err := zip.NewReader(bytes.NewReader(src), int64(len(src)))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open EPUB")
+	}
+	spine, err := epubSpine(book)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	// The mimetype comes first and uncompressed, for the book to be told
+	// apart from other zip files.
+	files := append([]*zip.File(nil), book.File...)
+	sort.SliceStable(files, func(i, j int) bool {
+		return files[i].Name == epubMimetype && files[j].Name != epubMimetype
+	})
+	for _, f := range files {
+		if f.Name == epubMimetype {
+			if err := storeZipFile(w, f); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if !spine[f.Name] {
+			if err := copyZipFile(w, f); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		content, err := readZipFile(f)
+		if err != nil {
+			return nil, err
+		}
+		translated, err := translateHTML(ctx, content, translate, true)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to translate %s", f.Name)
+		}
+		header := f.FileHeader
+		fw, err := w.CreateHeader(&header)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to write EPUB")
+		}
+		if _, err := fw.Write(translated); err != nil {
+			return nil, errors.Wrap(err, "failed to write EPUB")
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, errors.Wrap(err, "failed to write EPUB")
+	}
+	return buf.Bytes(), nil
+}
+
+// epubSpine returns the names of the XHTML files of the spine of a book.
+func epubSpine(book *zip.Reader) (map[string]bool, error) {
+	var container epubContainerXML
+	if err := readZipXML(book, epubContainer, &container); err != nil {
+		return nil, err
+	}
+	if len(container.Rootfiles) == 0 {
+		return nil, errors.New("EPUB has no package document")
+	}
+	opf := container.Rootfiles[0].FullPath
+	var pkg epubPackage
+	if err := readZipXML(book, opf, &pkg); err != nil {
+		return nil, err
+	}
+
+	items := make(map[string]string)
+	for _, item := range pkg.Items {
+		if item.MediaType != xhtmlMedia {
+			continue
+		}
+		href, err := url.PathUnescape(item.Href)
+		if err != nil {
+			href = item.Href
+		}
+		items[item.ID] = path.Join(path.Dir(opf), href)
+	}
+	spine := make(map[string]bool)
+	for _, ref := range pkg.Itemrefs {
+		if name, ok := items[ref.IDRef]; ok {
+			spine[name] = true
+		}
+	}
+	return spine, nil
+}
+
+func readZipXML(book *zip.Reader, name string, v interface{}) error {
+	for _, f := range book.File {
+		if f.Name != name {
+			continue
+		}
+		content, err := readZipFile(f)
+		if err != nil {
+			return err
+		}
+		return errors.Wrapf(xml.Unmarshal(content, v), "failed to parse %s", name)
+	}
+	return errors.Errorf("EPUB has no %s", name)
+}
+
+func readZipFile(f *zip.File) ([]byte, error) {
+	r, err := f.Open()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read %s", f.Name)
+	}
+	defer r.Close()
+	content, err := io.ReadAll(r)
+	return content, errors.Wrapf(err, "failed to read %s", f.Name)
+}
+
+// copyZipFile copies a file to w without compressing it again.
+func copyZipFile(w *zip.Writer, f *zip.File) error {
+	r, err := f.OpenRaw()
+	if err != nil {
+		return errors.Wrapf(err, "failed to read %s", f.Name)
+	}
+	header := f.FileHeader
+	fw, err := w.CreateRaw(&header)
+	if err != nil {
+		return errors.Wrap(err, "failed to write EPUB")
+	}
+	_, err = io.Copy(fw, r)
+	return errors.Wrap(err, "failed to write EPUB")
+}
+
+// storeZipFile copies a file to w uncompressed.
+func storeZipFile(w *zip.Writer, f *zip.File) error {
+	content, err := readZipFile(f)
+	if err != nil {
+		return err
+	}
+	fw, err := w.CreateHeader(&zip.FileHeader{Name: f.Name, Method: zip.Store, Modified: f.Modified})
+	if err != nil {
+		return errors.Wrap(err, "failed to write EPUB")
+	}
+	_, err = fw.Write(content)
+	return errors.Wrap(err, "failed to write EPUB")
+}
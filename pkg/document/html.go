@@ -0,0 +1,202 @@
+package document
+
+import (
+	"bytes"
+	"context"
+	"html"
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/pkg/errors"
+	nethtml "golang.org/x/net/html"
+
+	"github.com/gogodjzhu/word-flow/pkg/util"
+)
+
+// translationClass is the class of the elements holding translations.
+const translationClass = "wordflow-translation"
+
+// translationStyle sets the translations apart from the original text.
+const translationStyle = "." + translationClass + " { color: #4a5d75; border-left: 3px solid #c9d4e0; padding-left: 0.5em; }"
+
+var (
+	// htmlBlocks are the elements whose text is translated as a paragraph.
+	htmlBlocks = map[string]bool{
+		"address": true, "article": true, "aside": true, "blockquote": true, "caption": true,
+		"dd": true, "div": true, "dl": true, "dt": true, "figcaption": true, "figure": true,
+		"footer": true, "h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+		"header": true, "li": true, "main": true, "nav": true, "ol": true, "p": true,
+		"section": true, "table": true, "tbody": true, "td": true, "tfoot": true, "th": true,
+		"thead": true, "tr": true, "ul": true,
+	}
+	// htmlSiblings are the blocks followed by their translation in a copy of
+	// the element. Other blocks get their translation in a div inside, which
+	// keeps the numbering of lists and the layout of tables.
+	htmlSiblings = map[string]bool{
+		"p": true, "h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	}
+	// htmlSkipped are the elements whose content is not translated.
+	htmlSkipped = map[string]bool{
+		"pre": true, "script": true, "style": true, "noscript": true, "template": true,
+		"textarea": true, "svg": true, "math": true, "head": true,
+	}
+	// htmlCode are the inline elements kept as they are in translations.
+	htmlCode = map[string]bool{
+		"code": true, "kbd": true, "samp": true, "var": true, "tt": true,
+	}
+)
+
+var (
+	xmlDeclRe = regexp.MustCompile(`^\s*<\?xml[^>]*\?>\s*`)
+	// xmlEmptyRe matches the empty elements of XHTML, such as <a id="x"/>,
+	// which HTML only allows for void elements.
+	xmlEmptyRe = regexp.MustCompile(`<([A-Za-z][A-Za-z0-9:.-]*)(\s[^<>]*?)?\s*/>`)
+)
+
+// htmlVoid are the elements without content in HTML.
+var htmlVoid = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true, "img": true,
+	"input": true, "link": true, "meta": true, "param": true, "source": true, "track": true, "wbr": true,
+}
+
+// htmlUnit is a block of text translated as a paragraph.
+type htmlUnit struct {
+	sel          *goquery.Selection
+	text         string
+	placeholders *util.Placeholders
+	// before is the first nested block, the translation goes in front of it.
+	before *nethtml.Node
+}
+
+// TranslateHTML translates the paragraphs, headings, list items and table
+// cells of an HTML page into a bilingual page: each of them is followed by
+// its translation in an element of class wordflow-translation, styled in the
+// head of the page. Code, preformatted text and scripts are not translated.
+// The translation has the text of the original, without its inline markup
+// except for code. XHTML pages are written back as XML.
+func TranslateHTML(ctx context.Context, src []byte, translate TranslateFunc, _ Options) ([]byte, error) {
+	return translateHTML(ctx, src, translate, isXHTML(src))
+}
+
+// isXHTML reports whether src is an XHTML page, by its XML declaration or
+// namespace.
+func isXHTML(src []byte) bool {
+	head := src[:min(len(src), 1024)]
+	return xmlDeclRe.Match(head) || bytes.Contains(head, []byte(`xmlns="http://www.w3.org/1999/xhtml"`))
+}
+
+// translateHTML translates an HTML page, or an XHTML one that is written
+// back as XML.
+func translateHTML(ctx context.Context, src []byte, translate TranslateFunc, xhtml bool) ([]byte, error) {
+	var decl string
+	if xhtml {
+		decl = xmlDeclRe.FindString(string(src))
+		src = xmlEmptyRe.ReplaceAllFunc(src[len(decl):], func(m []byte) []byte {
+			name := string(xmlEmptyRe.FindSubmatch(m)[1])
+			if htmlVoid[strings.ToLower(name)] {
+				return m
+			}
+			return append(m[:len(m)-2:len(m)-2], []byte("></"+name+">")...)
+		})
+	}
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(src))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse HTML")
+	}
+
+	var units []*htmlUnit
+	blocks := strings.Join(keys(htmlBlocks), ",")
+	skipped := strings.Join(keys(htmlSkipped), ",") + ",." + translationClass
+	doc.Find("body").Find(blocks).Each(func(_ int, s *goquery.Selection) {
+		if s.Closest(skipped).Length() > 0 {
+			return
+		}
+		unit := &htmlUnit{sel: s, placeholders: util.NewPlaceholders("h")}
+		var b strings.Builder
+		if unit.collect(s.Nodes[0], &b) {
+			unit.text = strings.Join(strings.Fields(b.String()), " ")
+			units = append(units, unit)
+		}
+	})
+
+	texts := make([]string, len(units))
+	for i, unit := range units {
+		texts[i] = unit.text
+	}
+	translations, err := translateLines(ctx, texts, translate)
+	if err != nil {
+		return nil, err
+	}
+	for i, unit := range units {
+		unit.insert(translations[i])
+	}
+	if len(units) > 0 {
+		doc.Find("head").AppendHtml("<style>" + translationStyle + "</style>")
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(decl)
+	if err := nethtml.Render(&buf, doc.Nodes[0]); err != nil {
+		return nil, errors.Wrap(err, "failed to write HTML")
+	}
+	if xhtml || bytes.HasSuffix(src, []byte("\n")) {
+		buf.WriteString("\n")
+	}
+	return buf.Bytes(), nil
+}
+
+// collect writes the text of the children of n up to nested blocks, with
+// code masked, and reports whether the text has letters.
+func (u *htmlUnit) collect(n *nethtml.Node, b *strings.Builder) bool {
+	letters := false
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		switch {
+		case c.Type == nethtml.TextNode:
+			b.WriteString(c.Data)
+			letters = letters || hasLetters(c.Data)
+		case c.Type != nethtml.ElementNode || htmlSkipped[c.Data]:
+		case htmlBlocks[c.Data]:
+			// Nested blocks are translated on their own.
+			if u.before == nil {
+				u.before = c
+			}
+		case htmlCode[c.Data]:
+			var code bytes.Buffer
+			if nethtml.Render(&code, c) == nil {
+				b.WriteString(u.placeholders.Add(code.String()))
+			}
+		case c.Data == "br":
+			b.WriteString(" ")
+		default:
+			letters = u.collect(c, b) || letters
+		}
+	}
+	return letters
+}
+
+// insert adds the translation of the unit to the page. Code lost by the
+// translation is missing from it, the original is right above.
+func (u *htmlUnit) insert(translation string) {
+	if translation == "" {
+		return
+	}
+	content := u.placeholders.Restore(html.EscapeString(translation))
+	tag := goquery.NodeName(u.sel)
+	switch {
+	case htmlSiblings[tag]:
+		u.sel.AfterHtml("<" + tag + ` class="` + translationClass + `">` + content + "</" + tag + ">")
+	case u.before != nil:
+		u.sel.FindNodes(u.before).BeforeHtml(`<div class="` + translationClass + `">` + content + "</div>")
+	default:
+		u.sel.AppendHtml(`<div class="` + translationClass + `">` + content + "</div>")
+	}
+}
+
+func keys(m map[string]bool) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	return names
+}
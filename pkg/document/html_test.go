@@ -0,0 +1,161 @@
+package document
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// wrapLines marks each translated line.
+func wrapLines(calls *[]string) TranslateFunc {
+	return func(ctx context.Context, text string) (string, error) {
+		*calls = append(*calls, text)
+		return wrapEachLine(text), nil
+	}
+}
+
+func TestTranslateHTML(t *testing.T) {
+	src := `<!DOCTYPE html>
+<html><head><title>Guide</title></head>
+<body>
+<h1>Getting started</h1>
+<p>Run <code>make</code> and <a href="next.html">read on</a>.</p>
+<pre>make install</pre>
+<ul>
+  <li>First item
+    <ul><li>Nested item</li></ul>
+  </li>
+</ul>
+<table><tr><td>Cell</td><td>42</td></tr></table>
+<script>var s = "not prose";</script>
+</body></html>
+`
+	var calls []string
+	out, err := TranslateHTML(context.Background(), []byte(src), wrapLines(&calls), Options{})
+	if err != nil {
+		t.Fatalf("TranslateHTML() error = %v", err)
+	}
+	wantCalls := []string{"Getting started\nRun {{h1}} and read on.\nFirst item\nNested item\nCell"}
+	if !reflect.DeepEqual(calls, wantCalls) {
+		t.Errorf("translated %q, want %q", calls, wantCalls)
+	}
+	for _, want := range []string{
+		`<h1>Getting started</h1><h1 class="wordflow-translation">T(Getting started)</h1>`,
+		`<p>Run <code>make</code> and <a href="next.html">read on</a>.</p><p class="wordflow-translation">T(Run <code>make</code> and read on.)</p>`,
+		`<pre>make install</pre>`,
+		`<li>First item
+    <div class="wordflow-translation">T(First item)</div><ul><li>Nested item<div class="wordflow-translation">T(Nested item)</div></li></ul>`,
+		`<td>Cell<div class="wordflow-translation">T(Cell)</div></td><td>42</td>`,
+		`<style>.wordflow-translation {`,
+	} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("TranslateHTML() misses %s in\n%s", want, out)
+		}
+	}
+}
+
+func TestTranslateXHTML(t *testing.T) {
+	src := `<?xml version="1.0" encoding="utf-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops">
+<head><title>One</title><link rel="stylesheet" href="style.css"/></head>
+<body><a id="start"/><p>Tom &amp; Jerry<br/>again</p></body>
+</html>`
+	var calls []string
+	out, err := TranslateHTML(context.Background(), []byte(src), wrapLines(&calls), Options{})
+	if err != nil {
+		t.Fatalf("TranslateHTML() error = %v", err)
+	}
+	if !strings.HasPrefix(string(out), `<?xml version="1.0" encoding="utf-8"?>`) {
+		t.Errorf("TranslateHTML() lost the XML declaration:\n%s", out)
+	}
+	for d := xml.NewDecoder(bytes.NewReader(out)); ; {
+		if _, err := d.Token(); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("TranslateHTML() wrote invalid XML: %v\n%s", err, out)
+		}
+	}
+	for _, want := range []string{
+		`<link rel="stylesheet" href="style.css"/>`,
+		`<a id="start"></a><p>Tom &amp; Jerry<br/>again</p><p class="wordflow-translation">T(Tom &amp; Jerry again)</p>`,
+	} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("TranslateHTML() misses %s in\n%s", want, out)
+		}
+	}
+}
+
+func TestTranslateEPUB(t *testing.T) {
+	files := []struct{ name, content string }{
+		{"mimetype", "application/epub+zip"},
+		{"META-INF/container.xml", `<?xml version="1.0"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles><rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/></rootfiles>
+</container>`},
+		{"OEBPS/content.opf", `<?xml version="1.0"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0">
+  <manifest>
+    <item id="ch1" href="text/chapter%201.xhtml" media-type="application/xhtml+xml"/>
+    <item id="extra" href="text/extra.xhtml" media-type="application/xhtml+xml"/>
+    <item id="css" href="style.css" media-type="text/css"/>
+  </manifest>
+  <spine><itemref idref="ch1"/></spine>
+</package>`},
+		{"OEBPS/text/chapter 1.xhtml", `<?xml version="1.0" encoding="utf-8"?>
+<html xmlns="http://www.w3.org/1999/xhtml"><head><title>One</title></head><body><p>Hello world.</p></body></html>`},
+		{"OEBPS/text/extra.xhtml", `<html xmlns="http://www.w3.org/1999/xhtml"><body><p>Not in the spine.</p></body></html>`},
+		{"OEBPS/style.css", "p { margin: 0; }"},
+	}
+	var src bytes.Buffer
+	w := zip.NewWriter(&src)
+	for _, f := range files {
+		fw, err := w.Create(f.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fw.Write([]byte(f.content))
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var calls []string
+	out, err := TranslateEPUB(context.Background(), src.Bytes(), wrapLines(&calls), Options{})
+	if err != nil {
+		t.Fatalf("TranslateEPUB() error = %v", err)
+	}
+	if want := []string{"Hello world."}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("translated %q, want %q", calls, want)
+	}
+	book, err := zip.NewReader(bytes.NewReader(out), int64(len(out)))
+	if err != nil {
+		t.Fatalf("TranslateEPUB() wrote no zip: %v", err)
+	}
+	if len(book.File) != len(files) {
+		t.Fatalf("TranslateEPUB() wrote %d files, want %d", len(book.File), len(files))
+	}
+	if f := book.File[0]; f.Name != "mimetype" || f.Method != zip.Store {
+		t.Errorf("first file = %s (method %d), want stored mimetype", f.Name, f.Method)
+	}
+	for i, f := range book.File {
+		r, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, _ := io.ReadAll(r)
+		r.Close()
+		if f.Name == "OEBPS/text/chapter 1.xhtml" {
+			if !strings.Contains(string(content), `<p>Hello world.</p><p class="wordflow-translation">T(Hello world.)</p>`) {
+				t.Errorf("chapter not translated:\n%s", content)
+			}
+		} else if string(content) != files[i].content {
+			t.Errorf("%s = %q, want it copied", f.Name, content)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package document
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/gogodjzhu/word-flow/pkg/util"
+)
+
+// i18nPlaceholderRe matches the placeholders of resource strings other than
+// ICU arguments: printf verbs such as %s, %1$d or %(name)s, Ruby's %{name},
+// i18next and Vue's {{name}} and line breaks. A space is not taken as a
+// printf flag, "100% sure" has no placeholder.
+var i18nPlaceholderRe = regexp.MustCompile(`%(\d+\$)?[-+#0]*(\d+|\*)?(\.(\d+|\*))?(hh|h|ll|l|L|q|j|z|t)?[diouxXeEfFgGaAcspn@%]|%\([A-Za-z_][\w.-]*\)[-+#0]*\d*(\.\d+)?[diouxXeEfFgGcrs]|%\{[^{}\s]+\}|\{\{[^{}]*\}\}|\n`)
+
+// Mismatch is an entry of a resource file whose translation does not have
+// the placeholders of its source.
+type Mismatch struct {
+	Key string
+	// Missing are the placeholders of the source missing from the
+	// translation, Extra those the source does not have.
+	Missing []string
+	Extra   []string
+}
+
+// i18nEntry is a string of a resource file.
+type i18nEntry struct {
+	key         string
+	source      string
+	translation string
+	mismatch    bool
+}
+
+// i18nPlaceholders returns the spans of the placeholders of s: the matches
+// of i18nPlaceholderRe and ICU arguments, with their nested messages such as
+// {count, plural, one {# file} other {# files}}.
+func i18nPlaceholders(s string) [][2]int {
+	var spans [][2]int
+	matches := i18nPlaceholderRe.FindAllStringIndex(s, -1)
+	for i := 0; i < len(s); {
+		if len(matches) > 0 && matches[0][0] == i {
+			spans = append(spans, [2]int{matches[0][0], matches[0][1]})
+			i = matches[0][1]
+			matches = matches[1:]
+			continue
+		}
+		if s[i] == '{' {
+			if end := icuArgumentEnd(s, i); end > 0 {
+				spans = append(spans, [2]int{i, end})
+				for len(matches) > 0 && matches[0][0] < end {
+					matches = matches[1:]
+				}
+				i = end
+				continue
+			}
+		}
+		for len(matches) > 0 && matches[0][0] <= i {
+			matches = matches[1:]
+		}
+		i++
+	}
+	return spans
+}
+
+// icuArgumentEnd returns the end of the ICU argument starting at the brace
+// s[start], or 0 when there is none. Arguments start with a name or number.
+func icuArgumentEnd(s string, start int) int {
+	name := start + 1
+	for name < len(s) && (s[name] == '_' || s[name] >= '0' && s[name] <= '9' || s[name] >= 'A' && s[name] <= 'Z' || s[name] >= 'a' && s[name] <= 'z') {
+		name++
+	}
+	if name == start+1 || name == len(s) || s[name] != '}' && s[name] != ',' && s[name] != ' ' {
+		return 0
+	}
+	depth := 0
+	for i := start; i < len(s); i++ {
+		switch s[i] {
+		case '{':
+			depth++
+		case '}':
+			if depth--; depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return 0
+}
+
+// maskI18n replaces the placeholders of s with placeholders of p, and
+// returns the text left once they are removed.
+func maskI18n(s string, p *util.Placeholders) (masked, text string) {
+	var m, t strings.Builder
+	last := 0
+	for _, span := range i18nPlaceholders(s) {
+		m.WriteString(s[last:span[0]])
+		t.WriteString(s[last:span[0]])
+		m.WriteString(p.Add(s[span[0]:span[1]]))
+		last = span[1]
+	}
+	m.WriteString(s[last:])
+	t.WriteString(s[last:])
+	return m.String(), t.String()
+}
+
+// translateEntries translates the sources of entries, in batches of lines
+// with their placeholders masked. Sources without text are copied.
+// Translations that do not keep the placeholders of their source are
+// reported to opts.Mismatch.
+func translateEntries(ctx context.Context, entries []*i18nEntry, translate TranslateFunc, opts Options) error {
+	var pending []*i18nEntry
+	var texts []string
+	var placeholders []*util.Placeholders
+	for _, e := range entries {
+		p := util.NewPlaceholders("i")
+		masked, text := maskI18n(e.source, p)
+		if !hasLetters(text) {
+			e.translation = e.source
+			continue
+		}
+		pending = append(pending, e)
+		texts = append(texts, masked)
+		placeholders = append(placeholders, p)
+	}
+	translations, err := translateLines(ctx, texts, translate)
+	if err != nil {
+		return err
+	}
+	for i, e := range pending {
+		e.translation = placeholders[i].Restore(translations[i])
+		if missing, extra := placeholderDiff(e.source, e.translation); len(missing)+len(extra) > 0 {
+			e.mismatch = true
+			if opts.Mismatch != nil {
+				opts.Mismatch(Mismatch{Key: e.key, Missing: missing, Extra: extra})
+			}
+		}
+	}
+	return nil
+}
+
+// placeholderDiff compares the placeholders of a source and its translation.
+func placeholderDiff(source, translation string) (missing, extra []string) {
+	count := make(map[string]int)
+	for _, span := range i18nPlaceholders(source) {
+		count[source[span[0]:span[1]]]++
+	}
+	for _, span := range i18nPlaceholders(translation) {
+		placeholder := translation[span[0]:span[1]]
+		if count[placeholder] > 0 {
+			count[placeholder]--
+		} else {
+			extra = append(extra, placeholder)
+		}
+	}
+	for _, span := range i18nPlaceholders(source) {
+		placeholder := source[span[0]:span[1]]
+		if count[placeholder] > 0 {
+			count[placeholder]--
+			missing = append(missing, placeholder)
+		}
+	}
+	return missing, extra
+}
@@ -0,0 +1,216 @@
+package document
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// spanTexts returns the placeholders found by i18nPlaceholders.
+func spanTexts(s string) []string {
+	var texts []string
+	for _, span := range i18nPlaceholders(s) {
+		texts = append(texts, s[span[0]:span[1]])
+	}
+	return texts
+}
+
+func TestI18nPlaceholders(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"Hello {name}!", []string{"{name}"}},
+		{"{count, plural, one {# file} other {# files}} left", []string{"{count, plural, one {# file} other {# files}}"}},
+		{"%s of %1$d, %.2f and %%", []string{"%s", "%1$d", "%.2f", "%%"}},
+		{"Hi %(name)s and %{user}", []string{"%(name)s", "%{user}"}},
+		{"{{count}} items\nnext", []string{"{{count}}", "\n"}},
+		{"100% sure, {not an argument", nil},
+	}
+	for _, tt := range tests {
+		if got := spanTexts(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("i18nPlaceholders(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestPlaceholderDiff(t *testing.T) {
+	missing, extra := placeholderDiff("{a} and %s and %s", "%s {b} {a}")
+	if want := []string{"%s"}; !reflect.DeepEqual(missing, want) {
+		t.Errorf("missing = %q, want %q", missing, want)
+	}
+	if want := []string{"{b}"}; !reflect.DeepEqual(extra, want) {
+		t.Errorf("extra = %q, want %q", extra, want)
+	}
+}
+
+func TestTranslateJSON(t *testing.T) {
+	src := `{
+    "title": "Welcome, {name}!",
+    "count": 3,
+    "menu": {
+        "open": "Open %s",
+        "items": ["New", "{{n}}"],
+        "empty": {}
+    },
+    "done": "Done"
+}
+`
+	previous := `{"title": "", "menu": {"open": "打开 %s"}, "done": "Done"}`
+	var calls []string
+	var mismatches []Mismatch
+	opts := Options{Previous: []byte(previous), Mismatch: func(m Mismatch) { mismatches = append(mismatches, m) }}
+	translate := func(ctx context.Context, text string) (string, error) {
+		calls = append(calls, text)
+		// Loses the placeholder of the last line.
+		return strings.Replace(wrapEachLine(text), "T(Done)", "T(Done {{i9}})", 1), nil
+	}
+	out, err := TranslateJSON(context.Background(), []byte(src), translate, opts)
+	if err != nil {
+		t.Fatalf("TranslateJSON() error = %v", err)
+	}
+	want := `{
+    "title": "T(Welcome, {name}!)",
+    "count": 3,
+    "menu": {
+        "open": "打开 %s",
+        "items": [
+            "T(New)",
+            "{{n}}"
+        ],
+        "empty": {}
+    },
+    "done": "T(Done {{i9}})"
+}
+`
+	if string(out) != want {
+		t.Errorf("TranslateJSON() =\n%s\nwant\n%s", out, want)
+	}
+	if wantCalls := []string{"Welcome, {{i1}}!\nNew\nDone"}; !reflect.DeepEqual(calls, wantCalls) {
+		t.Errorf("translated %q, want %q", calls, wantCalls)
+	}
+	if wantMismatches := []Mismatch{{Key: "done", Extra: []string{"{{i9}}"}}}; !reflect.DeepEqual(mismatches, wantMismatches) {
+		t.Errorf("mismatches = %+v, want %+v", mismatches, wantMismatches)
+	}
+
+	calls = nil
+	opts.Force = true
+	if _, err := TranslateJSON(context.Background(), []byte(src), translate, opts); err != nil {
+		t.Fatalf("TranslateJSON() error = %v", err)
+	}
+	if wantCalls := []string{"Welcome, {{i1}}!\nOpen {{i1}}\nNew\nDone"}; !reflect.DeepEqual(calls, wantCalls) {
+		t.Errorf("forced translated %q, want %q", calls, wantCalls)
+	}
+}
+
+func TestTranslateYAML(t *testing.T) {
+	src := `# Messages of the app
+en:
+  greeting: Hello %{name}
+  # Shown on errors
+  errors:
+    - Not found
+    - 404
+  retry: "Try again?"
+`
+	previous := "en:\n  retry: 再试一次？\n"
+	var calls []string
+	out, err := TranslateYAML(context.Background(), []byte(src), wrapLines(&calls), Options{Previous: []byte(previous)})
+	if err != nil {
+		t.Fatalf("TranslateYAML() error = %v", err)
+	}
+	want := `# Messages of the app
+en:
+  greeting: T(Hello %{name})
+  # Shown on errors
+  errors:
+    - T(Not found)
+    - 404
+  retry: "再试一次？"
+`
+	if string(out) != want {
+		t.Errorf("TranslateYAML() =\n%s\nwant\n%s", out, want)
+	}
+	if wantCalls := []string{"Hello {{i1}}\nNot found"}; !reflect.DeepEqual(calls, wantCalls) {
+		t.Errorf("translated %q, want %q", calls, wantCalls)
+	}
+}
+
+func TestTranslatePO(t *testing.T) {
+	src := `msgid ""
+msgstr ""
+"Language: zh\n"
+"Plural-Forms: nplurals=1; plural=0;\n"
+
+#: app.py:10
+#, python-format
+msgid "Hello %(name)s"
+msgstr ""
+
+msgid "Save"
+msgstr "保存"
+
+msgid "One file"
+msgid_plural "%d files"
+msgstr[0] ""
+
+msgid ""
+"First line\n"
+"second line"
+msgstr ""
+`
+	var calls []string
+	var mismatches []Mismatch
+	translate := func(ctx context.Context, text string) (string, error) {
+		calls = append(calls, text)
+		// Drops the placeholder of the first message.
+		return strings.Replace(wrapEachLine(text), "{{i1}}", "", 1), nil
+	}
+	out, err := TranslatePO(context.Background(), []byte(src), translate, Options{Mismatch: func(m Mismatch) { mismatches = append(mismatches, m) }})
+	if err != nil {
+		t.Fatalf("TranslatePO() error = %v", err)
+	}
+	want := `msgid ""
+msgstr ""
+"Language: zh\n"
+"Plural-Forms: nplurals=1; plural=0;\n"
+
+#: app.py:10
+#, python-format, fuzzy
+msgid "Hello %(name)s"
+msgstr "T(Hello )"
+
+msgid "Save"
+msgstr "保存"
+
+msgid "One file"
+msgid_plural "%d files"
+msgstr[0] "T(%d files)"
+
+msgid ""
+"First line\n"
+"second line"
+msgstr ""
+"T(First line\n"
+"second line)"
+`
+	if string(out) != want {
+		t.Errorf("TranslatePO() =\n%s\nwant\n%s", out, want)
+	}
+	if wantCalls := []string{"Hello {{i1}}\n{{i1}} files\nFirst line{{i1}}second line"}; !reflect.DeepEqual(calls, wantCalls) {
+		t.Errorf("translated %q, want %q", calls, wantCalls)
+	}
+	if wantMismatches := []Mismatch{{Key: "Hello %(name)s", Missing: []string{"%(name)s"}}}; !reflect.DeepEqual(mismatches, wantMismatches) {
+		t.Errorf("mismatches = %+v, want %+v", mismatches, wantMismatches)
+	}
+}
+
+// wrapEachLine marks each line of text.
+func wrapEachLine(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = "T(" + line + ")"
+	}
+	return strings.Join(lines, "\n")
+}
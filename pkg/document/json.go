@@ -0,0 +1,196 @@
+package document
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var jsonIndentRe = regexp.MustCompile(`\n([ \t]+)\S`)
+
+// jsonNode is a JSON value that keeps the order of the keys of objects.
+type jsonNode struct {
+	// kind is '{' for objects, '[' for arrays, '"' for strings and 0 for
+	// other values, kept as they were written.
+	kind  byte
+	keys  []string
+	items []*jsonNode
+	str   string
+	raw   string
+}
+
+// TranslateJSON translates the string values of a JSON resource file, such
+// as en.json of i18next or vue-i18n. Keys, other values and the order of the
+// keys are kept, and so are placeholders like {name}, %s and {{count}}.
+// Values translated in opts.Previous, not empty nor the same as the source,
+// are kept unless opts.Force.
+func TranslateJSON(ctx context.Context, src []byte, translate TranslateFunc, opts Options) ([]byte, error) {
+	root, err := parseJSON(src)
+	if err != nil {
+		return nil, err
+	}
+	var previous *jsonNode
+	if len(opts.Previous) > 0 && !opts.Force {
+		// An unreadable previous translation is translated again.
+		previous, _ = parseJSON(opts.Previous)
+	}
+
+	var entries []*i18nEntry
+	var nodes []*jsonNode
+	root.walk("", previous, func(key string, n, prev *jsonNode) {
+		if prev != nil && prev.kind == '"' && prev.str != "" && prev.str != n.str {
+			n.str = prev.str
+			return
+		}
+		entries = append(entries, &i18nEntry{key: key, source: n.str})
+		nodes = append(nodes, n)
+	})
+	if err := translateEntries(ctx, entries, translate, opts); err != nil {
+		return nil, err
+	}
+	for i, e := range entries {
+		nodes[i].str = e.translation
+	}
+
+	indent := "  "
+	if m := jsonIndentRe.FindSubmatch(src); m != nil {
+		indent = string(m[1])
+	}
+	var b strings.Builder
+	root.write(&b, indent, "")
+	b.WriteString("\n")
+	return []byte(b.String()), nil
+}
+
+func parseJSON(src []byte) (*jsonNode, error) {
+	d := json.NewDecoder(bytes.NewReader(src))
+	d.UseNumber()
+	root, err := decodeJSON(d)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse JSON")
+	}
+	if _, err := d.Token(); err != io.EOF {
+		return nil, errors.New("failed to parse JSON: data after the top-level value")
+	}
+	return root, nil
+}
+
+func decodeJSON(d *json.Decoder) (*jsonNode, error) {
+	token, err := d.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch v := token.(type) {
+	case json.Delim:
+		n := &jsonNode{kind: byte(v)}
+		for d.More() {
+			if n.kind == '{' {
+				key, err := d.Token()
+				if err != nil {
+					return nil, err
+				}
+				n.keys = append(n.keys, key.(string))
+			}
+			item, err := decodeJSON(d)
+			if err != nil {
+				return nil, err
+			}
+			n.items = append(n.items, item)
+		}
+		// The closing delimiter.
+		if _, err := d.Token(); err != nil {
+			return nil, err
+		}
+		return n, nil
+	case string:
+		return &jsonNode{kind: '"', str: v}, nil
+	case json.Number:
+		return &jsonNode{raw: v.String()}, nil
+	case bool:
+		return &jsonNode{raw: strconv.FormatBool(v)}, nil
+	default:
+		return &jsonNode{raw: "null"}, nil
+	}
+}
+
+// walk calls f with the strings of n, their dotted key and the node at the
+// same place of prev, if any.
+func (n *jsonNode) walk(key string, prev *jsonNode, f func(key string, n, prev *jsonNode)) {
+	switch n.kind {
+	case '"':
+		f(key, n, prev)
+	case '{':
+		for i, k := range n.keys {
+			child := k
+			if key != "" {
+				child = key + "." + k
+			}
+			n.items[i].walk(child, prev.member(k), f)
+		}
+	case '[':
+		for i, item := range n.items {
+			var p *jsonNode
+			if prev != nil && prev.kind == '[' && i < len(prev.items) {
+				p = prev.items[i]
+			}
+			item.walk(key+"["+strconv.Itoa(i)+"]", p, f)
+		}
+	}
+}
+
+// member returns the value of a key of an object, or nil.
+func (n *jsonNode) member(key string) *jsonNode {
+	if n == nil || n.kind != '{' {
+		return nil
+	}
+	for i, k := range n.keys {
+		if k == key {
+			return n.items[i]
+		}
+	}
+	return nil
+}
+
+func (n *jsonNode) write(b *strings.Builder, indent, prefix string) {
+	switch n.kind {
+	case '"':
+		b.WriteString(jsonString(n.str))
+	case '{', '[':
+		closing := "}"
+		if n.kind == '[' {
+			closing = "]"
+		}
+		b.WriteByte(n.kind)
+		for i, item := range n.items {
+			if i > 0 {
+				b.WriteString(",")
+			}
+			b.WriteString("\n" + prefix + indent)
+			if n.kind == '{' {
+				b.WriteString(jsonString(n.keys[i]) + ": ")
+			}
+			item.write(b, indent, prefix+indent)
+		}
+		if len(n.items) > 0 {
+			b.WriteString("\n" + prefix)
+		}
+		b.WriteString(closing)
+	default:
+		b.WriteString(n.raw)
+	}
+}
+
+// jsonString quotes s without escaping HTML, which translations often have.
+func jsonString(s string) string {
+	var buf bytes.Buffer
+	e := json.NewEncoder(&buf)
+	e.SetEscapeHTML(false)
+	_ = e.Encode(s)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
@@ -0,0 +1,381 @@
+package document
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/gogodjzhu/word-flow/pkg/util"
+)
+
+// Block patterns of Markdown, matched on whole lines.
+var (
+	mdFenceRe        = regexp.MustCompile("^\\s{0,3}(`{3,}|~{3,})")
+	mdHTMLBlockRe    = regexp.MustCompile(`^\s{0,3}<(/?[A-Za-z][A-Za-z0-9-]*[\s/>]|/?[A-Za-z][A-Za-z0-9-]*$|!--)`)
+	mdIndentedRe     = regexp.MustCompile(`^( {4}|\t)`)
+	mdThematicRe     = regexp.MustCompile(`^\s{0,3}([-*_])(\s*([-*_])){2,}\s*$`)
+	mdSetextRe       = regexp.MustCompile(`^\s{0,3}(=+|-+)\s*$`)
+	mdHeadingRe      = regexp.MustCompile(`^(\s{0,3}#{1,6})(\s+)(.*?)(\s+#+)?\s*$`)
+	mdLinkDefRe      = regexp.MustCompile(`^\s{0,3}\[[^\]^][^\]]*\]:\s*\S`)
+	mdFootnoteDefRe  = regexp.MustCompile(`^(\s{0,3}\[\^[^\]]+\]:\s*)(.*)$`)
+	mdBlockquoteRe   = regexp.MustCompile(`^\s{0,3}> ?`)
+	mdListItemRe     = regexp.MustCompile(`^(\s*(?:[-*+]|\d{1,9}[.)])\s+(?:\[[ xX]\]\s+)?)(.*)$`)
+	mdTableDelimRe   = regexp.MustCompile(`^\s*\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)*\|?\s*$`)
+	mdFrontMatterRe  = regexp.MustCompile(`^(---|\+\+\+)\s*$`)
+	mdLeadingSpaceRe = regexp.MustCompile(`^\s*`)
+)
+
+// Inline patterns of Markdown kept out of the translation.
+var (
+	mdImageRe    = regexp.MustCompile(`!\[[^\]]*\]\([^)]*\)`)
+	mdLinkRe     = regexp.MustCompile(`\[([^\]]*)\](\([^)]*\)|\[[^\]]*\])`)
+	mdAutolinkRe = regexp.MustCompile(`<[A-Za-z][A-Za-z0-9+.-]*:[^>\s]*>|<[^@>\s]+@[^>\s]+>`)
+	mdInlineHTML = regexp.MustCompile(`</?[A-Za-z][A-Za-z0-9-]*(\s[^>]*)?/?>`)
+	mdURLRe      = regexp.MustCompile(`https?://[^\s<>()\[\]]+`)
+	mdFootnoteRe = regexp.MustCompile(`\[\^[^\]]+\]`)
+)
+
+// TranslateMarkdown translates the prose of a Markdown document: paragraphs,
+// headings, list items, table cells, block quotes and footnotes. Front
+// matter, code blocks, HTML blocks, link definitions and the code spans,
+// URLs and inline HTML of the prose are kept. Paragraphs wrapped over several
+// lines are translated, and written, as a single line. The translation
+// replaces the original, also with opts.Bilingual.
+func TranslateMarkdown(ctx context.Context, src []byte, translate TranslateFunc, _ Options) ([]byte, error) {
+	text := strings.ReplaceAll(string(src), "\r\n", "\n")
+	lines := strings.Split(text, "\n")
+	m := &markdown{ctx: ctx, translate: translate}
+	var out []string
+	if len(lines) > 0 && mdFrontMatterRe.MatchString(lines[0]) {
+		end := 1
+		for end < len(lines) && strings.TrimSpace(lines[end]) != strings.TrimSpace(lines[0]) {
+			end++
+		}
+		if end < len(lines) {
+			out = append(out, lines[:end+1]...)
+			lines = lines[end+1:]
+		}
+	}
+	translated, err := m.blocks(lines)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(strings.Join(append(out, translated...), "\n")), nil
+}
+
+type markdown struct {
+	ctx       context.Context
+	translate TranslateFunc
+}
+
+// blocks translates the lines of a document or of a block quote.
+func (m *markdown) blocks(lines []string) ([]string, error) {
+	var out []string
+	inList := false
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		switch {
+		case strings.TrimSpace(line) == "":
+			out = append(out, line)
+			i++
+
+		case mdFenceRe.MatchString(line):
+			end := fenceEnd(lines, i)
+			out = append(out, lines[i:end]...)
+			i = end
+
+		case mdIndentedRe.MatchString(line) && !inList && (i == 0 || strings.TrimSpace(lines[i-1]) == ""):
+			end := i
+			for end < len(lines) && (mdIndentedRe.MatchString(lines[end]) ||
+				strings.TrimSpace(lines[end]) == "" && end+1 < len(lines) && mdIndentedRe.MatchString(lines[end+1])) {
+				end++
+			}
+			out = append(out, lines[i:end]...)
+			i = end
+
+		case mdHTMLBlockRe.MatchString(line):
+			end := i
+			for end < len(lines) && strings.TrimSpace(lines[end]) != "" {
+				end++
+			}
+			out = append(out, lines[i:end]...)
+			i = end
+
+		case mdThematicRe.MatchString(line), mdSetextRe.MatchString(line), mdLinkDefRe.MatchString(line):
+			out = append(out, line)
+			i++
+
+		case mdHeadingRe.MatchString(line):
+			g := mdHeadingRe.FindStringSubmatch(line)
+			content, err := m.inline(g[3])
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, g[1]+g[2]+content+g[4])
+			inList = false
+			i++
+
+		case mdFootnoteDefRe.MatchString(line):
+			g := mdFootnoteDefRe.FindStringSubmatch(line)
+			content, err := m.inline(g[2])
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, g[1]+content)
+			i++
+
+		case mdBlockquoteRe.MatchString(line):
+			end := i
+			var inner []string
+			for end < len(lines) && mdBlockquoteRe.MatchString(lines[end]) {
+				inner = append(inner, mdBlockquoteRe.ReplaceAllString(lines[end], ""))
+				end++
+			}
+			translated, err := m.blocks(inner)
+			if err != nil {
+				return nil, err
+			}
+			for _, l := range translated {
+				out = append(out, strings.TrimRight("> "+l, " "))
+			}
+			i = end
+
+		case i+1 < len(lines) && strings.Contains(line, "|") && mdTableDelimRe.MatchString(lines[i+1]) && strings.Contains(lines[i+1], "-"):
+			row, err := m.tableRow(line)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, row, lines[i+1])
+			i += 2
+			for i < len(lines) && strings.Contains(lines[i], "|") && strings.TrimSpace(lines[i]) != "" {
+				if row, err = m.tableRow(lines[i]); err != nil {
+					return nil, err
+				}
+				out = append(out, row)
+				i++
+			}
+
+		default:
+			prefix, first := mdLeadingSpaceRe.FindString(line), strings.TrimSpace(line)
+			if g := mdListItemRe.FindStringSubmatch(line); g != nil {
+				prefix, first = g[1], g[2]
+				inList = true
+			} else if prefix == "" {
+				inList = false
+			}
+			end := i + 1
+			paragraph := []string{first}
+			for end < len(lines) && !m.interrupts(lines[end]) {
+				paragraph = append(paragraph, strings.TrimSpace(lines[end]))
+				end++
+			}
+			content, err := m.inline(strings.Join(paragraph, " "))
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, prefix+content)
+			i = end
+		}
+	}
+	return out, nil
+}
+
+// interrupts reports whether line ends a paragraph.
+func (m *markdown) interrupts(line string) bool {
+	return strings.TrimSpace(line) == "" ||
+		mdFenceRe.MatchString(line) ||
+		mdHTMLBlockRe.MatchString(line) ||
+		mdThematicRe.MatchString(line) ||
+		mdSetextRe.MatchString(line) ||
+		mdHeadingRe.MatchString(line) ||
+		mdBlockquoteRe.MatchString(line) ||
+		mdListItemRe.MatchString(line) ||
+		mdFootnoteDefRe.MatchString(line) ||
+		mdLinkDefRe.MatchString(line)
+}
+
+// fenceEnd returns the index after the fenced code block starting at line i.
+// An unclosed fence runs to the end of the document.
+func fenceEnd(lines []string, i int) int {
+	fence := mdFenceRe.FindStringSubmatch(lines[i])[1]
+	for j := i + 1; j < len(lines); j++ {
+		trimmed := strings.TrimSpace(lines[j])
+		if strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]) == "" {
+			return j + 1
+		}
+	}
+	return len(lines)
+}
+
+// tableRow translates the cells of a table row.
+func (m *markdown) tableRow(line string) (string, error) {
+	trimmed := strings.TrimSpace(line)
+	leading, trailing := strings.HasPrefix(trimmed, "|"), strings.HasSuffix(trimmed, "|") && !strings.HasSuffix(trimmed, `\|`)
+	trimmed = strings.TrimPrefix(trimmed, "|")
+	if trailing {
+		trimmed = strings.TrimSuffix(trimmed, "|")
+	}
+	cells := splitCells(trimmed)
+	for i, cell := range cells {
+		translated, err := m.inline(strings.TrimSpace(cell))
+		if err != nil {
+			return "", err
+		}
+		cells[i] = " " + translated + " "
+	}
+	row := strings.Join(cells, "|")
+	if leading {
+		row = "|" + row
+	}
+	if trailing {
+		row += "|"
+	}
+	return strings.TrimSpace(row), nil
+}
+
+// splitCells splits a table row on the pipes that are neither escaped nor in
+// code spans.
+func splitCells(row string) []string {
+	var cells []string
+	start, inCode := 0, false
+	for i := 0; i < len(row); i++ {
+		switch row[i] {
+		case '\\':
+			i++
+		case '`':
+			inCode = !inCode
+		case '|':
+			if !inCode {
+				cells = append(cells, row[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(cells, row[start:])
+}
+
+// inline translates a piece of prose, with its code spans, link targets,
+// URLs and inline HTML masked. Translations losing a masked part are not
+// trusted and the prose is kept.
+func (m *markdown) inline(text string) (string, error) {
+	if !hasLetters(text) {
+		return text, nil
+	}
+	masked, placeholders := maskInline(text)
+	if !hasLetters(stripPlaceholders(masked)) {
+		return text, nil
+	}
+	translation, err := m.translate(m.ctx, masked)
+	if err != nil {
+		return "", err
+	}
+	if len(placeholders.Missing(translation)) > 0 {
+		return text, nil
+	}
+	return placeholders.Restore(translation), nil
+}
+
+var mdPlaceholderRe = regexp.MustCompile(`\{\{m\d+\}\}`)
+
+// stripPlaceholders removes the placeholders of maskInline, whose m is not
+// prose.
+func stripPlaceholders(s string) string {
+	return mdPlaceholderRe.ReplaceAllString(s, "")
+}
+
+// maskInline replaces the parts of prose translation must keep with
+// placeholders.
+func maskInline(text string) (string, *util.Placeholders) {
+	var spans [][2]int
+	free := func(start, end int) bool {
+		for _, s := range spans {
+			if start < s[1] && s[0] < end {
+				return false
+			}
+		}
+		return true
+	}
+	add := func(start, end int) {
+		if start < end && free(start, end) {
+			spans = append(spans, [2]int{start, end})
+		}
+	}
+	for _, s := range codeSpans(text) {
+		add(s[0], s[1])
+	}
+	for _, loc := range mdImageRe.FindAllStringIndex(text, -1) {
+		add(loc[0], loc[1])
+	}
+	for _, loc := range mdLinkRe.FindAllStringSubmatchIndex(text, -1) {
+		// The link text is translated, the brackets and target are kept.
+		if loc[3] == loc[2] {
+			add(loc[0], loc[1])
+			continue
+		}
+		add(loc[0], loc[2])
+		add(loc[3], loc[1])
+	}
+	for _, re := range []*regexp.Regexp{mdAutolinkRe, mdInlineHTML, mdFootnoteRe} {
+		for _, loc := range re.FindAllStringIndex(text, -1) {
+			add(loc[0], loc[1])
+		}
+	}
+	for _, loc := range mdURLRe.FindAllStringIndex(text, -1) {
+		// Punctuation ending a sentence is not part of a bare URL.
+		add(loc[0], loc[0]+len(strings.TrimRight(text[loc[0]:loc[1]], ".,;:!?'\"")))
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i][0] < spans[j][0] })
+
+	placeholders := util.NewPlaceholders("m")
+	var b strings.Builder
+	last := 0
+	for _, s := range spans {
+		b.WriteString(text[last:s[0]])
+		b.WriteString(placeholders.Add(text[s[0]:s[1]]))
+		last = s[1]
+	}
+	b.WriteString(text[last:])
+	return b.String(), placeholders
+}
+
+// codeSpans returns the positions of the code spans of text: a run of
+// backticks up to the next run of the same length.
+func codeSpans(text string) [][2]int {
+	var spans [][2]int
+	for i := 0; i < len(text); {
+		if text[i] != '`' {
+			i++
+			continue
+		}
+		n := runLength(text, i)
+		end := -1
+		for j := i + n; j < len(text); {
+			if text[j] != '`' {
+				j++
+				continue
+			}
+			if k := runLength(text, j); k == n {
+				end = j + k
+				break
+			} else {
+				j += k
+			}
+		}
+		if end < 0 {
+			i += n
+			continue
+		}
+		spans = append(spans, [2]int{i, end})
+		i = end
+	}
+	return spans
+}
+
+func runLength(text string, i int) int {
+	n := 0
+	for i+n < len(text) && text[i+n] == '`' {
+		n++
+	}
+	return n
+}
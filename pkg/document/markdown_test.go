@@ -0,0 +1,134 @@
+package document
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// wrapTranslate marks the translated prose.
+func wrapTranslate(calls *[]string) TranslateFunc {
+	return func(ctx context.Context, text string) (string, error) {
+		*calls = append(*calls, text)
+		return "T(" + text + ")", nil
+	}
+}
+
+func TestTranslateMarkdown(t *testing.T) {
+	src := "---\n" +
+		"title: Getting started\n" +
+		"---\n" +
+		"# Getting started #\n" +
+		"\n" +
+		"Install the tool with `go install` and read\n" +
+		"the [guide](https://example.com/guide \"Guide\") or https://example.com.\n" +
+		"\n" +
+		"```bash\n" +
+		"# not a heading\n" +
+		"wordflow trans hello\n" +
+		"```\n" +
+		"\n" +
+		"    indented code\n" +
+		"\n" +
+		"- First item\n" +
+		"  - [x] Nested <b>done</b>\n" +
+		"1. Ordered item[^1]\n" +
+		"\n" +
+		"> Quoted text\n" +
+		"> still quoted\n" +
+		"\n" +
+		"| Name | Description |\n" +
+		"|:-----|------------:|\n" +
+		"| `ls` | List files |\n" +
+		"\n" +
+		"Setext title\n" +
+		"============\n" +
+		"\n" +
+		"***\n" +
+		"<div align=\"center\">\n" +
+		"  <img src=\"logo.png\">\n" +
+		"</div>\n" +
+		"\n" +
+		"[guide]: https://example.com/guide\n" +
+		"[^1]: A footnote.\n" +
+		"![logo](logo.png)\n"
+
+	want := "---\n" +
+		"title: Getting started\n" +
+		"---\n" +
+		"# T(Getting started) #\n" +
+		"\n" +
+		"T(Install the tool with `go install` and read the [guide](https://example.com/guide \"Guide\") or https://example.com.)\n" +
+		"\n" +
+		"```bash\n" +
+		"# not a heading\n" +
+		"wordflow trans hello\n" +
+		"```\n" +
+		"\n" +
+		"    indented code\n" +
+		"\n" +
+		"- T(First item)\n" +
+		"  - [x] T(Nested <b>done</b>)\n" +
+		"1. T(Ordered item[^1])\n" +
+		"\n" +
+		"> T(Quoted text still quoted)\n" +
+		"\n" +
+		"| T(Name) | T(Description) |\n" +
+		"|:-----|------------:|\n" +
+		"| `ls` | T(List files) |\n" +
+		"\n" +
+		"T(Setext title)\n" +
+		"============\n" +
+		"\n" +
+		"***\n" +
+		"<div align=\"center\">\n" +
+		"  <img src=\"logo.png\">\n" +
+		"</div>\n" +
+		"\n" +
+		"[guide]: https://example.com/guide\n" +
+		"[^1]: T(A footnote.)\n" +
+		"![logo](logo.png)\n"
+
+	var calls []string
+	got, err := TranslateMarkdown(context.Background(), []byte(src), wrapTranslate(&calls), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want {
+		t.Errorf("unexpected document:\n%s\nwant:\n%s", got, want)
+	}
+	wantCall := "Install the tool with {{m1}} and read the {{m2}}guide{{m3}} or {{m4}}."
+	if len(calls) < 2 || calls[1] != wantCall {
+		t.Errorf("expected the markup masked, got %q", calls)
+	}
+	for _, call := range calls {
+		if strings.Contains(call, "wordflow trans") || strings.Contains(call, "title:") {
+			t.Errorf("expected code and front matter to be kept, got %q", call)
+		}
+	}
+}
+
+func TestTranslateMarkdownLostPlaceholder(t *testing.T) {
+	translate := func(ctx context.Context, text string) (string, error) {
+		return "Führe aus.", nil
+	}
+	got, err := TranslateMarkdown(context.Background(), []byte("Run `make` now."), translate, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "Run `make` now." {
+		t.Errorf("expected the prose to be kept when a placeholder is lost, got %q", got)
+	}
+}
+
+func TestLookupFormat(t *testing.T) {
+	if f, ok := Lookup("Markdown"); !ok || f.Name != "markdown" {
+		t.Errorf("Lookup(Markdown) = %v, %v", f.Name, ok)
+	}
+	if f, ok := ForFile("docs/README.md"); !ok || f.Name != "markdown" {
+		t.Errorf("ForFile(README.md) = %v, %v", f.Name, ok)
+	}
+	if _, ok := ForFile("notes.txt"); ok {
+		t.Error("expected no format for .txt")
+	}
+}
@@ -0,0 +1,231 @@
+package document
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	poKeywordRe = regexp.MustCompile(`^(msgctxt|msgid|msgid_plural|msgstr(?:\[(\d+)\])?)\s+(".*")\s*$`)
+	nPluralsRe  = regexp.MustCompile(`nplurals\s*=\s*(\d+)`)
+)
+
+// poMessage is an entry of a PO file.
+type poMessage struct {
+	lines       []string
+	ctxt        *string
+	id          *string
+	idPlural    *string
+	str         map[int]string
+	flags       []string
+	translated  bool
+	mismatch    bool
+	translation []string
+}
+
+// TranslatePO translates the messages of a gettext PO or POT file, with
+// their placeholders such as %s, %(name)s or {0} kept. Messages that already
+// have a translation are skipped unless opts.Force. Plural messages get as
+// many translations as the Plural-Forms of the header tell, and messages
+// whose translation lost a placeholder are marked fuzzy.
+func TranslatePO(ctx context.Context, src []byte, translate TranslateFunc, opts Options) ([]byte, error) {
+	text := strings.ReplaceAll(string(src), "\r\n", "\n")
+	messages := parsePO(text)
+
+	nplurals := 2
+	for _, m := range messages {
+		if m.id != nil && *m.id == "" && m.ctxt == nil {
+			if match := nPluralsRe.FindStringSubmatch(m.str[0]); match != nil {
+				nplurals, _ = strconv.Atoi(match[1])
+			}
+		}
+	}
+
+	var entries []*i18nEntry
+	var pending []*poMessage
+	for _, m := range messages {
+		if m.id == nil || *m.id == "" || m.translated && !opts.Force {
+			continue
+		}
+		pending = append(pending, m)
+		// Languages of a single plural form only need the plural.
+		if m.idPlural == nil || nplurals > 1 {
+			entries = append(entries, &i18nEntry{key: *m.id, source: *m.id})
+		}
+		if m.idPlural != nil {
+			entries = append(entries, &i18nEntry{key: *m.idPlural, source: *m.idPlural})
+		}
+	}
+	if err := translateEntries(ctx, entries, translate, opts); err != nil {
+		return nil, err
+	}
+	for _, m := range pending {
+		var singular *i18nEntry
+		if m.idPlural == nil || nplurals > 1 {
+			singular, entries = entries[0], entries[1:]
+			m.mismatch = singular.mismatch
+		}
+		if m.idPlural == nil {
+			m.translation = []string{singular.translation}
+			continue
+		}
+		plural := entries[0]
+		entries = entries[1:]
+		m.mismatch = m.mismatch || plural.mismatch
+		m.translation = make([]string, nplurals)
+		for i := range m.translation {
+			m.translation[i] = plural.translation
+		}
+		if singular != nil {
+			m.translation[0] = singular.translation
+		}
+	}
+
+	var b strings.Builder
+	for i, m := range messages {
+		if i > 0 {
+			b.WriteString("\n\n")
+		}
+		b.WriteString(strings.Join(m.render(), "\n"))
+	}
+	b.WriteString("\n")
+	return []byte(b.String()), nil
+}
+
+// parsePO splits a PO file into messages separated by blank lines.
+func parsePO(text string) []*poMessage {
+	var messages []*poMessage
+	for _, block := range blankLinesRe.Split(strings.Trim(text, "\n"), -1) {
+		m := &poMessage{lines: strings.Split(strings.Trim(block, "\n"), "\n"), str: make(map[int]string)}
+		// current appends the continuation lines of the last keyword.
+		var current func(s string)
+		for _, line := range m.lines {
+			line = strings.TrimSpace(line)
+			if strings.HasPrefix(line, "#,") {
+				for _, flag := range strings.Split(line[2:], ",") {
+					m.flags = append(m.flags, strings.TrimSpace(flag))
+				}
+			}
+			if strings.HasPrefix(line, `"`) && current != nil {
+				current(poUnquote(line))
+				continue
+			}
+			match := poKeywordRe.FindStringSubmatch(line)
+			if match == nil {
+				current = nil
+				continue
+			}
+			value := poUnquote(match[3])
+			switch match[1] {
+			case "msgctxt":
+				m.ctxt = &value
+				current = func(s string) { *m.ctxt += s }
+			case "msgid":
+				m.id = &value
+				current = func(s string) { *m.id += s }
+			case "msgid_plural":
+				m.idPlural = &value
+				current = func(s string) { *m.idPlural += s }
+			default:
+				n, _ := strconv.Atoi(match[2])
+				m.str[n] = value
+				current = func(s string) { m.str[n] += s }
+			}
+		}
+		messages = append(messages, m)
+	}
+	for _, m := range messages {
+		for _, s := range m.str {
+			m.translated = m.translated || s != ""
+		}
+	}
+	return messages
+}
+
+// render returns the lines of a message, with its translation when it has a
+// new one.
+func (m *poMessage) render() []string {
+	if m.translation == nil {
+		return m.lines
+	}
+	var lines []string
+	flagged := false
+	addFlags := func() {
+		flags := m.flags
+		if m.mismatch && !contains(flags, "fuzzy") {
+			flags = append(flags, "fuzzy")
+		}
+		if len(flags) > 0 {
+			lines = append(lines, "#, "+strings.Join(flags, ", "))
+		}
+		flagged = true
+	}
+	inMsgstr := false
+	for _, line := range m.lines {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, "#,"):
+			addFlags()
+			continue
+		case strings.HasPrefix(trimmed, "msgstr"):
+			inMsgstr = true
+			continue
+		case strings.HasPrefix(trimmed, `"`) && inMsgstr:
+			continue
+		case !strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "#|"):
+			if !flagged {
+				addFlags()
+			}
+		}
+		inMsgstr = false
+		lines = append(lines, line)
+	}
+	if m.idPlural == nil {
+		return append(lines, poString("msgstr", m.translation[0])...)
+	}
+	for i, translation := range m.translation {
+		lines = append(lines, poString("msgstr["+strconv.Itoa(i)+"]", translation)...)
+	}
+	return lines
+}
+
+// poString writes a keyword and its string, one line per line of a string
+// with several.
+func poString(keyword, s string) []string {
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) <= 1 {
+		return []string{keyword + " " + poQuote(s)}
+	}
+	out := []string{keyword + ` ""`}
+	for _, line := range lines {
+		out = append(out, poQuote(line))
+	}
+	return out
+}
+
+var poEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`, "\r", `\r`)
+
+func poQuote(s string) string {
+	return `"` + poEscaper.Replace(s) + `"`
+}
+
+func poUnquote(s string) string {
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		return unquoted
+	}
+	return strings.Trim(s, `"`)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,260 @@
+package document
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gogodjzhu/word-flow/internal/lang"
+	"github.com/gogodjzhu/word-flow/pkg/util"
+)
+
+const (
+	// subtitleBatchSize is the number of bytes of sentences translated with a
+	// single request, one sentence per line.
+	subtitleBatchSize = 1500
+	// maxSentenceCues is the number of cues a sentence is merged over at most,
+	// for subtitles without punctuation.
+	maxSentenceCues = 6
+)
+
+var (
+	// subtitleTagRe matches the formatting of cue text: HTML-like tags such
+	// as <i> or <v Speaker>, timestamps and SubStation overrides like {\an8}.
+	subtitleTagRe = regexp.MustCompile(`<[^>]*>|\{\\[^}]*\}`)
+	// subtitlePrefixRe matches the voice and position of a cue, which are
+	// kept in front of its translation.
+	subtitlePrefixRe = regexp.MustCompile(`^(\s*(<v(\.[^ >]*)?\s[^>]*>|\{\\[^}]*\})\s*)+`)
+	// sentenceEndRe matches text ending a sentence, before closing quotes
+	// and brackets.
+	sentenceEndRe = regexp.MustCompile(`[.!?。！？…♪][\s"'”’)\]」』]*$`)
+	blankLinesRe  = regexp.MustCompile(`\n\s*\n`)
+)
+
+// cue is a block of a subtitle file. Blocks without timing, such as the
+// WEBVTT header and NOTE or STYLE blocks, only have head lines.
+type cue struct {
+	// head are the lines up to the timing line, kept as they are.
+	head   []string
+	timed  bool
+	prefix string
+	text   string
+	lines  []string
+	// translation of the text, when it has one.
+	translation string
+}
+
+// TranslateSubtitles translates the text of the cues of SubRip or WebVTT
+// subtitles, keeping numbering, identifiers and timing. Sentences split over
+// several cues are merged and translated as a whole, and the translation is
+// spread back over the cues in proportion to their length. Formatting tags
+// are dropped from translated text, voices and positions are kept. Bilingual
+// subtitles show the original lines above the translation.
+func TranslateSubtitles(ctx context.Context, src []byte, translate TranslateFunc, opts Options) ([]byte, error) {
+	text := strings.TrimPrefix(strings.ReplaceAll(string(src), "\r\n", "\n"), "\ufeff")
+	cues := parseCues(text)
+
+	var sentences [][]*cue
+	var current []*cue
+	for _, c := range cues {
+		if !c.timed || !hasLetters(c.text) {
+			continue
+		}
+		current = append(current, c)
+		if sentenceEndRe.MatchString(c.text) || len(current) == maxSentenceCues {
+			sentences = append(sentences, current)
+			current = nil
+		}
+	}
+	if len(current) > 0 {
+		sentences = append(sentences, current)
+	}
+
+	texts := make([]string, len(sentences))
+	for i, sentence := range sentences {
+		parts := make([]string, len(sentence))
+		for j, c := range sentence {
+			parts[j] = c.text
+		}
+		texts[i] = strings.Join(parts, " ")
+	}
+	translations, err := translateLines(ctx, texts, translate)
+	if err != nil {
+		return nil, err
+	}
+	for i, sentence := range sentences {
+		weights := make([]int, len(sentence))
+		for j, c := range sentence {
+			weights[j] = utf8.RuneCountInString(c.text)
+		}
+		for j, part := range splitProportionally(translations[i], weights) {
+			sentence[j].translation = part
+		}
+	}
+
+	var b strings.Builder
+	for i, c := range cues {
+		if i > 0 {
+			b.WriteString("\n\n")
+		}
+		b.WriteString(strings.Join(c.head, "\n"))
+		if !c.timed {
+			continue
+		}
+		switch {
+		case c.translation == "":
+			writeLines(&b, c.lines)
+		case opts.Bilingual:
+			writeLines(&b, c.lines)
+			b.WriteString("\n" + c.prefix + c.translation)
+		default:
+			b.WriteString("\n" + c.prefix + c.translation)
+		}
+	}
+	b.WriteString("\n")
+	return []byte(b.String()), nil
+}
+
+func writeLines(b *strings.Builder, lines []string) {
+	for _, line := range lines {
+		b.WriteString("\n" + line)
+	}
+}
+
+// parseCues splits subtitles into blocks separated by blank lines.
+func parseCues(text string) []*cue {
+	var cues []*cue
+	for _, block := range blankLinesRe.Split(strings.Trim(text, "\n"), -1) {
+		lines := strings.Split(strings.Trim(block, "\n"), "\n")
+		c := &cue{head: lines}
+		for i, line := range lines {
+			if strings.Contains(line, "-->") && !strings.HasPrefix(line, "NOTE") {
+				c.head, c.lines, c.timed = lines[:i+1], lines[i+1:], true
+				break
+			}
+		}
+		if c.timed {
+			joined := strings.Join(c.lines, " ")
+			c.prefix = subtitlePrefixRe.FindString(joined)
+			c.text = strings.Join(strings.Fields(subtitleTagRe.ReplaceAllString(joined, " ")), " ")
+		}
+		cues = append(cues, c)
+	}
+	return cues
+}
+
+// translateLines translates texts in batches of lines. Batches whose
+// translation does not have a line per text are translated text by text.
+func translateLines(ctx context.Context, texts []string, translate TranslateFunc) ([]string, error) {
+	translations := make([]string, 0, len(texts))
+	for _, batch := range util.BatchSegments(texts, subtitleBatchSize) {
+		translated, err := translate(ctx, strings.Join(batch, "\n"))
+		if err != nil {
+			return nil, err
+		}
+		var lines []string
+		for _, line := range strings.Split(translated, "\n") {
+			if strings.TrimSpace(line) != "" {
+				lines = append(lines, strings.TrimSpace(line))
+			}
+		}
+		if len(lines) == len(batch) {
+			translations = append(translations, lines...)
+			continue
+		}
+		for _, text := range batch {
+			translated, err := translate(ctx, text)
+			if err != nil {
+				return nil, err
+			}
+			translations = append(translations, strings.Join(strings.Fields(translated), " "))
+		}
+	}
+	return translations, nil
+}
+
+// splitProportionally splits text into len(weights) parts whose lengths
+// follow the weights. Text written with spaces is split between words, and
+// Chinese and Japanese between characters, after punctuation when there is
+// some nearby.
+func splitProportionally(text string, weights []int) []string {
+	parts := make([]string, len(weights))
+	if len(weights) == 1 {
+		parts[0] = text
+		return parts
+	}
+	var units []string
+	separator := " "
+	if code := lang.Detect(text).Code; lang.IsChinese(code) || code == "ja" {
+		separator = ""
+		for _, r := range text {
+			units = append(units, string(r))
+		}
+	} else {
+		units = strings.Fields(text)
+	}
+
+	total := 0
+	for _, w := range weights {
+		total += w
+	}
+	length := 0
+	for _, u := range units {
+		length += utf8.RuneCountInString(u)
+	}
+	if total == 0 || length == 0 {
+		parts[0] = text
+		return parts
+	}
+
+	start, consumed, cumulative := 0, 0, 0
+	for i, w := range weights {
+		cumulative += w
+		if i == len(weights)-1 {
+			parts[i] = strings.TrimSpace(strings.Join(units[start:], separator))
+			break
+		}
+		target := length * cumulative / total
+		// Leave a unit for each of the following cues, when there are enough.
+		limit := len(units) - (len(weights) - 1 - i)
+		end := start
+		for end < limit && consumed+utf8.RuneCountInString(units[end])/2 < target {
+			consumed += utf8.RuneCountInString(units[end])
+			end++
+		}
+		if separator == "" {
+			end, consumed = afterPunctuation(units, start, end, limit, consumed)
+		}
+		parts[i] = strings.TrimSpace(strings.Join(units[start:end], separator))
+		start = end
+	}
+	return parts
+}
+
+// afterPunctuation moves the end of a part of characters right after a
+// punctuation mark up to three characters away, before limit.
+func afterPunctuation(units []string, start, end, limit, consumed int) (int, int) {
+	for d := 0; d <= 3; d++ {
+		for _, e := range []int{end + d, end - d} {
+			if e > start && e <= limit && isPunct(units[e-1]) {
+				for e > end {
+					consumed += utf8.RuneCountInString(units[end])
+					end++
+				}
+				for e < end {
+					end--
+					consumed -= utf8.RuneCountInString(units[end])
+				}
+				return end, consumed
+			}
+		}
+	}
+	return end, consumed
+}
+
+func isPunct(s string) bool {
+	r, _ := utf8.DecodeRuneInString(s)
+	return unicode.IsPunct(r)
+}
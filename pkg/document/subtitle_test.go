@@ -0,0 +1,137 @@
+package document
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// wordTranslate translates each line of a text by upper-casing it.
+func wordTranslate(calls *[]string) TranslateFunc {
+	return func(ctx context.Context, text string) (string, error) {
+		*calls = append(*calls, text)
+		return strings.ToUpper(text), nil
+	}
+}
+
+func TestTranslateSubtitlesSRT(t *testing.T) {
+	src := "1\r\n" +
+		"00:00:01,000 --> 00:00:02,500\r\n" +
+		"<i>This sentence goes</i>\r\n" +
+		"\r\n" +
+		"2\r\n" +
+		"00:00:02,500 --> 00:00:04,000\r\n" +
+		"over two cues.\r\n" +
+		"\r\n" +
+		"3\r\n" +
+		"00:00:05,000 --> 00:00:06,000\r\n" +
+		"Hello!\r\n" +
+		"\r\n" +
+		"4\r\n" +
+		"00:00:07,000 --> 00:00:08,000\r\n" +
+		"♪ ♪\r\n"
+
+	var calls []string
+	out, err := TranslateSubtitles(context.Background(), []byte(src), wordTranslate(&calls), Options{})
+	if err != nil {
+		t.Fatalf("TranslateSubtitles() error = %v", err)
+	}
+	want := "1\n" +
+		"00:00:01,000 --> 00:00:02,500\n" +
+		"THIS SENTENCE GOES\n" +
+		"\n" +
+		"2\n" +
+		"00:00:02,500 --> 00:00:04,000\n" +
+		"OVER TWO CUES.\n" +
+		"\n" +
+		"3\n" +
+		"00:00:05,000 --> 00:00:06,000\n" +
+		"HELLO!\n" +
+		"\n" +
+		"4\n" +
+		"00:00:07,000 --> 00:00:08,000\n" +
+		"♪ ♪\n"
+	if string(out) != want {
+		t.Errorf("TranslateSubtitles() =\n%s\nwant\n%s", out, want)
+	}
+	wantCalls := []string{"This sentence goes over two cues.\nHello!"}
+	if !reflect.DeepEqual(calls, wantCalls) {
+		t.Errorf("translated %q, want %q", calls, wantCalls)
+	}
+
+	calls = nil
+	out, err = TranslateSubtitles(context.Background(), []byte(src), wordTranslate(&calls), Options{Bilingual: true})
+	if err != nil {
+		t.Fatalf("TranslateSubtitles() error = %v", err)
+	}
+	if !strings.Contains(string(out), "00:00:05,000 --> 00:00:06,000\nHello!\nHELLO!\n") {
+		t.Errorf("bilingual output misses the original lines:\n%s", out)
+	}
+}
+
+func TestTranslateSubtitlesVTT(t *testing.T) {
+	src := "WEBVTT\n" +
+		"\n" +
+		"NOTE written by hand --> not a cue\n" +
+		"\n" +
+		"intro\n" +
+		"00:01.000 --> 00:03.000 align:start\n" +
+		"<v Roger>How are you?\n"
+
+	var calls []string
+	out, err := TranslateSubtitles(context.Background(), []byte(src), wordTranslate(&calls), Options{})
+	if err != nil {
+		t.Fatalf("TranslateSubtitles() error = %v", err)
+	}
+	want := "WEBVTT\n" +
+		"\n" +
+		"NOTE written by hand --> not a cue\n" +
+		"\n" +
+		"intro\n" +
+		"00:01.000 --> 00:03.000 align:start\n" +
+		"<v Roger>HOW ARE YOU?\n"
+	if string(out) != want {
+		t.Errorf("TranslateSubtitles() =\n%s\nwant\n%s", out, want)
+	}
+}
+
+func TestTranslateSubtitlesLineMismatch(t *testing.T) {
+	src := "1\n00:00:01,000 --> 00:00:02,000\nOne.\n\n" +
+		"2\n00:00:03,000 --> 00:00:04,000\nTwo.\n"
+	var calls []string
+	translate := func(ctx context.Context, text string) (string, error) {
+		calls = append(calls, text)
+		// Joins the lines of a batch.
+		return strings.ReplaceAll(text, "\n", " ") + "!", nil
+	}
+	out, err := TranslateSubtitles(context.Background(), []byte(src), translate, Options{})
+	if err != nil {
+		t.Fatalf("TranslateSubtitles() error = %v", err)
+	}
+	wantCalls := []string{"One.\nTwo.", "One.", "Two."}
+	if !reflect.DeepEqual(calls, wantCalls) {
+		t.Errorf("translated %q, want %q", calls, wantCalls)
+	}
+	if !strings.Contains(string(out), "\nOne.!\n") || !strings.Contains(string(out), "\nTwo.!\n") {
+		t.Errorf("TranslateSubtitles() =\n%s", out)
+	}
+}
+
+func TestSplitProportionally(t *testing.T) {
+	tests := []struct {
+		text    string
+		weights []int
+		want    []string
+	}{
+		{"one two three four", []int{10, 10}, []string{"one two", "three four"}},
+		{"one two three", []int{30, 1}, []string{"one two", "three"}},
+		{"我们今天，学习翻译字幕", []int{5, 6}, []string{"我们今天，", "学习翻译字幕"}},
+		{"solo", []int{3}, []string{"solo"}},
+	}
+	for _, tt := range tests {
+		if got := splitProportionally(tt.text, tt.weights); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitProportionally(%q, %v) = %q, want %q", tt.text, tt.weights, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package document
+
+import (
+	"bytes"
+	"context"
+	"regexp"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+var yamlIndentRe = regexp.MustCompile(`\n( +)\S`)
+
+// TranslateYAML translates the string values of a YAML resource file, such
+// as the locales of Rails. Keys, other values, comments and the order of the
+// keys are kept, and so are placeholders like %{name} and {count}. Values
+// translated in opts.Previous, not empty nor the same as the source, are
+// kept unless opts.Force.
+func TranslateYAML(ctx context.Context, src []byte, translate TranslateFunc, opts Options) ([]byte, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(src, &root); err != nil {
+		return nil, errors.Wrap(err, "failed to parse YAML")
+	}
+	var previous *yaml.Node
+	if len(opts.Previous) > 0 && !opts.Force {
+		var prev yaml.Node
+		// An unreadable previous translation is translated again.
+		if yaml.Unmarshal(opts.Previous, &prev) == nil {
+			previous = &prev
+		}
+	}
+
+	var entries []*i18nEntry
+	var nodes []*yaml.Node
+	walkYAML("", &root, previous, func(key string, n, prev *yaml.Node) {
+		if prev != nil && prev.Kind == yaml.ScalarNode && prev.ShortTag() == "!!str" && prev.Value != "" && prev.Value != n.Value {
+			n.Value = prev.Value
+			return
+		}
+		entries = append(entries, &i18nEntry{key: key, source: n.Value})
+		nodes = append(nodes, n)
+	})
+	if err := translateEntries(ctx, entries, translate, opts); err != nil {
+		return nil, err
+	}
+	for i, e := range entries {
+		nodes[i].Value = e.translation
+	}
+
+	indent := 2
+	if m := yamlIndentRe.FindSubmatch(src); m != nil {
+		indent = len(m[1])
+	}
+	var buf bytes.Buffer
+	e := yaml.NewEncoder(&buf)
+	e.SetIndent(indent)
+	if err := e.Encode(&root); err != nil {
+		return nil, errors.Wrap(err, "failed to write YAML")
+	}
+	if err := e.Close(); err != nil {
+		return nil, errors.Wrap(err, "failed to write YAML")
+	}
+	return buf.Bytes(), nil
+}
+
+// walkYAML calls f with the string scalars of n, their dotted key and the
+// node at the same place of prev, if any. Keys and aliases are skipped.
+func walkYAML(key string, n, prev *yaml.Node, f func(key string, n, prev *yaml.Node)) {
+	if prev != nil && prev.Kind != n.Kind {
+		prev = nil
+	}
+	switch n.Kind {
+	case yaml.DocumentNode:
+		for i, child := range n.Content {
+			var p *yaml.Node
+			if prev != nil && i < len(prev.Content) {
+				p = prev.Content[i]
+			}
+			walkYAML(key, child, p, f)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			k := n.Content[i].Value
+			child := k
+			if key != "" {
+				child = key + "." + k
+			}
+			walkYAML(child, n.Content[i+1], yamlMember(prev, k), f)
+		}
+	case yaml.SequenceNode:
+		for i, item := range n.Content {
+			var p *yaml.Node
+			if prev != nil && i < len(prev.Content) {
+				p = prev.Content[i]
+			}
+			walkYAML(key+"["+strconv.Itoa(i)+"]", item, p, f)
+		}
+	case yaml.ScalarNode:
+		if n.ShortTag() == "!!str" {
+			f(key, n, prev)
+		}
+	}
+}
+
+// yamlMember returns the value of a key of a mapping, or nil.
+func yamlMember(n *yaml.Node, key string) *yaml.Node {
+	if n == nil || n.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i+1]
+		}
+	}
+	return nil
+}
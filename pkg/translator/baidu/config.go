@@ -0,0 +1,65 @@
+package baidu
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gogodjzhu/word-flow/internal/config"
+)
+
+// Config is the trans.baidu section.
+type Config struct {
+	AppID   string             `yaml:"app_id"`
+	Secret  string             `yaml:"secret"`
+	Timeout config.Duration    `yaml:"timeout,omitempty"`
+	HTTP    *config.HTTPConfig `yaml:"http,omitempty"`
+	From    string             `yaml:"from,omitempty"`
+	To      string             `yaml:"to,omitempty"`
+	// Concurrency is the number of batches of a text translated at once,
+	// the rate limit of HTTP still applies.
+	Concurrency int `yaml:"concurrency,omitempty"`
+}
+
+// ConfigSection registers Config with the translator.
+var ConfigSection = config.EndpointSpec{
+	New: func() config.EndpointConfig { return &Config{} },
+	Defaults: func(c config.EndpointConfig, dir string) {
+		baidu := c.(*Config)
+		config.DefaultTimeout(&baidu.Timeout, 30*time.Second)
+		// The standard Baidu Translate API allows one query per second.
+		config.DefaultHTTP(&baidu.HTTP, 1)
+		config.DefaultLanguages(&baidu.From, &baidu.To)
+		config.DefaultConcurrency(&baidu.Concurrency, 2)
+	},
+	Template: `baidu:
+  # Baidu Translate API credentials (required if trans.default is baidu)
+  # app_id: ""           # Required. Set via WORDFLOW_TRANS_BAIDU_APP_ID or wordflow config set trans.baidu.app_id
+  # secret: ""            # Required. Set via WORDFLOW_TRANS_BAIDU_SECRET or wordflow config set trans.baidu.secret
+  timeout: 30s
+  from: auto
+  to: zh
+  concurrency: 2          # Batches of long texts translated at once, within rate_limit
+  # http:
+  #   rate_limit: 1       # Queries per second of your Baidu plan
+`,
+}
+
+func (c *Config) Languages() (string, string) {
+	if c == nil {
+		return "", ""
+	}
+	return c.From, c.To
+}
+
+func (c *Config) Validate() error {
+	if c.AppID == "" {
+		return errors.New("trans.baidu.app_id is required")
+	}
+	if c.Secret == "" {
+		return errors.New("trans.baidu.secret is required")
+	}
+	if c.Concurrency < 0 {
+		return errors.New("trans.baidu.concurrency must not be negative")
+	}
+	return config.ValidateLanguages("trans.baidu", c.From, c.To)
+}
@@ -0,0 +1,41 @@
+package baidu
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConfigDefaults(t *testing.T) {
+	c := ConfigSection.New().(*Config)
+	ConfigSection.Defaults(c, t.TempDir())
+	if c.HTTP == nil || c.HTTP.RateLimit != 1 || c.HTTP.Retries != 2 || c.Concurrency != 2 {
+		t.Errorf("defaults = %+v, http = %+v, want rate_limit 1, 2 retries and 2 batches at once", c, c.HTTP)
+	}
+	if from, to := c.Languages(); from != "auto" || to != "zh" {
+		t.Errorf("languages = %s, %s, want auto, zh", from, to)
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  Config
+		wantErr string
+	}{
+		{name: "valid", config: Config{AppID: "id", Secret: "secret"}},
+		{name: "no app_id", config: Config{Secret: "secret"}, wantErr: "trans.baidu.app_id is required"},
+		{name: "no secret", config: Config{AppID: "id"}, wantErr: "trans.baidu.secret is required"},
+		{name: "languages", config: Config{AppID: "id", Secret: "secret", To: "xx"}, wantErr: "invalid trans.baidu.to"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("Validate() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("Validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package baidu
 
 import (
+	"context"
 	"crypto/md5"
 	"encoding/hex"
 	"encoding/json"
@@ -12,20 +13,71 @@ import (
 	"strings"
 	"time"
 
-	"github.com/gogodjzhu/word-flow/internal/config"
+	"github.com/gogodjzhu/word-flow/internal/buzz_error"
+	"github.com/gogodjzhu/word-flow/internal/lang"
 	httputil "github.com/gogodjzhu/word-flow/internal/util"
 	"github.com/gogodjzhu/word-flow/pkg/translator/types"
+	"github.com/gogodjzhu/word-flow/pkg/util"
 	"github.com/pkg/errors"
 )
 
 type TranslatorBaidu struct {
-	cfg *config.TransBaiduConfig
+	cfg *Config
 }
 
-func NewTranslatorBaidu(cfg *config.TransBaiduConfig) *TranslatorBaidu {
+func NewTranslatorBaidu(cfg *Config) *TranslatorBaidu {
 	return &TranslatorBaidu{cfg: cfg}
 }
 
+func (t *TranslatorBaidu) timeout() time.Duration {
+	if t.cfg == nil {
+		return 0
+	}
+	return time.Duration(t.cfg.Timeout)
+}
+
+// concurrency returns the number of batches translated at once.
+func (t *TranslatorBaidu) concurrency() int {
+	if t.cfg == nil {
+		return 1
+	}
+	return t.cfg.Concurrency
+}
+
+func (t *TranslatorBaidu) policy() *httputil.Policy {
+	if t.cfg == nil {
+		return nil
+	}
+	return httputil.PolicyFromConfig(t.cfg.HTTP)
+}
+
+// batchSize is the number of bytes of lines sent with a query, well below the
+// 6000 bytes Baidu allows.
+const batchSize = 2000
+
+// languageCodes maps the codes of internal/lang to Baidu's.
+var languageCodes = map[string]string{
+	lang.Auto: "auto",
+	"en":      "en",
+	"zh":      "zh",
+	"zh-TW":   "cht",
+	"lzh":     "wyw",
+	"ja":      "jp",
+	"ko":      "kor",
+	"de":      "de",
+	"fr":      "fra",
+	"es":      "spa",
+	"it":      "it",
+	"pt":      "pt",
+	"ru":      "ru",
+}
+
+// SupportsLanguage reports whether Baidu translates from or to code.
+func (t *TranslatorBaidu) SupportsLanguage(code string) bool {
+	_, ok := languageCodes[code]
+	return ok
+}
+
 type baiduResponse struct {
 	From        string `json:"from"`
 	To          string `json:"to"`
@@ -41,7 +93,7 @@ func generateSign(appid, text, salt, secret string) string {
 	return hex.EncodeToString(h[:])
 }
 
-func callBaiduTranslate(text string, cfg *config.TransBaiduConfig) (string, error) {
+func callBaiduTranslate(ctx context.Context, text, from, to string, cfg *Config) (string, error) {
 	salt := strconv.FormatInt(time.Now().Unix(), 10)
 	sign := generateSign(cfg.AppID, text, salt, cfg.Secret)
 
@@ -49,8 +101,8 @@ func callBaiduTranslate(text string, cfg *config.TransBaiduConfig) (string, erro
 
 	params := url.Values{}
 	params.Set("q", text)
-	params.Set("from", "auto")
-	params.Set("to", "zh")
+	params.Set("from", from)
+	params.Set("to", to)
 	params.Set("appid", cfg.AppID)
 	params.Set("salt", salt)
 	params.Set("sign", sign)
@@ -60,7 +112,7 @@ func callBaiduTranslate(text string, cfg *config.TransBaiduConfig) (string, erro
 		"Content-Type": "application/x-www-form-urlencoded",
 	}
 
-	result, err := httputil.SendPost(apiURL, headers, body, func(response *http.Response) (interface{}, error) {
+	result, err := httputil.SendPostContext(ctx, apiURL, headers, body, func(response *http.Response) (interface{}, error) {
 		if response.StatusCode != 200 {
 			return nil, fmt.Errorf("unexpected status: %s", response.Status)
 		}
@@ -85,18 +137,22 @@ func callBaiduTranslate(text string, cfg *config.TransBaiduConfig) (string, erro
 		return "", errors.Wrap(err, "failed to call Baidu Translate API")
 	}
 
+	// Each line of the query has its result, the translation keeps the lines.
 	baiduResp := result.(baiduResponse)
-	var translated strings.Builder
+	lines := make([]string, len(baiduResp.TransResult))
 	for i, item := range baiduResp.TransResult {
-		translated.WriteString(item.Dst)
-		if i < len(baiduResp.TransResult)-1 {
-			translated.WriteString(" ")
-		}
+		lines[i] = item.Dst
 	}
-	return translated.String(), nil
+	return strings.Join(lines, "\n"), nil
 }
 
 func (t *TranslatorBaidu) Translate(text string, out io.Writer, opts *types.TransOptions) error {
+	return t.TranslateContext(context.Background(), text, out, opts)
+}
+
+func (t *TranslatorBaidu) TranslateContext(ctx context.Context, text string, out io.Writer, opts *types.TransOptions) error {
+	ctx = httputil.WithRequestTimeout(ctx, t.timeout())
+	ctx = httputil.WithEndpoint(httputil.WithPolicy(ctx, t.policy()), "trans.baidu")
 	text = strings.TrimSpace(text)
 	if text == "" {
 		return errors.New("text is empty")
@@ -104,52 +160,63 @@ func (t *TranslatorBaidu) Translate(text string, out io.Writer, opts *types.Tran
 	if opts == nil {
 		opts = &types.TransOptions{}
 	}
+	source, target := opts.Languages(t.cfg)
+	from, ok := languageCodes[source]
+	if !ok {
+		return buzz_error.InvalidInput("Baidu Translate does not support source language " + source)
+	}
+	to, ok := languageCodes[target]
+	if !ok {
+		return buzz_error.InvalidInput("Baidu Translate does not support target language " + target)
+	}
 
-	switch {
-	case !opts.Ref && !opts.NoStream:
-		translated, err := callBaiduTranslate(text, t.cfg)
-		if err != nil {
-			return err
-		}
-		fmt.Fprint(out, translated)
-		return nil
-
-	case !opts.Ref && opts.NoStream:
-		translated, err := callBaiduTranslate(text, t.cfg)
-		if err != nil {
-			return err
-		}
-		fmt.Fprint(out, translated)
-		return nil
-
-	case opts.Ref && !opts.NoStream:
-		translated, err := callBaiduTranslate(text, t.cfg)
-		if err != nil {
-			return err
-		}
-		refPairs := []map[string]string{
-			{"raw": text, "translation": translated},
-		}
-		data, err := json.Marshal(refPairs)
-		if err != nil {
-			return errors.Wrap(err, "failed to marshal ref pairs")
-		}
-		out.Write(data)
-		return nil
-
-	default:
-		translated, err := callBaiduTranslate(text, t.cfg)
-		if err != nil {
-			return err
+	// Baidu translates the lines of a query one by one, long texts are sent
+	// as batches of lines.
+	batches := util.BatchSegments(strings.Split(text, "\n"), batchSize)
+	translations := make([]string, len(batches))
+	// Without streaming the output is written once it is complete.
+	w := out
+	var buffered strings.Builder
+	if opts.NoStream {
+		w = &buffered
+	}
+	// Batches are made of whole lines.
+	separator := "\n"
+	if opts.Ref {
+		fmt.Fprint(w, "[")
+		separator = ","
+	}
+	err := util.Ordered(ctx, len(batches), t.concurrency(), func(ctx context.Context, i int) error {
+		var err error
+		translations[i], err = callBaiduTranslate(ctx, strings.Join(batches[i], "\n"), from, to, t.cfg)
+		return err
+	}, func(i int) error {
+		if i > 0 {
+			fmt.Fprint(w, separator)
 		}
-		refPairs := []map[string]string{
-			{"raw": text, "translation": translated},
+		if !opts.Ref {
+			fmt.Fprint(w, translations[i])
+			return nil
 		}
-		data, err := json.Marshal(refPairs)
+		data, err := json.Marshal(map[string]string{
+			"raw":         strings.Join(batches[i], "\n"),
+			"translation": translations[i],
+		})
 		if err != nil {
-			return errors.Wrap(err, "failed to marshal ref pairs")
+			return errors.Wrap(err, "failed to marshal ref pair")
 		}
-		out.Write(data)
+		fmt.Fprint(w, string(data))
 		return nil
+	})
+	if opts.Ref {
+		// The pairs streamed before an error stay a JSON array.
+		fmt.Fprint(w, "]")
+	}
+	if err != nil {
+		return err
+	}
+	if opts.NoStream {
+		fmt.Fprint(out, buffered.String())
 	}
+	return nil
 }
\ No newline at end of file
@@ -1,10 +1,13 @@
 package baidu
 
 import (
+	"context"
 	"encoding/json"
+	"strings"
 	"testing"
 
-	"github.com/gogodjzhu/word-flow/internal/config"
+	"github.com/gogodjzhu/word-flow/internal/cassette"
+	"github.com/gogodjzhu/word-flow/pkg/translator/types"
 )
 
 func TestGenerateSign_DifferentInputs(t *testing.T) {
@@ -52,7 +55,7 @@ func TestBaiduResponse_Parsing(t *testing.T) {
 }
 
 func TestNewTranslatorBaidu(t *testing.T) {
-	cfg := &config.TransBaiduConfig{
+	cfg := &Config{
 		AppID:  "test_app_id",
 		Secret: "test_secret",
 	}
@@ -69,27 +72,27 @@ func TestNewTranslatorBaidu(t *testing.T) {
 func TestTransBaiduConfig_Validate(t *testing.T) {
 	tests := []struct {
 		name    string
-		cfg     config.TransBaiduConfig
+		cfg     Config
 		wantErr bool
 	}{
 		{
 			name:    "valid config",
-			cfg:     config.TransBaiduConfig{AppID: "app_id", Secret: "secret"},
+			cfg:     Config{AppID: "app_id", Secret: "secret"},
 			wantErr: false,
 		},
 		{
 			name:    "missing app_id",
-			cfg:     config.TransBaiduConfig{AppID: "", Secret: "secret"},
+			cfg:     Config{AppID: "", Secret: "secret"},
 			wantErr: true,
 		},
 		{
 			name:    "missing secret",
-			cfg:     config.TransBaiduConfig{AppID: "app_id", Secret: ""},
+			cfg:     Config{AppID: "app_id", Secret: ""},
 			wantErr: true,
 		},
 		{
 			name:    "both missing",
-			cfg:     config.TransBaiduConfig{AppID: "", Secret: ""},
+			cfg:     Config{AppID: "", Secret: ""},
 			wantErr: true,
 		},
 	}
@@ -102,4 +105,34 @@ func TestTransBaiduConfig_Validate(t *testing.T) {
 			}
 		})
 	}
+}
+
+func TestTranslatorBaidu_TranslateContext(t *testing.T) {
+	c := cassette.Load(t, "baidu", "appid", "salt", "sign")
+	translator := NewTranslatorBaidu(&Config{AppID: "test_app_id", Secret: "test_secret"})
+	tests := []struct {
+		name    string
+		text    string
+		opts    *types.TransOptions
+		want    string
+		wantErr bool
+	}{
+		{name: "plain", text: "Hello world.\nHow are you?", opts: &types.TransOptions{}, want: "你好，世界。\n你好吗？"},
+		{name: "ref", text: "Hello world.\nHow are you?", opts: &types.TransOptions{Ref: true},
+			want: `[{"raw":"Hello world.\nHow are you?","translation":"你好，世界。\n你好吗？"}]`},
+		{name: "api error", text: "Bad sign", opts: &types.TransOptions{}, wantErr: true},
+		{name: "english to japanese", text: "Good morning.", opts: &types.TransOptions{From: "en", To: "ja"}, want: "おはようございます。"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out strings.Builder
+			err := translator.TranslateContext(c.Context(context.Background()), tt.text, &out, tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("TranslateContext() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if out.String() != tt.want {
+				t.Errorf("TranslateContext() = %q, want %q", out.String(), tt.want)
+			}
+		})
+	}
 }
\ No newline at end of file
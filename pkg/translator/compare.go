@@ -0,0 +1,115 @@
+package translator
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/gogodjzhu/word-flow/pkg/translator/glossary"
+	"github.com/gogodjzhu/word-flow/pkg/translator/tm"
+	"github.com/gogodjzhu/word-flow/pkg/util"
+)
+
+// Comparison is the translation of a text by several translators, sentence by
+// sentence.
+type Comparison struct {
+	From, To string
+	Segments []string
+	// Renditions are in the order of the translators.
+	Renditions []Rendition
+}
+
+// Rendition is the translation of a compared text by one translator.
+type Rendition struct {
+	Translator string
+	// Translations are aligned with the segments of the comparison, nil when
+	// the translator failed.
+	Translations []string
+	Err          error
+	// Violations are the segments whose translation does not follow a
+	// glossary term, set by Check.
+	Violations []glossary.Violation
+}
+
+// Compare translates text with each of translators, named by names, in
+// parallel. The sentences of text are sent one per line so that the
+// renditions line up, and translated one by one when a translator merges or
+// splits lines. A translator that fails does not stop the others.
+func Compare(ctx context.Context, text string, names []string, translators []Translator, opts *TransOptions) *Comparison {
+	c := &Comparison{Renditions: make([]Rendition, len(translators))}
+	if opts != nil {
+		c.From, c.To = opts.From, opts.To
+	}
+	for _, segment := range util.SegmentText(strings.TrimSpace(text)) {
+		if segment = tm.Normalize(segment); segment != "" {
+			c.Segments = append(c.Segments, segment)
+		}
+	}
+
+	var wg sync.WaitGroup
+	for i, t := range translators {
+		c.Renditions[i].Translator = names[i]
+		wg.Add(1)
+		go func(r *Rendition, t Translator) {
+			defer wg.Done()
+			r.Translations, r.Err = translateAligned(ctx, t, c.Segments, c.From, c.To)
+		}(&c.Renditions[i], t)
+	}
+	wg.Wait()
+	return c
+}
+
+// Remember puts the segments of rendition i in the translation memory store,
+// as translations of translator so that it reuses them.
+func (c *Comparison) Remember(store *tm.Store, translator string, i int) error {
+	q := tm.Query{From: c.From, To: c.To, Translator: translator}
+	for j, segment := range c.Segments {
+		if err := store.Put(q, segment, c.Renditions[i].Translations[j]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Check records in each rendition the segments whose translation does not
+// follow a term of g.
+func (c *Comparison) Check(g *glossary.Glossary) {
+	for i := range c.Renditions {
+		r := &c.Renditions[i]
+		if r.Err != nil {
+			continue
+		}
+		for j, segment := range c.Segments {
+			for _, term := range glossary.Check(segment, r.Translations[j], g.Find(segment, c.From, c.To)) {
+				r.Violations = append(r.Violations, glossary.Violation{Segment: segment, Term: term})
+			}
+		}
+	}
+}
+
+func translateAligned(ctx context.Context, t Translator, segments []string, from, to string) ([]string, error) {
+	opts := &TransOptions{NoStream: true, From: from, To: to}
+	var buf bytes.Buffer
+	if err := Translate(ctx, t, strings.Join(segments, "\n"), &buf, opts); err != nil {
+		return nil, err
+	}
+	var lines []string
+	for _, line := range strings.Split(buf.String(), "\n") {
+		if line = tm.Normalize(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) == len(segments) {
+		return lines, nil
+	}
+	translations := make([]string, len(segments))
+	for i, segment := range segments {
+		buf.Reset()
+		if err := Translate(ctx, t, segment, &buf, opts); err != nil {
+			return nil, err
+		}
+		translations[i] = tm.Normalize(buf.String())
+	}
+	return translations, nil
+}
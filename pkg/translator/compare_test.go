@@ -0,0 +1,82 @@
+package translator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gogodjzhu/word-flow/pkg/translator/glossary"
+	"github.com/gogodjzhu/word-flow/pkg/translator/tm"
+)
+
+// joiningTranslator translates to lower case on a single line.
+type joiningTranslator struct {
+	calls int
+}
+
+func (j *joiningTranslator) Translate(text string, out io.Writer, opts *TransOptions) error {
+	j.calls++
+	_, err := fmt.Fprint(out, strings.ToLower(strings.ReplaceAll(text, "\n", " ")))
+	return err
+}
+
+type failingTranslator struct{}
+
+func (failingTranslator) Translate(text string, out io.Writer, opts *TransOptions) error {
+	return errors.New("quota exceeded")
+}
+
+func TestCompare(t *testing.T) {
+	joining := &joiningTranslator{}
+	c := Compare(context.Background(), "Hello there.  How are\nyou? ", []string{"upper", "lower", "broken"},
+		[]Translator{&upperTranslator{}, joining, failingTranslator{}}, &TransOptions{From: "en", To: "de"})
+
+	if want := []string{"Hello there.", "How are you?"}; !reflect.DeepEqual(c.Segments, want) {
+		t.Fatalf("segments = %q, want %q", c.Segments, want)
+	}
+	if want := []string{"HELLO THERE.", "HOW ARE YOU?"}; !reflect.DeepEqual(c.Renditions[0].Translations, want) || c.Renditions[0].Err != nil {
+		t.Errorf("upper = %q, %v, want %q", c.Renditions[0].Translations, c.Renditions[0].Err, want)
+	}
+	// The lines were merged, each segment is translated again.
+	if want := []string{"hello there.", "how are you?"}; !reflect.DeepEqual(c.Renditions[1].Translations, want) || joining.calls != 3 {
+		t.Errorf("lower = %q after %d calls, want %q after 3", c.Renditions[1].Translations, joining.calls, want)
+	}
+	if r := c.Renditions[2]; r.Translator != "broken" || r.Err == nil || r.Translations != nil {
+		t.Errorf("broken = %+v, want an error", r)
+	}
+
+	store, err := tm.Open(filepath.Join(t.TempDir(), "tm.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	if err := c.Remember(store, "google", 1); err != nil {
+		t.Fatal(err)
+	}
+	translation, ok, err := store.Lookup(tm.Query{From: "en", To: "de", Translator: "google"}, "How are you?")
+	if err != nil || !ok || translation != "how are you?" {
+		t.Errorf("Lookup() = %q, %v, %v, want the picked translation", translation, ok, err)
+	}
+}
+
+func TestComparison_Check(t *testing.T) {
+	g, err := glossary.ParseTSV(strings.NewReader("you\tyou\nthere\tdort\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := Compare(context.Background(), "Hello there. How are you?", []string{"upper", "broken"},
+		[]Translator{&upperTranslator{}, failingTranslator{}}, &TransOptions{From: "en", To: "de"})
+	c.Check(g)
+	v := c.Renditions[0].Violations
+	if len(v) != 1 || v[0].Segment != "Hello there." || v[0].Term.Source != "there" {
+		t.Errorf("violations = %+v, want there in the first segment", v)
+	}
+	if c.Renditions[1].Violations != nil {
+		t.Errorf("violations of a failed rendition = %+v", c.Renditions[1].Violations)
+	}
+}
@@ -0,0 +1,90 @@
+package deepl
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gogodjzhu/word-flow/internal/config"
+	"github.com/gogodjzhu/word-flow/internal/lang"
+)
+
+// Config is the trans.deepl section: the DeepL API, on the free or pro host
+// depending on the auth key.
+type Config struct {
+	AuthKey string `yaml:"auth_key"`
+	// URL replaces the API host chosen by the auth key.
+	URL string `yaml:"url,omitempty"`
+	// Formality is default, more, less, prefer_more or prefer_less. The
+	// prefer ones fall back to the default for languages without formality.
+	Formality  string             `yaml:"formality,omitempty"`
+	Glossaries []Glossary         `yaml:"glossaries,omitempty"`
+	Timeout    config.Duration    `yaml:"timeout,omitempty"`
+	HTTP       *config.HTTPConfig `yaml:"http,omitempty"`
+	From       string             `yaml:"from,omitempty"`
+	To         string             `yaml:"to,omitempty"`
+}
+
+// Glossary is a glossary created with the DeepL API, used for translations
+// between its languages.
+type Glossary struct {
+	From string `yaml:"from"`
+	To   string `yaml:"to"`
+	ID   string `yaml:"id"`
+}
+
+// ConfigSection registers Config with the translator.
+var ConfigSection = config.EndpointSpec{
+	New: func() config.EndpointConfig { return &Config{} },
+	Defaults: func(c config.EndpointConfig, dir string) {
+		deepl := c.(*Config)
+		config.DefaultTimeout(&deepl.Timeout, 30*time.Second)
+		config.DefaultHTTP(&deepl.HTTP, 0)
+		config.DefaultLanguages(&deepl.From, &deepl.To)
+		if deepl.Formality == "" {
+			deepl.Formality = "default"
+		}
+	},
+	Template: `# deepl:
+#   # DeepL API (required if trans.default is deepl)
+#   # auth_key: ""          # Required. Set via WORDFLOW_TRANS_DEEPL_AUTH_KEY or wordflow config set trans.deepl.auth_key
+#   # url: ""               # API host, api-free.deepl.com for free keys (ending in :fx), api.deepl.com otherwise
+#   formality: default      # default, more, less, prefer_more or prefer_less
+#   # glossaries:           # DeepL glossaries, by language pair
+#   #   - {from: en, to: de, id: ""}
+#   timeout: 30s
+#   from: auto
+#   to: zh
+`,
+}
+
+func (c *Config) Languages() (string, string) {
+	if c == nil {
+		return "", ""
+	}
+	return c.From, c.To
+}
+
+func (c *Config) Validate() error {
+	if c.AuthKey == "" {
+		return errors.New("trans.deepl.auth_key is required. Set it via: wordflow config set trans.deepl.auth_key <key> or env var WORDFLOW_TRANS_DEEPL_AUTH_KEY")
+	}
+	switch c.Formality {
+	case "", "default", "more", "less", "prefer_more", "prefer_less":
+	default:
+		return fmt.Errorf("invalid trans.deepl.formality %q, want default, more, less, prefer_more or prefer_less", c.Formality)
+	}
+	for _, g := range c.Glossaries {
+		if g.ID == "" {
+			return errors.New("trans.deepl.glossaries need an id")
+		}
+		// DeepL applies glossaries to a known source language only.
+		if g.From == "" || g.From == lang.Auto {
+			return fmt.Errorf("trans.deepl glossary %s needs a source language", g.ID)
+		}
+		if err := config.ValidateLanguages("trans.deepl glossary "+g.ID, g.From, g.To); err != nil {
+			return err
+		}
+	}
+	return config.ValidateLanguages("trans.deepl", c.From, c.To)
+}
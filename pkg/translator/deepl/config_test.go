@@ -0,0 +1,49 @@
+package deepl
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gogodjzhu/word-flow/internal/config"
+	"gopkg.in/yaml.v3"
+)
+
+func TestConfigDefaults(t *testing.T) {
+	c := ConfigSection.New().(*Config)
+	content := `auth_key: secret:fx
+glossaries:
+  - {from: en, to: de, id: gl-1}
+`
+	if err := yaml.Unmarshal([]byte(content), c); err != nil {
+		t.Fatal(err)
+	}
+	ConfigSection.Defaults(c, t.TempDir())
+	if c.Formality != "default" || c.Timeout != config.Duration(30*time.Second) || c.To != "zh" || len(c.Glossaries) != 1 {
+		t.Errorf("deepl = %+v, want the defaults and a glossary", c)
+	}
+	if err := c.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  Config
+		wantErr string
+	}{
+		{name: "no key", wantErr: "trans.deepl.auth_key is required"},
+		{name: "formality", config: Config{AuthKey: "k", Formality: "polite"}, wantErr: "invalid trans.deepl.formality"},
+		{name: "glossary id", config: Config{AuthKey: "k", Glossaries: []Glossary{{From: "en", To: "de"}}}, wantErr: "need an id"},
+		{name: "glossary source", config: Config{AuthKey: "k", Glossaries: []Glossary{{From: "auto", To: "de", ID: "g"}}}, wantErr: "needs a source language"},
+		{name: "glossary pair", config: Config{AuthKey: "k", Glossaries: []Glossary{{From: "en", To: "en", ID: "g"}}}, wantErr: "invalid trans.deepl glossary g languages"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.config.Validate(); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,261 @@
+package deepl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gogodjzhu/word-flow/internal/buzz_error"
+	"github.com/gogodjzhu/word-flow/internal/lang"
+	httputil "github.com/gogodjzhu/word-flow/internal/util"
+	"github.com/gogodjzhu/word-flow/pkg/translator/types"
+	"github.com/gogodjzhu/word-flow/pkg/util"
+	"github.com/pkg/errors"
+)
+
+const (
+	freeURL = "https://api-free.deepl.com"
+	proURL  = "https://api.deepl.com"
+	// maxTexts is the number of texts DeepL translates with a request.
+	maxTexts = 50
+)
+
+type TranslatorDeepL struct {
+	cfg *Config
+}
+
+func NewTranslatorDeepL(cfg *Config) *TranslatorDeepL {
+	return &TranslatorDeepL{cfg: cfg}
+}
+
+func (t *TranslatorDeepL) timeout() time.Duration {
+	if t.cfg == nil {
+		return 0
+	}
+	return time.Duration(t.cfg.Timeout)
+}
+
+func (t *TranslatorDeepL) policy() *httputil.Policy {
+	if t.cfg == nil {
+		return nil
+	}
+	return httputil.PolicyFromConfig(t.cfg.HTTP)
+}
+
+// apiURL returns the host of the API: the free one for free auth keys, which
+// end in :fx, unless the configuration has a URL.
+func apiURL(cfg *Config) string {
+	switch {
+	case cfg.URL != "":
+		return strings.TrimSuffix(cfg.URL, "/")
+	case strings.HasSuffix(cfg.AuthKey, ":fx"):
+		return freeURL
+	default:
+		return proURL
+	}
+}
+
+// sourceCodes maps the codes of internal/lang to DeepL's source languages,
+// which have no Classical Chinese.
+var sourceCodes = map[string]string{
+	"en":    "EN",
+	"zh":    "ZH",
+	"zh-TW": "ZH",
+	"ja":    "JA",
+	"ko":    "KO",
+	"de":    "DE",
+	"fr":    "FR",
+	"es":    "ES",
+	"it":    "IT",
+	"pt":    "PT",
+	"ru":    "RU",
+}
+
+// targetCodes maps the codes of internal/lang to DeepL's target languages,
+// some of which name a variant.
+var targetCodes = map[string]string{
+	"en":    "EN-US",
+	"zh":    "ZH-HANS",
+	"zh-TW": "ZH-HANT",
+	"ja":    "JA",
+	"ko":    "KO",
+	"de":    "DE",
+	"fr":    "FR",
+	"es":    "ES",
+	"it":    "IT",
+	"pt":    "PT-PT",
+	"ru":    "RU",
+}
+
+// SupportsLanguage reports whether DeepL translates from or to code.
+func (t *TranslatorDeepL) SupportsLanguage(code string) bool {
+	_, ok := targetCodes[code]
+	return ok || code == lang.Auto
+}
+
+type translateRequest struct {
+	Text       []string `json:"text"`
+	SourceLang string   `json:"source_lang,omitempty"`
+	TargetLang string   `json:"target_lang"`
+	Formality  string   `json:"formality,omitempty"`
+	GlossaryID string   `json:"glossary_id,omitempty"`
+}
+
+type translateResponse struct {
+	Translations []struct {
+		DetectedSourceLanguage string `json:"detected_source_language"`
+		Text                   string `json:"text"`
+	} `json:"translations"`
+}
+
+func callDeepLTranslate(ctx context.Context, cfg *Config, request *translateRequest) ([]string, error) {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal request")
+	}
+	headers := map[string]string{
+		"Content-Type":  "application/json",
+		"Authorization": "DeepL-Auth-Key " + cfg.AuthKey,
+	}
+	result, err := httputil.SendPostContext(ctx, apiURL(cfg)+"/v2/translate", headers, body, func(response *http.Response) (interface{}, error) {
+		defer response.Body.Close()
+		body, err := io.ReadAll(response.Body)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read response body")
+		}
+		switch response.StatusCode {
+		case http.StatusOK:
+		case http.StatusForbidden:
+			return nil, errors.New("the auth key was rejected, check trans.deepl.auth_key")
+		case 456:
+			return nil, errors.New("the translation quota is used up")
+		default:
+			var apiError struct {
+				Message string `json:"message"`
+			}
+			if json.Unmarshal(body, &apiError) == nil && apiError.Message != "" {
+				return nil, fmt.Errorf("unexpected status: %s: %s", response.Status, apiError.Message)
+			}
+			return nil, fmt.Errorf("unexpected status: %s", response.Status)
+		}
+
+		var resp translateResponse
+		if err := json.Unmarshal(body, &resp); err != nil {
+			return nil, errors.Wrap(err, "failed to parse response")
+		}
+		if len(resp.Translations) != len(request.Text) {
+			return nil, fmt.Errorf("got %d translations for %d texts", len(resp.Translations), len(request.Text))
+		}
+		translations := make([]string, len(resp.Translations))
+		for i, translation := range resp.Translations {
+			translations[i] = translation.Text
+		}
+		return translations, nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to call DeepL API")
+	}
+	return result.([]string), nil
+}
+
+// glossaryID returns the ID of the configured glossary of a language pair.
+func (t *TranslatorDeepL) glossaryID(from, to string) string {
+	for _, g := range t.cfg.Glossaries {
+		source, _ := lang.Normalize(g.From)
+		target, _ := lang.Normalize(g.To)
+		if source == from && target == to {
+			return g.ID
+		}
+	}
+	return ""
+}
+
+func (t *TranslatorDeepL) Translate(text string, out io.Writer, opts *types.TransOptions) error {
+	return t.TranslateContext(context.Background(), text, out, opts)
+}
+
+func (t *TranslatorDeepL) TranslateContext(ctx context.Context, text string, out io.Writer, opts *types.TransOptions) error {
+	ctx = httputil.WithRequestTimeout(ctx, t.timeout())
+	ctx = httputil.WithEndpoint(httputil.WithPolicy(ctx, t.policy()), "trans.deepl")
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return errors.New("text is empty")
+	}
+	if opts == nil {
+		opts = &types.TransOptions{}
+	}
+	from, to := opts.Languages(t.cfg)
+	request := translateRequest{}
+	if from != lang.Auto {
+		var ok bool
+		if request.SourceLang, ok = sourceCodes[from]; !ok {
+			return buzz_error.InvalidInput("DeepL does not support source language " + from)
+		}
+		request.GlossaryID = t.glossaryID(from, to)
+	}
+	var ok bool
+	if request.TargetLang, ok = targetCodes[to]; !ok {
+		return buzz_error.InvalidInput("DeepL does not support target language " + to)
+	}
+	if t.cfg.Formality != "default" {
+		request.Formality = t.cfg.Formality
+	}
+
+	sentences := util.SplitSentences(text)
+	// Without streaming the output is written once it is complete.
+	w := out
+	var buffered strings.Builder
+	if opts.NoStream {
+		w = &buffered
+	}
+	if opts.Ref {
+		fmt.Fprint(w, "[")
+	}
+	err := t.writeTranslations(ctx, &request, sentences, to, opts.Ref, w)
+	if opts.Ref {
+		// The pairs written before an error stay a JSON array.
+		fmt.Fprint(w, "]")
+	}
+	if err != nil {
+		return err
+	}
+	if opts.NoStream {
+		fmt.Fprint(out, buffered.String())
+	}
+	return nil
+}
+
+// writeTranslations translates sentences in batches and writes them to w,
+// as the elements of a JSON array of pairs with ref.
+func (t *TranslatorDeepL) writeTranslations(ctx context.Context, request *translateRequest, sentences []util.Sentence, to string, ref bool, w io.Writer) error {
+	for start := 0; start < len(sentences); start += maxTexts {
+		batch := sentences[start:min(start+maxTexts, len(sentences))]
+		request.Text = make([]string, len(batch))
+		for i, s := range batch {
+			request.Text[i] = s.Text
+		}
+		translations, err := callDeepLTranslate(ctx, t.cfg, request)
+		if err != nil {
+			return err
+		}
+		for i, s := range batch {
+			if !ref {
+				fmt.Fprint(w, util.SentenceSpace(s.Space, to)+translations[i])
+				continue
+			}
+			if start+i > 0 {
+				fmt.Fprint(w, ",")
+			}
+			data, err := json.Marshal(map[string]string{"raw": s.Text, "translation": translations[i]})
+			if err != nil {
+				return errors.Wrap(err, "failed to marshal ref pair")
+			}
+			fmt.Fprint(w, string(data))
+		}
+	}
+	return nil
+}
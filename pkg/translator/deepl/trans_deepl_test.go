@@ -0,0 +1,170 @@
+package deepl
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gogodjzhu/word-flow/pkg/translator/types"
+)
+
+// fakeDeepL answers /v2/translate with the texts in upper case and records
+// the requests.
+func fakeDeepL(t *testing.T, requests *[]translateRequest) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/translate" || r.Method != http.MethodPost {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Header.Get("Authorization") != "DeepL-Auth-Key secret:fx" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		var request translateRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, `{"message": "bad request"}`, http.StatusBadRequest)
+			return
+		}
+		*requests = append(*requests, request)
+		var resp translateResponse
+		for _, text := range request.Text {
+			resp.Translations = append(resp.Translations, struct {
+				DetectedSourceLanguage string `json:"detected_source_language"`
+				Text                   string `json:"text"`
+			}{"EN", strings.ToUpper(text)})
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestAPIURL(t *testing.T) {
+	tests := []struct {
+		cfg  Config
+		want string
+	}{
+		{Config{AuthKey: "0f1e:fx"}, freeURL},
+		{Config{AuthKey: "0f1e"}, proURL},
+		{Config{AuthKey: "0f1e:fx", URL: "http://localhost:5000/"}, "http://localhost:5000"},
+	}
+	for _, tt := range tests {
+		if got := apiURL(&tt.cfg); got != tt.want {
+			t.Errorf("apiURL(%+v) = %q, want %q", tt.cfg, got, tt.want)
+		}
+	}
+}
+
+func TestTranslatorDeepL_Translate(t *testing.T) {
+	var requests []translateRequest
+	server := fakeDeepL(t, &requests)
+	translator := NewTranslatorDeepL(&Config{
+		AuthKey:    "secret:fx",
+		URL:        server.URL,
+		Formality:  "prefer_less",
+		Glossaries: []Glossary{{From: "en", To: "de", ID: "gl-1"}, {From: "en", To: "fr", ID: "gl-2"}},
+		From:       "auto",
+		To:         "de",
+	})
+
+	var out bytes.Buffer
+	if err := translator.Translate("Hello there.  How are you?\n\nFine.", &out, &types.TransOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if want := "HELLO THERE. HOW ARE YOU?\n\nFINE."; out.String() != want {
+		t.Errorf("Translate() = %q, want %q", out.String(), want)
+	}
+	// Glossaries need a known source language.
+	if r := requests[0]; r.SourceLang != "" || r.TargetLang != "DE" || r.Formality != "prefer_less" || r.GlossaryID != "" || len(r.Text) != 3 {
+		t.Errorf("request = %+v", r)
+	}
+
+	out.Reset()
+	if err := translator.Translate("Hello. Bye.", &out, &types.TransOptions{Ref: true, NoStream: true, From: "en", To: "zh"}); err != nil {
+		t.Fatal(err)
+	}
+	var pairs []map[string]string
+	if err := json.Unmarshal(out.Bytes(), &pairs); err != nil {
+		t.Fatalf("ref output %q is not JSON: %v", out.String(), err)
+	}
+	if len(pairs) != 2 || pairs[1]["raw"] != "Bye." || pairs[1]["translation"] != "BYE." {
+		t.Errorf("ref pairs = %v", pairs)
+	}
+	if r := requests[1]; r.SourceLang != "EN" || r.TargetLang != "ZH-HANS" || r.GlossaryID != "" {
+		t.Errorf("request = %+v", r)
+	}
+
+	out.Reset()
+	if err := translator.Translate("Hello.", &out, &types.TransOptions{From: "en", To: "fr"}); err != nil {
+		t.Fatal(err)
+	}
+	if r := requests[2]; r.GlossaryID != "gl-2" {
+		t.Errorf("glossary_id = %q, want gl-2", r.GlossaryID)
+	}
+}
+
+func TestTranslatorDeepL_Batches(t *testing.T) {
+	var requests []translateRequest
+	server := fakeDeepL(t, &requests)
+	translator := NewTranslatorDeepL(&Config{AuthKey: "secret:fx", URL: server.URL, Formality: "default", From: "en", To: "ja"})
+
+	text := strings.Repeat("One more. ", 120)
+	var out bytes.Buffer
+	if err := translator.Translate(text, &out, &types.TransOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if len(requests) != 3 || len(requests[0].Text) != maxTexts || len(requests[2].Text) != 20 {
+		t.Errorf("sent %d requests, want 3 of at most %d texts", len(requests), maxTexts)
+	}
+	if requests[0].Formality != "" {
+		t.Errorf("formality = %q, want none", requests[0].Formality)
+	}
+	// Japanese sentences are not separated by spaces.
+	if want := strings.Repeat("ONE MORE.", 120); out.String() != want {
+		t.Errorf("Translate() = %q, want %q", out.String(), want)
+	}
+}
+
+func TestTranslatorDeepL_Errors(t *testing.T) {
+	var requests []translateRequest
+	server := fakeDeepL(t, &requests)
+	translator := NewTranslatorDeepL(&Config{AuthKey: "wrong", URL: server.URL, From: "en", To: "de"})
+	err := translator.Translate("Hello.", &bytes.Buffer{}, &types.TransOptions{})
+	if err == nil || !strings.Contains(err.Error(), "auth key was rejected") {
+		t.Errorf("Translate() error = %v, want a rejected auth key", err)
+	}
+
+	err = translator.Translate("Hello.", &bytes.Buffer{}, &types.TransOptions{From: "lzh"})
+	if err == nil || !strings.Contains(err.Error(), "does not support source language lzh") {
+		t.Errorf("Translate() error = %v, want an unsupported language", err)
+	}
+}
+
+func TestTranslatorDeepL_RefError(t *testing.T) {
+	var requests []translateRequest
+	server := fakeDeepL(t, &requests)
+	// The quota is used up after the first batch.
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(requests) > 0 {
+			w.WriteHeader(456)
+			return
+		}
+		server.Config.Handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(failing.Close)
+	translator := NewTranslatorDeepL(&Config{AuthKey: "secret:fx", URL: failing.URL, From: "en", To: "de"})
+
+	var out bytes.Buffer
+	err := translator.Translate(strings.Repeat("One more. ", 60), &out, &types.TransOptions{Ref: true})
+	if err == nil || !strings.Contains(err.Error(), "quota") {
+		t.Fatalf("Translate() error = %v, want the quota error", err)
+	}
+	var pairs []map[string]string
+	if err := json.Unmarshal(out.Bytes(), &pairs); err != nil || len(pairs) != maxTexts {
+		t.Errorf("ref output before the error = %q, want a JSON array of the first batch: %v", out.String(), err)
+	}
+}
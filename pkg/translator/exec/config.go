@@ -0,0 +1,24 @@
+package exec
+
+import (
+	"github.com/gogodjzhu/word-flow/internal/config"
+)
+
+// Config is the trans.exec section, a plugin using the protocol of dict.exec.
+type Config config.ExecConfig
+
+// ConfigSection registers Config with the translator.
+var ConfigSection = config.EndpointSpec{
+	New:      func() config.EndpointConfig { return &Config{} },
+	Defaults: func(c config.EndpointConfig, dir string) { (*config.ExecConfig)(c.(*Config)).SetDefaults() },
+	Template: `# exec:
+#   # External translator plugin, using the same protocol as dict.exec
+#   command: ""
+#   protocol: stdin
+#   timeout: 10s
+`,
+}
+
+func (c *Config) Validate() error {
+	return (*config.ExecConfig)(c).ValidateSection("trans")
+}
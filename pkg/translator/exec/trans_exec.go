@@ -0,0 +1,89 @@
+package exec
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/gogodjzhu/word-flow/internal/config"
+	"github.com/gogodjzhu/word-flow/internal/plugin"
+	"github.com/gogodjzhu/word-flow/pkg/translator/types"
+	"github.com/pkg/errors"
+)
+
+// translateRequest is sent to the plugin.
+type translateRequest struct {
+	Text string `json:"text"`
+	Ref  bool   `json:"ref"`
+	// From and To are the language codes of --from and --to, empty when not
+	// given; the plugin chooses its own defaults.
+	From string `json:"from,omitempty"`
+	To   string `json:"to,omitempty"`
+}
+
+// Segment is an original sentence with its translation.
+type Segment struct {
+	Raw         string `json:"raw"`
+	Translation string `json:"translation"`
+}
+
+// translateResponse is the plugin's answer. Segments are optional, without
+// them --ref shows the whole text as a single pair.
+type translateResponse struct {
+	Translation string    `json:"translation"`
+	Segments    []Segment `json:"segments,omitempty"`
+}
+
+// TranslatorExec translates with an external plugin process.
+type TranslatorExec struct {
+	plugin *plugin.Plugin
+}
+
+func NewTranslatorExec(cfg *Config) *TranslatorExec {
+	return &TranslatorExec{plugin: plugin.New((*config.ExecConfig)(cfg))}
+}
+
+func (t *TranslatorExec) Translate(text string, out io.Writer, opts *types.TransOptions) error {
+	return t.TranslateContext(context.Background(), text, out, opts)
+}
+
+func (t *TranslatorExec) TranslateContext(ctx context.Context, text string, out io.Writer, opts *types.TransOptions) error {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return errors.New("text is empty")
+	}
+	if opts == nil {
+		opts = &types.TransOptions{}
+	}
+
+	var response translateResponse
+	if err := t.plugin.CallContext(ctx, "translate", translateRequest{Text: text, Ref: opts.Ref, From: opts.From, To: opts.To}, &response); err != nil {
+		return err
+	}
+	if !opts.Ref {
+		if response.Translation == "" {
+			for _, seg := range response.Segments {
+				response.Translation += seg.Translation
+			}
+		}
+		fmt.Fprint(out, response.Translation)
+		return nil
+	}
+
+	segments := response.Segments
+	if len(segments) == 0 {
+		segments = []Segment{{Raw: text, Translation: response.Translation}}
+	}
+	data, err := json.Marshal(segments)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal ref pairs")
+	}
+	out.Write(data)
+	return nil
+}
+
+func (t *TranslatorExec) Close() error {
+	return t.plugin.Close()
+}
@@ -0,0 +1,60 @@
+package exec
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/gogodjzhu/word-flow/pkg/translator/types"
+)
+
+// TestHelperProcess is a JSON-RPC plugin that upper-cases the text.
+func TestHelperProcess(t *testing.T) {
+	if os.Getenv("WORDFLOW_TEST_PLUGIN") == "" {
+		return
+	}
+	defer os.Exit(0)
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		var request struct {
+			ID     int64            `json:"id"`
+			Params translateRequest `json:"params"`
+		}
+		_ = json.Unmarshal(scanner.Bytes(), &request)
+		result := translateResponse{Translation: fmt.Sprintf("[%s]", request.Params.Text)}
+		if request.Params.Ref {
+			result.Segments = []Segment{{Raw: "Hi.", Translation: "[Hi.]"}, {Raw: "Bye.", Translation: "[Bye.]"}}
+		}
+		data, _ := json.Marshal(map[string]interface{}{"jsonrpc": "2.0", "id": request.ID, "result": result})
+		fmt.Println(string(data))
+	}
+}
+
+func TestTranslatorExec_Translate(t *testing.T) {
+	tr := NewTranslatorExec(&Config{
+		Command:  os.Args[0],
+		Args:     []string{"-test.run=TestHelperProcess"},
+		Env:      []string{"WORDFLOW_TEST_PLUGIN=1"},
+		Protocol: "jsonrpc",
+	})
+	defer tr.Close()
+
+	var out bytes.Buffer
+	if err := tr.Translate("Hi. Bye.", &out, nil); err != nil {
+		t.Fatalf("Translate() error = %v", err)
+	}
+	if out.String() != "[Hi. Bye.]" {
+		t.Errorf("output = %q", out.String())
+	}
+
+	out.Reset()
+	if err := tr.Translate("Hi. Bye.", &out, &types.TransOptions{Ref: true}); err != nil {
+		t.Fatalf("Translate(ref) error = %v", err)
+	}
+	if want := `[{"raw":"Hi.","translation":"[Hi.]"},{"raw":"Bye.","translation":"[Bye.]"}]`; out.String() != want {
+		t.Errorf("ref output = %s, want %s", out.String(), want)
+	}
+}
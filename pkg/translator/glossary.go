@@ -0,0 +1,130 @@
+package translator
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+
+	"github.com/gogodjzhu/word-flow/internal/config"
+	"github.com/gogodjzhu/word-flow/pkg/translator/glossary"
+	"github.com/gogodjzhu/word-flow/pkg/util"
+)
+
+// TermTranslator is implemented by translators following the glossary terms
+// of TransOptions.Terms on their own, e.g. in a prompt. The terms are
+// protected with placeholders for the other translators.
+type TermTranslator interface {
+	Translator
+	FollowsTerms() bool
+}
+
+// GlossaryTranslator enforces the terms of a glossary in the translations of
+// a translator, and checks that the translations follow them.
+type GlossaryTranslator struct {
+	translator Translator
+	glossary   *glossary.Glossary
+	defaults   config.LanguageDefaults
+
+	violations []glossary.Violation
+}
+
+// NewGlossaryTranslator wraps t, the default translator of cfg, with the
+// terms of g.
+func NewGlossaryTranslator(cfg *config.TransConfig, t Translator, g *glossary.Glossary) *GlossaryTranslator {
+	gt := &GlossaryTranslator{translator: t, glossary: g}
+	if endpointConfig, err := cfg.GetEndpointConfig(cfg.Default); err == nil {
+		gt.defaults, _ = endpointConfig.(config.LanguageDefaults)
+	}
+	return gt
+}
+
+// Violations returns the segments translated so far whose translation does
+// not follow a term.
+func (g *GlossaryTranslator) Violations() []glossary.Violation {
+	return g.violations
+}
+
+// SupportsLanguage forwards to the wrapped translator.
+func (g *GlossaryTranslator) SupportsLanguage(code string) bool {
+	if lt, ok := g.translator.(LanguageTranslator); ok {
+		return lt.SupportsLanguage(code)
+	}
+	return true
+}
+
+func (g *GlossaryTranslator) Translate(text string, out io.Writer, opts *TransOptions) error {
+	return g.TranslateContext(context.Background(), text, out, opts)
+}
+
+func (g *GlossaryTranslator) TranslateContext(ctx context.Context, text string, out io.Writer, opts *TransOptions) error {
+	if opts == nil {
+		opts = &TransOptions{}
+	}
+	from, to := opts.Languages(g.defaults)
+	terms := g.glossary.Find(text, from, to)
+	if len(terms) == 0 {
+		return Translate(ctx, g.translator, text, out, opts)
+	}
+
+	if tt, ok := g.translator.(TermTranslator); ok && tt.FollowsTerms() {
+		termOpts := *opts
+		termOpts.Terms = terms
+		var buf bytes.Buffer
+		if err := Translate(ctx, g.translator, text, io.MultiWriter(out, &buf), &termOpts); err != nil {
+			return err
+		}
+		g.check(text, buf.String(), opts.Ref, terms)
+		return nil
+	}
+
+	masked, placeholders := glossary.Protect(text, terms)
+	var buf bytes.Buffer
+	if err := Translate(ctx, g.translator, masked, &buf, opts); err != nil {
+		return err
+	}
+	output := placeholders.Restore(buf.String())
+	if opts.Ref {
+		if pairs, ok := parsePairs(buf.String()); ok {
+			for i := range pairs {
+				pairs[i].Raw = placeholders.RestoreSource(pairs[i].Raw)
+				pairs[i].Translation = placeholders.Restore(pairs[i].Translation)
+			}
+			g.check(text, "", true, terms, pairs...)
+			return writePairs(out, pairs, true, "")
+		}
+	}
+	if _, err := io.WriteString(out, output); err != nil {
+		return err
+	}
+	g.check(text, output, opts.Ref, terms)
+	return nil
+}
+
+// check records the violations of a translation. The segments of --ref output
+// are checked one by one, other translations as a whole, reporting the
+// source segments containing the violated terms.
+func (g *GlossaryTranslator) check(text, output string, ref bool, terms []glossary.Term, pairs ...segmentPair) {
+	if ref && len(pairs) == 0 {
+		pairs, _ = parsePairs(output)
+	}
+	if len(pairs) > 0 {
+		for _, pair := range pairs {
+			for _, term := range glossary.Check(pair.Raw, pair.Translation, terms) {
+				g.violations = append(g.violations, glossary.Violation{Segment: strings.TrimSpace(pair.Raw), Term: term})
+			}
+		}
+		return
+	}
+	violated := glossary.Check(text, output, terms)
+	if len(violated) == 0 {
+		return
+	}
+	for _, segment := range util.SegmentText(text) {
+		for _, term := range violated {
+			if term.In(segment) {
+				g.violations = append(g.violations, glossary.Violation{Segment: strings.TrimSpace(segment), Term: term})
+			}
+		}
+	}
+}
@@ -0,0 +1,244 @@
+// Package glossary enforces terminology in translations: the terms of a TSV
+// or TBX glossary are given to translators that follow them, protected with
+// placeholders for the others, and checked in the translations.
+package glossary
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gogodjzhu/word-flow/internal/lang"
+	"github.com/pkg/errors"
+)
+
+// Term is a glossary entry translating Source as Target. A term without a
+// Target, or with the Target equal to the Source, stays untranslated.
+type Term struct {
+	// From and To are the language codes of the term, empty for any language.
+	From   string
+	To     string
+	Source string
+	Target string
+
+	re                 *regexp.Regexp
+	wordStart, wordEnd bool
+}
+
+// Keep reports whether the term stays untranslated.
+func (t Term) Keep() bool {
+	return t.Target == "" || t.Target == t.Source
+}
+
+// Translation returns the text expected in translations of the term.
+func (t Term) Translation() string {
+	if t.Keep() {
+		return t.Source
+	}
+	return t.Target
+}
+
+// index returns the positions of the occurrences of the term in text. Case is
+// ignored, and terms starting or ending with a letter of an alphabet only
+// match whole words.
+func (t Term) index(text string) [][]int {
+	var result [][]int
+	for _, loc := range t.re.FindAllStringIndex(text, -1) {
+		if t.wordStart {
+			if r, _ := utf8.DecodeLastRuneInString(text[:loc[0]]); isWordRune(r) {
+				continue
+			}
+		}
+		if t.wordEnd {
+			if r, _ := utf8.DecodeRuneInString(text[loc[1]:]); isWordRune(r) {
+				continue
+			}
+		}
+		result = append(result, loc)
+	}
+	return result
+}
+
+// In reports whether the term occurs in text.
+func (t Term) In(text string) bool {
+	return len(t.index(text)) > 0
+}
+
+func newTerm(from, to, source, target string) (Term, error) {
+	term := Term{From: from, To: to, Source: strings.TrimSpace(source), Target: strings.TrimSpace(target)}
+	if term.Source == "" {
+		return Term{}, errors.New("empty term")
+	}
+	runes := []rune(term.Source)
+	term.re = regexp.MustCompile("(?i)" + regexp.QuoteMeta(term.Source))
+	term.wordStart, term.wordEnd = isWordRune(runes[0]), isWordRune(runes[len(runes)-1])
+	return term, nil
+}
+
+// isWordRune reports whether r is part of words separated by spaces. Han,
+// kana and Hangul are written without spaces and match anywhere.
+func isWordRune(r rune) bool {
+	if r == utf8.RuneError {
+		return false
+	}
+	if unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul) {
+		return false
+	}
+	return unicode.IsLetter(r) || unicode.IsNumber(r)
+}
+
+// Glossary is a list of terms.
+type Glossary struct {
+	terms []Term
+}
+
+// IsGlossaryFile reports whether the extension of filename is a glossary
+// format: .tsv or .tbx.
+func IsGlossaryFile(filename string) bool {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".tsv", ".tbx":
+		return true
+	}
+	return false
+}
+
+// Load reads a TSV or TBX glossary file.
+func Load(filename string) (*Glossary, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open glossary")
+	}
+	defer file.Close()
+	var g *Glossary
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".tsv":
+		g, err = ParseTSV(file)
+	case ".tbx":
+		g, err = ParseTBX(file)
+	default:
+		return nil, errors.Errorf("unsupported glossary file %s, use .tsv or .tbx", filename)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid glossary %s", filename)
+	}
+	return g, nil
+}
+
+// ParseTSV reads a glossary with a term and its translation per line,
+// separated by a tab. An empty translation keeps the term untranslated. Lines
+// starting with # are comments. A first line of two language codes, e.g.
+// "en<TAB>zh", restricts the terms to that pair.
+func ParseTSV(r io.Reader) (*Glossary, error) {
+	g := &Glossary{}
+	var from, to string
+	scanner := bufio.NewScanner(r)
+	first := true
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		columns := strings.Split(line, "\t")
+		source, target := columns[0], ""
+		if len(columns) > 1 {
+			target = columns[1]
+		}
+		if first {
+			first = false
+			if f, t, ok := languagePair(source, target); ok {
+				from, to = f, t
+				continue
+			}
+		}
+		term, err := newTerm(from, to, source, target)
+		if err != nil {
+			return nil, errors.Errorf("line %d: %v", n, err)
+		}
+		g.terms = append(g.terms, term)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	g.sort()
+	return g, nil
+}
+
+func languagePair(a, b string) (string, string, bool) {
+	from, ok := language(a)
+	if !ok {
+		return "", "", false
+	}
+	to, ok := language(b)
+	return from, to, ok
+}
+
+// language returns the code of a supported language tag.
+func language(tag string) (string, bool) {
+	code, err := lang.NormalizeTag(tag)
+	return code, err == nil && code != "" && code != lang.Auto
+}
+
+// sort puts longer terms first, so that they win over the terms they contain.
+func (g *Glossary) sort() {
+	sort.SliceStable(g.terms, func(i, j int) bool {
+		return len([]rune(g.terms[i].Source)) > len([]rune(g.terms[j].Source))
+	})
+}
+
+// Len returns the number of terms.
+func (g *Glossary) Len() int {
+	if g == nil {
+		return 0
+	}
+	return len(g.terms)
+}
+
+// Find returns the terms of the language pair occurring in text, the longest
+// first. An auto source matches the terms of any source language.
+func (g *Glossary) Find(text, from, to string) []Term {
+	if g == nil {
+		return nil
+	}
+	var found []Term
+	seen := make(map[string]bool)
+	for _, term := range g.terms {
+		if term.From != "" && from != "" && from != lang.Auto && term.From != from {
+			continue
+		}
+		if term.To != "" && term.To != to {
+			continue
+		}
+		key := strings.ToLower(term.Source)
+		if seen[key] || !term.In(text) {
+			continue
+		}
+		seen[key] = true
+		found = append(found, term)
+	}
+	return found
+}
+
+// Violation is a segment whose translation does not follow a term.
+type Violation struct {
+	Segment string
+	Term    Term
+}
+
+// Check returns the terms occurring in source whose translation is missing
+// from translation.
+func Check(source, translation string, terms []Term) []Term {
+	var violated []Term
+	lower := strings.ToLower(translation)
+	for _, term := range terms {
+		if term.In(source) && !strings.Contains(lower, strings.ToLower(term.Translation())) {
+			violated = append(violated, term)
+		}
+	}
+	return violated
+}
@@ -0,0 +1,161 @@
+package glossary
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testTSV = `# product terms
+en	zh
+workspace	工作区
+WordFlow
+smart workspace	智能工作区
+`
+
+func TestParseTSV(t *testing.T) {
+	g, err := ParseTSV(strings.NewReader(testTSV))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.Len() != 3 {
+		t.Fatalf("expected 3 terms, got %d", g.Len())
+	}
+	terms := g.Find("Open the Smart Workspace in WordFlow.", "en", "zh")
+	var sources []string
+	for _, term := range terms {
+		sources = append(sources, term.Source)
+		if term.From != "en" || term.To != "zh" {
+			t.Errorf("expected the languages of the header, got %s → %s", term.From, term.To)
+		}
+	}
+	if got := strings.Join(sources, ","); got != "smart workspace,workspace,WordFlow" {
+		t.Errorf("unexpected terms %s", got)
+	}
+	if terms := g.Find("Open the workspace.", "en", "ja"); len(terms) != 0 {
+		t.Errorf("expected no terms for another target language, got %v", terms)
+	}
+	if terms := g.Find("Open the workspace.", "auto", "zh"); len(terms) != 1 {
+		t.Errorf("expected the terms of any source for auto, got %v", terms)
+	}
+
+	g, err = ParseTSV(strings.NewReader("kubelet\tkubelet\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if terms := g.Find("restart the kubelet", "de", "fr"); len(terms) != 1 || !terms[0].Keep() {
+		t.Errorf("expected a kept term for any language pair, got %v", terms)
+	}
+}
+
+func TestTermIn(t *testing.T) {
+	g, _ := ParseTSV(strings.NewReader("API\tAPI\n工作区\tworkspace\n"))
+	tests := []struct {
+		text string
+		want int
+	}{
+		{"Call the api.", 1},
+		{"Call the APIs.", 0},
+		{"RAPID growth", 0},
+		{"打开工作区。", 1},
+		{"API 和工作区", 2},
+	}
+	for _, tt := range tests {
+		if got := len(g.Find(tt.text, "", "en")); got != tt.want {
+			t.Errorf("Find(%q) found %d terms, want %d", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestParseTBX(t *testing.T) {
+	const tbx = `<?xml version="1.0" encoding="UTF-8"?>
+<martif type="TBX" xml:lang="en">
+  <text><body>
+    <termEntry id="1">
+      <langSet xml:lang="en-US"><tig><term>pull request</term></tig><tig><term>PR</term></tig></langSet>
+      <langSet xml:lang="zh-CN"><tig><term>拉取请求</term></tig></langSet>
+      <langSet xml:lang="sv"><tig><term>pull-begäran</term></tig></langSet>
+    </termEntry>
+  </body></text>
+</martif>`
+	g, err := ParseTBX(strings.NewReader(tbx))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.Len() != 2 {
+		t.Fatalf("expected a term in each direction, got %d", g.Len())
+	}
+	terms := g.Find("Open a pull request.", "en", "zh")
+	if len(terms) != 1 || terms[0].Target != "拉取请求" {
+		t.Errorf("unexpected terms %v", terms)
+	}
+	terms = g.Find("创建拉取请求", "zh", "en")
+	if len(terms) != 1 || terms[0].Target != "pull request" {
+		t.Errorf("unexpected reverse terms %v", terms)
+	}
+
+	const tbx3 = `<tbx type="TBX-Basic" style="dca" xml:lang="en" xmlns="urn:iso:std:iso:30042:ed-2">
+  <text><body>
+    <conceptEntry id="c1">
+      <langSec xml:lang="de"><termSec><term>Arbeitsbereich</term></termSec></langSec>
+      <langSec xml:lang="en"><termSec><term>workspace</term></termSec></langSec>
+    </conceptEntry>
+  </body></text>
+</tbx>`
+	g, err = ParseTBX(strings.NewReader(tbx3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if terms := g.Find("the workspace", "en", "de"); len(terms) != 1 || terms[0].Target != "Arbeitsbereich" {
+		t.Errorf("unexpected TBX 3 terms %v", terms)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "terms.tsv")
+	if err := os.WriteFile(filename, []byte(testTSV), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if g, err := Load(filename); err != nil || g.Len() != 3 {
+		t.Errorf("Load() = %v, %v", g, err)
+	}
+	if _, err := Load(filepath.Join(dir, "terms.csv")); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+	if !IsGlossaryFile("terms.TBX") || IsGlossaryFile("terms.csv") {
+		t.Error("unexpected IsGlossaryFile")
+	}
+}
+
+func TestProtect(t *testing.T) {
+	g, _ := ParseTSV(strings.NewReader(testTSV))
+	text := "The smart workspace of WordFlow is a workspace."
+	masked, p := Protect(text, g.Find(text, "en", "zh"))
+	if masked != "The {{1}} of {{2}} is a {{3}}." {
+		t.Fatalf("unexpected masked text %q", masked)
+	}
+	if p.Len() != 3 {
+		t.Errorf("expected 3 placeholders, got %d", p.Len())
+	}
+	if got := p.Restore("{{2}} 的 {{ 1 }} 是一个｛｛3｝｝。{{9}}"); got != "WordFlow 的 智能工作区 是一个工作区。{{9}}" {
+		t.Errorf("unexpected restored translation %q", got)
+	}
+	if got := p.RestoreSource(masked); got != text {
+		t.Errorf("unexpected restored source %q", got)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	g, _ := ParseTSV(strings.NewReader(testTSV))
+	source := "Open the workspace in WordFlow."
+	terms := g.Find(source, "en", "zh")
+	if violated := Check(source, "在 WordFlow 中打开工作区。", terms); len(violated) != 0 {
+		t.Errorf("expected no violation, got %v", violated)
+	}
+	violated := Check(source, "在词流中打开工作空间。", terms)
+	if len(violated) != 2 {
+		t.Errorf("expected 2 violations, got %v", violated)
+	}
+}
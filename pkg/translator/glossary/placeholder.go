@@ -0,0 +1,72 @@
+package glossary
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/gogodjzhu/word-flow/pkg/util"
+)
+
+// Placeholders are the terms of a text replaced by Protect.
+type Placeholders struct {
+	*util.Placeholders
+	// terms are the terms of the placeholders, whose values are the
+	// occurrences as written in the text.
+	terms []Term
+}
+
+// Protect replaces the occurrences of terms in text with numbered
+// placeholders, e.g. {{1}}, that machine translation leaves alone. Longer
+// terms win over overlapping shorter ones.
+func Protect(text string, terms []Term) (string, *Placeholders) {
+	type span struct {
+		start, end int
+		term       Term
+	}
+	var spans []span
+	overlaps := func(start, end int) bool {
+		for _, s := range spans {
+			if start < s.end && s.start < end {
+				return true
+			}
+		}
+		return false
+	}
+	for _, term := range terms {
+		for _, loc := range term.index(text) {
+			if start, end := loc[0], loc[1]; !overlaps(start, end) {
+				spans = append(spans, span{start: start, end: end, term: term})
+			}
+		}
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+
+	p := &Placeholders{Placeholders: util.NewPlaceholders("")}
+	var b strings.Builder
+	last := 0
+	for _, s := range spans {
+		b.WriteString(text[last:s.start])
+		b.WriteString(p.Add(text[s.start:s.end]))
+		p.terms = append(p.terms, s.term)
+		last = s.end
+	}
+	b.WriteString(text[last:])
+	return b.String(), p
+}
+
+// Restore replaces the placeholders of a translation with the translations
+// of their terms. Kept terms are restored as they were written.
+func (p *Placeholders) Restore(translation string) string {
+	return p.RestoreFunc(translation, func(i int, source string) string {
+		if p.terms[i].Keep() {
+			return source
+		}
+		return p.terms[i].Target
+	})
+}
+
+// RestoreSource replaces the placeholders of a source segment with the terms
+// as they were written.
+func (p *Placeholders) RestoreSource(segment string) string {
+	return p.Placeholders.Restore(segment)
+}
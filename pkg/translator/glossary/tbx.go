@@ -0,0 +1,88 @@
+package glossary
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// xmlNamespace is the namespace of the xml:lang attribute.
+const xmlNamespace = "http://www.w3.org/XML/1998/namespace"
+
+// ParseTBX reads a TBX glossary, either TBX 2 (martif with termEntry,
+// langSet and tig) or TBX 3 (conceptEntry, langSec and termSec). The first
+// term of each language of an entry translates the terms of the other
+// languages. Languages wordflow does not support are ignored.
+func ParseTBX(r io.Reader) (*Glossary, error) {
+	g := &Glossary{}
+	decoder := xml.NewDecoder(r)
+	var (
+		inEntry bool
+		lang    string
+		inTerm  bool
+		term    strings.Builder
+		codes   []string
+		terms   map[string]string
+	)
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid TBX")
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "termEntry", "conceptEntry":
+				inEntry, codes, terms = true, nil, make(map[string]string)
+			case "langSet", "langSec":
+				lang = ""
+				for _, attr := range t.Attr {
+					if attr.Name.Local == "lang" && (attr.Name.Space == xmlNamespace || attr.Name.Space == "xml" || attr.Name.Space == "") {
+						lang = attr.Value
+					}
+				}
+			case "term":
+				inTerm = inEntry
+				term.Reset()
+			}
+		case xml.CharData:
+			if inTerm {
+				term.Write(t)
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "term":
+				inTerm = false
+				code, ok := language(lang)
+				if !ok {
+					continue
+				}
+				if _, ok := terms[code]; !ok && strings.TrimSpace(term.String()) != "" {
+					codes = append(codes, code)
+					terms[code] = strings.TrimSpace(term.String())
+				}
+			case "termEntry", "conceptEntry":
+				inEntry = false
+				for _, from := range codes {
+					for _, to := range codes {
+						if from == to {
+							continue
+						}
+						entry, err := newTerm(from, to, terms[from], terms[to])
+						if err != nil {
+							return nil, err
+						}
+						g.terms = append(g.terms, entry)
+					}
+				}
+			}
+		}
+	}
+	g.sort()
+	return g, nil
+}
@@ -0,0 +1,107 @@
+package translator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/gogodjzhu/word-flow/internal/config"
+	"github.com/gogodjzhu/word-flow/pkg/translator/glossary"
+	trans_google "github.com/gogodjzhu/word-flow/pkg/translator/google"
+)
+
+// echoTranslator returns the text, or its ref pairs, prefixed with "T:".
+type echoTranslator struct {
+	texts   []string
+	terms   []glossary.Term
+	follows bool
+}
+
+func (e *echoTranslator) FollowsTerms() bool {
+	return e.follows
+}
+
+func (e *echoTranslator) Translate(text string, out io.Writer, opts *TransOptions) error {
+	e.texts = append(e.texts, text)
+	e.terms = opts.Terms
+	if opts.Ref {
+		return json.NewEncoder(out).Encode([]segmentPair{{Raw: text, Translation: "T:" + text}})
+	}
+	_, err := fmt.Fprint(out, "T:"+text)
+	return err
+}
+
+func newTestGlossaryTranslator(t *testing.T, inner Translator) *GlossaryTranslator {
+	t.Helper()
+	g, err := glossary.ParseTSV(strings.NewReader("workspace\tArbeitsbereich\nWordFlow\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.TransConfig{Default: "google", Endpoints: map[string]config.EndpointConfig{"google": &trans_google.Config{From: "en", To: "de"}}}
+	return NewGlossaryTranslator(cfg, inner, g)
+}
+
+func TestGlossaryTranslator_Placeholders(t *testing.T) {
+	inner := &echoTranslator{}
+	g := newTestGlossaryTranslator(t, inner)
+
+	var buf bytes.Buffer
+	if err := g.Translate("Open the workspace in WordFlow.", &buf, &TransOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if inner.texts[0] != "Open the {{1}} in {{2}}." {
+		t.Errorf("expected the terms to be protected, got %q", inner.texts[0])
+	}
+	if len(inner.terms) != 0 {
+		t.Errorf("expected no terms in the options, got %v", inner.terms)
+	}
+	if buf.String() != "T:Open the Arbeitsbereich in WordFlow." {
+		t.Errorf("unexpected translation %q", buf.String())
+	}
+	if len(g.Violations()) != 0 {
+		t.Errorf("expected no violations, got %v", g.Violations())
+	}
+
+	buf.Reset()
+	if err := g.Translate("Open the workspace.", &buf, &TransOptions{Ref: true}); err != nil {
+		t.Fatal(err)
+	}
+	var pairs []segmentPair
+	if err := json.Unmarshal(buf.Bytes(), &pairs); err != nil {
+		t.Fatalf("expected a JSON array, got %q: %v", buf.String(), err)
+	}
+	if len(pairs) != 1 || pairs[0].Raw != "Open the workspace." || pairs[0].Translation != "T:Open the Arbeitsbereich." {
+		t.Errorf("unexpected pairs %v", pairs)
+	}
+}
+
+func TestGlossaryTranslator_FollowsTerms(t *testing.T) {
+	inner := &echoTranslator{follows: true}
+	g := newTestGlossaryTranslator(t, inner)
+
+	var buf bytes.Buffer
+	if err := g.Translate("Open the workspace. Close WordFlow.", &buf, &TransOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if inner.texts[0] != "Open the workspace. Close WordFlow." {
+		t.Errorf("expected the text unchanged, got %q", inner.texts[0])
+	}
+	if len(inner.terms) != 2 {
+		t.Errorf("expected the terms in the options, got %v", inner.terms)
+	}
+	violations := g.Violations()
+	if len(violations) != 1 || violations[0].Segment != "Open the workspace." || violations[0].Term.Source != "workspace" {
+		t.Errorf("expected a violation of workspace, got %+v", violations)
+	}
+
+	inner.texts = nil
+	if err := g.Translate("No terms here.", io.Discard, &TransOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if inner.terms != nil {
+		t.Errorf("expected no terms for a text without terms, got %v", inner.terms)
+	}
+}
@@ -0,0 +1,50 @@
+package google
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gogodjzhu/word-flow/internal/config"
+)
+
+// Config is the trans.google section.
+type Config struct {
+	Timeout config.Duration    `yaml:"timeout,omitempty"`
+	HTTP    *config.HTTPConfig `yaml:"http,omitempty"`
+	From    string             `yaml:"from,omitempty"`
+	To      string             `yaml:"to,omitempty"`
+	// Concurrency is the number of batches of a text translated at once.
+	Concurrency int `yaml:"concurrency,omitempty"`
+}
+
+// ConfigSection registers Config with the translator.
+var ConfigSection = config.EndpointSpec{
+	New: func() config.EndpointConfig { return &Config{} },
+	Defaults: func(c config.EndpointConfig, dir string) {
+		google := c.(*Config)
+		config.DefaultTimeout(&google.Timeout, 30*time.Second)
+		config.DefaultHTTP(&google.HTTP, 0)
+		config.DefaultLanguages(&google.From, &google.To)
+		config.DefaultConcurrency(&google.Concurrency, 4)
+	},
+	Template: `google:
+  timeout: 30s            # Request timeout
+  from: auto              # Default source language, overridden by trans --from
+  to: zh                  # Default target language, overridden by trans --to
+  concurrency: 4          # Batches of long texts translated at once
+`,
+}
+
+func (c *Config) Languages() (string, string) {
+	if c == nil {
+		return "", ""
+	}
+	return c.From, c.To
+}
+
+func (c *Config) Validate() error {
+	if c.Concurrency < 0 {
+		return errors.New("trans.google.concurrency must not be negative")
+	}
+	return config.ValidateLanguages("trans.google", c.From, c.To)
+}
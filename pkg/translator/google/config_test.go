@@ -0,0 +1,39 @@
+package google
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConfigDefaults(t *testing.T) {
+	c := ConfigSection.New().(*Config)
+	ConfigSection.Defaults(c, t.TempDir())
+	if c.Concurrency != 4 || c.HTTP == nil || c.HTTP.RateLimit != 0 {
+		t.Errorf("defaults = %+v, want 4 batches at once without a rate limit", c)
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  Config
+		wantErr string
+	}{
+		{name: "defaults"},
+		{name: "aliases", config: Config{From: "EN", To: "jp"}},
+		{name: "unknown", config: Config{To: "xx"}, wantErr: "invalid trans.google.to"},
+		{name: "same", config: Config{From: "en", To: "en"}, wantErr: "invalid trans.google languages"},
+		{name: "concurrency", config: Config{Concurrency: -1}, wantErr: "trans.google.concurrency must not be negative"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("Validate() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("Validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -1,14 +1,17 @@
 package google
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	neturl "net/url"
 	"strings"
+	"time"
 
-	"github.com/gogodjzhu/word-flow/internal/config"
+	"github.com/gogodjzhu/word-flow/internal/buzz_error"
+	"github.com/gogodjzhu/word-flow/internal/lang"
 	httputil "github.com/gogodjzhu/word-flow/internal/util"
 	"github.com/gogodjzhu/word-flow/pkg/translator/types"
 	"github.com/gogodjzhu/word-flow/pkg/util"
@@ -16,13 +19,58 @@ import (
 )
 
 type TranslatorGoogle struct {
-	cfg *config.TransGoogleConfig
+	cfg *Config
 }
 
-func NewTranslatorGoogle(cfg *config.TransGoogleConfig) *TranslatorGoogle {
+func NewTranslatorGoogle(cfg *Config) *TranslatorGoogle {
 	return &TranslatorGoogle{cfg: cfg}
 }
 
+func (t *TranslatorGoogle) timeout() time.Duration {
+	if t.cfg == nil {
+		return 0
+	}
+	return time.Duration(t.cfg.Timeout)
+}
+
+// concurrency returns the number of batches translated at once.
+func (t *TranslatorGoogle) concurrency() int {
+	if t.cfg == nil {
+		return 1
+	}
+	return t.cfg.Concurrency
+}
+
+func (t *TranslatorGoogle) policy() *httputil.Policy {
+	if t.cfg == nil {
+		return nil
+	}
+	return httputil.PolicyFromConfig(t.cfg.HTTP)
+}
+
+// languageCodes maps the codes of internal/lang to Google's, which has no
+// Classical Chinese.
+var languageCodes = map[string]string{
+	lang.Auto: "auto",
+	"en":      "en",
+	"zh":      "zh-CN",
+	"zh-TW":   "zh-TW",
+	"ja":      "ja",
+	"ko":      "ko",
+	"de":      "de",
+	"fr":      "fr",
+	"es":      "es",
+	"it":      "it",
+	"pt":      "pt",
+	"ru":      "ru",
+}
+
+// SupportsLanguage reports whether Google translates from or to code.
+func (t *TranslatorGoogle) SupportsLanguage(code string) bool {
+	_, ok := languageCodes[code]
+	return ok
+}
+
 type translatedSegment struct {
 	translation string
 	original    string
@@ -59,12 +107,12 @@ func parseTranslateResponse(body []byte) ([]translatedSegment, error) {
 	return results, nil
 }
 
-func callGoogleTranslate(text string) ([]translatedSegment, error) {
+func callGoogleTranslate(ctx context.Context, text, sl, tl string) ([]translatedSegment, error) {
 	url := fmt.Sprintf(
-		"https://translate.googleapis.com/translate_a/single?client=gtx&sl=en&tl=zh-CN&dt=t&q=%s",
-		neturl.QueryEscape(text),
+		"https://translate.googleapis.com/translate_a/single?client=gtx&sl=%s&tl=%s&dt=t&q=%s",
+		sl, tl, neturl.QueryEscape(text),
 	)
-	result, err := httputil.SendGet(url, nil, func(response *http.Response) (interface{}, error) {
+	result, err := httputil.SendGetContext(ctx, url, nil, func(response *http.Response) (interface{}, error) {
 		if response.StatusCode != 200 {
 			return nil, fmt.Errorf("unexpected status: %s", response.Status)
 		}
@@ -82,6 +130,12 @@ func callGoogleTranslate(text string) ([]translatedSegment, error) {
 }
 
 func (t *TranslatorGoogle) Translate(text string, out io.Writer, opts *types.TransOptions) error {
+	return t.TranslateContext(context.Background(), text, out, opts)
+}
+
+func (t *TranslatorGoogle) TranslateContext(ctx context.Context, text string, out io.Writer, opts *types.TransOptions) error {
+	ctx = httputil.WithRequestTimeout(ctx, t.timeout())
+	ctx = httputil.WithEndpoint(httputil.WithPolicy(ctx, t.policy()), "trans.google")
 	text = strings.TrimSpace(text)
 	if text == "" {
 		return errors.New("text is empty")
@@ -89,93 +143,68 @@ func (t *TranslatorGoogle) Translate(text string, out io.Writer, opts *types.Tra
 	if opts == nil {
 		opts = &types.TransOptions{}
 	}
+	from, to := opts.Languages(t.cfg)
+	sl, ok := languageCodes[from]
+	if !ok {
+		return buzz_error.InvalidInput("Google Translate does not support source language " + from)
+	}
+	tl, ok := languageCodes[to]
+	if !ok {
+		return buzz_error.InvalidInput("Google Translate does not support target language " + to)
+	}
 
 	segments := util.SegmentText(text)
 	batches := util.BatchSegments(segments, 500)
 
-	switch {
-	case !opts.Ref && !opts.NoStream:
-		for i, batch := range batches {
-			batchText := strings.Join(batch, " ")
-			segResults, err := callGoogleTranslate(batchText)
-			if err != nil {
-				return errors.Wrap(err, "failed to translate batch")
-			}
-			for _, seg := range segResults {
-				fmt.Fprint(out, seg.translation)
-			}
-			if i < len(batches)-1 {
-				fmt.Fprint(out, " ")
-			}
-		}
-		return nil
-
-	case !opts.Ref && opts.NoStream:
-		var result strings.Builder
-		for i, batch := range batches {
-			batchText := strings.Join(batch, " ")
-			segResults, err := callGoogleTranslate(batchText)
-			if err != nil {
-				return errors.Wrap(err, "failed to translate batch")
-			}
-			for _, seg := range segResults {
-				result.WriteString(seg.translation)
-			}
-			if i < len(batches)-1 {
-				result.WriteString(" ")
+	// Without streaming the output is written once it is complete.
+	w := out
+	var buffered strings.Builder
+	if opts.NoStream {
+		w = &buffered
+	}
+	if opts.Ref {
+		fmt.Fprint(w, "[")
+	}
+	results := make([][]translatedSegment, len(batches))
+	first := true
+	err := util.Ordered(ctx, len(batches), t.concurrency(), func(ctx context.Context, i int) error {
+		var err error
+		results[i], err = callGoogleTranslate(ctx, strings.Join(batches[i], " "), sl, tl)
+		return errors.Wrap(err, "failed to translate batch")
+	}, func(i int) error {
+		for _, seg := range results[i] {
+			if !opts.Ref {
+				fmt.Fprint(w, seg.translation)
+				continue
 			}
-		}
-		fmt.Fprint(out, result.String())
-		return nil
-
-	case opts.Ref && !opts.NoStream:
-		fmt.Fprint(out, "[")
-		first := true
-		for _, batch := range batches {
-			batchText := strings.Join(batch, " ")
-			segResults, err := callGoogleTranslate(batchText)
-			if err != nil {
-				return errors.Wrap(err, "failed to translate batch")
+			if !first {
+				fmt.Fprint(w, ",")
 			}
-			for _, seg := range segResults {
-				if !first {
-					fmt.Fprint(out, ",")
-				}
-				first = false
-				pair := map[string]string{
-					"raw":         seg.original,
-					"translation": seg.translation,
-				}
-				data, err := json.Marshal(pair)
-				if err != nil {
-					return errors.Wrap(err, "failed to marshal ref pair")
-				}
-				out.Write(data)
+			first = false
+			pair := map[string]string{
+				"raw":         seg.original,
+				"translation": seg.translation,
 			}
-		}
-		fmt.Fprint(out, "]")
-		return nil
-
-	default:
-		var allPairs []map[string]string
-		for _, batch := range batches {
-			batchText := strings.Join(batch, " ")
-			segResults, err := callGoogleTranslate(batchText)
+			data, err := json.Marshal(pair)
 			if err != nil {
-				return errors.Wrap(err, "failed to translate batch")
-			}
-			for _, seg := range segResults {
-				allPairs = append(allPairs, map[string]string{
-					"raw":         seg.original,
-					"translation": seg.translation,
-				})
+				return errors.Wrap(err, "failed to marshal ref pair")
 			}
+			fmt.Fprint(w, string(data))
 		}
-		data, err := json.Marshal(allPairs)
-		if err != nil {
-			return errors.Wrap(err, "failed to marshal ref pairs")
+		if !opts.Ref && i < len(batches)-1 {
+			fmt.Fprint(w, " ")
 		}
-		out.Write(data)
 		return nil
+	})
+	if opts.Ref {
+		// The pairs streamed before an error stay a JSON array.
+		fmt.Fprint(w, "]")
+	}
+	if err != nil {
+		return err
+	}
+	if opts.NoStream {
+		fmt.Fprint(out, buffered.String())
 	}
+	return nil
 }
\ No newline at end of file
@@ -1,8 +1,20 @@
 package google
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
+
+	"github.com/gogodjzhu/word-flow/internal/cassette"
+	httputil "github.com/gogodjzhu/word-flow/internal/util"
+	"github.com/gogodjzhu/word-flow/pkg/translator/types"
 )
 
 func TestParseTranslateResponse_ValidResponse(t *testing.T) {
@@ -98,4 +110,127 @@ func TestParseTranslateResponse_ShortSegment(t *testing.T) {
 	if len(segments) != 0 {
 		t.Errorf("expected 0 segments for short segment array, got %d", len(segments))
 	}
-}
\ No newline at end of file
+}
+
+func TestTranslatorGoogle_TranslateContext(t *testing.T) {
+	c := cassette.Load(t, "google")
+	translator := NewTranslatorGoogle(&Config{})
+	tests := []struct {
+		name    string
+		text    string
+		opts    *types.TransOptions
+		want    string
+		wantErr bool
+	}{
+		{name: "stream", text: "Hello world. How are you?", opts: &types.TransOptions{}, want: "你好世界。你好吗？"},
+		{name: "no stream", text: "Hello world. How are you?", opts: &types.TransOptions{NoStream: true}, want: "你好世界。你好吗？"},
+		{name: "ref", text: "Hello world. How are you?", opts: &types.TransOptions{Ref: true},
+			want: `[{"raw":"Hello world. ","translation":"你好世界。"},{"raw":"How are you?","translation":"你好吗？"}]`},
+		{name: "ref no stream", text: "Hello world. How are you?", opts: &types.TransOptions{Ref: true, NoStream: true},
+			want: `[{"raw":"Hello world. ","translation":"你好世界。"},{"raw":"How are you?","translation":"你好吗？"}]`},
+		{name: "rate limited", text: "Too many requests", opts: &types.TransOptions{}, wantErr: true},
+		{name: "japanese to german", text: "おはようございます。", opts: &types.TransOptions{From: "ja", To: "de"}, want: "Guten Morgen."},
+		{name: "classical chinese", text: "学而时习之", opts: &types.TransOptions{To: "lzh"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out strings.Builder
+			err := translator.TranslateContext(c.Context(context.Background()), tt.text, &out, tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("TranslateContext() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if out.String() != tt.want {
+				t.Errorf("TranslateContext() = %q, want %q", out.String(), tt.want)
+			}
+		})
+	}
+}
+
+// slowTransport answers Google Translate with the upper-cased query, the
+// first queries last.
+type slowTransport struct {
+	calls int32
+}
+
+func (s *slowTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	n := atomic.AddInt32(&s.calls, 1)
+	time.Sleep(time.Duration(10-min(n, 10)) * 5 * time.Millisecond)
+	q := req.URL.Query().Get("q")
+	body, _ := json.Marshal([]interface{}{[]interface{}{[]interface{}{strings.ToUpper(q), q}}})
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Status:     "200 OK",
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(bytes.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+func TestTranslatorGoogle_TranslateContextConcurrent(t *testing.T) {
+	var sentences []string
+	for i := 0; i < 40; i++ {
+		sentences = append(sentences, fmt.Sprintf("Sentence number %d is here to fill a batch.", i))
+	}
+	text := strings.Join(sentences, " ")
+	translator := NewTranslatorGoogle(&Config{Concurrency: 4})
+
+	transport := &slowTransport{}
+	var out strings.Builder
+	ctx := httputil.WithTransport(context.Background(), transport)
+	if err := translator.TranslateContext(ctx, text, &out, &types.TransOptions{}); err != nil {
+		t.Fatalf("TranslateContext() error = %v", err)
+	}
+	if transport.calls < 2 {
+		t.Fatalf("sent %d queries, want several batches", transport.calls)
+	}
+	if strings.Join(strings.Fields(out.String()), " ") != strings.ToUpper(text) {
+		t.Errorf("TranslateContext() = %q, want the batches in order", out.String())
+	}
+
+	out.Reset()
+	if err := translator.TranslateContext(ctx, text, &out, &types.TransOptions{Ref: true}); err != nil {
+		t.Fatalf("TranslateContext() error = %v", err)
+	}
+	var pairs []map[string]string
+	if err := json.Unmarshal([]byte(out.String()), &pairs); err != nil {
+		t.Fatalf("ref output is not JSON: %v\n%s", err, out.String())
+	}
+	var raw []string
+	for _, pair := range pairs {
+		raw = append(raw, strings.TrimSpace(pair["raw"]))
+	}
+	if strings.Join(strings.Fields(strings.Join(raw, " ")), " ") != text {
+		t.Errorf("ref pairs out of order: %q", raw)
+	}
+}
+
+// failingTransport answers the first query like slowTransport and fails the
+// others.
+type failingTransport struct {
+	slowTransport
+}
+
+func (f *failingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if atomic.LoadInt32(&f.calls) > 0 {
+		return nil, fmt.Errorf("connection reset")
+	}
+	return f.slowTransport.RoundTrip(req)
+}
+
+func TestTranslatorGoogle_TranslateContextRefError(t *testing.T) {
+	var sentences []string
+	for i := 0; i < 40; i++ {
+		sentences = append(sentences, fmt.Sprintf("Sentence number %d is here to fill a batch.", i))
+	}
+	translator := NewTranslatorGoogle(&Config{Concurrency: 1})
+
+	var out strings.Builder
+	ctx := httputil.WithTransport(context.Background(), &failingTransport{})
+	if err := translator.TranslateContext(ctx, strings.Join(sentences, " "), &out, &types.TransOptions{Ref: true}); err == nil {
+		t.Fatal("TranslateContext() succeeded, want the error of the second batch")
+	}
+	var pairs []map[string]string
+	if err := json.Unmarshal([]byte(out.String()), &pairs); err != nil || len(pairs) == 0 {
+		t.Errorf("ref output before the error is not a JSON array of pairs: %v\n%s", err, out.String())
+	}
+}
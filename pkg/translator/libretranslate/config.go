@@ -0,0 +1,60 @@
+package libretranslate
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/gogodjzhu/word-flow/internal/config"
+)
+
+// Config is the trans.libretranslate section: a LibreTranslate server, such
+// as a self-hosted one.
+type Config struct {
+	URL     string             `yaml:"url"`
+	APIKey  string             `yaml:"api_key,omitempty"`
+	Timeout config.Duration    `yaml:"timeout,omitempty"`
+	HTTP    *config.HTTPConfig `yaml:"http,omitempty"`
+	From    string             `yaml:"from,omitempty"`
+	To      string             `yaml:"to,omitempty"`
+}
+
+// ConfigSection registers Config with the translator.
+var ConfigSection = config.EndpointSpec{
+	New: func() config.EndpointConfig { return &Config{} },
+	Defaults: func(c config.EndpointConfig, dir string) {
+		libre := c.(*Config)
+		if libre.URL == "" {
+			libre.URL = "http://localhost:5000"
+		}
+		config.DefaultTimeout(&libre.Timeout, 30*time.Second)
+		config.DefaultHTTP(&libre.HTTP, 0)
+		config.DefaultLanguages(&libre.From, &libre.To)
+	},
+	Template: `# libretranslate:
+#   # LibreTranslate server, e.g. self-hosted with docker run -p 5000:5000 libretranslate/libretranslate
+#   url: http://localhost:5000
+#   # api_key: ""           # Only if the server requires one. Set via WORDFLOW_TRANS_LIBRETRANSLATE_API_KEY
+#   timeout: 30s
+#   from: auto
+#   to: zh
+`,
+}
+
+func (c *Config) Languages() (string, string) {
+	if c == nil {
+		return "", ""
+	}
+	return c.From, c.To
+}
+
+func (c *Config) Validate() error {
+	if c.URL == "" {
+		return errors.New("trans.libretranslate.url is required")
+	}
+	if u, err := url.Parse(c.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("trans.libretranslate.url must be an http(s) URL, got %q", c.URL)
+	}
+	return config.ValidateLanguages("trans.libretranslate", c.From, c.To)
+}
@@ -0,0 +1,26 @@
+package libretranslate
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConfigDefaults(t *testing.T) {
+	c := ConfigSection.New().(*Config)
+	ConfigSection.Defaults(c, t.TempDir())
+	if c.URL != "http://localhost:5000" || c.APIKey != "" {
+		t.Errorf("libretranslate = %+v, want the local server without an API key", c)
+	}
+	if err := c.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	for _, u := range []string{"", "localhost:5000", "ftp://translate.example.com"} {
+		c := Config{URL: u}
+		if err := c.Validate(); err == nil || !strings.Contains(err.Error(), "trans.libretranslate.url") {
+			t.Errorf("Validate() of url %q error = %v, want a url error", u, err)
+		}
+	}
+}
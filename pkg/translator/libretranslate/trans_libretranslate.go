@@ -0,0 +1,243 @@
+package libretranslate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gogodjzhu/word-flow/internal/buzz_error"
+	"github.com/gogodjzhu/word-flow/internal/lang"
+	httputil "github.com/gogodjzhu/word-flow/internal/util"
+	"github.com/gogodjzhu/word-flow/pkg/translator/types"
+	"github.com/gogodjzhu/word-flow/pkg/util"
+	"github.com/pkg/errors"
+)
+
+const (
+	// batchSize is the number of bytes of sentences sent with a request.
+	batchSize = 2000
+	// minConfidence is the confidence, out of 100, below which a detected
+	// language is not trusted.
+	minConfidence = 50
+)
+
+type TranslatorLibreTranslate struct {
+	cfg *Config
+}
+
+func NewTranslatorLibreTranslate(cfg *Config) *TranslatorLibreTranslate {
+	return &TranslatorLibreTranslate{cfg: cfg}
+}
+
+func (t *TranslatorLibreTranslate) timeout() time.Duration {
+	if t.cfg == nil {
+		return 0
+	}
+	return time.Duration(t.cfg.Timeout)
+}
+
+func (t *TranslatorLibreTranslate) policy() *httputil.Policy {
+	if t.cfg == nil {
+		return nil
+	}
+	return httputil.PolicyFromConfig(t.cfg.HTTP)
+}
+
+// languageCodes maps the codes of internal/lang to LibreTranslate's, which
+// has no Classical Chinese.
+var languageCodes = map[string]string{
+	lang.Auto: "auto",
+	"en":      "en",
+	"zh":      "zh",
+	"zh-TW":   "zt",
+	"ja":      "ja",
+	"ko":      "ko",
+	"de":      "de",
+	"fr":      "fr",
+	"es":      "es",
+	"it":      "it",
+	"pt":      "pt",
+	"ru":      "ru",
+}
+
+// detectedCodes maps the Chinese codes of LibreTranslate, old and new, to
+// those of internal/lang. The other codes are the same.
+var detectedCodes = map[string]string{
+	"zh":      "zh",
+	"zh-Hans": "zh",
+	"zt":      "zh-TW",
+	"zh-Hant": "zh-TW",
+}
+
+// SupportsLanguage reports whether LibreTranslate translates from or to code.
+func (t *TranslatorLibreTranslate) SupportsLanguage(code string) bool {
+	_, ok := languageCodes[code]
+	return ok
+}
+
+type translateRequest struct {
+	Q      []string `json:"q"`
+	Source string   `json:"source"`
+	Target string   `json:"target"`
+	Format string   `json:"format"`
+	APIKey string   `json:"api_key,omitempty"`
+}
+
+type translateResponse struct {
+	TranslatedText []string `json:"translatedText"`
+}
+
+type detectRequest struct {
+	Q      string `json:"q"`
+	APIKey string `json:"api_key,omitempty"`
+}
+
+type detection struct {
+	Confidence float64 `json:"confidence"`
+	Language   string  `json:"language"`
+}
+
+// post sends request as JSON to an API path of the server and reads the
+// response into response.
+func (t *TranslatorLibreTranslate) post(ctx context.Context, path string, request, response interface{}) error {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal request")
+	}
+	headers := map[string]string{"Content-Type": "application/json"}
+	_, err = httputil.SendPostContext(ctx, strings.TrimSuffix(t.cfg.URL, "/")+path, headers, body, func(resp *http.Response) (interface{}, error) {
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read response body")
+		}
+		if resp.StatusCode != http.StatusOK {
+			var apiError struct {
+				Error string `json:"error"`
+			}
+			if json.Unmarshal(body, &apiError) == nil && apiError.Error != "" {
+				return nil, fmt.Errorf("unexpected status: %s: %s", resp.Status, apiError.Error)
+			}
+			return nil, fmt.Errorf("unexpected status: %s", resp.Status)
+		}
+		if err := json.Unmarshal(body, response); err != nil {
+			return nil, errors.Wrap(err, "failed to parse response")
+		}
+		return nil, nil
+	})
+	return errors.Wrap(err, "failed to call LibreTranslate")
+}
+
+func (t *TranslatorLibreTranslate) context(ctx context.Context) context.Context {
+	ctx = httputil.WithRequestTimeout(ctx, t.timeout())
+	return httputil.WithEndpoint(httputil.WithPolicy(ctx, t.policy()), "trans.libretranslate")
+}
+
+// Detect asks the server for the language of text, lang.Auto when it is not
+// confident or the language is not supported.
+func (t *TranslatorLibreTranslate) Detect(ctx context.Context, text string) (string, error) {
+	ctx = t.context(ctx)
+	var detections []detection
+	if err := t.post(ctx, "/detect", &detectRequest{Q: text, APIKey: t.cfg.APIKey}, &detections); err != nil {
+		return "", err
+	}
+	if len(detections) == 0 || detections[0].Confidence < minConfidence {
+		return lang.Auto, nil
+	}
+	code, ok := detectedCodes[detections[0].Language]
+	if !ok {
+		code = detections[0].Language
+	}
+	if _, ok := languageCodes[code]; !ok {
+		return lang.Auto, nil
+	}
+	return code, nil
+}
+
+func (t *TranslatorLibreTranslate) Translate(text string, out io.Writer, opts *types.TransOptions) error {
+	return t.TranslateContext(context.Background(), text, out, opts)
+}
+
+func (t *TranslatorLibreTranslate) TranslateContext(ctx context.Context, text string, out io.Writer, opts *types.TransOptions) error {
+	ctx = t.context(ctx)
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return errors.New("text is empty")
+	}
+	if opts == nil {
+		opts = &types.TransOptions{}
+	}
+	from, to := opts.Languages(t.cfg)
+	request := translateRequest{Format: "text", APIKey: t.cfg.APIKey}
+	var ok bool
+	if request.Source, ok = languageCodes[from]; !ok {
+		return buzz_error.InvalidInput("LibreTranslate does not support source language " + from)
+	}
+	if request.Target, ok = languageCodes[to]; !ok || to == lang.Auto {
+		return buzz_error.InvalidInput("LibreTranslate does not support target language " + to)
+	}
+
+	sentences := util.SplitSentences(text)
+	// Without streaming the output is written once it is complete.
+	w := out
+	var buffered strings.Builder
+	if opts.NoStream {
+		w = &buffered
+	}
+	if opts.Ref {
+		fmt.Fprint(w, "[")
+	}
+	err := t.writeTranslations(ctx, &request, sentences, to, opts.Ref, w)
+	if opts.Ref {
+		// The pairs written before an error stay a JSON array.
+		fmt.Fprint(w, "]")
+	}
+	if err != nil {
+		return err
+	}
+	if opts.NoStream {
+		fmt.Fprint(out, buffered.String())
+	}
+	return nil
+}
+
+// writeTranslations translates sentences in batches and writes them to w,
+// as the elements of a JSON array of pairs with ref.
+func (t *TranslatorLibreTranslate) writeTranslations(ctx context.Context, request *translateRequest, sentences []util.Sentence, to string, ref bool, w io.Writer) error {
+	texts := make([]string, len(sentences))
+	for i, s := range sentences {
+		texts[i] = s.Text
+	}
+	start := 0
+	for _, batch := range util.BatchSegments(texts, batchSize) {
+		request.Q = batch
+		var resp translateResponse
+		if err := t.post(ctx, "/translate", request, &resp); err != nil {
+			return err
+		}
+		if len(resp.TranslatedText) != len(batch) {
+			return errors.Errorf("LibreTranslate returned %d translations for %d texts", len(resp.TranslatedText), len(batch))
+		}
+		for i, translation := range resp.TranslatedText {
+			s := sentences[start+i]
+			if !ref {
+				fmt.Fprint(w, util.SentenceSpace(s.Space, to)+translation)
+				continue
+			}
+			if start+i > 0 {
+				fmt.Fprint(w, ",")
+			}
+			data, err := json.Marshal(map[string]string{"raw": s.Text, "translation": translation})
+			if err != nil {
+				return errors.Wrap(err, "failed to marshal ref pair")
+			}
+			fmt.Fprint(w, string(data))
+		}
+		start += len(batch)
+	}
+	return nil
+}
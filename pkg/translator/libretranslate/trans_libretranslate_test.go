@@ -0,0 +1,170 @@
+package libretranslate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gogodjzhu/word-flow/internal/lang"
+	"github.com/gogodjzhu/word-flow/pkg/translator/types"
+)
+
+// fakeServer is a LibreTranslate server translating to upper case, which
+// requires the API key "key" and records the translate requests.
+func fakeServer(t *testing.T, requests *[]translateRequest) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/translate", func(w http.ResponseWriter, r *http.Request) {
+		var request translateRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, `{"error": "Invalid request"}`, http.StatusBadRequest)
+			return
+		}
+		if request.APIKey != "key" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error": "Invalid API key"}`))
+			return
+		}
+		*requests = append(*requests, request)
+		var resp translateResponse
+		for _, q := range request.Q {
+			resp.TranslatedText = append(resp.TranslatedText, strings.ToUpper(q))
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	})
+	mux.HandleFunc("/detect", func(w http.ResponseWriter, r *http.Request) {
+		var request detectRequest
+		_ = json.NewDecoder(r.Body).Decode(&request)
+		detections := []detection{{Confidence: 20, Language: "en"}}
+		switch {
+		case strings.Contains(request.Q, "中文"):
+			detections = []detection{{Confidence: 90, Language: "zh-Hant"}}
+		case strings.Contains(request.Q, "Hallo"):
+			detections = []detection{{Confidence: 90, Language: "de"}}
+		case strings.Contains(request.Q, "Hej"):
+			detections = []detection{{Confidence: 90, Language: "sv"}}
+		}
+		_ = json.NewEncoder(w).Encode(detections)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestTranslatorLibreTranslate_Translate(t *testing.T) {
+	var requests []translateRequest
+	server := fakeServer(t, &requests)
+	translator := NewTranslatorLibreTranslate(&Config{URL: server.URL + "/", APIKey: "key", From: "auto", To: "de"})
+
+	var out bytes.Buffer
+	if err := translator.Translate("Hello there.  How are you?\nFine.", &out, &types.TransOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if want := "HELLO THERE. HOW ARE YOU?\nFINE."; out.String() != want {
+		t.Errorf("Translate() = %q, want %q", out.String(), want)
+	}
+	if r := requests[0]; r.Source != "auto" || r.Target != "de" || r.Format != "text" || len(r.Q) != 3 {
+		t.Errorf("request = %+v", r)
+	}
+
+	out.Reset()
+	if err := translator.Translate("Hello. Bye.", &out, &types.TransOptions{Ref: true, NoStream: true, From: "en", To: "zh-TW"}); err != nil {
+		t.Fatal(err)
+	}
+	var pairs []map[string]string
+	if err := json.Unmarshal(out.Bytes(), &pairs); err != nil {
+		t.Fatalf("ref output %q is not JSON: %v", out.String(), err)
+	}
+	if len(pairs) != 2 || pairs[0]["raw"] != "Hello." || pairs[0]["translation"] != "HELLO." {
+		t.Errorf("ref pairs = %v", pairs)
+	}
+	if r := requests[1]; r.Source != "en" || r.Target != "zt" {
+		t.Errorf("request = %+v, want en to zt", r)
+	}
+}
+
+func TestTranslatorLibreTranslate_Batches(t *testing.T) {
+	var requests []translateRequest
+	server := fakeServer(t, &requests)
+	translator := NewTranslatorLibreTranslate(&Config{URL: server.URL, APIKey: "key", From: "en", To: "ja"})
+
+	text := strings.Repeat("This sentence is forty characters long. ", 100)
+	var out bytes.Buffer
+	if err := translator.Translate(text, &out, &types.TransOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	sent := 0
+	for _, r := range requests {
+		sent += len(r.Q)
+	}
+	if len(requests) < 2 || sent != 100 {
+		t.Errorf("sent %d texts in %d requests, want 100 in several", sent, len(requests))
+	}
+	if want := strings.Repeat("THIS SENTENCE IS FORTY CHARACTERS LONG.", 100); out.String() != want {
+		t.Errorf("Translate() = %q, want %q", out.String(), want)
+	}
+}
+
+func TestTranslatorLibreTranslate_Errors(t *testing.T) {
+	var requests []translateRequest
+	server := fakeServer(t, &requests)
+	translator := NewTranslatorLibreTranslate(&Config{URL: server.URL, From: "en", To: "de"})
+	err := translator.Translate("Hello.", &bytes.Buffer{}, &types.TransOptions{})
+	if err == nil || !strings.Contains(err.Error(), "Invalid API key") {
+		t.Errorf("Translate() error = %v, want the server's error", err)
+	}
+	err = translator.Translate("Hello.", &bytes.Buffer{}, &types.TransOptions{To: "lzh"})
+	if err == nil || !strings.Contains(err.Error(), "does not support target language lzh") {
+		t.Errorf("Translate() error = %v, want an unsupported language", err)
+	}
+}
+
+func TestTranslatorLibreTranslate_Detect(t *testing.T) {
+	var requests []translateRequest
+	server := fakeServer(t, &requests)
+	translator := NewTranslatorLibreTranslate(&Config{URL: server.URL})
+	tests := []struct {
+		text, want string
+	}{
+		{"Hallo Welt", "de"},
+		{"中文", "zh-TW"},
+		// Swedish is not supported, short English not confident.
+		{"Hej", lang.Auto},
+		{"ok", lang.Auto},
+	}
+	for _, tt := range tests {
+		got, err := translator.Detect(context.Background(), tt.text)
+		if err != nil || got != tt.want {
+			t.Errorf("Detect(%q) = %q, %v, want %q", tt.text, got, err, tt.want)
+		}
+	}
+}
+
+func TestTranslatorLibreTranslate_RefError(t *testing.T) {
+	var requests []translateRequest
+	server := fakeServer(t, &requests)
+	// The server goes away after the first batch.
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(requests) > 0 {
+			http.Error(w, `{"error": "Slowdown"}`, http.StatusTooManyRequests)
+			return
+		}
+		server.Config.Handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(failing.Close)
+	translator := NewTranslatorLibreTranslate(&Config{URL: failing.URL, APIKey: "key", From: "en", To: "de"})
+
+	var out bytes.Buffer
+	text := strings.Repeat("This sentence is forty characters long. ", 100)
+	if err := translator.Translate(text, &out, &types.TransOptions{Ref: true}); err == nil || !strings.Contains(err.Error(), "Slowdown") {
+		t.Fatalf("Translate() error = %v, want the server's error", err)
+	}
+	var pairs []map[string]string
+	if err := json.Unmarshal(out.Bytes(), &pairs); err != nil || len(pairs) == 0 || len(pairs) != len(requests[0].Q) {
+		t.Errorf("ref output before the error = %q, want a JSON array of the first batch: %v", out.String(), err)
+	}
+}
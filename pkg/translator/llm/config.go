@@ -0,0 +1,75 @@
+package llm
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gogodjzhu/word-flow/internal/config"
+)
+
+// Config is the trans.llm section.
+type Config struct {
+	ApiKey      string             `yaml:"api_key,omitempty"`
+	URL         string             `yaml:"url,omitempty"`
+	Model       string             `yaml:"model,omitempty"`
+	Timeout     config.Duration    `yaml:"timeout,omitempty"`
+	MaxTokens   int                `yaml:"max_tokens,omitempty"`
+	Temperature float64            `yaml:"temperature,omitempty"`
+	HTTP        *config.HTTPConfig `yaml:"http,omitempty"`
+	From        string             `yaml:"from,omitempty"`
+	To          string             `yaml:"to,omitempty"`
+}
+
+// ConfigSection registers Config with the translator.
+var ConfigSection = config.EndpointSpec{
+	New: func() config.EndpointConfig { return &Config{} },
+	Defaults: func(c config.EndpointConfig, dir string) {
+		llm := c.(*Config)
+		config.DefaultTimeout(&llm.Timeout, 30*time.Second)
+		config.DefaultHTTP(&llm.HTTP, 0)
+		config.DefaultLanguages(&llm.From, &llm.To)
+		if llm.MaxTokens == 0 {
+			llm.MaxTokens = 2000
+		}
+		if llm.Temperature == 0 {
+			llm.Temperature = 0.3
+		}
+	},
+	Template: `# llm:
+#   # LLM provider settings for translation (required if trans.default is llm)
+#   # api_key: ""           # Required. Set via WORDFLOW_TRANS_LLM_API_KEY or wordflow config set trans.llm.api_key
+#   # url: ""               # Required. Full API endpoint URL
+#   # model: ""             # Required. LLM model name
+#   timeout: 30s
+#   max_tokens: 2000
+#   temperature: 0.3
+#   from: auto
+#   to: zh
+`,
+}
+
+func (c *Config) Languages() (string, string) {
+	if c == nil {
+		return "", ""
+	}
+	return c.From, c.To
+}
+
+func (c *Config) Validate() error {
+	if c.ApiKey == "" {
+		return errors.New("trans.llm.api_key is required. Set it via: wordflow config set trans.llm.api_key <key> or env var WORDFLOW_TRANS_LLM_API_KEY")
+	}
+	if c.URL == "" {
+		return errors.New("trans.llm.url is required. Set it via: wordflow config set trans.llm.url <url> or env var WORDFLOW_TRANS_LLM_URL")
+	}
+	if c.Model == "" {
+		return errors.New("trans.llm.model is required. Set it via: wordflow config set trans.llm.model <model> or env var WORDFLOW_TRANS_LLM_MODEL")
+	}
+	if c.MaxTokens <= 0 {
+		return errors.New("trans.llm.max_tokens must be positive")
+	}
+	if c.Temperature < 0 || c.Temperature > 2 {
+		return errors.New("trans.llm.temperature must be between 0 and 2")
+	}
+	return config.ValidateLanguages("trans.llm", c.From, c.To)
+}
@@ -0,0 +1,50 @@
+package llm
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gogodjzhu/word-flow/internal/config"
+)
+
+func TestConfigDefaults(t *testing.T) {
+	c := ConfigSection.New().(*Config)
+	ConfigSection.Defaults(c, t.TempDir())
+	if c.Timeout != config.Duration(30*time.Second) || c.MaxTokens != 2000 || c.Temperature != 0.3 {
+		t.Errorf("defaults = %+v, want 30s, 2000 max tokens and temperature 0.3", c)
+	}
+	if c.From != config.DefaultSourceLanguage || c.To != config.DefaultTargetLanguage || c.HTTP == nil {
+		t.Errorf("defaults = %+v, want the default languages and http settings", c)
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	valid := Config{ApiKey: "test-key", URL: "https://api.example.com", Model: "test-model", MaxTokens: 2000, Temperature: 0.3}
+	tests := []struct {
+		name    string
+		edit    func(c *Config)
+		wantErr string
+	}{
+		{name: "valid", edit: func(c *Config) {}},
+		{name: "missing api_key", edit: func(c *Config) { c.ApiKey = "" }, wantErr: "trans.llm.api_key is required"},
+		{name: "missing url", edit: func(c *Config) { c.URL = "" }, wantErr: "trans.llm.url is required"},
+		{name: "missing model", edit: func(c *Config) { c.Model = "" }, wantErr: "trans.llm.model is required"},
+		{name: "max_tokens", edit: func(c *Config) { c.MaxTokens = 0 }, wantErr: "trans.llm.max_tokens must be positive"},
+		{name: "temperature", edit: func(c *Config) { c.Temperature = 2.5 }, wantErr: "trans.llm.temperature must be between 0 and 2"},
+		{name: "languages", edit: func(c *Config) { c.To = "auto" }, wantErr: "invalid trans.llm languages"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := valid
+			tt.edit(&c)
+			err := c.Validate()
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("Validate() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("Validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
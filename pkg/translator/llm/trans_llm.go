@@ -1,6 +1,7 @@
 package llm
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -8,7 +9,7 @@ import (
 	"strings"
 	"time"
 
-	"github.com/gogodjzhu/word-flow/internal/config"
+	"github.com/gogodjzhu/word-flow/internal/lang"
 	"github.com/gogodjzhu/word-flow/internal/llm"
 	"github.com/gogodjzhu/word-flow/internal/util"
 	"github.com/gogodjzhu/word-flow/pkg/translator/types"
@@ -16,20 +17,50 @@ import (
 )
 
 type TranslatorLLM struct {
-	cfg *config.TransLLMConfig
+	cfg *Config
 }
 
-func NewTranslatorLLM(cfg *config.TransLLMConfig) *TranslatorLLM {
+func NewTranslatorLLM(cfg *Config) *TranslatorLLM {
 	return &TranslatorLLM{cfg: cfg}
 }
 
+// SupportsLanguage reports whether code is a language of internal/lang, all
+// of which the model is prompted to translate.
+func (t *TranslatorLLM) SupportsLanguage(code string) bool {
+	_, ok := lang.Lookup(code)
+	return ok || code == lang.Auto
+}
+
+// FollowsTerms reports that the glossary terms of the options are given to
+// the model in the system prompt.
+func (t *TranslatorLLM) FollowsTerms() bool {
+	return true
+}
+
 func (t *TranslatorLLM) Translate(text string, out io.Writer, opts *types.TransOptions) error {
+	return t.TranslateContext(context.Background(), text, out, opts)
+}
+
+func (t *TranslatorLLM) TranslateContext(ctx context.Context, text string, out io.Writer, opts *types.TransOptions) error {
+	ctx = util.WithRequestTimeout(ctx, time.Duration(t.cfg.Timeout))
+	ctx = util.WithEndpoint(util.WithPolicy(ctx, util.PolicyFromConfig(t.cfg.HTTP)), "trans.llm")
 	if strings.TrimSpace(text) == "" {
 		return errors.New("empty input text")
 	}
+	if opts == nil {
+		opts = &types.TransOptions{}
+	}
+	from, to := opts.Languages(t.cfg)
 
 	promptBuilder := llm.NewPromptBuilder()
-	systemPrompt := promptBuilder.BuildTranslationPrompt(opts.Ref)
+	if len(opts.Terms) > 0 {
+		terms := make([]llm.GlossaryTerm, len(opts.Terms))
+		for i, term := range opts.Terms {
+			terms[i] = llm.GlossaryTerm{Source: term.Source, Target: term.Target}
+		}
+		promptBuilder.WithGlossary(terms)
+	}
+	systemPrompt := promptBuilder.BuildTranslationPrompt(from, to, opts.Ref)
 
 	request := &llm.ChatRequest{
 		Model: t.cfg.Model,
@@ -52,15 +83,15 @@ func (t *TranslatorLLM) Translate(text string, out io.Writer, opts *types.TransO
 	}
 
 	if opts.NoStream {
-		return t.handleNonStreaming(headers, requestBytes, out)
+		return t.handleNonStreaming(ctx, headers, requestBytes, out)
 	}
-	return t.handleStreaming(headers, requestBytes, out)
+	return t.handleStreaming(ctx, headers, requestBytes, out)
 }
 
-func (t *TranslatorLLM) handleNonStreaming(headers map[string]string, requestBytes []byte, out io.Writer) error {
+func (t *TranslatorLLM) handleNonStreaming(ctx context.Context, headers map[string]string, requestBytes []byte, out io.Writer) error {
 	responseProcessor := llm.NewResponseProcessor()
 
-	result, err := util.SendPostWithTimeout(t.cfg.URL, headers, requestBytes, time.Duration(t.cfg.Timeout), func(response *http.Response) (interface{}, error) {
+	result, err := util.SendPostContext(ctx, t.cfg.URL, headers, requestBytes, func(response *http.Response) (interface{}, error) {
 		return responseProcessor.ProcessResponse(response)
 	})
 	if err != nil {
@@ -72,10 +103,10 @@ func (t *TranslatorLLM) handleNonStreaming(headers map[string]string, requestByt
 	return nil
 }
 
-func (t *TranslatorLLM) handleStreaming(headers map[string]string, requestBytes []byte, out io.Writer) error {
+func (t *TranslatorLLM) handleStreaming(ctx context.Context, headers map[string]string, requestBytes []byte, out io.Writer) error {
 	streamProcessor := llm.NewStreamProcessor(out)
 
-	err := util.SendPostStreamWithTimeout(t.cfg.URL, headers, requestBytes, time.Duration(t.cfg.Timeout), func(response *http.Response) error {
+	err := util.SendPostStreamContext(ctx, t.cfg.URL, headers, requestBytes, func(response *http.Response) error {
 		_, err := streamProcessor.ProcessStream(response)
 		return err
 	})
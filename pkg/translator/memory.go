@@ -0,0 +1,237 @@
+package translator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/gogodjzhu/word-flow/internal/config"
+	"github.com/gogodjzhu/word-flow/pkg/translator/tm"
+	"github.com/gogodjzhu/word-flow/pkg/util"
+	"github.com/pkg/errors"
+)
+
+// suggestionsPerSegment is the number of fuzzy matches offered for a segment
+// missing from the translation memory.
+const suggestionsPerSegment = 1
+
+// MemoryTranslator looks the segments of a text up in a translation memory
+// before translating them, and remembers the new translations. Texts found
+// entirely in the memory are not sent to the translator.
+type MemoryTranslator struct {
+	translator Translator
+	store      *tm.Store
+	name       string
+	defaults   config.LanguageDefaults
+	threshold  float64
+
+	hits, segments int
+	suggestions    []tm.Match
+}
+
+// NewMemoryTranslator wraps t, the default translator of cfg, with the
+// translation memory in store.
+func NewMemoryTranslator(cfg *config.TransConfig, t Translator, store *tm.Store) *MemoryTranslator {
+	m := &MemoryTranslator{translator: t, store: store, name: cfg.Default}
+	if endpointConfig, err := cfg.GetEndpointConfig(cfg.Default); err == nil {
+		m.defaults, _ = endpointConfig.(config.LanguageDefaults)
+	}
+	if cfg.Memory != nil {
+		m.threshold = cfg.Memory.FuzzyThreshold
+	}
+	return m
+}
+
+// Hits returns the number of segments translated so far that were found in
+// the memory, and the number of segments.
+func (m *MemoryTranslator) Hits() (hits, segments int) {
+	return m.hits, m.segments
+}
+
+// Suggestions returns the fuzzy matches of the segments translated so far
+// that were missing from the memory.
+func (m *MemoryTranslator) Suggestions() []tm.Match {
+	return m.suggestions
+}
+
+// SupportsLanguage forwards to the wrapped translator.
+func (m *MemoryTranslator) SupportsLanguage(code string) bool {
+	if lt, ok := m.translator.(LanguageTranslator); ok {
+		return lt.SupportsLanguage(code)
+	}
+	return true
+}
+
+func (m *MemoryTranslator) Translate(text string, out io.Writer, opts *TransOptions) error {
+	return m.TranslateContext(context.Background(), text, out, opts)
+}
+
+func (m *MemoryTranslator) TranslateContext(ctx context.Context, text string, out io.Writer, opts *TransOptions) error {
+	if opts == nil {
+		opts = &TransOptions{}
+	}
+	from, to := opts.Languages(m.defaults)
+	q := tm.Query{From: from, To: to, Translator: m.name}
+
+	text = strings.TrimSpace(text)
+	if translation, ok, err := m.store.Lookup(q, text); err != nil {
+		return err
+	} else if ok {
+		m.hits++
+		m.segments++
+		return writePairs(out, []segmentPair{{Raw: text, Translation: translation}}, opts.Ref, to)
+	}
+
+	sentences := util.SplitSentences(text)
+	m.segments += len(sentences)
+	translations := make([]string, len(sentences))
+	found := make([]bool, len(sentences))
+	hits := 0
+	for i, s := range sentences {
+		translation, ok, err := m.store.Lookup(q, s.Text)
+		if err != nil {
+			return err
+		}
+		translations[i], found[i] = translation, ok
+		if ok {
+			hits++
+		} else if err := m.suggest(q, s.Text); err != nil {
+			return err
+		}
+	}
+
+	m.hits += hits
+	if hits == 0 {
+		// Nothing to reuse, the translation streams as usual and is split
+		// into sentences afterwards.
+		var buf bytes.Buffer
+		if err := Translate(ctx, m.translator, text, io.MultiWriter(out, &buf), opts); err != nil {
+			return err
+		}
+		if !opts.Ref {
+			return m.remember(q, []segmentPair{{Raw: text, Translation: buf.String()}})
+		}
+		// Output that is not a JSON array of pairs is not worth keeping.
+		if pairs, ok := parsePairs(buf.String()); ok {
+			return m.remember(q, pairs)
+		}
+		return nil
+	}
+
+	// Some sentences come from the memory, the runs of missing ones are
+	// translated each with a single request asking for pairs and the whole
+	// translation is written once complete.
+	var pairs []segmentPair
+	for i := 0; i < len(sentences); {
+		if found[i] {
+			pairs = append(pairs, segmentPair{Raw: sentences[i].Text, Translation: translations[i], space: sentences[i].Space})
+			i++
+			continue
+		}
+		j := i
+		var run strings.Builder
+		for ; j < len(sentences) && !found[j]; j++ {
+			if j > i {
+				run.WriteString(sentences[j].Space)
+			}
+			run.WriteString(sentences[j].Text)
+		}
+		var buf bytes.Buffer
+		runOpts := &TransOptions{Ref: true, NoStream: true, From: opts.From, To: opts.To, Terms: opts.Terms}
+		if err := Translate(ctx, m.translator, run.String(), &buf, runOpts); err != nil {
+			return err
+		}
+		runPairs, ok := parsePairs(buf.String())
+		if !ok {
+			runPairs = []segmentPair{{Raw: run.String(), Translation: buf.String()}}
+		}
+		if err := m.remember(q, runPairs); err != nil {
+			return err
+		}
+		// Pairs matching the sentences keep their line breaks.
+		if len(runPairs) == j-i {
+			for k := range runPairs {
+				runPairs[k].space = sentences[i+k].Space
+			}
+		} else {
+			runPairs[0].space = sentences[i].Space
+		}
+		pairs = append(pairs, runPairs...)
+		i = j
+	}
+	return writePairs(out, pairs, opts.Ref, to)
+}
+
+func (m *MemoryTranslator) suggest(q tm.Query, segment string) error {
+	if m.threshold <= 0 {
+		return nil
+	}
+	matches, err := m.store.Fuzzy(q, segment, m.threshold, suggestionsPerSegment)
+	if err != nil {
+		return err
+	}
+	m.suggestions = append(m.suggestions, matches...)
+	return nil
+}
+
+// remember puts the sentences of pairs into the memory. Pairs may cover
+// several sentences, as Baidu's cover a whole batch; those whose raw text and
+// translation do not split into as many sentences are skipped.
+func (m *MemoryTranslator) remember(q tm.Query, pairs []segmentPair) error {
+	for _, pair := range pairs {
+		raw, translation := util.SplitSentences(pair.Raw), util.SplitSentences(pair.Translation)
+		if len(raw) != len(translation) {
+			continue
+		}
+		for i := range raw {
+			if err := m.store.Put(q, raw[i].Text, translation[i].Text); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// segmentPair is a segment of the --ref output.
+type segmentPair struct {
+	Raw         string `json:"raw"`
+	Translation string `json:"translation"`
+	// space is the white space before the segment in the text.
+	space string
+}
+
+// parsePairs reads the --ref output of a translator.
+func parsePairs(output string) ([]segmentPair, bool) {
+	var pairs []segmentPair
+	if err := json.Unmarshal([]byte(strings.TrimSpace(output)), &pairs); err != nil || len(pairs) == 0 {
+		return nil, false
+	}
+	return pairs, true
+}
+
+func writePairs(out io.Writer, pairs []segmentPair, ref bool, to string) error {
+	if ref {
+		data, err := json.Marshal(pairs)
+		if err != nil {
+			return errors.Wrap(err, "failed to marshal ref pairs")
+		}
+		_, err = out.Write(data)
+		return err
+	}
+	var b strings.Builder
+	for i, pair := range pairs {
+		if i > 0 {
+			space := pair.space
+			if space == "" {
+				space = " "
+			}
+			b.WriteString(util.SentenceSpace(space, to))
+		}
+		b.WriteString(strings.TrimSpace(pair.Translation))
+	}
+	_, err := fmt.Fprint(out, b.String())
+	return err
+}
@@ -0,0 +1,224 @@
+package translator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gogodjzhu/word-flow/internal/config"
+	trans_google "github.com/gogodjzhu/word-flow/pkg/translator/google"
+	"github.com/gogodjzhu/word-flow/pkg/translator/tm"
+	"github.com/gogodjzhu/word-flow/pkg/util"
+)
+
+// upperTranslator translates to upper case and counts its calls.
+type upperTranslator struct {
+	calls []string
+}
+
+func (u *upperTranslator) Translate(text string, out io.Writer, opts *TransOptions) error {
+	u.calls = append(u.calls, text)
+	if !opts.Ref {
+		_, err := fmt.Fprint(out, strings.ToUpper(strings.TrimSpace(text)))
+		return err
+	}
+	var pairs []segmentPair
+	for _, segment := range util.SegmentText(text) {
+		pairs = append(pairs, segmentPair{Raw: strings.TrimSpace(segment), Translation: strings.ToUpper(strings.TrimSpace(segment))})
+	}
+	return json.NewEncoder(out).Encode(pairs)
+}
+
+func newTestMemoryTranslator(t *testing.T) (*MemoryTranslator, *upperTranslator, *tm.Store) {
+	t.Helper()
+	store, err := tm.Open(filepath.Join(t.TempDir(), "tm.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+	cfg := &config.TransConfig{
+		Default:   "google",
+		Endpoints: map[string]config.EndpointConfig{"google": &trans_google.Config{From: "en", To: "de"}},
+		Memory:    &config.TransMemoryConfig{FuzzyThreshold: 0.8},
+	}
+	inner := &upperTranslator{}
+	return NewMemoryTranslator(cfg, inner, store), inner, store
+}
+
+func TestMemoryTranslator_RemembersTranslations(t *testing.T) {
+	m, inner, _ := newTestMemoryTranslator(t)
+
+	var buf bytes.Buffer
+	if err := m.Translate("Hello there.", &buf, &TransOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "HELLO THERE." || len(inner.calls) != 1 {
+		t.Fatalf("expected a translation, got %q after %d calls", buf.String(), len(inner.calls))
+	}
+
+	buf.Reset()
+	if err := m.Translate("  Hello   there. ", &buf, &TransOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "HELLO THERE." {
+		t.Errorf("expected the remembered translation, got %q", buf.String())
+	}
+	if len(inner.calls) != 1 {
+		t.Errorf("expected no new call, got %v", inner.calls)
+	}
+	if hits, segments := m.Hits(); hits != 1 || segments != 2 {
+		t.Errorf("expected 1 of 2 segments reused, got %d of %d", hits, segments)
+	}
+
+	buf.Reset()
+	if err := m.Translate("Hello there.", &buf, &TransOptions{To: "fr"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(inner.calls) != 2 {
+		t.Errorf("expected another language pair to be translated, got %v", inner.calls)
+	}
+}
+
+func TestMemoryTranslator_TranslatesMissingSegments(t *testing.T) {
+	m, inner, store := newTestMemoryTranslator(t)
+	q := tm.Query{From: "en", To: "de", Translator: "google"}
+	_ = store.Put(q, "One fish.", "Ein Fisch.")
+	_ = store.Put(q, "Four fish.", "Vier Fische.")
+
+	var buf bytes.Buffer
+	text := "One fish. Two fish. Three fish. Four fish."
+	if err := m.Translate(text, &buf, &TransOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "Ein Fisch. TWO FISH. THREE FISH. Vier Fische." {
+		t.Errorf("unexpected translation %q", buf.String())
+	}
+	if len(inner.calls) != 1 || strings.TrimSpace(inner.calls[0]) != "Two fish. Three fish." {
+		t.Errorf("expected a single call for the missing run, got %q", inner.calls)
+	}
+	if hits, segments := m.Hits(); hits != 2 || segments != 4 {
+		t.Errorf("expected 2 of 4 segments reused, got %d of %d", hits, segments)
+	}
+	if translation, ok, _ := store.Lookup(q, "Three fish."); !ok || translation != "THREE FISH." {
+		t.Errorf("expected the new segment to be remembered, got %q, %v", translation, ok)
+	}
+}
+
+func TestMemoryTranslator_RemembersSentences(t *testing.T) {
+	m, inner, store := newTestMemoryTranslator(t)
+
+	var buf bytes.Buffer
+	if err := m.Translate("One fish.\n\nTwo fish.", &buf, &TransOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "ONE FISH.\n\nTWO FISH." {
+		t.Errorf("unexpected translation %q", buf.String())
+	}
+	q := tm.Query{From: "en", To: "de", Translator: "google"}
+	if translation, ok, _ := store.Lookup(q, "Two fish."); !ok || translation != "TWO FISH." {
+		t.Errorf("expected the sentence to be remembered, got %q, %v", translation, ok)
+	}
+
+	buf.Reset()
+	if err := m.Translate("Two fish. Red fish.", &buf, &TransOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if len(inner.calls) != 2 || strings.TrimSpace(inner.calls[1]) != "Red fish." {
+		t.Errorf("expected only the new sentence to be translated, got %q", inner.calls)
+	}
+	if buf.String() != "TWO FISH. RED FISH." {
+		t.Errorf("unexpected translation %q", buf.String())
+	}
+}
+
+func TestMemoryTranslator_RefOutputIsJSON(t *testing.T) {
+	m, _, store := newTestMemoryTranslator(t)
+	_ = store.Put(tm.Query{From: "en", To: "de", Translator: "google"}, "One fish.", "Ein Fisch.")
+
+	var buf bytes.Buffer
+	if err := m.Translate("One fish. Two fish.", &buf, &TransOptions{Ref: true}); err != nil {
+		t.Fatal(err)
+	}
+	var pairs []segmentPair
+	if err := json.Unmarshal(buf.Bytes(), &pairs); err != nil {
+		t.Fatalf("expected a JSON array, got %q: %v", buf.String(), err)
+	}
+	want := []segmentPair{{Raw: "One fish.", Translation: "Ein Fisch."}, {Raw: "Two fish.", Translation: "TWO FISH."}}
+	if fmt.Sprint(pairs) != fmt.Sprint(want) {
+		t.Errorf("expected %v, got %v", want, pairs)
+	}
+}
+
+func TestMemoryTranslator_Suggestions(t *testing.T) {
+	m, _, store := newTestMemoryTranslator(t)
+	_ = store.Put(tm.Query{From: "en", To: "de", Translator: "google"}, "The report is ready.", "Der Bericht ist fertig.")
+
+	if err := m.Translate("The reports are ready.", io.Discard, &TransOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	suggestions := m.Suggestions()
+	if len(suggestions) != 1 || suggestions[0].Translation != "Der Bericht ist fertig." {
+		t.Errorf("expected a fuzzy match, got %+v", suggestions)
+	}
+}
+
+// funcTranslator translates with a function.
+type funcTranslator func(text string, out io.Writer, opts *TransOptions) error
+
+func (f funcTranslator) Translate(text string, out io.Writer, opts *TransOptions) error {
+	return f(text, out, opts)
+}
+
+func TestMemoryTranslator_StreamsNewTranslations(t *testing.T) {
+	m, _, store := newTestMemoryTranslator(t)
+	var buf bytes.Buffer
+	m.translator = funcTranslator(func(text string, out io.Writer, opts *TransOptions) error {
+		if opts.Ref || opts.NoStream {
+			t.Errorf("expected the plain translation to stream, got %+v", opts)
+		}
+		if _, err := fmt.Fprint(out, "EIN FISCH."); err != nil {
+			return err
+		}
+		if buf.String() != "EIN FISCH." {
+			t.Errorf("expected the first sentence to be shown before the end, got %q", buf.String())
+		}
+		_, err := fmt.Fprint(out, " ZWEI FISCHE.")
+		return err
+	})
+
+	if err := m.Translate("One fish. Two fish.", &buf, &TransOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	q := tm.Query{From: "en", To: "de", Translator: "google"}
+	if translation, ok, _ := store.Lookup(q, "Two fish."); !ok || translation != "ZWEI FISCHE." {
+		t.Errorf("expected the sentence to be remembered, got %q, %v", translation, ok)
+	}
+}
+
+func TestMemoryTranslator_SplitsRefPairs(t *testing.T) {
+	m, _, store := newTestMemoryTranslator(t)
+	// A pair for the whole batch, as Baidu answers.
+	m.translator = funcTranslator(func(text string, out io.Writer, opts *TransOptions) error {
+		return json.NewEncoder(out).Encode([]segmentPair{
+			{Raw: "One fish.\nTwo fish.", Translation: "EIN FISCH.\nZWEI FISCHE."},
+			{Raw: "Red fish. Blue fish.", Translation: "ROTE UND BLAUE FISCHE."},
+		})
+	})
+
+	if err := m.Translate("One fish.\nTwo fish.\nRed fish. Blue fish.", io.Discard, &TransOptions{Ref: true}); err != nil {
+		t.Fatal(err)
+	}
+	q := tm.Query{From: "en", To: "de", Translator: "google"}
+	if translation, ok, _ := store.Lookup(q, "Two fish."); !ok || translation != "ZWEI FISCHE." {
+		t.Errorf("expected the sentence to be remembered, got %q, %v", translation, ok)
+	}
+	for _, segment := range []string{"One fish.\nTwo fish.", "Red fish.", "Red fish. Blue fish."} {
+		if translation, ok, _ := store.Lookup(q, segment); ok {
+			t.Errorf("expected %q not to be remembered, got %q", segment, translation)
+		}
+	}
+}
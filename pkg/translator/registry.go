@@ -0,0 +1,70 @@
+package translator
+
+import (
+	"sync"
+
+	"github.com/gogodjzhu/word-flow/internal/config"
+)
+
+// Registration describes a translator endpoint: its name and description,
+// its configuration section and the factory creating it.
+type Registration struct {
+	Name        string
+	Description string
+	// Config is the configuration section of the endpoint. It may be left nil
+	// when the section is already registered with config.RegisterTransEndpoint.
+	Config *config.EndpointSpec
+	New    func(endpointConfig config.TransEndpointConfig) (Translator, error)
+}
+
+var (
+	registryMu    sync.RWMutex
+	registrations []Registration
+)
+
+func init() {
+	for _, r := range builtinTranslators {
+		Register(r)
+	}
+}
+
+// Register adds a translator endpoint. It panics if the name is already taken
+// or the endpoint has no configuration section.
+func Register(r Registration) {
+	if r.Name == "" || r.New == nil {
+		panic("translator: endpoint needs a name and a factory")
+	}
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	for _, existing := range registrations {
+		if existing.Name == r.Name {
+			panic("translator: endpoint " + r.Name + " registered twice")
+		}
+	}
+	if r.Config != nil {
+		spec := *r.Config
+		spec.Name = r.Name
+		config.RegisterTransEndpoint(spec)
+	} else if _, ok := config.TransEndpoint(r.Name); !ok {
+		panic("translator: endpoint " + r.Name + " has no config section")
+	}
+	registrations = append(registrations, r)
+}
+
+// Lookup returns the registration of a translator endpoint.
+func Lookup(name string) (Registration, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	for _, r := range registrations {
+		if r.Name == name {
+			return r, true
+		}
+	}
+	return Registration{}, false
+}
+
+func registered() []Registration {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	return append([]Registration(nil), registrations...)
+}
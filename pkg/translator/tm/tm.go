@@ -0,0 +1,211 @@
+// Package tm is the translation memory of wordflow: translated segments in a
+// local SQLite database, keyed by the normalized segment, the language pair
+// and the translator. It is shared between users as TMX files.
+package tm
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/logger"
+
+	_ "modernc.org/sqlite"
+)
+
+// fuzzyCandidates is the number of entries of similar length compared by
+// Fuzzy, the most recently used first.
+const fuzzyCandidates = 500
+
+// Entry is a translated segment.
+type Entry struct {
+	ID uint `gorm:"primaryKey"`
+	// Key is the normalized source segment.
+	Key         string `gorm:"uniqueIndex:idx_tm_key;not null"`
+	From        string `gorm:"uniqueIndex:idx_tm_key;not null"`
+	To          string `gorm:"uniqueIndex:idx_tm_key;not null"`
+	Translator  string `gorm:"uniqueIndex:idx_tm_key;not null"`
+	Source      string `gorm:"not null"`
+	Translation string `gorm:"not null"`
+	// Length is the number of runes of Key, candidates of fuzzy matches
+	// have a similar length.
+	Length     int `gorm:"index"`
+	UsageCount int
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+func (Entry) TableName() string {
+	return "tm_entry"
+}
+
+// Query selects the entries of a language pair and translator.
+type Query struct {
+	From       string
+	To         string
+	Translator string
+}
+
+// Match is an entry similar to a looked up segment.
+type Match struct {
+	Entry
+	// Similarity is between 0 and 1, 1 for identical segments.
+	Similarity float64
+}
+
+// Store is a translation memory database.
+type Store struct {
+	db *gorm.DB
+}
+
+// Open opens or creates the translation memory in dbFilename.
+func Open(dbFilename string) (*Store, error) {
+	if err := os.MkdirAll(filepath.Dir(dbFilename), 0755); err != nil {
+		return nil, errors.Wrap(err, "failed to create translation memory directory")
+	}
+	db, err := gorm.Open(sqlite.New(sqlite.Config{DriverName: "sqlite", DSN: dbFilename}), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open translation memory")
+	}
+	if err := db.AutoMigrate(&Entry{}); err != nil {
+		return nil, errors.Wrap(err, "failed to migrate translation memory")
+	}
+	return &Store{db: db}, nil
+}
+
+func (s *Store) Close() error {
+	db, err := s.db.DB()
+	if err != nil {
+		return err
+	}
+	return db.Close()
+}
+
+// Normalize returns the key of a segment: trimmed, with runs of white space
+// collapsed to a single space.
+func Normalize(segment string) string {
+	return strings.Join(strings.Fields(segment), " ")
+}
+
+// Lookup returns the translation of segment, counting the use of the entry.
+func (s *Store) Lookup(q Query, segment string) (string, bool, error) {
+	var entry Entry
+	err := s.db.Where(&Entry{Key: Normalize(segment), From: q.From, To: q.To, Translator: q.Translator}).
+		Limit(1).Find(&entry).Error
+	if err != nil {
+		return "", false, errors.Wrap(err, "failed to look up translation memory")
+	}
+	if entry.ID == 0 {
+		return "", false, nil
+	}
+	s.db.Model(&entry).UpdateColumn("usage_count", gorm.Expr("usage_count + 1"))
+	return entry.Translation, true, nil
+}
+
+// Fuzzy returns the entries whose similarity to segment is at least
+// threshold, the most similar first. Identical segments are left to Lookup.
+func (s *Store) Fuzzy(q Query, segment string, threshold float64, limit int) ([]Match, error) {
+	key := Normalize(segment)
+	length := len([]rune(key))
+	if length == 0 {
+		return nil, nil
+	}
+	// Similarity is bounded by the ratio of the lengths.
+	minLength, maxLength := int(float64(length)*threshold), int(float64(length)/threshold)
+	var entries []Entry
+	err := s.db.Where(&Entry{From: q.From, To: q.To, Translator: q.Translator}).
+		Where("length between ? and ? and `key` <> ?", minLength, maxLength, key).
+		Order("updated_at desc").Limit(fuzzyCandidates).Find(&entries).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to search translation memory")
+	}
+	var matches []Match
+	for _, entry := range entries {
+		if similarity := Similarity(key, entry.Key); similarity >= threshold {
+			matches = append(matches, Match{Entry: entry, Similarity: similarity})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Similarity > matches[j].Similarity
+	})
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches, nil
+}
+
+// Put stores the translation of a segment, replacing an earlier one.
+func (s *Store) Put(q Query, source, translation string) error {
+	return s.put(&Entry{
+		Key:         Normalize(source),
+		From:        q.From,
+		To:          q.To,
+		Translator:  q.Translator,
+		Source:      strings.TrimSpace(source),
+		Translation: strings.TrimSpace(translation),
+	})
+}
+
+func (s *Store) put(entry *Entry) error {
+	if entry.Key == "" || entry.Translation == "" {
+		return nil
+	}
+	entry.Length = len([]rune(entry.Key))
+	err := s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "key"}, {Name: "from"}, {Name: "to"}, {Name: "translator"}},
+		DoUpdates: clause.AssignmentColumns([]string{"source", "translation", "updated_at"}),
+	}).Create(entry).Error
+	return errors.Wrap(err, "failed to save translation memory")
+}
+
+// Each calls fn with every entry, the oldest first.
+func (s *Store) Each(fn func(*Entry) error) error {
+	var entries []Entry
+	return s.db.Order("id").FindInBatches(&entries, 500, func(tx *gorm.DB, batch int) error {
+		for i := range entries {
+			if err := fn(&entries[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}).Error
+}
+
+// Similarity is one minus the edit distance of the runes of a and b divided
+// by the length of the longer one.
+func Similarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longer := max(len(ra), len(rb))
+	if longer == 0 {
+		return 1
+	}
+	return 1 - float64(levenshtein(ra, rb))/float64(longer)
+}
+
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
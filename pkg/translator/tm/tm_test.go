@@ -0,0 +1,113 @@
+package tm
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func openTestStore(t *testing.T) *Store {
+	t.Helper()
+	store, err := Open(filepath.Join(t.TempDir(), "tm", "tm.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+	return store
+}
+
+func TestLookup(t *testing.T) {
+	store := openTestStore(t)
+	q := Query{From: "en", To: "zh", Translator: "google"}
+	if err := store.Put(q, "Hello  world. ", "你好，世界。"); err != nil {
+		t.Fatal(err)
+	}
+
+	translation, ok, err := store.Lookup(q, "Hello world.")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok || translation != "你好，世界。" {
+		t.Errorf("expected a hit, got %q, %v", translation, ok)
+	}
+	if _, ok, _ := store.Lookup(Query{From: "en", To: "ja", Translator: "google"}, "Hello world."); ok {
+		t.Error("expected a miss for another language pair")
+	}
+	if _, ok, _ := store.Lookup(Query{From: "en", To: "zh", Translator: "llm"}, "Hello world."); ok {
+		t.Error("expected a miss for another translator")
+	}
+
+	if err := store.Put(q, "Hello world.", "世界你好。"); err != nil {
+		t.Fatal(err)
+	}
+	translation, _, _ = store.Lookup(q, "Hello world.")
+	if translation != "世界你好。" {
+		t.Errorf("expected the translation to be replaced, got %q", translation)
+	}
+	var entries []*Entry
+	_ = store.Each(func(e *Entry) error {
+		entries = append(entries, e)
+		return nil
+	})
+	if len(entries) != 1 || entries[0].UsageCount != 2 {
+		t.Errorf("expected a single entry used twice, got %+v", entries)
+	}
+}
+
+func TestFuzzy(t *testing.T) {
+	store := openTestStore(t)
+	q := Query{From: "en", To: "zh", Translator: "google"}
+	for source, translation := range map[string]string{
+		"The quick brown fox jumps over the lazy dog.": "敏捷的棕色狐狸跳过了懒狗。",
+		"The quick brown cat jumps over the lazy dog.": "敏捷的棕色猫跳过了懒狗。",
+		"Something else entirely.":                     "完全是另一回事。",
+	} {
+		if err := store.Put(q, source, translation); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	matches, err := store.Fuzzy(q, "The quick brown fox jumps over the lazy cat.", 0.8, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 2 {
+		t.Fatalf("expected 2 matches, got %d", len(matches))
+	}
+	if matches[0].Source != "The quick brown fox jumps over the lazy dog." {
+		t.Errorf("expected the closest match first, got %q", matches[0].Source)
+	}
+	if matches[0].Similarity < matches[1].Similarity {
+		t.Errorf("expected matches by similarity, got %v, %v", matches[0].Similarity, matches[1].Similarity)
+	}
+
+	matches, _ = store.Fuzzy(q, "The quick brown fox jumps over the lazy dog.", 0.8, 0)
+	for _, m := range matches {
+		if m.Similarity == 1 {
+			t.Error("expected the identical segment to be left to Lookup")
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	if got := Normalize("  Hello \n\t world.  "); got != "Hello world." {
+		t.Errorf("unexpected key %q", got)
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{"", "", 1},
+		{"abc", "abc", 1},
+		{"abcd", "abce", 0.75},
+		{"abcdef", "abcxyz", 0.5},
+		{"你好世界", "你好", 0.5},
+	}
+	for _, tt := range tests {
+		if got := Similarity(tt.a, tt.b); got != tt.want {
+			t.Errorf("Similarity(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
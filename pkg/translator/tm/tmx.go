@@ -0,0 +1,191 @@
+package tm
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/gogodjzhu/word-flow/internal/lang"
+	"github.com/pkg/errors"
+)
+
+// translatorProp is the TMX property holding the translator of a unit.
+const translatorProp = "x-translator"
+
+// tmxTime is the date format of TMX, in UTC.
+const tmxTime = "20060102T150405Z"
+
+type tmxDocument struct {
+	XMLName xml.Name  `xml:"tmx"`
+	Version string    `xml:"version,attr"`
+	Header  tmxHeader `xml:"header"`
+	Units   []tmxUnit `xml:"body>tu"`
+}
+
+type tmxHeader struct {
+	CreationTool        string `xml:"creationtool,attr"`
+	CreationToolVersion string `xml:"creationtoolversion,attr"`
+	SegType             string `xml:"segtype,attr"`
+	OTmf                string `xml:"o-tmf,attr"`
+	AdminLang           string `xml:"adminlang,attr"`
+	SrcLang             string `xml:"srclang,attr"`
+	DataType            string `xml:"datatype,attr"`
+}
+
+type tmxUnit struct {
+	SrcLang      string       `xml:"srclang,attr,omitempty"`
+	CreationDate string       `xml:"creationdate,attr,omitempty"`
+	ChangeDate   string       `xml:"changedate,attr,omitempty"`
+	UsageCount   int          `xml:"usagecount,attr,omitempty"`
+	Props        []tmxProp    `xml:"prop"`
+	Variants     []tmxVariant `xml:"tuv"`
+}
+
+type tmxProp struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+type tmxVariant struct {
+	// Lang is xml:lang, TMX 1.1 files use a plain lang attribute.
+	Lang    string `xml:"http://www.w3.org/XML/1998/namespace lang,attr"`
+	OldLang string `xml:"lang,attr,omitempty"`
+	Seg     string `xml:"seg"`
+}
+
+func (v tmxVariant) lang() string {
+	if v.Lang != "" {
+		return v.Lang
+	}
+	return v.OldLang
+}
+
+// Export writes every entry as a translation unit of a TMX 1.4 document and
+// returns the number of units.
+func (s *Store) Export(w io.Writer) (int, error) {
+	doc := tmxDocument{
+		Version: "1.4",
+		Header: tmxHeader{
+			CreationTool:        "wordflow",
+			CreationToolVersion: "1",
+			SegType:             "sentence",
+			OTmf:                "wordflow",
+			AdminLang:           "en",
+			SrcLang:             "*all*",
+			DataType:            "plaintext",
+		},
+	}
+	err := s.Each(func(e *Entry) error {
+		unit := tmxUnit{
+			SrcLang:      e.From,
+			CreationDate: e.CreatedAt.UTC().Format(tmxTime),
+			ChangeDate:   e.UpdatedAt.UTC().Format(tmxTime),
+			UsageCount:   e.UsageCount,
+			Variants: []tmxVariant{
+				{Lang: e.From, Seg: e.Source},
+				{Lang: e.To, Seg: e.Translation},
+			},
+		}
+		if e.Translator != "" {
+			unit.Props = []tmxProp{{Type: translatorProp, Value: e.Translator}}
+		}
+		doc.Units = append(doc.Units, unit)
+		return nil
+	})
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to read translation memory")
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return 0, err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return 0, errors.Wrap(err, "failed to write TMX")
+	}
+	_, err = io.WriteString(w, "\n")
+	return len(doc.Units), err
+}
+
+// Import adds the translation units of a TMX document. Every variant of a
+// unit is stored as a translation of its source variant, with the translator
+// of the unit's x-translator property or translator. Units in languages
+// wordflow does not support are skipped.
+func (s *Store) Import(r io.Reader, translator string) (imported, skipped int, err error) {
+	var doc tmxDocument
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return 0, 0, errors.Wrap(err, "invalid TMX")
+	}
+	for _, unit := range doc.Units {
+		source, ok := unit.source(doc.Header.SrcLang)
+		from, err := tmxLanguage(source.lang())
+		if !ok || err != nil {
+			skipped++
+			continue
+		}
+		unitTranslator := translator
+		for _, prop := range unit.Props {
+			if prop.Type == translatorProp && strings.TrimSpace(prop.Value) != "" {
+				unitTranslator = strings.TrimSpace(prop.Value)
+			}
+		}
+		for _, variant := range unit.Variants {
+			to, err := tmxLanguage(variant.lang())
+			if err != nil {
+				skipped++
+				continue
+			}
+			if to == from {
+				continue
+			}
+			entry := &Entry{
+				Key:         Normalize(source.Seg),
+				From:        from,
+				To:          to,
+				Translator:  unitTranslator,
+				Source:      strings.TrimSpace(source.Seg),
+				Translation: strings.TrimSpace(variant.Seg),
+				UsageCount:  unit.UsageCount,
+			}
+			if created, err := time.Parse(tmxTime, unit.CreationDate); err == nil {
+				entry.CreatedAt = created
+			}
+			if err := s.put(entry); err != nil {
+				return imported, skipped, err
+			}
+			imported++
+		}
+	}
+	return imported, skipped, nil
+}
+
+// source returns the variant in the source language of the unit, or of the
+// document, and the first variant when the source language is not given.
+func (u tmxUnit) source(docSrcLang string) (tmxVariant, bool) {
+	if len(u.Variants) < 2 {
+		return tmxVariant{}, false
+	}
+	srcLang := u.SrcLang
+	if srcLang == "" {
+		srcLang = docSrcLang
+	}
+	if srcLang == "" || srcLang == "*all*" {
+		return u.Variants[0], true
+	}
+	for _, v := range u.Variants {
+		if strings.EqualFold(v.lang(), srcLang) {
+			return v, true
+		}
+	}
+	return tmxVariant{}, false
+}
+
+// tmxLanguage returns the language code of a TMX language tag.
+func tmxLanguage(tag string) (string, error) {
+	code, err := lang.NormalizeTag(tag)
+	if err == nil && (code == "" || code == lang.Auto) {
+		err = errors.Errorf("invalid language %q", tag)
+	}
+	return code, err
+}
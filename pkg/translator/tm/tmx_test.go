@@ -0,0 +1,75 @@
+package tm
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestTMXRoundTrip(t *testing.T) {
+	store := openTestStore(t)
+	_ = store.Put(Query{From: "en", To: "zh", Translator: "google"}, "Hello world.", "你好，世界。")
+	_ = store.Put(Query{From: "ja", To: "en", Translator: "llm"}, "こんにちは。", "Hello.")
+
+	var buf bytes.Buffer
+	n, err := store.Export(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Errorf("expected 2 units, got %d", n)
+	}
+	for _, want := range []string{`<tmx version="1.4">`, `srclang="en"`, `<prop type="x-translator">llm</prop>`, `<seg>你好，世界。</seg>`} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("expected %s in the TMX:\n%s", want, buf.String())
+		}
+	}
+
+	other := openTestStore(t)
+	imported, skipped, err := other.Import(&buf, "baidu")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if imported != 2 || skipped != 0 {
+		t.Errorf("expected 2 imported and 0 skipped, got %d and %d", imported, skipped)
+	}
+	if translation, ok, _ := other.Lookup(Query{From: "ja", To: "en", Translator: "llm"}, "こんにちは。"); !ok || translation != "Hello." {
+		t.Errorf("expected the imported translation, got %q, %v", translation, ok)
+	}
+}
+
+func TestImport(t *testing.T) {
+	const doc = `<?xml version="1.0" encoding="UTF-8"?>
+<tmx version="1.4">
+  <header creationtool="other" segtype="sentence" o-tmf="other" adminlang="en-US" srclang="en-US" datatype="plaintext"/>
+  <body>
+    <tu>
+      <tuv xml:lang="de-DE"><seg>Guten Morgen.</seg></tuv>
+      <tuv xml:lang="en-US"><seg>Good morning.</seg></tuv>
+      <tuv xml:lang="zh-CN"><seg>早上好。</seg></tuv>
+    </tu>
+    <tu>
+      <tuv lang="EN"><seg>Thanks.</seg></tuv>
+      <tuv lang="SV"><seg>Tack.</seg></tuv>
+    </tu>
+  </body>
+</tmx>`
+	store := openTestStore(t)
+	imported, skipped, err := store.Import(strings.NewReader(doc), "google")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if imported != 2 || skipped != 1 {
+		t.Errorf("expected 2 imported and 1 skipped, got %d and %d", imported, skipped)
+	}
+	for to, want := range map[string]string{"de": "Guten Morgen.", "zh": "早上好。"} {
+		translation, ok, _ := store.Lookup(Query{From: "en", To: to, Translator: "google"}, "Good morning.")
+		if !ok || translation != want {
+			t.Errorf("expected %q in %s, got %q, %v", want, to, translation, ok)
+		}
+	}
+
+	if _, _, err := store.Import(strings.NewReader("not xml"), "google"); err == nil {
+		t.Error("expected an error for an invalid TMX")
+	}
+}
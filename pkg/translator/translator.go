@@ -1,12 +1,18 @@
 package translator
 
 import (
+	"context"
+	"fmt"
 	"io"
 
 	"github.com/gogodjzhu/word-flow/internal/buzz_error"
 	"github.com/gogodjzhu/word-flow/internal/config"
+	"github.com/gogodjzhu/word-flow/internal/lang"
 	trans_baidu "github.com/gogodjzhu/word-flow/pkg/translator/baidu"
+	trans_deepl "github.com/gogodjzhu/word-flow/pkg/translator/deepl"
+	trans_exec "github.com/gogodjzhu/word-flow/pkg/translator/exec"
 	trans_google "github.com/gogodjzhu/word-flow/pkg/translator/google"
+	trans_libre "github.com/gogodjzhu/word-flow/pkg/translator/libretranslate"
 	trans_llm "github.com/gogodjzhu/word-flow/pkg/translator/llm"
 	"github.com/gogodjzhu/word-flow/pkg/translator/types"
 	"github.com/pkg/errors"
@@ -18,34 +24,133 @@ type Translator interface {
 	Translate(text string, out io.Writer, opts *TransOptions) error
 }
 
-type Endpoint string
+// ContextTranslator is implemented by translators whose requests can be
+// cancelled. Their deadline is the timeout of the endpoint.
+type ContextTranslator interface {
+	Translator
+	TranslateContext(ctx context.Context, text string, out io.Writer, opts *TransOptions) error
+}
 
-const (
-	TransLLM    Endpoint = "llm"
-	TransGoogle Endpoint = "google"
-	TransBaidu  Endpoint = "baidu"
-)
+// Translate translates text with t, cancelled with ctx if t is a
+// ContextTranslator.
+func Translate(ctx context.Context, t Translator, text string, out io.Writer, opts *TransOptions) error {
+	if ct, ok := t.(ContextTranslator); ok {
+		return ct.TranslateContext(ctx, text, out, opts)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return t.Translate(text, out, opts)
+}
+
+// LanguageTranslator is implemented by translators supporting a subset of
+// the languages of internal/lang.
+type LanguageTranslator interface {
+	Translator
+	SupportsLanguage(code string) bool
+}
+
+// CheckLanguages validates the normalized source and target language of a
+// translation with t. Translators that are not LanguageTranslators accept all
+// languages.
+func CheckLanguages(t Translator, from, to string) error {
+	if err := lang.CheckPair(from, to); err != nil {
+		return buzz_error.InvalidInput(err.Error())
+	}
+	lt, ok := t.(LanguageTranslator)
+	if !ok {
+		return nil
+	}
+	for _, code := range []string{from, to} {
+		if !lt.SupportsLanguage(code) {
+			return buzz_error.InvalidInput(fmt.Sprintf("The translator does not support language %s", code))
+		}
+	}
+	return nil
+}
+
+// Detector is implemented by translators that tell the language of a text
+// themselves. Detect returns a code of internal/lang, or lang.Auto when the
+// language is not known.
+type Detector interface {
+	Detect(ctx context.Context, text string) (string, error)
+}
 
 type TranslatorInfo struct {
 	Name        string
 	Description string
 }
 
-func AvailableTranslators() []TranslatorInfo {
-	return []TranslatorInfo{
-		{
-			Name:        string(TransLLM),
-			Description: "AI-powered translation using Large Language Models, requires LLM API key and endpoint.",
+// builtinTranslators are registered in this order, which is also the order of
+// their sections in the config template.
+var builtinTranslators = []Registration{
+	{
+		Name:        "llm",
+		Description: "AI-powered translation using Large Language Models, requires LLM API key and endpoint.",
+		Config:      &trans_llm.ConfigSection,
+		New: func(c config.TransEndpointConfig) (Translator, error) {
+			return trans_llm.NewTranslatorLLM(c.(*trans_llm.Config)), nil
+		},
+	},
+	{
+		Name:        "google",
+		Description: "[Free] Google Translate API for translation.",
+		Config:      &trans_google.ConfigSection,
+		New: func(c config.TransEndpointConfig) (Translator, error) {
+			return trans_google.NewTranslatorGoogle(c.(*trans_google.Config)), nil
+		},
+	},
+	{
+		Name:        "baidu",
+		Description: "[API] Baidu Translate API, requires app_id and secret.",
+		Config:      &trans_baidu.ConfigSection,
+		New: func(c config.TransEndpointConfig) (Translator, error) {
+			return trans_baidu.NewTranslatorBaidu(c.(*trans_baidu.Config)), nil
+		},
+	},
+	{
+		Name:        "deepl",
+		Description: "[API] DeepL API, requires auth_key. Free keys use the free API host.",
+		Config:      &trans_deepl.ConfigSection,
+		New: func(c config.TransEndpointConfig) (Translator, error) {
+			return trans_deepl.NewTranslatorDeepL(c.(*trans_deepl.Config)), nil
 		},
-		{
-			Name:        string(TransGoogle),
-			Description: "[Free] Google Translate API for translation.",
+	},
+	{
+		Name:        "libretranslate",
+		Description: "[Self-hosted] LibreTranslate server at trans.libretranslate.url, with an optional api_key.",
+		Config:      &trans_libre.ConfigSection,
+		New: func(c config.TransEndpointConfig) (Translator, error) {
+			return trans_libre.NewTranslatorLibreTranslate(c.(*trans_libre.Config)), nil
 		},
-		{
-			Name:        string(TransBaidu),
-			Description: "[API] Baidu Translate API, requires app_id and secret.",
+	},
+	{
+		Name:        "exec",
+		Description: "External translator plugin speaking JSON over stdin/stdout, configured in trans.exec.",
+		Config:      &trans_exec.ConfigSection,
+		New: func(c config.TransEndpointConfig) (Translator, error) {
+			return trans_exec.NewTranslatorExec(c.(*trans_exec.Config)), nil
 		},
+	},
+}
+
+// AvailableTranslators returns the registered translators in order.
+func AvailableTranslators() []TranslatorInfo {
+	regs := registered()
+	infos := make([]TranslatorInfo, len(regs))
+	for i, r := range regs {
+		infos[i] = TranslatorInfo{Name: r.Name, Description: r.Description}
+	}
+	return infos
+}
+
+func AvailableEndpoints() []string {
+	translators := AvailableTranslators()
+	endpoints := make([]string, len(translators))
+	for i, t := range translators {
+		endpoints[i] = t.Name
 	}
+	return endpoints
 }
 
 func NewTranslator(cfg *config.TransConfig) (Translator, error) {
@@ -58,14 +163,9 @@ func NewTranslator(cfg *config.TransConfig) (Translator, error) {
 		return nil, errors.Wrap(err, "config validation failed")
 	}
 
-	switch Endpoint(cfg.Default) {
-	case TransLLM:
-		return trans_llm.NewTranslatorLLM(cfg.LLM), nil
-	case TransGoogle:
-		return trans_google.NewTranslatorGoogle(cfg.Google), nil
-	case TransBaidu:
-		return trans_baidu.NewTranslatorBaidu(cfg.Baidu), nil
-	default:
+	r, ok := Lookup(cfg.Default)
+	if !ok {
 		return nil, buzz_error.InvalidEndpoint(cfg.Default)
 	}
+	return r.New(endpointConfig)
 }
\ No newline at end of file
@@ -1,18 +1,22 @@
 package translator
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/gogodjzhu/word-flow/internal/config"
 	trans_baidu "github.com/gogodjzhu/word-flow/pkg/translator/baidu"
+	trans_deepl "github.com/gogodjzhu/word-flow/pkg/translator/deepl"
 	trans_google "github.com/gogodjzhu/word-flow/pkg/translator/google"
 	trans_llm "github.com/gogodjzhu/word-flow/pkg/translator/llm"
 )
 
 func TestNewTranslator_Google(t *testing.T) {
 	cfg := &config.TransConfig{
-		Default: "google",
-		Google:  &config.TransGoogleConfig{},
+		Default:   "google",
+		Endpoints: map[string]config.EndpointConfig{"google": &trans_google.Config{}},
 	}
 	trans, err := NewTranslator(cfg)
 	if err != nil {
@@ -25,8 +29,8 @@ func TestNewTranslator_Google(t *testing.T) {
 
 func TestNewTranslator_GoogleWithConfig(t *testing.T) {
 	cfg := &config.TransConfig{
-		Default: "google",
-		Google:  &config.TransGoogleConfig{},
+		Default:   "google",
+		Endpoints: map[string]config.EndpointConfig{"google": &trans_google.Config{}},
 	}
 	trans, err := NewTranslator(cfg)
 	if err != nil {
@@ -39,8 +43,8 @@ func TestNewTranslator_GoogleWithConfig(t *testing.T) {
 
 func TestNewTranslator_LLM(t *testing.T) {
 	cfg := &config.TransConfig{
-		Default: "llm",
-		LLM:     &config.TransLLMConfig{ApiKey: "test", URL: "http://test", Model: "test", MaxTokens: 100, Temperature: 0.3},
+		Default:   "llm",
+		Endpoints: map[string]config.EndpointConfig{"llm": &trans_llm.Config{ApiKey: "test", URL: "http://test", Model: "test", MaxTokens: 100, Temperature: 0.3}},
 	}
 	trans, err := NewTranslator(cfg)
 	if err != nil {
@@ -53,8 +57,8 @@ func TestNewTranslator_LLM(t *testing.T) {
 
 func TestNewTranslator_LLMValidationError(t *testing.T) {
 	cfg := &config.TransConfig{
-		Default: "llm",
-		LLM:     &config.TransLLMConfig{},
+		Default:   "llm",
+		Endpoints: map[string]config.EndpointConfig{"llm": &trans_llm.Config{}},
 	}
 	_, err := NewTranslator(cfg)
 	if err == nil {
@@ -74,8 +78,8 @@ func TestNewTranslator_UnknownEndpoint(t *testing.T) {
 
 func TestNewTranslator_Baidu(t *testing.T) {
 	cfg := &config.TransConfig{
-		Default: "baidu",
-		Baidu:   &config.TransBaiduConfig{AppID: "test_app_id", Secret: "test_secret"},
+		Default:   "baidu",
+		Endpoints: map[string]config.EndpointConfig{"baidu": &trans_baidu.Config{AppID: "test_app_id", Secret: "test_secret"}},
 	}
 	trans, err := NewTranslator(cfg)
 	if err != nil {
@@ -88,8 +92,8 @@ func TestNewTranslator_Baidu(t *testing.T) {
 
 func TestNewTranslator_BaiduValidationError(t *testing.T) {
 	cfg := &config.TransConfig{
-		Default: "baidu",
-		Baidu:   &config.TransBaiduConfig{AppID: "", Secret: ""},
+		Default:   "baidu",
+		Endpoints: map[string]config.EndpointConfig{"baidu": &trans_baidu.Config{AppID: "", Secret: ""}},
 	}
 	_, err := NewTranslator(cfg)
 	if err == nil {
@@ -99,8 +103,8 @@ func TestNewTranslator_BaiduValidationError(t *testing.T) {
 
 func TestNewTranslator_BaiduValidationErrorAppIDOnly(t *testing.T) {
 	cfg := &config.TransConfig{
-		Default: "baidu",
-		Baidu:   &config.TransBaiduConfig{AppID: "test_app_id", Secret: ""},
+		Default:   "baidu",
+		Endpoints: map[string]config.EndpointConfig{"baidu": &trans_baidu.Config{AppID: "test_app_id", Secret: ""}},
 	}
 	_, err := NewTranslator(cfg)
 	if err == nil {
@@ -110,8 +114,8 @@ func TestNewTranslator_BaiduValidationErrorAppIDOnly(t *testing.T) {
 
 func TestNewTranslator_BaiduValidationErrorSecretOnly(t *testing.T) {
 	cfg := &config.TransConfig{
-		Default: "baidu",
-		Baidu:   &config.TransBaiduConfig{AppID: "", Secret: "test_secret"},
+		Default:   "baidu",
+		Endpoints: map[string]config.EndpointConfig{"baidu": &trans_baidu.Config{AppID: "", Secret: "test_secret"}},
 	}
 	_, err := NewTranslator(cfg)
 	if err == nil {
@@ -134,7 +138,74 @@ func TestAvailableTranslators(t *testing.T) {
 	if !names["baidu"] {
 		t.Error("expected 'baidu' translator to be available")
 	}
-	if len(translators) != 3 {
-		t.Errorf("expected 3 available translators, got %d", len(translators))
+	if !names["deepl"] {
+		t.Error("expected 'deepl' translator to be available")
+	}
+	if !names["libretranslate"] {
+		t.Error("expected 'libretranslate' translator to be available")
+	}
+	if !names["exec"] {
+		t.Error("expected 'exec' translator to be available")
+	}
+	if len(translators) != 6 {
+		t.Errorf("expected 6 available translators, got %d", len(translators))
+	}
+}
+
+func TestBuiltinConfigSections(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	content := `version: v1
+trans:
+  default: deepl
+  deepl:
+    auth_key: secret:fx
+    formality: less
+`
+	if err := os.WriteFile(configFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("WORDFLOW_TRANS_BAIDU_APP_ID", "env-id")
+	cfg, err := config.LoadConfig(configFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := config.ValidateForTrans(cfg); err != nil {
+		t.Errorf("ValidateForTrans() error = %v", err)
+	}
+	deepl, ok := cfg.Trans.Endpoints["deepl"].(*trans_deepl.Config)
+	if !ok || deepl.AuthKey != "secret:fx" || deepl.Formality != "less" || deepl.To != "zh" {
+		t.Errorf("deepl = %#v, want the configured section with its defaults", cfg.Trans.Endpoints["deepl"])
+	}
+	baidu, ok := cfg.Trans.Endpoints["baidu"].(*trans_baidu.Config)
+	if !ok || baidu.AppID != "env-id" || baidu.Concurrency != 2 {
+		t.Errorf("baidu = %#v, want the defaults and the app_id from the environment", cfg.Trans.Endpoints["baidu"])
+	}
+	for _, section := range []string{"\n  # llm:\n", "\n  google:\n", "\n  # libretranslate:\n", "\n  # exec:\n"} {
+		if !strings.Contains(config.ConfigTemplate(), section) {
+			t.Errorf("config template misses %q", section)
+		}
+	}
+}
+
+func TestCheckLanguages(t *testing.T) {
+	google := trans_google.NewTranslatorGoogle(&trans_google.Config{})
+	llm := trans_llm.NewTranslatorLLM(&trans_llm.Config{})
+	tests := []struct {
+		name     string
+		t        Translator
+		from, to string
+		wantErr  bool
+	}{
+		{name: "google", t: google, from: "auto", to: "zh-TW"},
+		{name: "google classical chinese", t: google, from: "auto", to: "lzh", wantErr: true},
+		{name: "llm classical chinese", t: llm, from: "zh", to: "lzh"},
+		{name: "same language", t: llm, from: "en", to: "en", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := CheckLanguages(tt.t, tt.from, tt.to); (err != nil) != tt.wantErr {
+				t.Errorf("CheckLanguages() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
 	}
 }
\ No newline at end of file
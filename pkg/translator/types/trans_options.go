@@ -1,6 +1,42 @@
 package types
 
+import (
+	"github.com/gogodjzhu/word-flow/internal/config"
+	"github.com/gogodjzhu/word-flow/pkg/translator/glossary"
+)
+
 type TransOptions struct {
 	Ref      bool
 	NoStream bool
+	// From and To are normalized language codes of internal/lang, empty for
+	// the defaults of the translator's configuration.
+	From string
+	To   string
+	// Terms are the glossary terms of the text, set for translators that
+	// follow them on their own.
+	Terms []glossary.Term
+}
+
+// Languages returns the source and target language of the options, falling
+// back to the configured defaults and then to auto and zh.
+func (o *TransOptions) Languages(defaults config.LanguageDefaults) (from, to string) {
+	if o != nil {
+		from, to = o.From, o.To
+	}
+	if defaults != nil {
+		defaultFrom, defaultTo := defaults.Languages()
+		if from == "" {
+			from = defaultFrom
+		}
+		if to == "" {
+			to = defaultTo
+		}
+	}
+	if from == "" {
+		from = config.DefaultSourceLanguage
+	}
+	if to == "" {
+		to = config.DefaultTargetLanguage
+	}
+	return from, to
 }
\ No newline at end of file
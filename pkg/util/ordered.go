@@ -0,0 +1,80 @@
+package util
+
+import (
+	"context"
+	"sync"
+)
+
+// Ordered runs do for the indexes 0 to n-1 with at most workers of them at
+// a time, and calls emit for each index in order, as soon as do is done with
+// it and with every index before it. The first error, of do or emit, cancels
+// the context of the other calls and is returned once the running ones are
+// done. Nothing is emitted from the first failed or cancelled index on.
+func Ordered(ctx context.Context, n, workers int, do func(ctx context.Context, i int) error, emit func(i int) error) error {
+	if workers < 1 {
+		workers = 1
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg    sync.WaitGroup
+		once  sync.Once
+		first error
+	)
+	fail := func(err error) {
+		once.Do(func() {
+			first = err
+			cancel()
+		})
+	}
+	errs := make([]error, n)
+	done := make([]chan struct{}, n)
+	for i := range done {
+		done[i] = make(chan struct{})
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		sem := make(chan struct{}, workers)
+		for i := 0; i < n; i++ {
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				// The indexes left are not run.
+				for ; i < n; i++ {
+					errs[i] = ctx.Err()
+					close(done[i])
+				}
+				return
+			}
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				if errs[i] = do(ctx, i); errs[i] != nil {
+					fail(errs[i])
+				}
+				<-sem
+				close(done[i])
+			}(i)
+		}
+	}()
+
+	var err error
+	for i := 0; i < n; i++ {
+		<-done[i]
+		if err = errs[i]; err != nil {
+			break
+		}
+		if err = emit(i); err != nil {
+			fail(err)
+			break
+		}
+	}
+	cancel()
+	wg.Wait()
+	if first != nil {
+		return first
+	}
+	return err
+}
@@ -0,0 +1,117 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestOrderedEmitsInOrder(t *testing.T) {
+	var running, peak int32
+	var emitted []int
+	err := Ordered(context.Background(), 8, 3, func(ctx context.Context, i int) error {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		// Later indexes finish first.
+		time.Sleep(time.Duration(8-i) * time.Millisecond)
+		return nil
+	}, func(i int) error {
+		emitted = append(emitted, i)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Ordered() error = %v", err)
+	}
+	if want := []int{0, 1, 2, 3, 4, 5, 6, 7}; !reflect.DeepEqual(emitted, want) {
+		t.Errorf("emitted %v, want %v", emitted, want)
+	}
+	if peak > 3 {
+		t.Errorf("%d calls ran at once, want at most 3", peak)
+	}
+}
+
+func TestOrderedStreamsPrefix(t *testing.T) {
+	release := make(chan struct{})
+	first := make(chan struct{})
+	err := Ordered(context.Background(), 2, 2, func(ctx context.Context, i int) error {
+		if i == 1 {
+			// The second call waits until the first result is emitted.
+			<-release
+		}
+		return nil
+	}, func(i int) error {
+		if i == 0 {
+			close(first)
+			close(release)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Ordered() error = %v", err)
+	}
+	select {
+	case <-first:
+	default:
+		t.Error("the first result was not emitted")
+	}
+}
+
+func TestOrderedStopsAtError(t *testing.T) {
+	failure := errors.New("failed")
+	var emitted []int
+	var cancelled int32
+	err := Ordered(context.Background(), 4, 4, func(ctx context.Context, i int) error {
+		switch i {
+		case 1:
+			return failure
+		case 2, 3:
+			<-ctx.Done()
+			atomic.AddInt32(&cancelled, 1)
+			return ctx.Err()
+		}
+		return nil
+	}, func(i int) error {
+		emitted = append(emitted, i)
+		return nil
+	})
+	if err != failure {
+		t.Errorf("Ordered() error = %v, want %v", err, failure)
+	}
+	if want := []int{0}; !reflect.DeepEqual(emitted, want) {
+		t.Errorf("emitted %v, want %v", emitted, want)
+	}
+	if cancelled != 2 {
+		t.Errorf("%d calls were cancelled, want 2", cancelled)
+	}
+}
+
+func TestOrderedCancelsOnLaterError(t *testing.T) {
+	failure := errors.New("failed")
+	var emitted []int
+	err := Ordered(context.Background(), 3, 3, func(ctx context.Context, i int) error {
+		if i == 2 {
+			return failure
+		}
+		// The earlier calls only end when they are cancelled.
+		<-ctx.Done()
+		return ctx.Err()
+	}, func(i int) error {
+		emitted = append(emitted, i)
+		return nil
+	})
+	if err != failure {
+		t.Errorf("Ordered() error = %v, want %v", err, failure)
+	}
+	if len(emitted) != 0 {
+		t.Errorf("emitted %v after the error, want nothing", emitted)
+	}
+}
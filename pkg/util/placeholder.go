@@ -0,0 +1,71 @@
+package util
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// Placeholders masks the parts of a text translation must leave alone,
+// replacing them with numbered placeholders such as {{1}} that machine
+// translation keeps. Placeholders of different prefixes, e.g. {{1}} and
+// {{m1}}, can be nested without mixing up.
+type Placeholders struct {
+	prefix string
+	values []string
+	re     *regexp.Regexp
+}
+
+// NewPlaceholders returns placeholders written {{<prefix><n>}}.
+func NewPlaceholders(prefix string) *Placeholders {
+	return &Placeholders{
+		prefix: prefix,
+		// Translators add spaces or turn the braces into full-width ones.
+		re: regexp.MustCompile(`[{｛]{2}\s*` + regexp.QuoteMeta(prefix) + `(\d+)\s*[}｝]{2}`),
+	}
+}
+
+// Add returns the placeholder of value.
+func (p *Placeholders) Add(value string) string {
+	p.values = append(p.values, value)
+	return fmt.Sprintf("{{%s%d}}", p.prefix, len(p.values))
+}
+
+// Len returns the number of placeholders.
+func (p *Placeholders) Len() int {
+	return len(p.values)
+}
+
+// Restore replaces the placeholders of s with their values.
+func (p *Placeholders) Restore(s string) string {
+	return p.RestoreFunc(s, func(i int, value string) string { return value })
+}
+
+// RestoreFunc replaces the placeholders of s with replace of their index and
+// value. Unknown placeholders are left as they are.
+func (p *Placeholders) RestoreFunc(s string, replace func(i int, value string) string) string {
+	return p.re.ReplaceAllStringFunc(s, func(match string) string {
+		n, err := strconv.Atoi(p.re.FindStringSubmatch(match)[1])
+		if err != nil || n < 1 || n > len(p.values) {
+			return match
+		}
+		return replace(n-1, p.values[n-1])
+	})
+}
+
+// Missing returns the values whose placeholder is not in s.
+func (p *Placeholders) Missing(s string) []string {
+	found := make(map[int]bool)
+	for _, m := range p.re.FindAllStringSubmatch(s, -1) {
+		if n, err := strconv.Atoi(m[1]); err == nil {
+			found[n] = true
+		}
+	}
+	var missing []string
+	for i, value := range p.values {
+		if !found[i+1] {
+			missing = append(missing, value)
+		}
+	}
+	return missing
+}
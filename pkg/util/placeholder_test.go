@@ -0,0 +1,20 @@
+package util
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPlaceholders(t *testing.T) {
+	p := NewPlaceholders("m")
+	masked := "Run " + p.Add("`make`") + " in " + p.Add("/tmp") + "."
+	if masked != "Run {{m1}} in {{m2}}." {
+		t.Fatalf("unexpected masked text %q", masked)
+	}
+	if got := p.Restore("Führe {{ m1 }} in ｛｛m2｝｝ aus. {{1}} {{m3}}"); got != "Führe `make` in /tmp aus. {{1}} {{m3}}" {
+		t.Errorf("unexpected restored text %q", got)
+	}
+	if got := p.Missing("Führe {{m2}} aus."); !reflect.DeepEqual(got, []string{"`make`"}) {
+		t.Errorf("got missing %v", got)
+	}
+}
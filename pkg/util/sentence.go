@@ -0,0 +1,63 @@
+package util
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Sentence is a sentence of a text and the white space before it.
+type Sentence struct {
+	Space string
+	Text  string
+}
+
+// cjkSentenceEndRe matches the full stops of Chinese and Japanese, which are
+// not followed by a space, with the closing quotes after them.
+var cjkSentenceEndRe = regexp.MustCompile(`[。！？]+[」』”’）]*`)
+
+// SplitSentences splits text into sentences like SegmentText, and after the
+// full stops of Chinese and Japanese, with the white space around them kept
+// apart.
+func SplitSentences(text string) []Sentence {
+	var sentences []Sentence
+	space := ""
+	for _, s := range splitCJKSentences(SegmentText(text)) {
+		trimmed := strings.TrimSpace(s)
+		if trimmed == "" {
+			space += s
+			continue
+		}
+		i := strings.Index(s, trimmed)
+		sentences = append(sentences, Sentence{Space: space + s[:i], Text: trimmed})
+		space = s[i+len(trimmed):]
+	}
+	return sentences
+}
+
+func splitCJKSentences(segments []string) []string {
+	var result []string
+	for _, s := range segments {
+		prev := 0
+		for _, loc := range cjkSentenceEndRe.FindAllStringIndex(s, -1) {
+			result = append(result, s[prev:loc[1]])
+			prev = loc[1]
+		}
+		if prev < len(s) {
+			result = append(result, s[prev:])
+		}
+	}
+	return result
+}
+
+// SentenceSpace returns what goes before the translation of a sentence that
+// followed space: its line breaks, or a space unless the target language is
+// written without.
+func SentenceSpace(space, to string) string {
+	if n := strings.Count(space, "\n"); n > 0 {
+		return strings.Repeat("\n", n)
+	}
+	if space == "" || to == "zh" || to == "zh-TW" || to == "ja" {
+		return ""
+	}
+	return " "
+}
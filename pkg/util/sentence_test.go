@@ -0,0 +1,36 @@
+package util
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitSentences(t *testing.T) {
+	got := SplitSentences("Hello there.  How are you?\n\nFine.")
+	want := []Sentence{{"", "Hello there."}, {"  ", "How are you?"}, {"\n\n", "Fine."}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SplitSentences() = %q, want %q", got, want)
+	}
+
+	got = SplitSentences("你好。“你好吗？”\n很好！")
+	want = []Sentence{{"", "你好。"}, {"", "“你好吗？”"}, {"\n", "很好！"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SplitSentences() = %q, want %q", got, want)
+	}
+}
+
+func TestSentenceSpace(t *testing.T) {
+	tests := []struct {
+		space, to, want string
+	}{
+		{"", "en", ""},
+		{"  ", "de", " "},
+		{" ", "ja", ""},
+		{" \n\n ", "zh", "\n\n"},
+	}
+	for _, tt := range tests {
+		if got := SentenceSpace(tt.space, tt.to); got != tt.want {
+			t.Errorf("SentenceSpace(%q, %q) = %q, want %q", tt.space, tt.to, got, tt.want)
+		}
+	}
+}